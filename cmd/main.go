@@ -20,19 +20,22 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	"github.com/aryasoni98/wooak/internal/adapters/cli"
+	"github.com/aryasoni98/wooak/internal/adapters/data/settings_file"
 	"github.com/aryasoni98/wooak/internal/adapters/data/ssh_config_file"
 	"github.com/aryasoni98/wooak/internal/logger"
 
 	"github.com/aryasoni98/wooak/internal/adapters/ui"
-	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
-	securityDomain "github.com/aryasoni98/wooak/internal/core/domain/security"
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
 	"github.com/aryasoni98/wooak/internal/core/services"
 	aiService "github.com/aryasoni98/wooak/internal/core/services/ai"
 	"github.com/aryasoni98/wooak/internal/core/services/monitoring"
 	securityService "github.com/aryasoni98/wooak/internal/core/services/security"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 var (
@@ -55,11 +58,117 @@ func main() {
 	monitoringService.Start()
 	defer monitoringService.Stop()
 
-	// Get metrics port from environment variable, default to 9091
-	metricsPort := os.Getenv("METRICS_PORT")
-	if metricsPort == "" {
-		metricsPort = "9091" // Use 9091 to avoid conflict with Prometheus
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Errorw("failed to get user home directory", "error", err)
+		//nolint:gocritic // exitAfterDefer: ensure immediate exit on unrecoverable error
+		os.Exit(1)
+	}
+
+	// Load ~/.wooak/config.yaml (or $WOOAK_CONFIG) with environment overrides
+	settingsPath := filepath.Join(home, ".wooak", "config.yaml")
+	if p := os.Getenv(settings_file.EnvConfigPath); p != "" {
+		settingsPath = p
+	}
+	settingsStore := settings_file.NewStore(log, settings_file.ExpandPath(settingsPath, home))
+	appSettings, err := settingsStore.Load()
+	if err != nil {
+		// Start with the defaults; the store will not save over the broken
+		// file, and the watcher picks it up once it is fixed.
+		log.Warnw("failed to load settings, using defaults", "path", settingsStore.Path(), "error", err)
+		_, _ = fmt.Fprintf(os.Stderr, "wooak: %v; using default settings\n", err)
+		appSettings = settingsStore.Get()
+	}
+
+	sshConfigFile := settings_file.ExpandPath(appSettings.Paths.SSHConfig, home)
+	metaDataFile := settings_file.ExpandPath(appSettings.Paths.Metadata, home)
+
+	serverRepo := ssh_config_file.NewRepository(log, sshConfigFile, metaDataFile)
+
+	// Set monitoring for repository
+	if repo, ok := serverRepo.(*ssh_config_file.Repository); ok {
+		repo.SetMonitoring(monitoringService)
+	}
+
+	// Initialize security service
+	securityPolicy := &appSettings.Security
+	securitySvc := securityService.NewSecurityServiceWithLogger(securityPolicy, log)
+
+	// Initialize AI service
+	aiConfig := &appSettings.AI
+	aiSvc := aiService.NewAIServiceWithLogger(aiConfig, log)
+
+	// Set monitoring for AI service
+	aiSvc.SetMonitoring(monitoringService)
+
+	// The search index follows server edits so natural language search only
+	// re-embeds servers that changed.
+	searchIndex := aiService.NewEmbeddingIndex(aiSvc, filepath.Join(home, ".wooak", "ai", "embeddings.json"), log)
+	aiSvc.SetSearchIndex(searchIndex)
+	aiSvc.SetSessionStore(aiService.NewSessionStore(filepath.Join(home, ".wooak", "ai", "sessions"), log))
+	aiSvc.SetDiskCache(aiService.NewDiskCache(filepath.Join(home, ".wooak", "ai", "cache"), log))
+	aiSvc.SetUsageLedger(aiService.NewUsageLedger(filepath.Join(home, ".wooak", "ai", "usage.json"), log))
+
+	// Prompt templates in ~/.wooak/prompts override the built-in ones; files
+	// that fail validation are skipped.
+	promptTemplates, err := aiService.LoadPromptTemplates(filepath.Join(home, ".wooak", "prompts"))
+	if err != nil {
+		log.Warnw("some prompt templates were not loaded", "error", err)
+	}
+	aiSvc.SetPromptTemplates(promptTemplates)
+
+	// Server edits update the search index and drop cached AI answers about
+	// the changed server.
+	serverService := services.NewServerServiceWithPolicy(log, serverRepo, securitySvc, searchIndex, aiSvc)
+
+	// Lint what servers inherit from Host * and Match blocks as well.
+	securitySvc.SetEffectiveConfigSource(serverService.GetEffectiveConfigs)
+
+	tui := ui.NewTUI(log, serverService, securitySvc, aiSvc, settingsStore, version, gitCommit)
+
+	rootCmd := &cobra.Command{
+		Use:     ui.AppName,
+		Short:   "Wooak SSH server picker TUI",
+		Version: version,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Reload settings while the TUI runs
+			watchCtx, stopWatch := context.WithCancel(context.Background())
+			defer stopWatch()
+			go settingsStore.Watch(watchCtx, settings_file.DefaultWatchInterval)
+
+			stopMetrics := startMetricsServer(log, monitoringService, appSettings.Metrics)
+			defer stopMetrics()
+			return tui.Run()
+		},
+	}
+	rootCmd.SetVersionTemplate(fmt.Sprintf("Wooak version %s (commit: %s)\n", version, gitCommit))
+	rootCmd.SilenceUsage = true
+
+	rootCmd.AddCommand(
+		cli.NewListCommand(serverService),
+		cli.NewConnectCommand(serverService),
+		cli.NewAddCommand(serverService, ui.ValidateField),
+		cli.NewEditCommand(serverService, ui.ValidateField),
+		cli.NewRemoveCommand(serverService),
+		cli.NewTagCommand(serverService),
+		cli.NewPinCommand(serverService),
+		cli.NewKeysCommand(serverService, securitySvc.AuditKeys),
+	)
+
+	if err := rootCmd.Execute(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// startMetricsServer exposes /metrics and /health for the interactive TUI and
+// returns a function that shuts the server down gracefully. Non-interactive
+// subcommands do not start it so they never compete for the metrics port.
+func startMetricsServer(log *zap.SugaredLogger, monitoringService *monitoring.MonitoringService, cfg settings.MetricsSettings) func() {
+	if !cfg.Enabled {
+		return func() {}
 	}
+	metricsPort := strconv.Itoa(cfg.Port)
 
 	// Start HTTP server for metrics endpoint
 	metricsServer := &http.Server{
@@ -97,58 +206,11 @@ func main() {
 	}()
 
 	// Ensure graceful shutdown of metrics server
-	defer func() {
+	return func() {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
 			log.Errorw("Metrics server shutdown error", "error", err)
 		}
-	}()
-
-	home, err := os.UserHomeDir()
-	if err != nil {
-		log.Errorw("failed to get user home directory", "error", err)
-		//nolint:gocritic // exitAfterDefer: ensure immediate exit on unrecoverable error
-		os.Exit(1)
-	}
-	sshConfigFile := filepath.Join(home, ".ssh", "config")
-	metaDataFile := filepath.Join(home, ".wooak", "metadata.json")
-
-	serverRepo := ssh_config_file.NewRepository(log, sshConfigFile, metaDataFile)
-
-	// Set monitoring for repository
-	if repo, ok := serverRepo.(*ssh_config_file.Repository); ok {
-		repo.SetMonitoring(monitoringService)
-	}
-
-	serverService := services.NewServerService(log, serverRepo)
-
-	// Initialize security service
-	securityPolicy := securityDomain.DefaultSecurityPolicy()
-	securitySvc := securityService.NewSecurityServiceWithLogger(securityPolicy, log)
-
-	// Initialize AI service
-	aiConfig := aiDomain.DefaultAIConfig()
-	aiSvc := aiService.NewAIServiceWithLogger(aiConfig, log)
-
-	// Set monitoring for AI service
-	aiSvc.SetMonitoring(monitoringService)
-
-	tui := ui.NewTUI(log, serverService, securitySvc, aiSvc, version, gitCommit)
-
-	rootCmd := &cobra.Command{
-		Use:     ui.AppName,
-		Short:   "Wooak SSH server picker TUI",
-		Version: version,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return tui.Run()
-		},
-	}
-	rootCmd.SetVersionTemplate(fmt.Sprintf("Wooak version %s (commit: %s)\n", version, gitCommit))
-	rootCmd.SilenceUsage = true
-
-	if err := rootCmd.Execute(); err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
 	}
 }
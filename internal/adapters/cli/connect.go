@@ -0,0 +1,222 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/ports"
+	"github.com/spf13/cobra"
+)
+
+// errSelectionCancelled is returned when the user leaves the picker without choosing.
+var errSelectionCancelled = errors.New("selection cancelled")
+
+// NewConnectCommand returns the `connect <alias|query>` subcommand. The target
+// is resolved by exact alias, then by secondary alias, then by the same query
+// matching used by the TUI search bar. The connection itself goes through
+// ServerService.SSH so access validation and usage tracking still apply.
+func NewConnectCommand(serverService ports.ServerService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "connect <alias|query>",
+		Short: "Connect to a server by alias, secondary alias or search query",
+		Example: `  wooak connect prod-web
+  wooak connect staging`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := resolveServer(serverService, args[0], cmd.InOrStdin(), cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			return serverService.SSH(server.Alias, func(message string) {
+				// ssh puts the terminal in raw mode, so lines need explicit carriage returns.
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "\r\nwooak: %s\r\n", message)
+			})
+		},
+	}
+}
+
+// resolveServer finds the single server referenced by target, prompting the
+// user to pick one when the query is ambiguous.
+func resolveServer(serverService ports.ServerService, target string, in io.Reader, out io.Writer) (domain.Server, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return domain.Server{}, errors.New("server alias or query is required")
+	}
+
+	servers, err := serverService.ListServers("")
+	if err != nil {
+		return domain.Server{}, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	if server, ok := findByAlias(servers, target); ok {
+		return server, nil
+	}
+
+	matches, err := serverService.ListServers(target)
+	if err != nil {
+		return domain.Server{}, fmt.Errorf("failed to search servers (query: %q): %w", target, err)
+	}
+	matches = appendAliasMatches(matches, servers, target)
+	if len(matches) == 0 {
+		matches = fuzzyMatches(servers, target)
+	}
+
+	switch len(matches) {
+	case 0:
+		return domain.Server{}, fmt.Errorf("no server matches %q", target)
+	case 1:
+		return matches[0], nil
+	default:
+		return pickServer(matches, in, out)
+	}
+}
+
+// findByAlias returns the server whose primary alias, or failing that one of
+// its secondary aliases, equals target exactly.
+func findByAlias(servers []domain.Server, target string) (domain.Server, bool) {
+	for _, s := range servers {
+		if s.Alias == target {
+			return s, true
+		}
+	}
+	for _, s := range servers {
+		for _, a := range s.Aliases {
+			if a == target {
+				return s, true
+			}
+		}
+	}
+	return domain.Server{}, false
+}
+
+// appendAliasMatches adds servers whose primary alias contains the query but
+// were not already returned by the service search.
+func appendAliasMatches(matches, servers []domain.Server, query string) []domain.Server {
+	seen := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		seen[m.Alias] = true
+	}
+	q := strings.ToLower(query)
+	for _, s := range servers {
+		if !seen[s.Alias] && strings.Contains(strings.ToLower(s.Alias), q) {
+			matches = append(matches, s)
+			seen[s.Alias] = true
+		}
+	}
+	return matches
+}
+
+// fuzzyMatches returns the servers whose aliases contain the query as a
+// case-insensitive subsequence, best match first. A single best match is
+// returned on its own so that e.g. "prdweb" connects to "prod-web" directly.
+func fuzzyMatches(servers []domain.Server, query string) []domain.Server {
+	type scored struct {
+		server domain.Server
+		score  int
+	}
+	var results []scored
+	for _, s := range servers {
+		best, ok := fuzzyScore(s.Alias, query)
+		for _, a := range s.Aliases {
+			if score, found := fuzzyScore(a, query); found && (!ok || score < best) {
+				best, ok = score, true
+			}
+		}
+		if ok {
+			results = append(results, scored{server: s, score: best})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score < results[j].score
+	})
+
+	if len(results) > 1 && results[0].score < results[1].score {
+		results = results[:1]
+	}
+	matches := make([]domain.Server, 0, len(results))
+	for _, r := range results {
+		matches = append(matches, r.server)
+	}
+	return matches
+}
+
+// fuzzyScore reports whether query is a case-insensitive subsequence of
+// candidate. Lower scores are better: every skipped character costs one
+// point, except separators before the next matched character, so matches
+// on word starts and shorter candidates rank first.
+func fuzzyScore(candidate, query string) (int, bool) {
+	c := []rune(strings.ToLower(candidate))
+	q := []rune(strings.ToLower(query))
+	score, i := 0, 0
+	for _, r := range q {
+		for i < len(c) && c[i] != r {
+			if !strings.ContainsRune("-_. ", c[i]) {
+				score++
+			}
+			i++
+		}
+		if i == len(c) {
+			return 0, false
+		}
+		i++
+	}
+	return score + len(c) - i, true
+}
+
+// pickServer renders a numbered list and reads the user's choice from in.
+func pickServer(servers []domain.Server, in io.Reader, out io.Writer) (domain.Server, error) {
+	_, _ = fmt.Fprintf(out, "Multiple servers match:\n")
+	for i, s := range servers {
+		dest := s.Host
+		if s.User != "" {
+			dest = s.User + "@" + dest
+		}
+		line := fmt.Sprintf("  %2d) %-20s %s:%s", i+1, s.Alias, dest, portString(s.Port))
+		if len(s.Tags) > 0 {
+			line += "  [" + strings.Join(s.Tags, ", ") + "]"
+		}
+		_, _ = fmt.Fprintln(out, line)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		_, _ = fmt.Fprintf(out, "Select server [1-%d] (empty to cancel): ", len(servers))
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return domain.Server{}, errSelectionCancelled
+		}
+
+		if n, convErr := strconv.Atoi(input); convErr == nil && n >= 1 && n <= len(servers) {
+			return servers[n-1], nil
+		}
+		if server, ok := findByAlias(servers, input); ok {
+			return server, nil
+		}
+
+		if err != nil {
+			return domain.Server{}, errSelectionCancelled
+		}
+		_, _ = fmt.Fprintf(out, "Invalid selection %q\n", input)
+	}
+}
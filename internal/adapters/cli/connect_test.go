@@ -0,0 +1,142 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+)
+
+func connectServers() []domain.Server {
+	return []domain.Server{
+		{Alias: "web-1", Aliases: []string{"web-1", "www"}, Host: "10.0.0.1", Tags: []string{"prod"}},
+		{Alias: "web-2", Aliases: []string{"web-2"}, Host: "10.0.0.2", Tags: []string{"prod"}},
+		{Alias: "db", Aliases: []string{"db"}, Host: "db.internal", User: "postgres"},
+	}
+}
+
+func TestResolveServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		input     string
+		wantAlias string
+		wantErr   bool
+	}{
+		{name: "exact alias", target: "web-2", wantAlias: "web-2"},
+		{name: "secondary alias", target: "www", wantAlias: "web-1"},
+		{name: "unique query", target: "postgres", wantAlias: "db"},
+		{name: "ambiguous tag picks by number", target: "prod", input: "2\n", wantAlias: "web-2"},
+		{name: "ambiguous alias substring picks by alias", target: "web", input: "web-1\n", wantAlias: "web-1"},
+		{name: "invalid then valid selection", target: "prod", input: "9\n1\n", wantAlias: "web-1"},
+		{name: "cancelled selection", target: "prod", input: "\n", wantErr: true},
+		{name: "no match", target: "nothing", wantErr: true},
+		{name: "empty target", target: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockServerService{servers: connectServers()}
+			var out bytes.Buffer
+			got, err := resolveServer(svc, tt.target, strings.NewReader(tt.input), &out)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got server %q", got.Alias)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Alias != tt.wantAlias {
+				t.Errorf("resolved %q, want %q", got.Alias, tt.wantAlias)
+			}
+		})
+	}
+}
+
+func TestResolveServer_Fuzzy(t *testing.T) {
+	servers := []domain.Server{
+		{Alias: "prod-web", Aliases: []string{"prod-web"}, Host: "10.0.1.1"},
+		{Alias: "prod-worker", Aliases: []string{"prod-worker"}, Host: "10.0.1.2"},
+		{Alias: "stage-web", Aliases: []string{"stage-web", "sweb"}, Host: "10.0.2.1"},
+		{Alias: "app-1", Aliases: []string{"app-1"}, Host: "10.0.3.1"},
+		{Alias: "api-1", Aliases: []string{"api-1"}, Host: "10.0.3.2"},
+	}
+	tests := []struct {
+		name      string
+		target    string
+		input     string
+		wantAlias string
+		wantErr   bool
+	}{
+		{name: "subsequence", target: "prdweb", wantAlias: "prod-web"},
+		{name: "case insensitive", target: "PRDWRK", wantAlias: "prod-worker"},
+		{name: "best score wins", target: "pw", wantAlias: "prod-web"},
+		{name: "secondary alias", target: "swb", wantAlias: "stage-web"},
+		{name: "tie prompts", target: "a1", input: "2\n", wantAlias: "api-1"},
+		{name: "no subsequence", target: "wdp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockServerService{servers: servers}
+			var out bytes.Buffer
+			got, err := resolveServer(svc, tt.target, strings.NewReader(tt.input), &out)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got server %q", got.Alias)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Alias != tt.wantAlias {
+				t.Errorf("resolved %q, want %q", got.Alias, tt.wantAlias)
+			}
+		})
+	}
+}
+
+func TestPickServer_EOFCancels(t *testing.T) {
+	var out bytes.Buffer
+	_, err := pickServer(connectServers(), strings.NewReader(""), &out)
+	if !errors.Is(err, errSelectionCancelled) {
+		t.Errorf("expected errSelectionCancelled, got %v", err)
+	}
+	if !strings.Contains(out.String(), "web-1") || !strings.Contains(out.String(), "postgres@db.internal") {
+		t.Errorf("picker output missing servers:\n%s", out.String())
+	}
+}
+
+func TestConnectCommand_UsesService(t *testing.T) {
+	svc := &mockServerService{servers: connectServers()}
+	cmd := NewConnectCommand(svc)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"www"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	if len(svc.sshCalls) != 1 || svc.sshCalls[0] != "web-1" {
+		t.Errorf("expected SSH to be called with primary alias web-1, got %v", svc.sshCalls)
+	}
+}
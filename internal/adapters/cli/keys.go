@@ -0,0 +1,212 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
+	"github.com/aryasoni98/wooak/internal/core/ports"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// KeyAuditor checks the SSH keys in ~/.ssh and those the given servers use
+// against the security policy. It is injected so the CLI and the TUI run the
+// same scan.
+type KeyAuditor func(servers []domain.Server) []security.KeyAuditEntry
+
+// keyRecord is the machine-readable representation of an audited key.
+type keyRecord struct {
+	Path        string   `json:"path" yaml:"path"`
+	Type        string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Size        int      `json:"size,omitempty" yaml:"size,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	Comment     string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Passphrase  bool     `json:"passphrase" yaml:"passphrase"`
+	Mode        string   `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Servers     []string `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Valid       bool     `json:"valid" yaml:"valid"`
+	Issues      []string `json:"issues,omitempty" yaml:"issues,omitempty"`
+	Warnings    []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	Error       string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func toKeyRecord(e security.KeyAuditEntry) keyRecord {
+	r := keyRecord{
+		Path:    e.Path,
+		Mode:    e.Mode,
+		Servers: e.Servers,
+		Valid:   !e.HasIssues(),
+		Error:   e.Error,
+	}
+	if v := e.Validation; v != nil {
+		r.Issues, r.Warnings = v.Issues, v.Warnings
+		if info := v.KeyInfo; info != nil {
+			r.Type, r.Size, r.Fingerprint, r.Comment = info.Type, info.Size, info.Fingerprint, info.Comment
+			r.Passphrase = info.Encrypted
+		}
+	}
+	return r
+}
+
+// NewKeysCommand returns the `keys` command group.
+func NewKeysCommand(serverService ports.ServerService, audit KeyAuditor) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Inspect SSH keys",
+	}
+	cmd.AddCommand(newKeysAuditCommand(serverService, audit))
+	return cmd
+}
+
+// newKeysAuditCommand returns the `keys audit` subcommand, which lists the
+// private keys in ~/.ssh and every IdentityFile of the configured servers
+// with their type, size, fingerprint, passphrase, permissions and users.
+func newKeysAuditCommand(serverService ports.ServerService, audit KeyAuditor) *cobra.Command {
+	var output string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Check local and referenced SSH keys against the security policy",
+		Example: `  wooak keys audit
+  wooak keys audit -o json
+  wooak keys audit --strict`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+
+			servers, err := serverService.ListServers("")
+			if err != nil {
+				return fmt.Errorf("failed to list servers: %w", err)
+			}
+			entries := audit(servers)
+
+			if err := writeKeyAudit(cmd.OutOrStdout(), entries, output); err != nil {
+				return err
+			}
+			if strict {
+				failed := 0
+				for i := range entries {
+					if entries[i].HasIssues() {
+						failed++
+					}
+				}
+				if failed > 0 {
+					return fmt.Errorf("%d of %d keys have issues", failed, len(entries))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", OutputTable, "output format: table, json or yaml")
+	cmd.Flags().BoolVar(&strict, "strict", false, "exit with an error if any key has issues")
+
+	return cmd
+}
+
+// writeKeyAudit renders entries to w in the requested format.
+func writeKeyAudit(w io.Writer, entries []security.KeyAuditEntry, format string) error {
+	records := make([]keyRecord, 0, len(entries))
+	for _, e := range entries {
+		records = append(records, toKeyRecord(e))
+	}
+
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(records); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return writeKeyTable(w, records)
+	}
+}
+
+func writeKeyTable(w io.Writer, records []keyRecord) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "No SSH keys found.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STATUS\tPATH\tTYPE\tBITS\tFINGERPRINT\tPASSPHRASE\tMODE\tSERVERS")
+	for _, r := range records {
+		status := "ok"
+		if !r.Valid {
+			status = "FAIL"
+		} else if len(r.Warnings) > 0 {
+			status = "warn"
+		}
+		bits := "-"
+		if r.Size > 0 {
+			bits = strconv.Itoa(r.Size)
+		}
+		passphrase := "-"
+		if r.Type != "" {
+			passphrase = "no"
+			if r.Passphrase {
+				passphrase = "yes"
+			}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			status,
+			r.Path,
+			valueOrDash(r.Type),
+			bits,
+			valueOrDash(r.Fingerprint),
+			passphrase,
+			valueOrDash(r.Mode),
+			valueOrDash(strings.Join(r.Servers, ",")),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Explain the findings below the table.
+	for _, r := range records {
+		var findings []string
+		if r.Error != "" {
+			findings = append(findings, r.Error)
+		}
+		findings = append(findings, r.Issues...)
+		findings = append(findings, r.Warnings...)
+		if len(findings) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "\n%s:\n", r.Path)
+		for _, finding := range findings {
+			_, _ = fmt.Fprintf(w, "  - %s\n", finding)
+		}
+	}
+	return nil
+}
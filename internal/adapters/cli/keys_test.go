@@ -0,0 +1,115 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
+)
+
+// fakeKeyAudit returns one valid and one misconfigured key, attributing the
+// second to every server it is given.
+func fakeKeyAudit(servers []domain.Server) []security.KeyAuditEntry {
+	var aliases []string
+	for _, s := range servers {
+		aliases = append(aliases, s.Alias)
+	}
+	return []security.KeyAuditEntry{
+		{
+			Path: "~/.ssh/id_ed25519",
+			Mode: "0600",
+			Validation: &security.KeyValidationResult{
+				IsValid: true,
+				KeyInfo: &security.KeyInfo{Type: "ed25519", Size: 256, Fingerprint: "SHA256:abc", Private: true, Encrypted: true},
+			},
+		},
+		{
+			Path:    "~/.ssh/legacy",
+			Mode:    "0644",
+			Servers: aliases,
+			Validation: &security.KeyValidationResult{
+				IsValid: false,
+				KeyInfo: &security.KeyInfo{Type: "rsa", Size: 1024, Private: true},
+				Issues:  []string{"Key size 1024 is below minimum required size 2048"},
+			},
+		},
+	}
+}
+
+func runKeysAudit(svc *mockServerService, args ...string) (string, error) {
+	cmd := NewKeysCommand(svc, fakeKeyAudit)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(append([]string{"audit"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestKeysAuditCommand_Table(t *testing.T) {
+	out, err := runKeysAudit(&mockServerService{servers: testServers()})
+	if err != nil {
+		t.Fatalf("keys audit failed: %v", err)
+	}
+
+	lines := strings.Split(out, "\n")
+	if !strings.Contains(lines[0], "FINGERPRINT") || !strings.Contains(lines[0], "PASSPHRASE") {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "ok") || !strings.Contains(lines[1], "yes") {
+		t.Errorf("expected a valid encrypted key row, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "FAIL") || !strings.Contains(lines[2], "prod,dev") {
+		t.Errorf("expected a failing row listing servers, got %q", lines[2])
+	}
+	if !strings.Contains(out, "~/.ssh/legacy:\n  - Key size 1024") {
+		t.Errorf("expected issues below the table, got:\n%s", out)
+	}
+}
+
+func TestKeysAuditCommand_JSON(t *testing.T) {
+	out, err := runKeysAudit(&mockServerService{servers: testServers()}, "-o", "json")
+	if err != nil {
+		t.Fatalf("keys audit failed: %v", err)
+	}
+
+	var records []keyRecord
+	if err := json.Unmarshal([]byte(out), &records); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if !records[0].Valid || !records[0].Passphrase || records[0].Fingerprint != "SHA256:abc" {
+		t.Errorf("unexpected first record: %+v", records[0])
+	}
+	if records[1].Valid || records[1].Mode != "0644" || len(records[1].Issues) != 1 || len(records[1].Servers) != 2 {
+		t.Errorf("unexpected second record: %+v", records[1])
+	}
+}
+
+func TestKeysAuditCommand_Strict(t *testing.T) {
+	if _, err := runKeysAudit(&mockServerService{}, "--strict"); err == nil || !strings.Contains(err.Error(), "1 of 2 keys") {
+		t.Errorf("expected strict mode to fail on the invalid key, got %v", err)
+	}
+	if _, err := runKeysAudit(&mockServerService{}, "-o", "xml"); err == nil {
+		t.Error("expected error for unsupported output format")
+	}
+}
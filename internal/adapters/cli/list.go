@@ -0,0 +1,59 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/aryasoni98/wooak/internal/core/ports"
+	"github.com/spf13/cobra"
+)
+
+// NewListCommand returns the `list [query]` subcommand. Servers are printed in
+// the same order as the TUI (pinned first) and can be filtered with an optional
+// query that matches alias, host, user and tags.
+func NewListCommand(serverService ports.ServerService) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "list [query]",
+		Short: "List SSH servers without starting the TUI",
+		Example: `  wooak list
+  wooak list prod -o json
+  wooak list --output yaml`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
+			}
+
+			servers, err := serverService.ListServers(query)
+			if err != nil {
+				return fmt.Errorf("failed to list servers: %w", err)
+			}
+
+			return writeServers(cmd.OutOrStdout(), servers, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", OutputTable, "output format: table, json or yaml")
+
+	return cmd
+}
@@ -0,0 +1,240 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// mockServerService is a minimal in-memory ports.ServerService for CLI tests.
+type mockServerService struct {
+	servers   []domain.Server
+	lastQuery string
+	sshCalls  []string
+	added     []domain.Server
+	updated   [][2]domain.Server
+	deleted   []domain.Server
+	pinned    map[string]bool
+}
+
+// ListServers mirrors the repository's substring matching on host, user,
+// tags and aliases.
+func (m *mockServerService) ListServers(query string) ([]domain.Server, error) {
+	m.lastQuery = query
+	if query == "" {
+		return m.servers, nil
+	}
+	q := strings.ToLower(query)
+	var out []domain.Server
+	for _, s := range m.servers {
+		fields := append([]string{s.Host, s.User}, s.Tags...)
+		fields = append(fields, s.Aliases...)
+		for _, f := range fields {
+			if strings.Contains(strings.ToLower(f), q) {
+				out = append(out, s)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *mockServerService) UpdateServer(server domain.Server, newServer domain.Server) error {
+	m.updated = append(m.updated, [2]domain.Server{server, newServer})
+	return nil
+}
+
+func (m *mockServerService) AddServer(server domain.Server) error {
+	m.added = append(m.added, server)
+	return nil
+}
+
+func (m *mockServerService) DeleteServer(server domain.Server) error {
+	m.deleted = append(m.deleted, server)
+	return nil
+}
+
+func (m *mockServerService) SetPinned(alias string, pinned bool) error {
+	if m.pinned == nil {
+		m.pinned = make(map[string]bool)
+	}
+	m.pinned[alias] = pinned
+	return nil
+}
+
+func (m *mockServerService) SSH(alias string, notify ports.SSHNotifier) error {
+	m.sshCalls = append(m.sshCalls, alias)
+	return nil
+}
+
+func (m *mockServerService) Ping(server domain.Server) (bool, time.Duration, error) {
+	return true, 0, nil
+}
+
+func (m *mockServerService) GetEffectiveConfig(alias string) (domain.EffectiveConfig, error) {
+	return domain.EffectiveConfig{Alias: alias}, nil
+}
+
+func (m *mockServerService) GetEffectiveConfigs(aliases []string) (map[string]domain.EffectiveConfig, error) {
+	configs := make(map[string]domain.EffectiveConfig, len(aliases))
+	for _, alias := range aliases {
+		configs[alias] = domain.EffectiveConfig{Alias: alias}
+	}
+	return configs, nil
+}
+
+func (m *mockServerService) ResolveConfig(alias string) (domain.ResolvedConfig, error) {
+	return domain.ResolvedConfig{Alias: alias}, nil
+}
+
+func (m *mockServerService) ListProfiles() ([]domain.Profile, error) {
+	return nil, nil
+}
+
+func (m *mockServerService) AddProfile(profile domain.Profile) error {
+	return nil
+}
+
+func (m *mockServerService) UpdateProfile(profile domain.Profile, newProfile domain.Profile) error {
+	return nil
+}
+
+func (m *mockServerService) DeleteProfile(profile domain.Profile) error {
+	return nil
+}
+
+func testServers() []domain.Server {
+	pinnedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	lastSeen := time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+	return []domain.Server{
+		{
+			Alias:    "prod",
+			Host:     "prod.example.com",
+			User:     "deploy",
+			Port:     2222,
+			Tags:     []string{"prod", "web"},
+			PinnedAt: pinnedAt,
+			LastSeen: lastSeen,
+			SSHCount: 7,
+		},
+		{
+			Alias: "dev",
+			Host:  "10.0.0.5",
+			Port:  22,
+		},
+	}
+}
+
+func runList(t *testing.T, svc *mockServerService, args ...string) string {
+	t.Helper()
+	cmd := NewListCommand(svc)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("list %v failed: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestListCommand_Table(t *testing.T) {
+	svc := &mockServerService{servers: testServers()}
+	out := runList(t, svc)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d lines:\n%s", len(lines), out)
+	}
+	if !strings.Contains(lines[0], "ALIAS") || !strings.Contains(lines[0], "SSH COUNT") {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "*") || !strings.Contains(lines[1], "prod,web") {
+		t.Errorf("expected pinned row with tags, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "never") {
+		t.Errorf("expected unseen server to show 'never', got %q", lines[2])
+	}
+}
+
+func TestListCommand_Query(t *testing.T) {
+	svc := &mockServerService{servers: testServers()}
+	out := runList(t, svc, "web")
+
+	if svc.lastQuery != "web" {
+		t.Errorf("expected query %q to be passed to service, got %q", "web", svc.lastQuery)
+	}
+	if !strings.Contains(out, "prod.example.com") || strings.Contains(out, "10.0.0.5") {
+		t.Errorf("expected only the tagged server, got:\n%s", out)
+	}
+}
+
+func TestListCommand_JSON(t *testing.T) {
+	servers := testServers()
+	servers[1].Port = 0
+	svc := &mockServerService{servers: servers}
+	out := runList(t, svc, "--output", "json")
+
+	var records []serverRecord
+	if err := json.Unmarshal([]byte(out), &records); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if !records[0].Pinned || records[0].PinnedAt != "2025-01-02T03:04:05Z" {
+		t.Errorf("unexpected pin state: %+v", records[0])
+	}
+	if records[0].LastSeen != "2025-02-03T04:05:06Z" || records[0].SSHCount != 7 {
+		t.Errorf("unexpected usage fields: %+v", records[0])
+	}
+	if records[1].Pinned || records[1].LastSeen != "" {
+		t.Errorf("expected unpinned, unseen record, got %+v", records[1])
+	}
+	if records[0].Port != 2222 || records[1].Port != 22 {
+		t.Errorf("expected the ports ssh uses, got %d and %d", records[0].Port, records[1].Port)
+	}
+}
+
+func TestListCommand_YAML(t *testing.T) {
+	svc := &mockServerService{servers: testServers()}
+	out := runList(t, svc, "-o", "yaml")
+
+	var records []serverRecord
+	if err := yaml.Unmarshal([]byte(out), &records); err != nil {
+		t.Fatalf("invalid YAML output: %v\n%s", err, out)
+	}
+	if len(records) != 2 || records[0].Alias != "prod" || len(records[0].Tags) != 2 {
+		t.Errorf("unexpected YAML records: %+v", records)
+	}
+}
+
+func TestListCommand_InvalidOutput(t *testing.T) {
+	cmd := NewListCommand(&mockServerService{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"-o", "xml"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error for unsupported output format")
+	}
+}
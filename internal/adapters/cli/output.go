@@ -0,0 +1,162 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported output formats for non-interactive commands.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+const timeLayout = "2006-01-02 15:04"
+
+// serverRecord is the machine-readable representation of a server.
+// Field names are kept stable so scripts can rely on them. Port is the port
+// ssh connects to, 22 when the server does not set one.
+type serverRecord struct {
+	Alias         string   `json:"alias" yaml:"alias"`
+	Aliases       []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Host          string   `json:"host" yaml:"host"`
+	User          string   `json:"user,omitempty" yaml:"user,omitempty"`
+	Port          int      `json:"port" yaml:"port"`
+	IdentityFiles []string `json:"identity_files,omitempty" yaml:"identity_files,omitempty"`
+	ProxyJump     string   `json:"proxy_jump,omitempty" yaml:"proxy_jump,omitempty"`
+	Tags          []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Pinned        bool     `json:"pinned" yaml:"pinned"`
+	PinnedAt      string   `json:"pinned_at,omitempty" yaml:"pinned_at,omitempty"`
+	LastSeen      string   `json:"last_seen,omitempty" yaml:"last_seen,omitempty"`
+	SSHCount      int      `json:"ssh_count" yaml:"ssh_count"`
+	SourceFile    string   `json:"source_file,omitempty" yaml:"source_file,omitempty"`
+}
+
+func toServerRecord(s domain.Server) serverRecord {
+	r := serverRecord{
+		Alias:         s.Alias,
+		Aliases:       s.Aliases,
+		Host:          s.Host,
+		User:          s.User,
+		Port:          effectivePort(s.Port),
+		IdentityFiles: s.IdentityFiles,
+		ProxyJump:     s.ProxyJump,
+		Tags:          s.Tags,
+		Pinned:        !s.PinnedAt.IsZero(),
+		SSHCount:      s.SSHCount,
+		SourceFile:    s.SourceFile,
+	}
+	if !s.PinnedAt.IsZero() {
+		r.PinnedAt = s.PinnedAt.Format(time.RFC3339)
+	}
+	if !s.LastSeen.IsZero() {
+		r.LastSeen = s.LastSeen.Format(time.RFC3339)
+	}
+	return r
+}
+
+// validateOutputFormat returns an error for unknown output formats.
+func validateOutputFormat(format string) error {
+	switch format {
+	case OutputTable, OutputJSON, OutputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q (expected %s, %s or %s)", format, OutputTable, OutputJSON, OutputYAML)
+	}
+}
+
+// writeServers renders servers to w in the requested format.
+func writeServers(w io.Writer, servers []domain.Server, format string) error {
+	if err := validateOutputFormat(format); err != nil {
+		return err
+	}
+
+	records := make([]serverRecord, 0, len(servers))
+	for _, s := range servers {
+		records = append(records, toServerRecord(s))
+	}
+
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(records); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return writeServerTable(w, servers)
+	}
+}
+
+func writeServerTable(w io.Writer, servers []domain.Server) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PIN\tALIAS\tHOST\tUSER\tPORT\tTAGS\tLAST SEEN\tSSH COUNT")
+	for _, s := range servers {
+		pin := ""
+		if !s.PinnedAt.IsZero() {
+			pin = "*"
+		}
+		lastSeen := "never"
+		if !s.LastSeen.IsZero() {
+			lastSeen = s.LastSeen.Local().Format(timeLayout)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			pin,
+			s.Alias,
+			s.Host,
+			valueOrDash(s.User),
+			portString(s.Port),
+			valueOrDash(strings.Join(s.Tags, ",")),
+			lastSeen,
+			s.SSHCount,
+		)
+	}
+	return tw.Flush()
+}
+
+func valueOrDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+func portString(port int) string {
+	return strconv.Itoa(effectivePort(port))
+}
+
+// effectivePort returns the port ssh uses for a server's Port option.
+func effectivePort(port int) int {
+	if port == 0 {
+		return 22
+	}
+	return port
+}
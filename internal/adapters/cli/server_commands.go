@@ -0,0 +1,252 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/ports"
+	"github.com/spf13/cobra"
+)
+
+// NewAddCommand returns the `add <alias>` subcommand.
+func NewAddCommand(serverService ports.ServerService, validate FieldValidator) *cobra.Command {
+	var flags serverFlags
+
+	cmd := &cobra.Command{
+		Use:   "add <alias>",
+		Short: "Add a server to the SSH config",
+		Example: `  wooak add web-1 --host 10.0.0.10 --user deploy --tag prod --tag web
+  wooak add bastion --host bastion.example.com --option ForwardAgent=no`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server := domain.Server{Alias: args[0]}
+			if err := flags.apply(cmd, &server, false); err != nil {
+				return err
+			}
+			if err := validateServerFields(server, validate); err != nil {
+				return err
+			}
+			if err := serverService.AddServer(server); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added %s\n", server.Alias)
+			return nil
+		},
+	}
+	flags.register(cmd)
+
+	return cmd
+}
+
+// NewEditCommand returns the `edit <alias>` subcommand. Only flags that are
+// explicitly given are changed; everything else is kept as is.
+func NewEditCommand(serverService ports.ServerService, validate FieldValidator) *cobra.Command {
+	var flags serverFlags
+
+	cmd := &cobra.Command{
+		Use:   "edit <alias>",
+		Short: "Edit an existing server in the SSH config",
+		Example: `  wooak edit web-1 --port 2222
+  wooak edit web-1 --alias web-01 --option LocalForward=8080:localhost:80`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := findServer(serverService, args[0])
+			if err != nil {
+				return err
+			}
+
+			updated := copyServer(server)
+			if err := flags.apply(cmd, &updated, true); err != nil {
+				return err
+			}
+			if err := validateServerFields(updated, validate); err != nil {
+				return err
+			}
+			if err := serverService.UpdateServer(server, updated); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Updated %s\n", updated.Alias)
+			return nil
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().StringVar(&flags.alias, "alias", "", "rename the server")
+
+	return cmd
+}
+
+// NewRemoveCommand returns the `rm <alias>` subcommand.
+func NewRemoveCommand(serverService ports.ServerService) *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:     "rm <alias>",
+		Aliases: []string{"remove", "delete"},
+		Short:   "Remove a server from the SSH config",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := findServer(serverService, args[0])
+			if err != nil {
+				return err
+			}
+
+			if !yes {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Delete server %q (%s)? [y/N]: ", server.Alias, server.Host)
+				answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				answer = strings.ToLower(strings.TrimSpace(answer))
+				if answer != "y" && answer != "yes" {
+					return errSelectionCancelled
+				}
+			}
+
+			if err := serverService.DeleteServer(server); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", server.Alias)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+
+	return cmd
+}
+
+// NewTagCommand returns the `tag <alias> [tag...]` subcommand. Tags are added
+// by default; --remove removes them and --clear drops all existing tags first.
+func NewTagCommand(serverService ports.ServerService) *cobra.Command {
+	var (
+		remove bool
+		clear  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tag <alias> [tag...]",
+		Short: "Add, remove or clear server tags",
+		Example: `  wooak tag web-1 prod web
+  wooak tag web-1 --remove web
+  wooak tag web-1 --clear`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if remove && clear {
+				return fmt.Errorf("--remove and --clear cannot be used together")
+			}
+
+			server, err := findServer(serverService, args[0])
+			if err != nil {
+				return err
+			}
+
+			updated := copyServer(server)
+			updated.Tags = updateTags(server.Tags, args[1:], remove, clear)
+			if err := serverService.UpdateServer(server, updated); err != nil {
+				return err
+			}
+
+			tags := "(none)"
+			if len(updated.Tags) > 0 {
+				tags = strings.Join(updated.Tags, ", ")
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Tags for %s: %s\n", updated.Alias, tags)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&remove, "remove", false, "remove the given tags instead of adding them")
+	cmd.Flags().BoolVar(&clear, "clear", false, "remove all existing tags before adding")
+
+	return cmd
+}
+
+// NewPinCommand returns the `pin <alias>` subcommand.
+func NewPinCommand(serverService ports.ServerService) *cobra.Command {
+	var unpin bool
+
+	cmd := &cobra.Command{
+		Use:   "pin <alias>",
+		Short: "Pin or unpin a server",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := findServer(serverService, args[0])
+			if err != nil {
+				return err
+			}
+			if err := serverService.SetPinned(server.Alias, !unpin); err != nil {
+				return err
+			}
+
+			state := "Pinned"
+			if unpin {
+				state = "Unpinned"
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", state, server.Alias)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&unpin, "unpin", false, "unpin the server instead")
+
+	return cmd
+}
+
+// findServer looks up a server by exact primary or secondary alias. Unlike
+// connect, mutations never fall back to fuzzy matching.
+func findServer(serverService ports.ServerService, alias string) (domain.Server, error) {
+	servers, err := serverService.ListServers("")
+	if err != nil {
+		return domain.Server{}, fmt.Errorf("failed to list servers: %w", err)
+	}
+	server, ok := findByAlias(servers, alias)
+	if !ok {
+		return domain.Server{}, fmt.Errorf("server %q not found", alias)
+	}
+	return server, nil
+}
+
+// copyServer returns a copy of server whose slices can be modified without
+// affecting the original.
+func copyServer(server domain.Server) domain.Server {
+	c := server
+	c.Aliases = append([]string(nil), server.Aliases...)
+	c.IdentityFiles = append([]string(nil), server.IdentityFiles...)
+	c.Tags = append([]string(nil), server.Tags...)
+	c.LocalForward = append([]string(nil), server.LocalForward...)
+	c.RemoteForward = append([]string(nil), server.RemoteForward...)
+	c.DynamicForward = append([]string(nil), server.DynamicForward...)
+	c.SendEnv = append([]string(nil), server.SendEnv...)
+	c.SetEnv = append([]string(nil), server.SetEnv...)
+	return c
+}
+
+func updateTags(current, tags []string, remove, clear bool) []string {
+	if remove {
+		drop := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			drop[strings.TrimSpace(tag)] = true
+		}
+		out := make([]string, 0, len(current))
+		for _, tag := range current {
+			if !drop[tag] {
+				out = append(out, tag)
+			}
+		}
+		return out
+	}
+	if clear {
+		return normalizeTags(tags)
+	}
+	return normalizeTags(append(append([]string{}, current...), tags...))
+}
@@ -0,0 +1,229 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/spf13/cobra"
+)
+
+// rejectingValidator fails for a single field so tests can check that the
+// injected UI validators are consulted.
+func rejectingValidator(field string) FieldValidator {
+	return func(fieldName, value string) error {
+		if fieldName == field && value != "" {
+			return fmt.Errorf("%s rejected", fieldName)
+		}
+		return nil
+	}
+}
+
+func execute(t *testing.T, cmd *cobra.Command, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestAddCommand(t *testing.T) {
+	svc := &mockServerService{}
+	_, err := execute(t, NewAddCommand(svc, nil), "",
+		"web-1",
+		"--host", "10.0.0.10",
+		"--user", "deploy",
+		"--port", "2222",
+		"--identity-file", "~/.ssh/id_ed25519",
+		"--proxy-jump", "bastion",
+		"--tag", "prod", "--tag", "web,prod",
+		"--option", "ForwardAgent=no",
+		"--option", "localforward=8080:localhost:80",
+		"--option", "LocalForward=8443:localhost:443",
+	)
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if len(svc.added) != 1 {
+		t.Fatalf("expected one AddServer call, got %d", len(svc.added))
+	}
+
+	got := svc.added[0]
+	want := domain.Server{
+		Alias:         "web-1",
+		Host:          "10.0.0.10",
+		User:          "deploy",
+		Port:          2222,
+		IdentityFiles: []string{"~/.ssh/id_ed25519"},
+		ProxyJump:     "bastion",
+		Tags:          []string{"prod", "web"},
+		ForwardAgent:  "no",
+		LocalForward:  []string{"8080:localhost:80", "8443:localhost:443"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected server:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestAddCommand_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate FieldValidator
+		args     []string
+	}{
+		{name: "malformed option", args: []string{"a", "--host", "h", "--option", "ForwardAgent"}},
+		{name: "unknown option", args: []string{"a", "--host", "h", "--option", "Bogus=1"}},
+		{name: "reserved option", args: []string{"a", "--host", "h", "--option", "HostName=x"}},
+		{name: "managed option", args: []string{"a", "--host", "h", "--option", "SSHCount=3"}},
+		{name: "source file", args: []string{"a", "--host", "h", "--option", "SourceFile=/etc/passwd"}},
+		{name: "validator rejects host", validate: rejectingValidator("Host"), args: []string{"a", "--host", "h"}},
+		{name: "validator rejects option", validate: rejectingValidator("LocalForward"), args: []string{"a", "--host", "h", "--option", "LocalForward=x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockServerService{}
+			if _, err := execute(t, NewAddCommand(svc, tt.validate), "", tt.args...); err == nil {
+				t.Fatal("expected error")
+			}
+			if len(svc.added) != 0 {
+				t.Errorf("AddServer should not be called, got %+v", svc.added)
+			}
+		})
+	}
+}
+
+func TestEditCommand_OnlyChangedFields(t *testing.T) {
+	original := domain.Server{
+		Alias:        "web-1",
+		Aliases:      []string{"web-1"},
+		Host:         "10.0.0.10",
+		User:         "deploy",
+		Port:         22,
+		Tags:         []string{"prod"},
+		LocalForward: []string{"8080:localhost:80"},
+	}
+	svc := &mockServerService{servers: []domain.Server{original}}
+
+	_, err := execute(t, NewEditCommand(svc, nil), "", "web-1", "--port", "2200", "--alias", "web-01")
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if len(svc.updated) != 1 {
+		t.Fatalf("expected one UpdateServer call, got %d", len(svc.updated))
+	}
+
+	oldServer, newServer := svc.updated[0][0], svc.updated[0][1]
+	if oldServer.Alias != "web-1" {
+		t.Errorf("expected original alias web-1, got %q", oldServer.Alias)
+	}
+	if newServer.Alias != "web-01" || newServer.Port != 2200 {
+		t.Errorf("expected alias and port to change, got %+v", newServer)
+	}
+	if newServer.Host != original.Host || newServer.User != original.User || !reflect.DeepEqual(newServer.Tags, original.Tags) {
+		t.Errorf("unchanged fields were modified: %+v", newServer)
+	}
+	if !reflect.DeepEqual(newServer.LocalForward, original.LocalForward) {
+		t.Errorf("expected LocalForward to be preserved, got %v", newServer.LocalForward)
+	}
+}
+
+func TestEditCommand_NotFound(t *testing.T) {
+	svc := &mockServerService{servers: connectServers()}
+	if _, err := execute(t, NewEditCommand(svc, nil), "", "web", "--port", "2200"); err == nil {
+		t.Error("expected error for non-exact alias")
+	}
+}
+
+func TestRemoveCommand(t *testing.T) {
+	t.Run("confirmed", func(t *testing.T) {
+		svc := &mockServerService{servers: connectServers()}
+		if _, err := execute(t, NewRemoveCommand(svc), "y\n", "db"); err != nil {
+			t.Fatalf("rm failed: %v", err)
+		}
+		if len(svc.deleted) != 1 || svc.deleted[0].Alias != "db" {
+			t.Errorf("expected db to be deleted, got %+v", svc.deleted)
+		}
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		svc := &mockServerService{servers: connectServers()}
+		if _, err := execute(t, NewRemoveCommand(svc), "n\n", "db"); err == nil {
+			t.Error("expected cancellation error")
+		}
+		if len(svc.deleted) != 0 {
+			t.Errorf("expected no deletion, got %+v", svc.deleted)
+		}
+	})
+
+	t.Run("yes flag", func(t *testing.T) {
+		svc := &mockServerService{servers: connectServers()}
+		if _, err := execute(t, NewRemoveCommand(svc), "", "www", "--yes"); err != nil {
+			t.Fatalf("rm failed: %v", err)
+		}
+		if len(svc.deleted) != 1 || svc.deleted[0].Alias != "web-1" {
+			t.Errorf("expected web-1 to be deleted via secondary alias, got %+v", svc.deleted)
+		}
+	})
+}
+
+func TestTagCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "add", args: []string{"web-1", "web", "prod"}, want: []string{"prod", "web"}},
+		{name: "remove", args: []string{"web-1", "--remove", "prod"}, want: []string{}},
+		{name: "clear and set", args: []string{"web-1", "--clear", "edge"}, want: []string{"edge"}},
+		{name: "clear all", args: []string{"web-1", "--clear"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockServerService{servers: connectServers()}
+			if _, err := execute(t, NewTagCommand(svc), "", tt.args...); err != nil {
+				t.Fatalf("tag failed: %v", err)
+			}
+			if len(svc.updated) != 1 {
+				t.Fatalf("expected one UpdateServer call, got %d", len(svc.updated))
+			}
+			if got := svc.updated[0][1].Tags; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tags = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPinCommand(t *testing.T) {
+	svc := &mockServerService{servers: connectServers()}
+	if _, err := execute(t, NewPinCommand(svc), "", "db"); err != nil {
+		t.Fatalf("pin failed: %v", err)
+	}
+	if _, err := execute(t, NewPinCommand(svc), "", "www", "--unpin"); err != nil {
+		t.Fatalf("unpin failed: %v", err)
+	}
+	if !svc.pinned["db"] || svc.pinned["web-1"] {
+		t.Errorf("unexpected pin state: %v", svc.pinned)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/spf13/cobra"
+)
+
+// FieldValidator validates a single server field by its form field name
+// (e.g. "Host", "Port", "LocalForward"). The TUI's validation rules are
+// injected through this type so the CLI applies exactly the same checks.
+type FieldValidator func(fieldName, value string) error
+
+// reservedOptions are server fields that have dedicated flags or are managed
+// by wooak itself and therefore cannot be set through --option.
+var reservedOptions = map[string]string{
+	"alias":         "use the alias argument",
+	"aliases":       "use the alias argument",
+	"host":          "use --host",
+	"hostname":      "use --host",
+	"user":          "use --user",
+	"port":          "use --port",
+	"identityfile":  "use --identity-file",
+	"identityfiles": "use --identity-file",
+	"proxyjump":     "use --proxy-jump",
+	"tags":          "use --tag",
+	"lastseen":      "managed by wooak",
+	"pinnedat":      "managed by wooak",
+	"sshcount":      "managed by wooak",
+	"sourcefile":    "managed by wooak",
+}
+
+// serverFlags holds the flag values shared by add and edit.
+type serverFlags struct {
+	alias         string
+	host          string
+	user          string
+	port          int
+	identityFiles []string
+	proxyJump     string
+	tags          []string
+	options       []string
+}
+
+func (f *serverFlags) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.host, "host", "", "host name or IP address (HostName)")
+	flags.StringVar(&f.user, "user", "", "login user")
+	flags.IntVar(&f.port, "port", 0, "SSH port")
+	flags.StringSliceVar(&f.identityFiles, "identity-file", nil, "identity file path (repeatable)")
+	flags.StringVar(&f.proxyJump, "proxy-jump", "", "ProxyJump host(s)")
+	flags.StringSliceVar(&f.tags, "tag", nil, "tag (repeatable)")
+	flags.StringArrayVar(&f.options, "option", nil, "additional SSH option as Key=Value (repeatable)")
+}
+
+// apply copies flag values onto server. When onlyChanged is true, only flags
+// explicitly given on the command line are applied, which is what edit needs.
+func (f *serverFlags) apply(cmd *cobra.Command, server *domain.Server, onlyChanged bool) error {
+	set := func(name string) bool {
+		return !onlyChanged || cmd.Flags().Changed(name)
+	}
+
+	if onlyChanged && cmd.Flags().Changed("alias") {
+		server.Alias = f.alias
+	}
+	if set("host") {
+		server.Host = f.host
+	}
+	if set("user") {
+		server.User = f.user
+	}
+	if set("port") {
+		server.Port = f.port
+	}
+	if set("identity-file") {
+		server.IdentityFiles = append([]string{}, f.identityFiles...)
+	}
+	if set("proxy-jump") {
+		server.ProxyJump = f.proxyJump
+	}
+	if set("tag") {
+		server.Tags = normalizeTags(f.tags)
+	}
+
+	for _, opt := range f.options {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			return fmt.Errorf("invalid --option %q: expected Key=Value", opt)
+		}
+		if _, err := setServerOption(server, strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setServerOption sets the domain.Server field whose name matches key
+// case-insensitively. String fields are replaced and list fields (such as
+// LocalForward) are appended to. It returns the canonical field name.
+func setServerOption(server *domain.Server, key, value string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("option key must not be empty")
+	}
+	if hint, ok := reservedOptions[strings.ToLower(key)]; ok {
+		return "", fmt.Errorf("option %q cannot be set with --option: %s", key, hint)
+	}
+
+	v := reflect.ValueOf(server).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !strings.EqualFold(field.Name, key) {
+			continue
+		}
+
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(value)
+		case reflect.Slice:
+			if fv.Type().Elem().Kind() != reflect.String {
+				return "", fmt.Errorf("option %q is not supported", key)
+			}
+			if value == "" {
+				fv.Set(reflect.ValueOf([]string{}))
+			} else {
+				fv.Set(reflect.Append(fv, reflect.ValueOf(value)))
+			}
+		default:
+			return "", fmt.Errorf("option %q is not supported", key)
+		}
+		return field.Name, nil
+	}
+
+	return "", fmt.Errorf("unknown SSH option %q", key)
+}
+
+// validateServerFields runs the field validators over every populated field
+// of server, returning the first failure.
+func validateServerFields(server domain.Server, validate FieldValidator) error {
+	if validate == nil {
+		return nil
+	}
+
+	checks := []struct {
+		field string
+		value string
+	}{
+		{"Alias", server.Alias},
+		{"Host", server.Host},
+		{"User", server.User},
+		{"Keys", strings.Join(server.IdentityFiles, ",")},
+	}
+	if server.Port != 0 {
+		checks = append(checks, struct {
+			field string
+			value string
+		}{"Port", strconv.Itoa(server.Port)})
+	}
+	for _, c := range checks {
+		if err := validate(c.field, c.value); err != nil {
+			return fmt.Errorf("invalid %s: %w", c.field, err)
+		}
+	}
+
+	v := reflect.ValueOf(server)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if _, reserved := reservedOptions[strings.ToLower(name)]; reserved {
+			continue
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.String:
+			if err := validate(name, fv.String()); err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+		case reflect.Slice:
+			if fv.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			for j := 0; j < fv.Len(); j++ {
+				if err := validate(name, fv.Index(j).String()); err != nil {
+					return fmt.Errorf("invalid %s: %w", name, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeTags trims, drops empties and de-duplicates tags while keeping order.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package settings_file
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+)
+
+// EnvConfigPath overrides the location of the settings file itself.
+const EnvConfigPath = "WOOAK_CONFIG"
+
+// applyEnvOverrides replaces settings with values from WOOAK_* environment
+// variables. METRICS_PORT is still honored for compatibility, but
+// WOOAK_METRICS_PORT wins when both are set.
+func applyEnvOverrides(st *settings.Settings) error {
+	if v, ok := os.LookupEnv("WOOAK_SSH_CONFIG"); ok && v != "" {
+		st.Paths.SSHConfig = v
+	}
+	if v, ok := os.LookupEnv("WOOAK_METADATA"); ok && v != "" {
+		st.Paths.Metadata = v
+	}
+
+	for _, name := range []string{"METRICS_PORT", "WOOAK_METRICS_PORT"} {
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			port, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q: %w", name, v, err)
+			}
+			st.Metrics.Port = port
+		}
+	}
+	if err := envBool("WOOAK_METRICS_ENABLED", &st.Metrics.Enabled); err != nil {
+		return err
+	}
+
+	if err := envBool("WOOAK_AI_ENABLED", &st.AI.Enabled); err != nil {
+		return err
+	}
+	if v, ok := os.LookupEnv("WOOAK_AI_PROVIDER"); ok && v != "" {
+		st.AI.Provider = ai.AIProvider(v)
+	}
+	if v, ok := os.LookupEnv("WOOAK_AI_MODEL"); ok && v != "" {
+		st.AI.Model = v
+	}
+	if v, ok := os.LookupEnv("WOOAK_AI_BASE_URL"); ok && v != "" {
+		st.AI.BaseURL = v
+	}
+	if v, ok := os.LookupEnv("WOOAK_AI_API_KEY"); ok && v != "" {
+		st.AI.APIKey = v
+	}
+
+	if v, ok := os.LookupEnv("WOOAK_THEME"); ok && v != "" {
+		st.Theme.Name = v
+	}
+	return nil
+}
+
+func envBool(name string, target *bool) error {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	*target = b
+	return nil
+}
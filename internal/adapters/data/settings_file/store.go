@@ -0,0 +1,247 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package settings_file
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const fileHeader = "# wooak settings. Environment variables (WOOAK_*) override values in this file.\n"
+
+// Store loads and saves wooak's settings file. It keeps two copies: the
+// values stored in the file and the effective values with environment
+// overrides applied, so saving never writes an override back to disk.
+type Store struct {
+	path   string
+	logger *zap.SugaredLogger
+
+	mu        sync.RWMutex
+	file      *settings.Settings
+	effective *settings.Settings
+	modTime   time.Time
+	size      int64
+	// loadErr is why the file last failed to load; while it is set the
+	// store holds the defaults and refuses to save over the file.
+	loadErr error
+
+	listenersMu sync.Mutex
+	listeners   []func(s *settings.Settings)
+}
+
+// NewStore creates a store for the settings file at path. Call Load before
+// using it.
+func NewStore(logger *zap.SugaredLogger, path string) *Store {
+	return &Store{
+		path:      path,
+		logger:    logger,
+		file:      settings.Default(),
+		effective: settings.Default(),
+	}
+}
+
+// Path returns the location of the settings file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the settings file, migrates it to the current schema version and
+// applies environment overrides. A missing file yields the defaults. If the
+// file cannot be loaded the store keeps the defaults, which Get returns, and
+// Update fails until the file is fixed.
+func (s *Store) Load() (*settings.Settings, error) {
+	file, effective, info, err := s.read()
+	if err != nil {
+		s.mu.Lock()
+		s.loadErr = err
+		// Remember the broken file so the watcher only reloads it once it
+		// changes.
+		if current, statErr := os.Stat(s.path); statErr == nil {
+			s.recordFileInfo(current)
+		}
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.file, s.effective = file, effective
+	s.loadErr = nil
+	s.recordFileInfo(info)
+	s.mu.Unlock()
+
+	return effective.Clone(), nil
+}
+
+// Get returns the effective settings.
+func (s *Store) Get() *settings.Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.effective.Clone()
+}
+
+// Update applies mutate to the stored settings, validates the result and
+// writes it back to the settings file.
+func (s *Store) Update(mutate func(st *settings.Settings)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loadErr != nil {
+		// Saving the defaults would overwrite the user's broken file.
+		return fmt.Errorf("not saving settings until '%s' loads: %w", s.path, s.loadErr)
+	}
+
+	next := s.file.Clone()
+	mutate(next)
+	next.Version = settings.CurrentVersion
+
+	effective := next.Clone()
+	if err := applyEnvOverrides(effective); err != nil {
+		return err
+	}
+	if err := effective.Validate(); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+
+	if err := s.save(next); err != nil {
+		return err
+	}
+	s.file, s.effective = next, effective
+	if info, err := os.Stat(s.path); err == nil {
+		s.recordFileInfo(info)
+	}
+	return nil
+}
+
+// OnChange registers fn to be called with the new effective settings after
+// the file is changed on disk by something other than this store.
+func (s *Store) OnChange(fn func(st *settings.Settings)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// read parses the settings file without touching the store's state.
+func (s *Store) read() (*settings.Settings, *settings.Settings, os.FileInfo, error) {
+	file := settings.Default()
+
+	info, err := os.Stat(s.path)
+	switch {
+	case os.IsNotExist(err):
+		info = nil
+	case err != nil:
+		return nil, nil, nil, fmt.Errorf("stat settings '%s': %w", s.path, err)
+	default:
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("read settings '%s': %w", s.path, err)
+		}
+		if err := decode(data, file); err != nil {
+			return nil, nil, nil, fmt.Errorf("parse settings '%s': %w", s.path, err)
+		}
+	}
+
+	effective := file.Clone()
+	if err := applyEnvOverrides(effective); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := effective.Validate(); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid settings '%s': %w", s.path, err)
+	}
+	return file, effective, info, nil
+}
+
+// decode parses data on top of the defaults already in st and migrates older
+// schema versions.
+func decode(data []byte, st *settings.Settings) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	st.Version = 0
+	if err := yaml.Unmarshal(data, st); err != nil {
+		return err
+	}
+	return migrate(st)
+}
+
+// migrate upgrades st to settings.CurrentVersion. Files written before the
+// version field existed are treated as version 1.
+func migrate(st *settings.Settings) error {
+	if st.Version == 0 {
+		st.Version = 1
+	}
+	if st.Version > settings.CurrentVersion {
+		return fmt.Errorf("settings version %d is newer than this wooak supports (%d)", st.Version, settings.CurrentVersion)
+	}
+	return nil
+}
+
+// save writes st to the settings file using a write-temp-then-rename so
+// readers never see a partially written file.
+func (s *Store) save(st *settings.Settings) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("ensure settings directory for '%s': %w", s.path, err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fileHeader)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(st); err != nil {
+		return fmt.Errorf("marshal settings for '%s': %w", s.path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("marshal settings for '%s': %w", s.path, err)
+	}
+
+	tempFile := s.path + ".tmp"
+	if err := os.WriteFile(tempFile, buf.Bytes(), 0o600); err != nil {
+		s.logger.Errorw("failed to write temporary settings file", "path", tempFile, "error", err)
+		return fmt.Errorf("write temporary settings '%s': %w", tempFile, err)
+	}
+	if err := os.Rename(tempFile, s.path); err != nil {
+		s.logger.Errorw("failed to rename temporary settings file", "temp", tempFile, "target", s.path, "error", err)
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("rename temporary settings '%s' to '%s': %w", tempFile, s.path, err)
+	}
+	return nil
+}
+
+// recordFileInfo remembers the file state the store last saw (called with
+// s.mu held).
+func (s *Store) recordFileInfo(info os.FileInfo) {
+	if info == nil {
+		s.modTime, s.size = time.Time{}, 0
+		return
+	}
+	s.modTime, s.size = info.ModTime(), info.Size()
+}
+
+// ExpandPath expands a leading ~ in path to home.
+func ExpandPath(path, home string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,280 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package settings_file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+	"go.uber.org/zap"
+)
+
+func newTestStore(t *testing.T, content string) *Store {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write settings: %v", err)
+		}
+	}
+	return NewStore(zap.NewNop().Sugar(), path)
+}
+
+func TestStore_Load_MissingFile(t *testing.T) {
+	store := newTestStore(t, "")
+
+	st, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if st.Version != settings.CurrentVersion || st.Metrics.Port != settings.DefaultMetricsPort || st.Paths.SSHConfig != "~/.ssh/config" {
+		t.Errorf("Expected defaults, got %+v", st)
+	}
+	if _, err := os.Stat(store.Path()); !os.IsNotExist(err) {
+		t.Error("Load must not create the settings file")
+	}
+}
+
+func TestStore_Load_MergesWithDefaults(t *testing.T) {
+	store := newTestStore(t, `ai:
+  model: qwen2.5:7b
+  timeout: 45s
+security:
+  min_key_size: 3072
+  allowed_hosts: ["*.corp"]
+metrics:
+  port: 9200
+keybindings:
+  search: f
+`)
+
+	st, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if st.Version != 1 {
+		t.Errorf("Expected an unversioned file to migrate to version 1, got %d", st.Version)
+	}
+	if st.AI.Model != "qwen2.5:7b" || st.AI.Timeout != 45*time.Second || st.AI.BaseURL != "http://localhost:11434" {
+		t.Errorf("Unexpected AI settings: %+v", st.AI)
+	}
+	if st.Security.MinKeySize != 3072 || len(st.Security.AllowedHosts) != 1 || !st.Security.RequireHostKeyCheck {
+		t.Errorf("Unexpected security settings: %+v", st.Security)
+	}
+	if st.Metrics.Port != 9200 || !st.Metrics.Enabled {
+		t.Errorf("Unexpected metrics settings: %+v", st.Metrics)
+	}
+	bindings := st.ResolvedKeyBindings()
+	if bindings[settings.ActionSearch] != "f" || bindings[settings.ActionQuit] != "q" {
+		t.Errorf("Unexpected key bindings: %v", bindings)
+	}
+}
+
+func TestStore_Load_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "newer version", content: "version: 99\n", want: "newer than this wooak supports"},
+		{name: "invalid yaml", content: "ai: [\n", want: "parse settings"},
+		{name: "invalid value", content: "security:\n  audit_log_level: verbose\n", want: "audit_log_level"},
+		{name: "duplicate key", content: "keybindings:\n  search: q\n", want: "bound to both"},
+		{name: "unknown action", content: "keybindings:\n  launch: x\n", want: "unknown action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestStore(t, tt.content).Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestStore_Load_ErrorKeepsDefaults(t *testing.T) {
+	store := newTestStore(t, "ai: [\n")
+	if _, err := store.Load(); err == nil {
+		t.Fatal("Expected Load to fail for invalid yaml")
+	}
+	if got := store.Get(); got.Metrics.Port != settings.Default().Metrics.Port {
+		t.Errorf("Expected the defaults after a failed load, got %+v", got)
+	}
+
+	if err := store.Update(func(st *settings.Settings) { st.Metrics.Port = 9300 }); err == nil {
+		t.Error("Expected Update to refuse to overwrite a file that failed to load")
+	}
+	if data, _ := os.ReadFile(store.Path()); string(data) != "ai: [\n" {
+		t.Errorf("The broken file must be left alone, got %q", data)
+	}
+
+	// Once the file is fixed the watcher loads it and saving works again.
+	if err := os.WriteFile(store.Path(), []byte("metrics:\n  port: 9200\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if !store.checkForChanges() || store.Get().Metrics.Port != 9200 {
+		t.Fatalf("Expected the fixed file to be reloaded, got %+v", store.Get().Metrics)
+	}
+	if err := store.Update(func(st *settings.Settings) { st.Metrics.Port = 9300 }); err != nil {
+		t.Errorf("Update failed after the file was fixed: %v", err)
+	}
+}
+
+func TestStore_EnvOverrides(t *testing.T) {
+	t.Setenv("METRICS_PORT", "9300")
+	t.Setenv("WOOAK_AI_MODEL", "llama3.1:8b")
+	t.Setenv("WOOAK_SSH_CONFIG", "/tmp/ssh_config")
+
+	store := newTestStore(t, "ai:\n  model: file-model\n")
+	st, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if st.Metrics.Port != 9300 || st.AI.Model != "llama3.1:8b" || st.Paths.SSHConfig != "/tmp/ssh_config" {
+		t.Errorf("Expected environment overrides, got %+v", st)
+	}
+
+	t.Setenv("WOOAK_METRICS_PORT", "9400")
+	if st, _ = store.Load(); st.Metrics.Port != 9400 {
+		t.Errorf("Expected WOOAK_METRICS_PORT to win over METRICS_PORT, got %d", st.Metrics.Port)
+	}
+
+	t.Setenv("WOOAK_AI_ENABLED", "maybe")
+	if _, err := store.Load(); err == nil || !strings.Contains(err.Error(), "WOOAK_AI_ENABLED") {
+		t.Errorf("Expected an error for an invalid boolean, got %v", err)
+	}
+}
+
+func TestStore_Update(t *testing.T) {
+	t.Setenv("WOOAK_AI_MODEL", "env-model")
+
+	store := newTestStore(t, "ai:\n  model: file-model\n")
+	if _, err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	err := store.Update(func(st *settings.Settings) {
+		st.Security.MinKeySize = 4096
+		st.AI.Temperature = 0.2
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	data, err := os.ReadFile(store.Path())
+	if err != nil {
+		t.Fatalf("Failed to read settings: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"version: 1", "min_key_size: 4096", "temperature: 0.2", "model: file-model", "timeout: 30s"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected settings file to contain %q, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "env-model") {
+		t.Error("Environment overrides must not be written to the settings file")
+	}
+	if got := store.Get(); got.AI.Model != "env-model" || got.Security.MinKeySize != 4096 {
+		t.Errorf("Unexpected effective settings: %+v", got)
+	}
+
+	if err := store.Update(func(st *settings.Settings) { st.Security.MinKeySize = 10 }); err == nil {
+		t.Error("Expected Update to reject invalid settings")
+	}
+	if store.Get().Security.MinKeySize != 4096 {
+		t.Error("A rejected update must not change the settings")
+	}
+
+	info, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatalf("Failed to stat settings: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected settings file mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestStore_CheckForChanges(t *testing.T) {
+	store := newTestStore(t, "metrics:\n  port: 9200\n")
+	if _, err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var notified []*settings.Settings
+	store.OnChange(func(st *settings.Settings) { notified = append(notified, st) })
+
+	if store.checkForChanges() {
+		t.Error("Expected no reload for an unchanged file")
+	}
+
+	// Saving through the store is not an external change.
+	if err := store.Update(func(st *settings.Settings) { st.Metrics.Port = 9201 }); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if store.checkForChanges() {
+		t.Error("Expected no reload after the store's own write")
+	}
+
+	writeAndTouch(t, store.Path(), "metrics:\n  port: 9300\n")
+	if !store.checkForChanges() {
+		t.Fatal("Expected a reload after the file changed")
+	}
+	if len(notified) != 1 || notified[0].Metrics.Port != 9300 || store.Get().Metrics.Port != 9300 {
+		t.Errorf("Expected listeners to see port 9300, got %d notifications", len(notified))
+	}
+
+	writeAndTouch(t, store.Path(), "metrics:\n  port: nope\n")
+	if store.checkForChanges() {
+		t.Error("Expected an invalid file to be ignored")
+	}
+	if store.Get().Metrics.Port != 9300 {
+		t.Error("Expected the last good settings to stay in effect")
+	}
+}
+
+// writeAndTouch writes content and moves the modification time forward so the
+// change is detected even on filesystems with coarse timestamps.
+func writeAndTouch(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Failed to touch settings: %v", err)
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "~/.ssh/config", want: "/home/u/.ssh/config"},
+		{path: "~", want: "/home/u"},
+		{path: "/etc/ssh/ssh_config", want: "/etc/ssh/ssh_config"},
+		{path: "~other/config", want: "~other/config"},
+	}
+	for _, tt := range tests {
+		if got := ExpandPath(tt.path, "/home/u"); got != tt.want {
+			t.Errorf("ExpandPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
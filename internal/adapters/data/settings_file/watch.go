@@ -0,0 +1,90 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package settings_file
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+)
+
+// DefaultWatchInterval is how often Watch checks the settings file.
+const DefaultWatchInterval = 2 * time.Second
+
+// Watch polls the settings file until ctx is done and reloads it when its
+// modification time or size changes. Listeners registered with OnChange are
+// called with the new settings. A file that fails to parse or validate is
+// logged and ignored, keeping the last good settings in effect.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkForChanges()
+		}
+	}
+}
+
+// checkForChanges reloads the file if it differs from what the store last
+// loaded or saved, and reports whether listeners were notified.
+func (s *Store) checkForChanges() bool {
+	info, err := os.Stat(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		s.logger.Warnw("failed to stat settings file", "path", s.path, "error", err)
+		return false
+	}
+
+	s.mu.RLock()
+	unchanged := (info == nil && s.modTime.IsZero()) ||
+		(info != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size)
+	s.mu.RUnlock()
+	if unchanged {
+		return false
+	}
+
+	file, effective, info, err := s.read()
+	if err != nil {
+		s.logger.Warnw("ignoring invalid settings file", "path", s.path, "error", err)
+		// Remember the broken file so the warning is not repeated every tick.
+		if current, statErr := os.Stat(s.path); statErr == nil {
+			s.mu.Lock()
+			s.recordFileInfo(current)
+			s.mu.Unlock()
+		}
+		return false
+	}
+
+	s.mu.Lock()
+	s.file, s.effective = file, effective
+	s.loadErr = nil
+	s.recordFileInfo(info)
+	s.mu.Unlock()
+
+	s.logger.Infow("settings reloaded", "path", s.path)
+
+	s.listenersMu.Lock()
+	listeners := append([]func(*settings.Settings){}, s.listeners...)
+	s.listenersMu.Unlock()
+	for _, fn := range listeners {
+		fn(effective.Clone())
+	}
+	return true
+}
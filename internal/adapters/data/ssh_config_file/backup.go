@@ -24,11 +24,11 @@ import (
 	"time"
 )
 
-// createBackup creates a timestamped backup of the current config file
-func (r *Repository) createBackup() error {
+// createBackup creates a timestamped backup of the config file at path
+func (r *Repository) createBackup(path string) error {
 	start := time.Now()
 
-	if _, err := r.fileSystem.Stat(r.configPath); os.IsNotExist(err) {
+	if _, err := r.fileSystem.Stat(path); os.IsNotExist(err) {
 		return nil
 	} else if err != nil {
 		if r.monitoring != nil {
@@ -38,9 +38,9 @@ func (r *Repository) createBackup() error {
 	}
 
 	timestamp := time.Now().UnixMilli()
-	backupPath := fmt.Sprintf("%s-%d-%s", r.configPath, timestamp, BackupSuffix)
+	backupPath := fmt.Sprintf("%s-%d-%s", path, timestamp, BackupSuffix)
 
-	if err := r.copyFile(r.configPath, backupPath); err != nil {
+	if err := r.copyFile(path, backupPath); err != nil {
 		if r.monitoring != nil {
 			r.monitoring.RecordOperation("ssh_config_backup", time.Since(start), false)
 		}
@@ -49,9 +49,9 @@ func (r *Repository) createBackup() error {
 
 	r.logger.Infof("Created backup: %s", backupPath)
 
-	configDir := filepath.Dir(r.configPath)
+	configDir := filepath.Dir(path)
 
-	backupFiles, err := r.findBackupFiles(configDir)
+	backupFiles, err := r.findBackupFiles(configDir, filepath.Base(path))
 	if err != nil {
 		return err
 	}
@@ -126,8 +126,8 @@ func (r *Repository) copyFile(src, dst string) error {
 	return destFile.Sync()
 }
 
-// findBackupFiles finds all backup files for the given config file
-func (r *Repository) findBackupFiles(dir string) ([]os.FileInfo, error) {
+// findBackupFiles finds all backup files for the config file baseName in dir
+func (r *Repository) findBackupFiles(dir, baseName string) ([]os.FileInfo, error) {
 	entries, err := r.fileSystem.ReadDir(dir)
 	if err != nil {
 		return nil, err
@@ -137,7 +137,7 @@ func (r *Repository) findBackupFiles(dir string) ([]os.FileInfo, error) {
 
 	for _, entry := range entries {
 		name := entry.Name()
-		if strings.HasSuffix(name, BackupSuffix) {
+		if strings.HasPrefix(name, baseName+"-") && strings.HasSuffix(name, BackupSuffix) {
 			info, err := entry.Info()
 			if err != nil {
 				r.logger.Warnf("failed to get info for backup file %s: %v", name, err)
@@ -150,17 +150,20 @@ func (r *Repository) findBackupFiles(dir string) ([]os.FileInfo, error) {
 	return backupFiles, nil
 }
 
-// createOriginalBackupIfNeeded creates a one-time original backup of the current SSH config.
-func (r *Repository) createOriginalBackupIfNeeded() error {
+// createOriginalBackupIfNeeded creates a one-time original backup of the SSH config file at path.
+// The main config uses OriginalBackupName; included files get "<name>.original.backup" next to them.
+func (r *Repository) createOriginalBackupIfNeeded(path string) error {
 	// If no SSH config file, nothing to do.
-	if _, err := r.fileSystem.Stat(r.configPath); os.IsNotExist(err) {
+	if _, err := r.fileSystem.Stat(path); os.IsNotExist(err) {
 		return nil
 	} else if err != nil {
 		return fmt.Errorf("failed to check if config file exists: %w", err)
 	}
 
-	configDir := filepath.Dir(r.configPath)
-	originalBackupPath := filepath.Join(configDir, OriginalBackupName)
+	originalBackupPath := filepath.Join(filepath.Dir(path), OriginalBackupName)
+	if path != r.configPath {
+		originalBackupPath = path + ".original.backup"
+	}
 
 	if _, err := r.fileSystem.Stat(originalBackupPath); err == nil {
 		return nil
@@ -168,7 +171,7 @@ func (r *Repository) createOriginalBackupIfNeeded() error {
 		return fmt.Errorf("failed to check if original backup exists: %w", err)
 	}
 
-	if err := r.copyFile(r.configPath, originalBackupPath); err != nil {
+	if err := r.copyFile(path, originalBackupPath); err != nil {
 		return fmt.Errorf("failed to create original backup: %w", err)
 	}
 
@@ -16,21 +16,31 @@ package ssh_config_file
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/kevinburke/ssh_config"
 )
 
-// loadConfig reads and parses the SSH config file.
+// loadConfig reads and parses the main SSH config file.
+func (r *Repository) loadConfig() (*ssh_config.Config, error) {
+	return r.loadConfigFile(r.configPath)
+}
+
+// loadConfigFile reads and parses a single SSH config file. Include lines are
+// kept as marked comments and Match lines as pseudo hosts; see
+// neutralizeDirectives and loadConfigFiles.
 // If the file does not exist, it returns an empty config without error to support first-run behavior.
 // Uses in-memory cache to avoid redundant file I/O operations.
-func (r *Repository) loadConfig() (*ssh_config.Config, error) {
+func (r *Repository) loadConfigFile(path string) (*ssh_config.Config, error) {
 	start := time.Now()
+	cache := r.cacheFor(path)
 
 	// Try to get from cache first
-	if cached, valid := r.cache.get(r.fileSystem, r.configPath); valid {
+	if cached, valid := cache.get(r.fileSystem, path); valid {
 		if r.monitoring != nil {
 			r.monitoring.RecordOperation("ssh_config_load", time.Since(start), true)
 			r.monitoring.RecordCacheHit("ssh_config")
@@ -46,12 +56,12 @@ func (r *Repository) loadConfig() (*ssh_config.Config, error) {
 		r.monitoring.RecordCacheMiss("ssh_config")
 	}
 
-	file, err := r.fileSystem.Open(r.configPath)
+	file, err := r.fileSystem.Open(path)
 	if err != nil {
 		if r.fileSystem.IsNotExist(err) {
 			emptyConfig := &ssh_config.Config{Hosts: []*ssh_config.Host{}}
 			// Cache empty config for non-existent file
-			r.cache.set(r.fileSystem, r.configPath, emptyConfig)
+			cache.set(r.fileSystem, path, emptyConfig)
 			if r.monitoring != nil {
 				r.monitoring.RecordOperation("ssh_config_load", time.Since(start), true)
 			}
@@ -70,14 +80,22 @@ func (r *Repository) loadConfig() (*ssh_config.Config, error) {
 
 	// Get file info for size metrics
 	if r.monitoring != nil {
-		if info, err := r.fileSystem.Stat(r.configPath); err == nil {
+		if info, err := r.fileSystem.Stat(path); err == nil {
 			r.monitoring.GetMetrics().SetGauge("ssh_config_file_size_bytes", float64(info.Size()), map[string]string{
 				"operation": "load",
 			})
 		}
 	}
 
-	cfg, err := ssh_config.Decode(file)
+	data, err := io.ReadAll(file)
+	if err != nil {
+		if r.monitoring != nil {
+			r.monitoring.RecordOperation("ssh_config_load", time.Since(start), false)
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg, err := ssh_config.DecodeBytes(neutralizeDirectives(data))
 	if err != nil {
 		if r.monitoring != nil {
 			r.monitoring.RecordOperation("ssh_config_load", time.Since(start), false)
@@ -86,7 +104,7 @@ func (r *Repository) loadConfig() (*ssh_config.Config, error) {
 	}
 
 	// Cache the loaded config
-	r.cache.set(r.fileSystem, r.configPath, cfg)
+	cache.set(r.fileSystem, path, cfg)
 
 	if r.monitoring != nil {
 		r.monitoring.RecordOperation("ssh_config_load", time.Since(start), true)
@@ -99,12 +117,17 @@ func (r *Repository) loadConfig() (*ssh_config.Config, error) {
 	return cfg, nil
 }
 
-// saveConfig writes the SSH config back to the file with atomic operations and backup management.
+// saveConfig writes the main SSH config back to disk.
 func (r *Repository) saveConfig(cfg *ssh_config.Config) error {
+	return r.saveConfigFile(r.configPath, cfg)
+}
+
+// saveConfigFile writes an SSH config back to the file at path with atomic operations and backup management.
+func (r *Repository) saveConfigFile(path string, cfg *ssh_config.Config) error {
 	start := time.Now()
-	configDir := filepath.Dir(r.configPath)
+	configDir := filepath.Dir(path)
 
-	tempFile, err := r.createTempFile(configDir)
+	tempFile, err := r.createTempFile(configDir, filepath.Base(path))
 	if err != nil {
 		if r.monitoring != nil {
 			r.monitoring.RecordOperation("ssh_config_save", time.Since(start), false)
@@ -135,21 +158,21 @@ func (r *Repository) saveConfig(cfg *ssh_config.Config) error {
 	}
 
 	// Ensure a one-time original backup exists before any modifications managed by wooak.
-	if err := r.createOriginalBackupIfNeeded(); err != nil {
+	if err := r.createOriginalBackupIfNeeded(path); err != nil {
 		if r.monitoring != nil {
 			r.monitoring.RecordOperation("ssh_config_save", time.Since(start), false)
 		}
 		return fmt.Errorf("failed to create original backup: %w", err)
 	}
 
-	if err := r.createBackup(); err != nil {
+	if err := r.createBackup(path); err != nil {
 		if r.monitoring != nil {
 			r.monitoring.RecordOperation("ssh_config_save", time.Since(start), false)
 		}
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	if err := r.fileSystem.Rename(tempFile, r.configPath); err != nil {
+	if err := r.fileSystem.Rename(tempFile, path); err != nil {
 		if r.monitoring != nil {
 			r.monitoring.RecordOperation("ssh_config_save", time.Since(start), false)
 		}
@@ -157,7 +180,7 @@ func (r *Repository) saveConfig(cfg *ssh_config.Config) error {
 	}
 
 	// Invalidate cache after successful save
-	r.cache.invalidate()
+	r.cacheFor(path).invalidate()
 
 	if r.monitoring != nil {
 		r.monitoring.RecordOperation("ssh_config_save", time.Since(start), true)
@@ -166,7 +189,7 @@ func (r *Repository) saveConfig(cfg *ssh_config.Config) error {
 		})
 	}
 
-	r.logger.Infof("SSH config successfully updated: %s", r.configPath)
+	r.logger.Infof("SSH config successfully updated: %s", path)
 	return nil
 }
 
@@ -182,7 +205,7 @@ func (r *Repository) writeConfigToFile(filePath string, cfg *ssh_config.Config)
 		}
 	}()
 
-	configContent := cfg.String()
+	configContent := restoreDirectives(marshalConfig(cfg))
 	if _, err := file.WriteString(configContent); err != nil {
 		return fmt.Errorf("failed to write config content: %w", err)
 	}
@@ -195,9 +218,9 @@ func (r *Repository) writeConfigToFile(filePath string, cfg *ssh_config.Config)
 }
 
 // createTempFile creates a temporary file in the specified directory
-func (r *Repository) createTempFile(dir string) (string, error) {
+func (r *Repository) createTempFile(dir, baseName string) (string, error) {
 	timestamp := time.Now().Format("20060102150405")
-	tempFileName := fmt.Sprintf("config%s%s", timestamp, TempSuffix)
+	tempFileName := fmt.Sprintf("%s%s%s", baseName, timestamp, TempSuffix)
 	tempFilePath := filepath.Join(dir, tempFileName)
 
 	// Create the temp file with explicit 0600 permissions
@@ -211,3 +234,64 @@ func (r *Repository) createTempFile(dir string) (string, error) {
 
 	return tempFilePath, nil
 }
+
+// cacheFor returns the cache used for the config file at path. The main config
+// uses the repository cache; included files get their own lazily created cache.
+func (r *Repository) cacheFor(path string) *configCache {
+	if path == r.configPath {
+		return r.cache
+	}
+
+	r.includeCachesMu.Lock()
+	defer r.includeCachesMu.Unlock()
+
+	if r.includeCaches == nil {
+		r.includeCaches = make(map[string]*configCache)
+	}
+	cache, ok := r.includeCaches[path]
+	if !ok {
+		cache = newConfigCache()
+		r.includeCaches[path] = cache
+	}
+	return cache
+}
+
+// marshalConfig serializes cfg like cfg.String(), except that negated Host
+// patterns keep their leading "!", which the library drops when printing.
+func marshalConfig(cfg *ssh_config.Config) string {
+	var buf strings.Builder
+	for i, host := range cfg.Hosts {
+		out := host.String()
+		if i > 0 && hasNegatedPattern(host) {
+			line, rest, _ := strings.Cut(out, "\n")
+			trimmed := strings.TrimLeft(line, " \t")
+			header := line[:len(line)-len(trimmed)] + "Host " + hostPatterns(host)
+			if host.EOLComment != "" {
+				header += " #" + host.EOLComment
+			}
+			out = header + "\n" + rest
+		}
+		buf.WriteString(out)
+	}
+	return buf.String()
+}
+
+// patternString returns the pattern as written in the config, including the
+// "!" of a negated pattern. A non-negated pattern always matches its own text,
+// so a pattern that does not is negated.
+func patternString(p *ssh_config.Pattern) string {
+	host := &ssh_config.Host{Patterns: []*ssh_config.Pattern{p}}
+	if host.Matches(p.String()) {
+		return p.String()
+	}
+	return "!" + p.String()
+}
+
+func hasNegatedPattern(host *ssh_config.Host) bool {
+	for _, p := range host.Patterns {
+		if strings.HasPrefix(patternString(p), "!") {
+			return true
+		}
+	}
+	return false
+}
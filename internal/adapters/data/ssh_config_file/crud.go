@@ -66,9 +66,27 @@ func (r *Repository) matchesQuery(server domain.Server, query string) bool {
 	return false
 }
 
-// serverExists checks if a server with the given alias already exists in the config.
-func (r *Repository) serverExists(cfg *ssh_config.Config, alias string) bool {
-	return r.findHostByAlias(cfg, alias) != nil
+// findHostInFiles finds the host with the given alias across all loaded config
+// files. When sourceFile is set, a match in that file is preferred; otherwise
+// the first definition in evaluation order wins, as in OpenSSH.
+func (r *Repository) findHostInFiles(files []configFile, alias, sourceFile string) (*configFile, *ssh_config.Host) {
+	var (
+		firstFile *configFile
+		firstHost *ssh_config.Host
+	)
+	for i := range files {
+		host := r.findHostByAlias(files[i].cfg, alias)
+		if host == nil {
+			continue
+		}
+		if sourceFile == "" || files[i].path == sourceFile {
+			return &files[i], host
+		}
+		if firstHost == nil {
+			firstFile, firstHost = &files[i], host
+		}
+	}
+	return firstFile, firstHost
 }
 
 // findHostByAlias finds a host by its alias in the SSH config.
@@ -84,7 +102,7 @@ func (r *Repository) findHostByAlias(cfg *ssh_config.Config, alias string) *ssh_
 // hostContainsPattern checks if a host contains a specific pattern.
 func (r *Repository) hostContainsPattern(host *ssh_config.Host, target string) bool {
 	for _, pattern := range host.Patterns {
-		if pattern.String() == target {
+		if patternString(pattern) == target {
 			return true
 		}
 	}
@@ -0,0 +1,240 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh_config_file
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/kevinburke/ssh_config"
+)
+
+// multiValueKeys are options that accumulate across blocks instead of being
+// set once by the first block that mentions them.
+var multiValueKeys = map[string]bool{
+	"identityfile":    true,
+	"certificatefile": true,
+	"localforward":    true,
+	"remoteforward":   true,
+	"dynamicforward":  true,
+	"sendenv":         true,
+}
+
+// GetEffectiveConfig resolves every block that applies to alias, in the same
+// first-match-wins order ssh uses, and reports where each value came from.
+func (r *Repository) GetEffectiveConfig(alias string) (domain.EffectiveConfig, error) {
+	set, err := r.loadConfigSet()
+	if err != nil {
+		return domain.EffectiveConfig{}, fmt.Errorf("failed to load SSH config file (operation: effective config, alias: %q, path: %s): %w", alias, r.configPath, err)
+	}
+	return r.resolveEffectiveConfig(set.blocks, alias, localUsername()), nil
+}
+
+// GetEffectiveConfigs is GetEffectiveConfig for several aliases, loading the
+// config files only once.
+func (r *Repository) GetEffectiveConfigs(aliases []string) (map[string]domain.EffectiveConfig, error) {
+	set, err := r.loadConfigSet()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSH config file (operation: effective configs, aliases: %d, path: %s): %w", len(aliases), r.configPath, err)
+	}
+	localUser := localUsername()
+	configs := make(map[string]domain.EffectiveConfig, len(aliases))
+	for _, alias := range aliases {
+		configs[alias] = r.resolveEffectiveConfig(set.blocks, alias, localUser)
+	}
+	return configs, nil
+}
+
+// resolveEffectiveConfig walks blocks in evaluation order and collects the
+// options of every block that applies to alias.
+func (r *Repository) resolveEffectiveConfig(blocks []*configBlock, alias, localUser string) domain.EffectiveConfig {
+	result := domain.EffectiveConfig{Alias: alias}
+	applied := make(map[*configBlock]bool, len(blocks))
+	seen := make(map[string]bool)
+	unresolved := make(map[string]bool)
+
+	current := func(key string) string {
+		if opt, ok := result.Get(key); ok {
+			return opt.Value
+		}
+		return ""
+	}
+
+	for _, block := range blocks {
+		if block.guard != nil && !applied[block.guard] {
+			continue
+		}
+
+		label, kind := blockLabel(block)
+		switch kind {
+		case domain.BlockGlobal:
+			applied[block] = true
+		case domain.BlockHost:
+			applied[block] = block.host.Matches(alias)
+		case domain.BlockMatch:
+			ok, resolved := evaluateMatch(label, matchContext{
+				alias:     alias,
+				hostName:  current("HostName"),
+				user:      current("User"),
+				localUser: localUser,
+			})
+			if !resolved {
+				if !unresolved[label] {
+					unresolved[label] = true
+					result.Unresolved = append(result.Unresolved, label)
+				}
+				continue
+			}
+			applied[block] = ok
+		}
+		if !applied[block] {
+			continue
+		}
+
+		inherited := kind != domain.BlockHost || !r.hostContainsPattern(block.host, alias)
+		for _, node := range block.host.Nodes {
+			kv, ok := node.(*ssh_config.KV)
+			if !ok || kv.Key == "" {
+				continue
+			}
+			key := strings.ToLower(kv.Key)
+			if multiValueKeys[key] {
+				// Like ssh, ignore a value repeated by a file included twice.
+				key += " " + kv.Value
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result.Options = append(result.Options, domain.EffectiveOption{
+				Key:        r.getProperKeyCase(kv.Key),
+				Value:      kv.Value,
+				Block:      label,
+				Kind:       kind,
+				SourceFile: block.path,
+				Inherited:  inherited,
+			})
+		}
+	}
+
+	return result
+}
+
+// blockLabel describes a block the way it is written in the config file.
+func blockLabel(block *configBlock) (string, domain.BlockKind) {
+	if block.implicit {
+		return "global", domain.BlockGlobal
+	}
+	if line, ok := matchLine(block.host); ok {
+		keyword, args := splitDirective(line)
+		return keyword + " " + strings.Join(args, " "), domain.BlockMatch
+	}
+	return "Host " + hostPatterns(block.host), domain.BlockHost
+}
+
+func hostPatterns(host *ssh_config.Host) string {
+	patterns := make([]string, 0, len(host.Patterns))
+	for _, p := range host.Patterns {
+		patterns = append(patterns, patternString(p))
+	}
+	return strings.Join(patterns, " ")
+}
+
+// matchContext holds the values Match criteria are evaluated against.
+type matchContext struct {
+	alias     string
+	hostName  string
+	user      string
+	localUser string
+}
+
+// evaluateMatch evaluates a "Match ..." label. The second result is false when
+// the line uses a criterion that can only be decided by ssh at connect time
+// (exec, localnetwork, canonical, tagged, ...).
+func evaluateMatch(label string, ctx matchContext) (bool, bool) {
+	_, args := splitDirective(label)
+	if len(args) == 0 {
+		return false, false
+	}
+
+	matched := true
+	for i := 0; i < len(args); i++ {
+		criterion := strings.ToLower(args[i])
+		negate := strings.HasPrefix(criterion, "!")
+		criterion = strings.TrimPrefix(criterion, "!")
+
+		var ok bool
+		switch criterion {
+		case "all", "final":
+			ok = true
+		case "host", "originalhost", "user", "localuser":
+			if i+1 >= len(args) {
+				return false, false
+			}
+			i++
+			ok = matchPatternList(args[i], matchSubject(criterion, ctx))
+		default:
+			return false, false
+		}
+
+		if ok == negate {
+			matched = false
+		}
+	}
+	return matched, true
+}
+
+func matchSubject(criterion string, ctx matchContext) string {
+	switch criterion {
+	case "host":
+		if ctx.hostName != "" {
+			return strings.ReplaceAll(ctx.hostName, "%h", ctx.alias)
+		}
+		return ctx.alias
+	case "user":
+		if ctx.user != "" {
+			return ctx.user
+		}
+		return ctx.localUser
+	case "localuser":
+		return ctx.localUser
+	default:
+		return ctx.alias
+	}
+}
+
+// matchPatternList matches value against a comma-separated pattern list with
+// the same semantics as a Host line, including negation.
+func matchPatternList(list, value string) bool {
+	host := &ssh_config.Host{}
+	for _, p := range strings.Split(list, ",") {
+		pattern, err := ssh_config.NewPattern(strings.TrimSpace(p))
+		if err != nil {
+			continue
+		}
+		host.Patterns = append(host.Patterns, pattern)
+	}
+	return host.Matches(value)
+}
+
+func localUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
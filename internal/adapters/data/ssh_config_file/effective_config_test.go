@@ -0,0 +1,319 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh_config_file
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+const effectiveTestConfig = `ForwardAgent no
+
+Host web
+    HostName web.prod.example.com
+    User deploy
+
+Match host *.prod.example.com
+    ServerAliveInterval 30
+    User ignored
+
+Match exec "test -f /tmp/x"
+    LogLevel DEBUG
+
+Host *.prod !bastion
+    IdentityFile ~/.ssh/prod
+
+Host bastion web
+    Port 2222
+
+Host *
+    IdentityFile ~/.ssh/id_ed25519
+    Compression yes
+`
+
+func TestRepository_GetEffectiveConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	writeTestFile(t, configPath, effectiveTestConfig)
+
+	repo := NewRepository(zap.NewNop().Sugar(), configPath, filepath.Join(tmpDir, "metadata.json")).(*Repository)
+
+	servers, err := repo.ListServers("")
+	if err != nil {
+		t.Fatalf("Expected Match blocks to be tolerated, got: %v", err)
+	}
+	if len(servers) != 2 {
+		t.Errorf("Expected Match pseudo hosts to be hidden, got %+v", servers)
+	}
+
+	cfg, err := repo.GetEffectiveConfig("web")
+	if err != nil {
+		t.Fatalf("GetEffectiveConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		key       string
+		value     string
+		block     string
+		inherited bool
+	}{
+		{key: "ForwardAgent", value: "no", block: "global", inherited: true},
+		{key: "HostName", value: "web.prod.example.com", block: "Host web"},
+		{key: "User", value: "deploy", block: "Host web"},
+		{key: "ServerAliveInterval", value: "30", block: "Match host *.prod.example.com", inherited: true},
+		{key: "Port", value: "2222", block: "Host bastion web"},
+		{key: "Compression", value: "yes", block: "Host *", inherited: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			opt, ok := cfg.Get(tt.key)
+			if !ok {
+				t.Fatalf("Expected %s to be set", tt.key)
+			}
+			if opt.Value != tt.value || opt.Block != tt.block || opt.Inherited != tt.inherited {
+				t.Errorf("Got %+v, want value %q from %q (inherited %v)", opt, tt.value, tt.block, tt.inherited)
+			}
+		})
+	}
+
+	var identities []string
+	for _, opt := range cfg.Options {
+		if opt.Key == "IdentityFile" {
+			identities = append(identities, opt.Value)
+		}
+	}
+	if !reflect.DeepEqual(identities, []string{"~/.ssh/id_ed25519"}) {
+		t.Errorf("Expected only the Host * identity for web, got %v", identities)
+	}
+	if !reflect.DeepEqual(cfg.Unresolved, []string{`Match exec test -f /tmp/x`}) {
+		t.Errorf("Expected the exec Match block to be unresolved, got %v", cfg.Unresolved)
+	}
+	if _, ok := cfg.Get("LogLevel"); ok {
+		t.Error("Options from unresolved Match blocks must not be applied")
+	}
+
+	// The negated pattern excludes bastion from the *.prod profile.
+	cfg, _ = repo.GetEffectiveConfig("bastion")
+	if opt, _ := cfg.Get("IdentityFile"); opt.Value != "~/.ssh/id_ed25519" {
+		t.Errorf("Expected bastion to skip the *.prod profile, got %+v", opt)
+	}
+	cfg, _ = repo.GetEffectiveConfig("db.prod")
+	if opt, _ := cfg.Get("IdentityFile"); opt.Value != "~/.ssh/prod" {
+		t.Errorf("Expected db.prod to use the *.prod profile, got %+v", opt)
+	}
+}
+
+func TestRepository_GetEffectiveConfigs(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	writeTestFile(t, configPath, effectiveTestConfig)
+
+	repo := NewRepository(zap.NewNop().Sugar(), configPath, filepath.Join(tmpDir, "metadata.json")).(*Repository)
+
+	aliases := []string{"web", "bastion", "db.prod"}
+	configs, err := repo.GetEffectiveConfigs(aliases)
+	if err != nil {
+		t.Fatalf("GetEffectiveConfigs failed: %v", err)
+	}
+	if len(configs) != len(aliases) {
+		t.Fatalf("Expected %d configs, got %d", len(aliases), len(configs))
+	}
+	for _, alias := range aliases {
+		want, err := repo.GetEffectiveConfig(alias)
+		if err != nil {
+			t.Fatalf("GetEffectiveConfig(%q) failed: %v", alias, err)
+		}
+		if !reflect.DeepEqual(configs[alias], want) {
+			t.Errorf("%s: batch config %+v differs from %+v", alias, configs[alias], want)
+		}
+	}
+}
+
+func TestRepository_GetEffectiveConfig_ConditionalInclude(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	writeTestFile(t, configPath, `Host *.corp
+    Include corp.conf
+`)
+	writeTestFile(t, filepath.Join(tmpDir, "corp.conf"), `User corpuser
+`)
+
+	repo := NewRepository(zap.NewNop().Sugar(), configPath, filepath.Join(tmpDir, "metadata.json")).(*Repository)
+
+	cfg, err := repo.GetEffectiveConfig("git.corp")
+	if err != nil {
+		t.Fatalf("GetEffectiveConfig failed: %v", err)
+	}
+	if opt, _ := cfg.Get("User"); opt.Value != "corpuser" || opt.SourceFile != filepath.Join(tmpDir, "corp.conf") {
+		t.Errorf("Expected User from the included file, got %+v", opt)
+	}
+
+	cfg, _ = repo.GetEffectiveConfig("home")
+	if _, ok := cfg.Get("User"); ok {
+		t.Error("Include inside a non-matching Host block must not apply")
+	}
+}
+
+func TestRepository_GetEffectiveConfig_IncludedTwice(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	writeTestFile(t, configPath, `Host *.corp
+    Include common.conf
+
+Host *.lab
+    Include common.conf
+
+Host *
+    Include common.conf
+`)
+	writeTestFile(t, filepath.Join(tmpDir, "common.conf"), `User shared
+IdentityFile ~/.ssh/shared
+
+Host *.internal
+    ProxyJump bastion
+`)
+
+	repo := NewRepository(zap.NewNop().Sugar(), configPath, filepath.Join(tmpDir, "metadata.json")).(*Repository)
+
+	for _, alias := range []string{"git.corp", "box.lab"} {
+		cfg, err := repo.GetEffectiveConfig(alias)
+		if err != nil {
+			t.Fatalf("GetEffectiveConfig(%q) failed: %v", alias, err)
+		}
+		if opt, _ := cfg.Get("User"); opt.Value != "shared" || opt.SourceFile != filepath.Join(tmpDir, "common.conf") {
+			t.Errorf("%s: expected User from the file included under its Host guard, got %+v", alias, opt)
+		}
+		identities := 0
+		for _, opt := range cfg.Options {
+			if opt.Key == "IdentityFile" {
+				identities++
+			}
+		}
+		if identities != 1 {
+			t.Errorf("%s: expected the repeated IdentityFile once, got %d", alias, identities)
+		}
+	}
+
+	profiles, err := repo.ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles failed: %v", err)
+	}
+	var names []string
+	for _, p := range profiles {
+		names = append(names, p.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"*.corp", "*.internal", "*.lab", "*"}) {
+		t.Errorf("Expected each profile once, got %v", names)
+	}
+
+	files, err := repo.loadConfigFiles()
+	if err != nil {
+		t.Fatalf("loadConfigFiles failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected the main config and common.conf once each, got %d files", len(files))
+	}
+}
+
+func TestEvaluateMatch(t *testing.T) {
+	ctx := matchContext{alias: "web", hostName: "web.example.com", user: "", localUser: "alice"}
+	tests := []struct {
+		label    string
+		matched  bool
+		resolved bool
+	}{
+		{label: "Match all", matched: true, resolved: true},
+		{label: "Match host *.example.com", matched: true, resolved: true},
+		{label: "Match originalhost web,db", matched: true, resolved: true},
+		{label: "Match !host *.example.com", matched: false, resolved: true},
+		{label: "Match user alice localuser bob", matched: false, resolved: true},
+		{label: "Match user alice", matched: true, resolved: true},
+		{label: "Match host *.example.com exec true", matched: false, resolved: false},
+		{label: "Match host", matched: false, resolved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			matched, resolved := evaluateMatch(tt.label, ctx)
+			if matched != tt.matched || resolved != tt.resolved {
+				t.Errorf("evaluateMatch(%q) = %v, %v; want %v, %v", tt.label, matched, resolved, tt.matched, tt.resolved)
+			}
+		})
+	}
+}
+
+func TestRepository_Profiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	writeTestFile(t, configPath, effectiveTestConfig)
+
+	repo := NewRepository(zap.NewNop().Sugar(), configPath, filepath.Join(tmpDir, "metadata.json")).(*Repository)
+
+	profiles, err := repo.ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles failed: %v", err)
+	}
+	var names []string
+	for _, p := range profiles {
+		names = append(names, p.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"*.prod !bastion", "*"}) {
+		t.Fatalf("Unexpected profiles: %v", names)
+	}
+
+	updated := profiles[0]
+	updated.Patterns = []string{"*.prod", "!bastion", "!jump"}
+	updated.Options = []domain.ConfigOption{{Key: "identityfile", Value: "~/.ssh/prod2"}, {Key: "User", Value: "ops"}}
+	if err := repo.UpdateProfile(profiles[0], updated); err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+
+	if err := repo.AddProfile(domain.Profile{Patterns: []string{"*.staging"}, Options: []domain.ConfigOption{{Key: "User", Value: "stage"}}}); err != nil {
+		t.Fatalf("AddProfile failed: %v", err)
+	}
+	if err := repo.AddProfile(domain.Profile{Patterns: []string{"*"}}); err == nil {
+		t.Error("Expected error when adding a duplicate profile")
+	}
+	if err := repo.DeleteProfile(profiles[1]); err != nil {
+		t.Fatalf("DeleteProfile failed: %v", err)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	got := string(content)
+	for _, want := range []string{
+		"Host *.prod !bastion !jump\n",
+		"IdentityFile ~/.ssh/prod2\n",
+		"User ops\n",
+		"Match host *.prod.example.com\n",
+		`Match exec "test -f /tmp/x"` + "\n",
+		"Host *.staging",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected config to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Host *\n") || strings.Contains(got, "Compression yes") {
+		t.Errorf("Expected Host * profile to be deleted, got:\n%s", got)
+	}
+}
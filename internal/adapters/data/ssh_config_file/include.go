@@ -0,0 +1,407 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh_config_file
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kevinburke/ssh_config"
+)
+
+const (
+	// includeMarker prefixes Include lines that are turned into comments before
+	// decoding. The ssh_config library would otherwise expand Include itself,
+	// always relative to the real ~/.ssh, bypassing FileSystem and failing on
+	// cycles. The marker is stripped again when the file is written back.
+	includeMarker = "wooak-include:"
+
+	// matchHostPrefix names the pseudo Host that stands in for a Match line.
+	// The library refuses to parse Match, so each Match line is rewritten to
+	// "Host wooak-match-<hex of the original line>" before decoding; its
+	// options are then kept in place and the line is restored on write.
+	matchHostPrefix = "wooak-match-"
+
+	// maxIncludeDepth mirrors OpenSSH's READCONF_MAX_DEPTH.
+	maxIncludeDepth = 16
+)
+
+// configFile is a parsed SSH config file together with the path it was read from.
+type configFile struct {
+	path string
+	cfg  *ssh_config.Config
+}
+
+// configBlock is a single Host or Match block (or the implicit global section
+// at the top of a file) in OpenSSH evaluation order.
+type configBlock struct {
+	host     *ssh_config.Host
+	path     string
+	implicit bool
+	// guard is the enclosing block when the file was included from inside a
+	// Host or Match block; the block only applies if its guard does.
+	guard *configBlock
+}
+
+// configSet is the main config together with all included files.
+type configSet struct {
+	// files lists every inclusion in evaluation order; a file included more
+	// than once appears once per Include.
+	files  []configFile
+	blocks []*configBlock
+	// parsed caches each file so that repeated inclusions share one parse.
+	parsed map[string]*ssh_config.Config
+}
+
+// loadConfigFiles loads the main config and, recursively, every file pulled in
+// with Include. Files are returned in evaluation order: the main config first,
+// followed by included files depth-first in the order their Include lines
+// appear. A file included more than once, e.g. from two Host blocks, is
+// returned only the first time.
+func (r *Repository) loadConfigFiles() ([]configFile, error) {
+	set, err := r.loadConfigSet()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]configFile, 0, len(set.files))
+	seen := make(map[string]bool, len(set.files))
+	for _, file := range set.files {
+		if key := filepath.Clean(file.path); !seen[key] {
+			seen[key] = true
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// loadConfigSet loads all config files like loadConfigFiles and additionally
+// flattens their blocks into evaluation order. Blocks of an included file are
+// placed right after the block containing the Include line, once for every
+// Include that reaches the file, as ssh processes each of them. Only an
+// Include of a file that is already being read, i.e. a cycle, is skipped.
+func (r *Repository) loadConfigSet() (*configSet, error) {
+	set := &configSet{files: make([]configFile, 0, 1), parsed: make(map[string]*ssh_config.Config)}
+	including := make(map[string]bool)
+	if err := r.collectConfigFiles(r.configPath, 0, nil, including, set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// collectConfigFiles adds path and the files it includes to set. including
+// holds the files on the current Include chain.
+func (r *Repository) collectConfigFiles(path string, depth int, guard *configBlock, including map[string]bool, set *configSet) error {
+	key := filepath.Clean(path)
+	including[key] = true
+	defer delete(including, key)
+
+	cfg, ok := set.parsed[key]
+	if !ok {
+		var err error
+		cfg, err = r.loadConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to load config file %s: %w", path, err)
+		}
+		set.parsed[key] = cfg
+	}
+	set.files = append(set.files, configFile{path: path, cfg: cfg})
+
+	for i, host := range cfg.Hosts {
+		block := &configBlock{host: host, path: path, implicit: i == 0, guard: guard}
+		set.blocks = append(set.blocks, block)
+
+		// Files included from the global section inherit this file's guard.
+		includeGuard := guard
+		if !block.implicit {
+			includeGuard = block
+		}
+
+		for _, pattern := range hostIncludePatterns(host) {
+			matches, err := r.glob(r.resolveIncludePath(pattern))
+			if err != nil {
+				return fmt.Errorf("invalid Include pattern %q in %s: %w", pattern, path, err)
+			}
+
+			for _, match := range matches {
+				if including[filepath.Clean(match)] {
+					r.logger.Warnf("skipping Include of %s from %s: include cycle", match, path)
+					continue
+				}
+				if depth+1 > maxIncludeDepth {
+					return fmt.Errorf("include depth exceeds %d while including %s from %s", maxIncludeDepth, match, path)
+				}
+				if err := r.collectConfigFiles(match, depth+1, includeGuard, including, set); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveIncludePath expands ~ and environment variables and makes relative
+// paths absolute. As in OpenSSH, relative paths in a user config are relative
+// to ~/.ssh, i.e. the directory of the main config file, not of the file that
+// contains the Include.
+func (r *Repository) resolveIncludePath(pattern string) string {
+	pattern = os.ExpandEnv(pattern)
+	if pattern == "~" || strings.HasPrefix(pattern, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern = filepath.Join(home, strings.TrimPrefix(pattern, "~"))
+		}
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(r.configPath), pattern)
+	}
+	return filepath.Clean(pattern)
+}
+
+// glob expands pattern through the repository FileSystem. Wildcards are
+// supported in every path component; matches are returned sorted, like glob(3).
+// Directories are never returned since only regular files can be included.
+func (r *Repository) glob(pattern string) ([]string, error) {
+	if !hasGlobMeta(pattern) {
+		info, err := r.fileSystem.Stat(pattern)
+		if err != nil || info.IsDir() {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	dir, file := filepath.Split(pattern)
+	dir = filepath.Clean(dir)
+
+	dirs := []string{dir}
+	if hasGlobMeta(dir) {
+		var err error
+		dirs, err = r.globDirs(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []string
+	for _, d := range dirs {
+		entries, err := r.fileSystem.ReadDir(d)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			ok, err := filepath.Match(file, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matches = append(matches, filepath.Join(d, entry.Name()))
+			}
+		}
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// globDirs expands a directory pattern into the directories it matches.
+func (r *Repository) globDirs(pattern string) ([]string, error) {
+	if !hasGlobMeta(pattern) {
+		info, err := r.fileSystem.Stat(pattern)
+		if err != nil || !info.IsDir() {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	parent, base := filepath.Split(pattern)
+	parents, err := r.globDirs(filepath.Clean(parent))
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, p := range parents {
+		entries, err := r.fileSystem.ReadDir(p)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			ok, err := filepath.Match(base, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				dirs = append(dirs, filepath.Join(p, entry.Name()))
+			}
+		}
+	}
+	return dirs, nil
+}
+
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// neutralizeDirectives rewrites the directives the ssh_config decoder cannot
+// handle itself: Include lines become comments marked with includeMarker and
+// Match lines become pseudo Host blocks named with matchHostPrefix.
+func neutralizeDirectives(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(trimmed)]
+		switch {
+		case isIncludeLine(trimmed):
+			lines[i] = indent + "#" + includeMarker + trimmed
+		case isMatchLine(trimmed):
+			lines[i] = indent + "Host " + matchHostPrefix + hex.EncodeToString([]byte(strings.TrimRight(trimmed, "\r")))
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// restoreDirectives reverses neutralizeDirectives on serialized config content.
+func restoreDirectives(content string) string {
+	if !strings.Contains(content, includeMarker) && !strings.Contains(content, matchHostPrefix) {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(trimmed)]
+		if strings.HasPrefix(trimmed, "#"+includeMarker) {
+			lines[i] = indent + strings.TrimPrefix(trimmed, "#"+includeMarker)
+			continue
+		}
+		if original, ok := matchLineFromHostLine(trimmed); ok {
+			lines[i] = indent + original
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matchLineFromHostLine decodes the original Match line from a pseudo Host line.
+func matchLineFromHostLine(line string) (string, bool) {
+	keyword, args := splitDirective(line)
+	if !strings.EqualFold(keyword, "host") || len(args) != 1 {
+		return "", false
+	}
+	return decodeMatchPattern(args[0])
+}
+
+// decodeMatchPattern returns the Match line encoded in a pseudo Host pattern.
+func decodeMatchPattern(pattern string) (string, bool) {
+	if !strings.HasPrefix(pattern, matchHostPrefix) {
+		return "", false
+	}
+	decoded, err := hex.DecodeString(strings.TrimPrefix(pattern, matchHostPrefix))
+	if err != nil {
+		return "", false
+	}
+	return string(decoded), true
+}
+
+// matchLine returns the original Match line if host is a Match pseudo host.
+func matchLine(host *ssh_config.Host) (string, bool) {
+	if len(host.Patterns) != 1 {
+		return "", false
+	}
+	return decodeMatchPattern(host.Patterns[0].String())
+}
+
+func isMatchLine(trimmed string) bool {
+	keyword, _ := splitDirective(trimmed)
+	return strings.EqualFold(keyword, "match")
+}
+
+func isIncludeLine(trimmed string) bool {
+	keyword, _ := splitDirective(trimmed)
+	return strings.EqualFold(keyword, "include")
+}
+
+// hostIncludePatterns returns the arguments of all Include lines in a block
+// in the order they appear.
+func hostIncludePatterns(host *ssh_config.Host) []string {
+	var patterns []string
+	for _, node := range host.Nodes {
+		empty, ok := node.(*ssh_config.Empty)
+		if !ok || !strings.HasPrefix(empty.Comment, includeMarker) {
+			continue
+		}
+		_, args := splitDirective(strings.TrimPrefix(empty.Comment, includeMarker))
+		patterns = append(patterns, args...)
+	}
+	return patterns
+}
+
+// splitDirective splits a config line into its keyword and arguments. It
+// accepts both "Key value" and "Key=value" forms, honors double quotes and
+// stops at an unquoted comment.
+func splitDirective(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return line, nil
+	}
+	keyword := line[:end]
+	rest := strings.TrimLeft(line[end:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+
+	var (
+		args    []string
+		current strings.Builder
+		quoted  bool
+		hasArg  bool
+	)
+	for _, c := range rest {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			hasArg = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		case !quoted && c == '#':
+			if hasArg {
+				args = append(args, current.String())
+			}
+			return keyword, args
+		default:
+			current.WriteRune(c)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return keyword, args
+}
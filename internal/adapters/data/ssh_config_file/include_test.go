@@ -0,0 +1,236 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh_config_file
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func findServer(servers []domain.Server, alias string) (domain.Server, bool) {
+	for _, s := range servers {
+		if s.Alias == alias {
+			return s, true
+		}
+	}
+	return domain.Server{}, false
+}
+
+func TestRepository_Include_ListServers(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	metaDataPath := filepath.Join(tmpDir, "metadata.json")
+
+	writeTestFile(t, configPath, `Include config.d/*
+Include "`+filepath.Join(tmpDir, "extra", "*.conf")+`"
+
+Host main
+    HostName main.example.com
+`)
+	writeTestFile(t, filepath.Join(tmpDir, "config.d", "10-web"), `Host web
+    HostName web.example.com
+    # Relative includes resolve against the main config directory
+    Include nested/db
+`)
+	writeTestFile(t, filepath.Join(tmpDir, "nested", "db"), `Host db
+    HostName db.example.com
+`)
+	writeTestFile(t, filepath.Join(tmpDir, "extra", "a.conf"), `Host extra
+    HostName extra.example.com
+`)
+	writeTestFile(t, filepath.Join(tmpDir, "extra", "ignored.txt"), `Host ignored
+    HostName ignored.example.com
+`)
+
+	repo := NewRepository(zap.NewNop().Sugar(), configPath, metaDataPath).(*Repository)
+	servers, err := repo.ListServers("")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	want := map[string]string{
+		"main":  configPath,
+		"web":   filepath.Join(tmpDir, "config.d", "10-web"),
+		"db":    filepath.Join(tmpDir, "nested", "db"),
+		"extra": filepath.Join(tmpDir, "extra", "a.conf"),
+	}
+	if len(servers) != len(want) {
+		t.Fatalf("Expected %d servers, got %d: %+v", len(want), len(servers), servers)
+	}
+	for alias, source := range want {
+		server, ok := findServer(servers, alias)
+		if !ok {
+			t.Errorf("Expected server %q to be listed", alias)
+			continue
+		}
+		if server.SourceFile != source {
+			t.Errorf("Server %q: expected SourceFile %s, got %s", alias, source, server.SourceFile)
+		}
+	}
+}
+
+func TestRepository_Include_Cycle(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	metaDataPath := filepath.Join(tmpDir, "metadata.json")
+
+	writeTestFile(t, configPath, `Include a
+Host main
+    HostName main.example.com
+`)
+	writeTestFile(t, filepath.Join(tmpDir, "a"), `Include b
+Host a
+    HostName a.example.com
+`)
+	writeTestFile(t, filepath.Join(tmpDir, "b"), `Include a
+Include config
+Host b
+    HostName b.example.com
+`)
+
+	repo := NewRepository(zap.NewNop().Sugar(), configPath, metaDataPath).(*Repository)
+	servers, err := repo.ListServers("")
+	if err != nil {
+		t.Fatalf("Expected cyclic includes to be tolerated, got: %v", err)
+	}
+	if len(servers) != 3 {
+		t.Errorf("Expected 3 servers, got %d", len(servers))
+	}
+}
+
+func TestRepository_Include_UpdateAndDeleteWriteToSourceFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	includedPath := filepath.Join(tmpDir, "config.d", "web")
+	metaDataPath := filepath.Join(tmpDir, "metadata.json")
+
+	mainContent := `Include config.d/*
+
+Host main
+    HostName main.example.com
+`
+	writeTestFile(t, configPath, mainContent)
+	writeTestFile(t, includedPath, `Host web
+    HostName web.example.com
+    User old
+
+Host api
+    HostName api.example.com
+`)
+
+	repo := NewRepository(zap.NewNop().Sugar(), configPath, metaDataPath).(*Repository)
+	servers, err := repo.ListServers("")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	web, _ := findServer(servers, "web")
+
+	updated := web
+	updated.User = "new"
+	if err := repo.UpdateServer(web, updated); err != nil {
+		t.Fatalf("UpdateServer failed: %v", err)
+	}
+
+	included, err := os.ReadFile(includedPath)
+	if err != nil {
+		t.Fatalf("Failed to read included file: %v", err)
+	}
+	if !strings.Contains(string(included), "User new") {
+		t.Errorf("Expected included file to be updated, got:\n%s", included)
+	}
+	mainAfter, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read main config: %v", err)
+	}
+	if string(mainAfter) != mainContent {
+		t.Errorf("Expected main config to be untouched, got:\n%s", mainAfter)
+	}
+
+	api, _ := findServer(servers, "api")
+	if err := repo.DeleteServer(api); err != nil {
+		t.Fatalf("DeleteServer failed: %v", err)
+	}
+	included, _ = os.ReadFile(includedPath)
+	if strings.Contains(string(included), "Host api") {
+		t.Errorf("Expected api to be removed from included file, got:\n%s", included)
+	}
+
+	// New servers still go to the main config, and the Include line survives the rewrite.
+	if err := repo.AddServer(domain.Server{Alias: "new", Host: "new.example.com"}); err != nil {
+		t.Fatalf("AddServer failed: %v", err)
+	}
+	mainAfter, _ = os.ReadFile(configPath)
+	if !strings.HasPrefix(string(mainAfter), "Include config.d/*\n") || !strings.Contains(string(mainAfter), "Host new") {
+		t.Errorf("Unexpected main config after add:\n%s", mainAfter)
+	}
+
+	// Adding an alias that already exists in an included file is rejected.
+	if err := repo.AddServer(domain.Server{Alias: "web", Host: "dup.example.com"}); err == nil {
+		t.Error("Expected error when adding an alias defined in an included file")
+	}
+}
+
+func TestSplitDirective(t *testing.T) {
+	tests := []struct {
+		line    string
+		keyword string
+		args    []string
+	}{
+		{line: "Include config.d/*", keyword: "Include", args: []string{"config.d/*"}},
+		{line: "include=a b", keyword: "include", args: []string{"a", "b"}},
+		{line: `Include "my dir/*" other # comment`, keyword: "Include", args: []string{"my dir/*", "other"}},
+		{line: "# Include nothing", keyword: "", args: nil},
+		{line: "Include", keyword: "Include", args: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			keyword, args := splitDirective(tt.line)
+			if keyword != tt.keyword || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("splitDirective(%q) = %q, %q; want %q, %q", tt.line, keyword, args, tt.keyword, tt.args)
+			}
+		})
+	}
+}
+
+func TestNeutralizeAndRestoreDirectives(t *testing.T) {
+	content := "Include a\n  include = b # note\nHost x\n    IncludeFoo bar\nMatch user root exec \"true\" # c\n    User admin\n"
+	neutralized := string(neutralizeDirectives([]byte(content)))
+	if strings.Count(neutralized, "#"+includeMarker) != 2 {
+		t.Fatalf("Expected two neutralized Include lines, got:\n%s", neutralized)
+	}
+	if strings.Count(neutralized, "Host "+matchHostPrefix) != 1 {
+		t.Fatalf("Expected one Match pseudo host, got:\n%s", neutralized)
+	}
+	if restored := restoreDirectives(neutralized); restored != content {
+		t.Errorf("Round trip mismatch:\n got %q\nwant %q", restored, content)
+	}
+}
@@ -24,14 +24,18 @@ import (
 )
 
 // toDomainServer converts ssh_config.Config to a slice of domain.Server.
-func (r *Repository) toDomainServer(cfg *ssh_config.Config) []domain.Server {
+func (r *Repository) toDomainServer(cfg *ssh_config.Config, sourceFile string) []domain.Server {
 	servers := make([]domain.Server, 0, len(cfg.Hosts))
 	for _, host := range cfg.Hosts {
+		// Match blocks only contribute inherited options; see effective_config.go.
+		if _, ok := matchLine(host); ok {
+			continue
+		}
 
 		aliases := make([]string, 0, len(host.Patterns))
 
 		for _, pattern := range host.Patterns {
-			alias := pattern.String()
+			alias := patternString(pattern)
 			// Skip if alias contains wildcards (not a concrete Host)
 			if strings.ContainsAny(alias, "!*?[]") {
 				continue
@@ -46,6 +50,7 @@ func (r *Repository) toDomainServer(cfg *ssh_config.Config) []domain.Server {
 			Aliases:       aliases,
 			Port:          22,
 			IdentityFiles: []string{},
+			SourceFile:    sourceFile,
 		}
 
 		for _, node := range host.Nodes {
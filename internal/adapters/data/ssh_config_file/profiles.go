@@ -0,0 +1,228 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh_config_file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/kevinburke/ssh_config"
+)
+
+// ListProfiles returns every wildcard Host block across the main config and
+// included files, in evaluation order.
+func (r *Repository) ListProfiles() ([]domain.Profile, error) {
+	set, err := r.loadConfigSet()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSH config file (operation: list profiles, path: %s): %w", r.configPath, err)
+	}
+
+	profiles := make([]domain.Profile, 0)
+	seen := make(map[*ssh_config.Host]bool)
+	for _, block := range set.blocks {
+		// A file included more than once yields the same hosts again.
+		if block.implicit || !isProfileHost(block.host) || seen[block.host] {
+			continue
+		}
+		seen[block.host] = true
+		profiles = append(profiles, toDomainProfile(block.host, block.path))
+	}
+	return profiles, nil
+}
+
+// AddProfile appends a new wildcard Host block to the end of the main config,
+// where it acts as a default for every matching server defined above it.
+func (r *Repository) AddProfile(profile domain.Profile) error {
+	files, err := r.loadConfigFiles()
+	if err != nil {
+		return fmt.Errorf("failed to load SSH config file (operation: add profile, patterns: %q, path: %s): %w", profile.Name(), r.configPath, err)
+	}
+	if file, _ := findProfileHost(files, profile.Name(), ""); file != nil {
+		return fmt.Errorf("profile '%s' already exists in SSH config (path: %s)", profile.Name(), file.path)
+	}
+
+	patterns, err := toPatterns(profile.Patterns)
+	if err != nil {
+		return fmt.Errorf("invalid profile patterns %q: %w", profile.Name(), err)
+	}
+	host := &ssh_config.Host{
+		Patterns:   patterns,
+		Nodes:      make([]ssh_config.Node, 0, len(profile.Options)),
+		EOLComment: "Added by wooak",
+	}
+	for _, opt := range profile.Options {
+		r.addKVNodeIfNotEmpty(host, r.getProperKeyCase(opt.Key), opt.Value)
+	}
+
+	cfg := files[0].cfg
+	cfg.Hosts = append(cfg.Hosts, host)
+	if err := r.saveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save SSH config file (operation: add profile, patterns: %q, path: %s): %w", profile.Name(), r.configPath, err)
+	}
+	return nil
+}
+
+// UpdateProfile rewrites the patterns and options of an existing wildcard Host
+// block in the file that defines it. Comments and Include lines are kept.
+func (r *Repository) UpdateProfile(profile domain.Profile, newProfile domain.Profile) error {
+	files, err := r.loadConfigFiles()
+	if err != nil {
+		return fmt.Errorf("failed to load SSH config file (operation: update profile, patterns: %q -> %q, path: %s): %w", profile.Name(), newProfile.Name(), r.configPath, err)
+	}
+
+	file, host := findProfileHost(files, profile.Name(), profile.SourceFile)
+	if host == nil {
+		return fmt.Errorf("profile '%s' not found in SSH config (path: %s)", profile.Name(), r.configPath)
+	}
+	if profile.Name() != newProfile.Name() {
+		if existing, _ := findProfileHost(files, newProfile.Name(), ""); existing != nil {
+			return fmt.Errorf("profile '%s' already exists in SSH config (path: %s)", newProfile.Name(), existing.path)
+		}
+	}
+
+	patterns, err := toPatterns(newProfile.Patterns)
+	if err != nil {
+		return fmt.Errorf("invalid profile patterns %q: %w", newProfile.Name(), err)
+	}
+	host.Patterns = patterns
+	host.Nodes = r.replaceKVNodes(host.Nodes, newProfile.Options)
+
+	if err := r.saveConfigFile(file.path, file.cfg); err != nil {
+		return fmt.Errorf("failed to save SSH config file (operation: update profile, patterns: %q -> %q, path: %s): %w", profile.Name(), newProfile.Name(), file.path, err)
+	}
+	return nil
+}
+
+// DeleteProfile removes a wildcard Host block from the file that defines it.
+func (r *Repository) DeleteProfile(profile domain.Profile) error {
+	files, err := r.loadConfigFiles()
+	if err != nil {
+		return fmt.Errorf("failed to load SSH config file (operation: delete profile, patterns: %q, path: %s): %w", profile.Name(), r.configPath, err)
+	}
+
+	file, host := findProfileHost(files, profile.Name(), profile.SourceFile)
+	if host == nil {
+		return fmt.Errorf("profile '%s' not found in SSH config (path: %s)", profile.Name(), r.configPath)
+	}
+
+	hosts := make([]*ssh_config.Host, 0, len(file.cfg.Hosts))
+	for _, h := range file.cfg.Hosts {
+		if h != host {
+			hosts = append(hosts, h)
+		}
+	}
+	file.cfg.Hosts = hosts
+
+	if err := r.saveConfigFile(file.path, file.cfg); err != nil {
+		return fmt.Errorf("failed to save SSH config file (operation: delete profile, patterns: %q, path: %s): %w", profile.Name(), file.path, err)
+	}
+	return nil
+}
+
+// replaceKVNodes swaps all KV nodes for options, inserting them where the first
+// KV used to be so surrounding comments stay in place.
+func (r *Repository) replaceKVNodes(nodes []ssh_config.Node, options []domain.ConfigOption) []ssh_config.Node {
+	newKVs := make([]ssh_config.Node, 0, len(options))
+	for _, opt := range options {
+		if opt.Value == "" {
+			continue
+		}
+		newKVs = append(newKVs, &ssh_config.KV{Key: r.getProperKeyCase(opt.Key), Value: opt.Value})
+	}
+
+	result := make([]ssh_config.Node, 0, len(nodes)+len(newKVs))
+	inserted := false
+	for _, node := range nodes {
+		if _, ok := node.(*ssh_config.KV); ok {
+			if !inserted {
+				result = append(result, newKVs...)
+				inserted = true
+			}
+			continue
+		}
+		result = append(result, node)
+	}
+	if !inserted {
+		result = append(result, newKVs...)
+	}
+	return result
+}
+
+// isProfileHost reports whether host is a wildcard Host block, i.e. one whose
+// options are inherited by other servers rather than describing a server.
+func isProfileHost(host *ssh_config.Host) bool {
+	if _, ok := matchLine(host); ok {
+		return false
+	}
+	for _, p := range host.Patterns {
+		if strings.ContainsAny(patternString(p), "!*?[]") {
+			return true
+		}
+	}
+	return false
+}
+
+// findProfileHost finds a wildcard Host block by its full pattern list. When
+// sourceFile is set, a match in that file is preferred.
+func findProfileHost(files []configFile, name, sourceFile string) (*configFile, *ssh_config.Host) {
+	var (
+		firstFile *configFile
+		firstHost *ssh_config.Host
+	)
+	for i := range files {
+		for j, host := range files[i].cfg.Hosts {
+			if j == 0 || !isProfileHost(host) || hostPatterns(host) != name {
+				continue
+			}
+			if sourceFile == "" || files[i].path == sourceFile {
+				return &files[i], host
+			}
+			if firstHost == nil {
+				firstFile, firstHost = &files[i], host
+			}
+		}
+	}
+	return firstFile, firstHost
+}
+
+func toDomainProfile(host *ssh_config.Host, sourceFile string) domain.Profile {
+	profile := domain.Profile{
+		Patterns:   make([]string, 0, len(host.Patterns)),
+		Options:    make([]domain.ConfigOption, 0, len(host.Nodes)),
+		SourceFile: sourceFile,
+	}
+	for _, p := range host.Patterns {
+		profile.Patterns = append(profile.Patterns, patternString(p))
+	}
+	for _, node := range host.Nodes {
+		if kv, ok := node.(*ssh_config.KV); ok && kv.Key != "" {
+			profile.Options = append(profile.Options, domain.ConfigOption{Key: kv.Key, Value: kv.Value})
+		}
+	}
+	return profile
+}
+
+func toPatterns(values []string) ([]*ssh_config.Pattern, error) {
+	patterns := make([]*ssh_config.Pattern, 0, len(values))
+	for _, v := range values {
+		p, err := ssh_config.NewPattern(v)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
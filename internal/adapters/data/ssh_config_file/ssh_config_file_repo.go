@@ -16,6 +16,7 @@ package ssh_config_file
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/aryasoni98/wooak/internal/core/domain"
 	"github.com/aryasoni98/wooak/internal/core/ports"
@@ -32,6 +33,10 @@ type Repository struct {
 	logger          *zap.SugaredLogger
 	monitoring      *monitoring.MonitoringService
 	cache           *configCache
+
+	// includeCaches holds per-file caches for files pulled in with Include.
+	includeCaches   map[string]*configCache
+	includeCachesMu sync.Mutex
 }
 
 // NewRepository creates a new SSH config repository.
@@ -64,14 +69,18 @@ func (r *Repository) SetMonitoring(mon *monitoring.MonitoringService) {
 }
 
 // ListServers returns all servers matching the query pattern.
-// Empty query returns all servers.
+// Empty query returns all servers. Hosts from files pulled in with Include are
+// listed too, each recording the file it was read from in SourceFile.
 func (r *Repository) ListServers(query string) ([]domain.Server, error) {
-	cfg, err := r.loadConfig()
+	files, err := r.loadConfigFiles()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SSH config file (operation: list servers, query: %q, path: %s): %w", query, r.configPath, err)
 	}
 
-	servers := r.toDomainServer(cfg)
+	servers := make([]domain.Server, 0)
+	for _, f := range files {
+		servers = append(servers, r.toDomainServer(f.cfg, f.path)...)
+	}
 	metadata, err := r.metadataManager.loadAll()
 	if err != nil {
 		r.logger.Warnf("Failed to load metadata: %v", err)
@@ -85,17 +94,18 @@ func (r *Repository) ListServers(query string) ([]domain.Server, error) {
 	return r.filterServers(servers, query), nil
 }
 
-// AddServer adds a new server to the SSH config.
+// AddServer adds a new server to the main SSH config.
 func (r *Repository) AddServer(server domain.Server) error {
-	cfg, err := r.loadConfig()
+	files, err := r.loadConfigFiles()
 	if err != nil {
 		return fmt.Errorf("failed to load SSH config file (operation: add server, alias: %q, path: %s): %w", server.Alias, r.configPath, err)
 	}
 
-	if r.serverExists(cfg, server.Alias) {
-		return fmt.Errorf("server with alias '%s' already exists in SSH config (path: %s)", server.Alias, r.configPath)
+	if existing, _ := r.findHostInFiles(files, server.Alias, ""); existing != nil {
+		return fmt.Errorf("server with alias '%s' already exists in SSH config (path: %s)", server.Alias, existing.path)
 	}
 
+	cfg := files[0].cfg
 	host := r.createHostFromServer(server)
 	cfg.Hosts = append(cfg.Hosts, host)
 
@@ -106,21 +116,21 @@ func (r *Repository) AddServer(server domain.Server) error {
 	return r.metadataManager.updateServer(server, server.Alias)
 }
 
-// UpdateServer updates an existing server in the SSH config.
+// UpdateServer updates an existing server in the SSH config file that defines it.
 func (r *Repository) UpdateServer(server domain.Server, newServer domain.Server) error {
-	cfg, err := r.loadConfig()
+	files, err := r.loadConfigFiles()
 	if err != nil {
 		return fmt.Errorf("failed to load SSH config file (operation: update server, alias: %q -> %q, path: %s): %w", server.Alias, newServer.Alias, r.configPath, err)
 	}
 
-	host := r.findHostByAlias(cfg, server.Alias)
+	file, host := r.findHostInFiles(files, server.Alias, server.SourceFile)
 	if host == nil {
 		return fmt.Errorf("server with alias '%s' not found in SSH config (path: %s)", server.Alias, r.configPath)
 	}
 
 	if server.Alias != newServer.Alias {
-		if r.serverExists(cfg, newServer.Alias) {
-			return fmt.Errorf("server with alias '%s' already exists in SSH config (path: %s, cannot rename from '%s')", newServer.Alias, r.configPath, server.Alias)
+		if existing, _ := r.findHostInFiles(files, newServer.Alias, ""); existing != nil {
+			return fmt.Errorf("server with alias '%s' already exists in SSH config (path: %s, cannot rename from '%s')", newServer.Alias, existing.path, server.Alias)
 		}
 
 		newPatterns := make([]*ssh_config.Pattern, 0, len(host.Patterns))
@@ -144,31 +154,31 @@ func (r *Repository) UpdateServer(server domain.Server, newServer domain.Server)
 
 	r.updateHostNodes(host, newServer)
 
-	if err := r.saveConfig(cfg); err != nil {
+	if err := r.saveConfigFile(file.path, file.cfg); err != nil {
 		r.logger.Warnf("Failed to save config while updating server: %v", err)
-		return fmt.Errorf("failed to save SSH config file (operation: update server, alias: %q -> %q, path: %s): %w", server.Alias, newServer.Alias, r.configPath, err)
+		return fmt.Errorf("failed to save SSH config file (operation: update server, alias: %q -> %q, path: %s): %w", server.Alias, newServer.Alias, file.path, err)
 	}
 	// Update metadata; pass old alias to allow inline migration
 	return r.metadataManager.updateServer(newServer, server.Alias)
 }
 
-// DeleteServer removes a server from the SSH config.
+// DeleteServer removes a server from the SSH config file that defines it.
 func (r *Repository) DeleteServer(server domain.Server) error {
-	cfg, err := r.loadConfig()
+	files, err := r.loadConfigFiles()
 	if err != nil {
 		return fmt.Errorf("failed to load SSH config file (operation: delete server, alias: %q, path: %s): %w", server.Alias, r.configPath, err)
 	}
 
-	initialCount := len(cfg.Hosts)
-	cfg.Hosts = r.removeHostByAlias(cfg.Hosts, server.Alias)
-
-	if len(cfg.Hosts) == initialCount {
+	file, host := r.findHostInFiles(files, server.Alias, server.SourceFile)
+	if host == nil {
 		return fmt.Errorf("server with alias '%s' not found in SSH config (path: %s)", server.Alias, r.configPath)
 	}
 
-	if err := r.saveConfig(cfg); err != nil {
+	file.cfg.Hosts = r.removeHostByAlias(file.cfg.Hosts, server.Alias)
+
+	if err := r.saveConfigFile(file.path, file.cfg); err != nil {
 		r.logger.Warnf("Failed to save config while deleting server: %v", err)
-		return fmt.Errorf("failed to save SSH config file (operation: delete server, alias: %q, path: %s): %w", server.Alias, r.configPath, err)
+		return fmt.Errorf("failed to save SSH config file (operation: delete server, alias: %q, path: %s): %w", server.Alias, file.path, err)
 	}
 	return r.metadataManager.deleteServer(server.Alias)
 }
@@ -16,35 +16,124 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aryasoni98/wooak/internal/core/domain"
 	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+	"github.com/aryasoni98/wooak/internal/core/ports"
 	aiService "github.com/aryasoni98/wooak/internal/core/services/ai"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
+const (
+	// queryTimeout bounds a whole query, including retries.
+	queryTimeout = 2 * time.Minute
+	// maxContextServers caps the server list added to recommendation prompts.
+	maxContextServers = 50
+	// streamRedrawInterval limits how often a streamed answer is redrawn.
+	streamRedrawInterval = 100 * time.Millisecond
+	// modelListTimeout bounds asking the provider which models it serves.
+	modelListTimeout = 5 * time.Second
+)
+
+// formLabels maps the AIConfig fields edited by the form to their labels.
+var formLabels = map[string]string{
+	"provider":    "Provider",
+	"model":       "Model",
+	"base_url":    "Base URL",
+	"max_tokens":  "Max Tokens",
+	"temperature": "Temperature",
+}
+
+// QueryContext is the SSH configuration sent along with a query.
+type QueryContext struct {
+	// Alias is the selected server; empty when none is selected.
+	Alias string
+	// Selected holds the selected server's effective options.
+	Selected map[string]string
+	// Servers summarizes every configured server.
+	Servers []map[string]string
+}
+
+// proposal holds the option changes the last answer proposed for a server.
+type proposal struct {
+	alias   string
+	changes []domain.ServerFieldChange
+}
+
 // AIPanel provides a UI for AI features
 type AIPanel struct {
-	app        *tview.Application
-	aiSvc      *aiService.AIService
-	config     *aiDomain.AIConfig
-	form       *tview.Form
-	textView   *tview.TextView
+	app      *tview.Application
+	aiSvc    *aiService.AIService
+	settings ports.SettingsStore
+	config   *aiDomain.AIConfig
+	form     *tview.Form
+	textView *tview.TextView
+
+	providerField    *tview.DropDown
+	modelField       *tview.DropDown
+	baseURLField     *tview.InputField
+	maxTokensField   *tview.InputField
+	temperatureField *tview.InputField
+	enabledField     *tview.Checkbox
+	cacheField       *tview.Checkbox
+	redactionField   *tview.DropDown
+	// formProvider is the provider selected in the form and modelSeq
+	// identifies the latest model discovery, so a slow answer for a
+	// provider no longer selected is dropped.
+	formProvider aiDomain.AIProvider
+	modelSeq     int
+	// setLabel sets a field's label by AIConfig field key.
+	setLabel map[string]func(label string)
+
 	queryInput *tview.InputField
 	resultView *tview.TextView
 	statusView *tview.TextView
+
+	// conversation holds the questions and answers so far, sent as history
+	// with follow-up questions and saved to sessions when there is a store.
+	// transcript is the conversation rendered for the answer view.
+	conversation *aiDomain.Conversation
+	sessions     *aiService.SessionStore
+	transcript   string
+	// chatPages switches the answer view with the session browser.
+	chatPages   *tview.Pages
+	sessionList *tview.List
+	renameInput *tview.InputField
+	browser     *tview.Flex
+	listed      []*aiDomain.Conversation
+
+	queryContext func() QueryContext
+	cancel       context.CancelFunc
+	querySeq     int
+	// partial is the streamed answer shown so far, kept when a query is
+	// canceled mid-stream.
+	partial string
+
+	// answer is the last formatted answer, proposed the changes it contains
+	// and review the function that shows them for approval.
+	answer   string
+	proposed proposal
+	review   func(alias string, changes []domain.ServerFieldChange)
 }
 
-// NewAIPanel creates a new AI panel
-func NewAIPanel(app *tview.Application, aiSvc *aiService.AIService) *AIPanel {
+// NewAIPanel creates a new AI panel. Saved configuration is written to
+// settingsStore; a nil store keeps changes in memory only.
+func NewAIPanel(app *tview.Application, aiSvc *aiService.AIService, settingsStore ports.SettingsStore) *AIPanel {
 	ap := &AIPanel{
-		app:    app,
-		aiSvc:  aiSvc,
-		config: aiSvc.GetConfig(),
+		app:      app,
+		aiSvc:    aiSvc,
+		settings: settingsStore,
+		config:   aiSvc.GetConfig(),
+		sessions: aiSvc.Sessions(),
 	}
+	ap.conversation = ap.newConversation()
 
 	ap.setupUI()
 	return ap
@@ -57,13 +146,30 @@ func (ap *AIPanel) setupUI() {
 	ap.form.SetBorder(true).SetTitle(" AI Configuration ")
 
 	// Add AI configuration fields
-	ap.form.AddDropDown("Provider", []string{"ollama", "openai"}, 0, nil)
-	ap.form.AddDropDown("Model", aiService.GetAvailableModels(), 0, nil)
-	ap.form.AddInputField("Base URL", ap.config.BaseURL, 30, nil, nil)
-	ap.form.AddInputField("Max Tokens", fmt.Sprintf("%d", ap.config.MaxTokens), 10, nil, nil)
-	ap.form.AddInputField("Temperature", fmt.Sprintf("%.2f", ap.config.Temperature), 10, nil, nil)
-	ap.form.AddCheckbox("Enabled", ap.config.Enabled, nil)
-	ap.form.AddCheckbox("Cache Enabled", ap.config.CacheEnabled, nil)
+	ap.providerField = tview.NewDropDown().SetLabel(formLabels["provider"])
+	ap.modelField = tview.NewDropDown().SetLabel(formLabels["model"])
+	ap.baseURLField = tview.NewInputField().SetLabel(formLabels["base_url"]).SetFieldWidth(30)
+	ap.maxTokensField = tview.NewInputField().SetLabel(formLabels["max_tokens"]).SetFieldWidth(10)
+	ap.temperatureField = tview.NewInputField().SetLabel(formLabels["temperature"]).SetFieldWidth(10)
+	ap.enabledField = tview.NewCheckbox().SetLabel("Enabled")
+	ap.cacheField = tview.NewCheckbox().SetLabel("Cache Enabled")
+	ap.redactionField = tview.NewDropDown().SetLabel("Redaction")
+	ap.form.AddFormItem(ap.providerField).
+		AddFormItem(ap.modelField).
+		AddFormItem(ap.baseURLField).
+		AddFormItem(ap.maxTokensField).
+		AddFormItem(ap.temperatureField).
+		AddFormItem(ap.enabledField).
+		AddFormItem(ap.cacheField).
+		AddFormItem(ap.redactionField)
+	ap.setLabel = map[string]func(string){
+		"provider":    func(l string) { ap.providerField.SetLabel(l) },
+		"model":       func(l string) { ap.modelField.SetLabel(l) },
+		"base_url":    func(l string) { ap.baseURLField.SetLabel(l) },
+		"max_tokens":  func(l string) { ap.maxTokensField.SetLabel(l) },
+		"temperature": func(l string) { ap.temperatureField.SetLabel(l) },
+	}
+	ap.fillForm(ap.config)
 
 	// Add buttons
 	ap.form.AddButton("Save Config", ap.saveConfig)
@@ -100,25 +206,233 @@ func (ap *AIPanel) setupAIInteraction() {
 	ap.textView = tview.NewTextView()
 	ap.textView.SetBorder(true).SetTitle(" AI Assistant ")
 	ap.textView.SetDynamicColors(true)
-	ap.textView.SetText("[blue]Welcome to Wooak AI Assistant!\n\n" +
-		"Ask me about:\n" +
-		"• SSH configuration optimization\n" +
-		"• Security best practices\n" +
-		"• Server recommendations\n" +
-		"• Natural language search\n\n" +
-		"Type your question and press Enter to get AI-powered insights.")
+	ap.textView.SetText(welcomeText)
+	ap.setupSessionBrowser()
+
 }
 
-// saveConfig saves the current AI configuration
+// saveConfig validates the form, applies the new configuration to the AI
+// service and writes it to the settings file. An invalid value is reported
+// next to its field and nothing is changed.
 func (ap *AIPanel) saveConfig() {
-	// Get form values and update config
-	// This is a simplified version - in a real implementation,
-	// you'd need to get the actual form values and update the config
+	ap.clearFieldErrors()
+
+	config, err := ap.configFromForm()
+	if err == nil {
+		err = config.Validate()
+	}
+	if err != nil {
+		ap.showConfigError("Invalid AI configuration", err)
+		return
+	}
+	if err := ap.persist(config); err != nil {
+		ap.showConfigError("Failed to save AI configuration", err)
+		return
+	}
+	if err := ap.aiSvc.UpdateConfig(config); err != nil {
+		ap.showConfigError("Failed to update AI configuration", err)
+		return
+	}
+	ap.config = config
 
 	ap.updateStatus()
+	ap.refreshModels(config)
 	ap.resultView.SetText("[green]AI configuration saved successfully![white]")
 }
 
+// configFromForm builds an AIConfig from the current form values. Fields the
+// form does not edit keep their current values.
+func (ap *AIPanel) configFromForm() (*aiDomain.AIConfig, error) {
+	config := *ap.config
+
+	_, provider := ap.providerField.GetCurrentOption()
+	_, model := ap.modelField.GetCurrentOption()
+	config.Provider = aiDomain.AIProvider(provider)
+	config.Model = model
+	config.BaseURL = strings.TrimRight(strings.TrimSpace(ap.baseURLField.GetText()), "/")
+
+	maxTokens, err := strconv.Atoi(strings.TrimSpace(ap.maxTokensField.GetText()))
+	if err != nil {
+		return nil, &aiDomain.ConfigError{Field: "max_tokens", Reason: "must be a whole number"}
+	}
+	config.MaxTokens = maxTokens
+
+	temperature, err := strconv.ParseFloat(strings.TrimSpace(ap.temperatureField.GetText()), 64)
+	if err != nil {
+		return nil, &aiDomain.ConfigError{Field: "temperature", Reason: "must be a number"}
+	}
+	config.Temperature = temperature
+
+	config.Enabled = ap.enabledField.IsChecked()
+	config.CacheEnabled = ap.cacheField.IsChecked()
+
+	// The redaction field edits the level of the selected provider only.
+	_, level := ap.redactionField.GetCurrentOption()
+	redaction := make(map[aiDomain.AIProvider]aiDomain.RedactionLevel, len(config.Redaction)+1)
+	for p, l := range config.Redaction {
+		redaction[p] = l
+	}
+	redaction[config.Provider] = aiDomain.RedactionLevel(level)
+	config.Redaction = redaction
+	return &config, nil
+}
+
+// fillForm shows config in the form fields.
+func (ap *AIPanel) fillForm(config *aiDomain.AIConfig) {
+	var providers []string
+	for _, provider := range aiDomain.Providers() {
+		providers = append(providers, string(provider))
+	}
+	providers, providerIndex := optionIndex(providers, string(config.Provider))
+	ap.formProvider = config.Provider
+	ap.providerField.SetOptions(providers, func(provider string, _ int) {
+		ap.selectProvider(config, aiDomain.AIProvider(provider))
+	}).SetCurrentOption(providerIndex)
+	ap.baseURLField.SetText(config.BaseURL)
+	ap.maxTokensField.SetText(strconv.Itoa(config.MaxTokens))
+	ap.temperatureField.SetText(fmt.Sprintf("%.2f", config.Temperature))
+	ap.enabledField.SetChecked(config.Enabled)
+	ap.cacheField.SetChecked(config.CacheEnabled)
+	ap.refreshModels(config)
+}
+
+// selectProvider updates the fields that depend on the provider when another
+// one is picked: the redaction level, the base URL unless the user typed
+// their own, and the model list.
+func (ap *AIPanel) selectProvider(config *aiDomain.AIConfig, provider aiDomain.AIProvider) {
+	ap.showRedactionLevel(config, provider)
+	previous := ap.formProvider
+	if provider == previous {
+		return
+	}
+	ap.formProvider = provider
+
+	baseURL := strings.TrimRight(strings.TrimSpace(ap.baseURLField.GetText()), "/")
+	old, _ := aiDomain.LookupProviderInfo(previous)
+	if info, ok := aiDomain.LookupProviderInfo(provider); ok && (baseURL == "" || baseURL == old.DefaultBaseURL) {
+		baseURL = info.DefaultBaseURL
+		ap.baseURLField.SetText(baseURL)
+	}
+
+	c := *config
+	c.Provider = provider
+	c.BaseURL = baseURL
+	_, c.Model = ap.modelField.GetCurrentOption()
+	ap.refreshModels(&c)
+}
+
+// refreshModels offers config's model, plus the well-known ones for Ollama,
+// and then replaces them with the models the provider reports. The selected
+// model is kept even when the provider does not list it.
+func (ap *AIPanel) refreshModels(config *aiDomain.AIConfig) {
+	var suggested []string
+	if config.Provider == aiDomain.ProviderOllama {
+		suggested = aiService.GetAvailableModels()
+	}
+	models, index := optionIndex(suggested, config.Model)
+	ap.modelField.SetOptions(models, nil).SetCurrentOption(index)
+
+	ap.modelSeq++
+	seq := ap.modelSeq
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), modelListTimeout)
+		defer cancel()
+
+		discovered, err := ap.aiSvc.ListModels(ctx, config)
+		ap.app.QueueUpdateDraw(func() {
+			if seq != ap.modelSeq {
+				return
+			}
+			if err != nil {
+				if !errors.Is(err, aiService.ErrModelListUnsupported) && ap.statusView != nil {
+					ap.updateStatus()
+					fmt.Fprintf(ap.statusView, "\n[yellow]Could not list models: %s[-]", tview.Escape(err.Error()))
+				}
+				return
+			}
+			if len(discovered) == 0 {
+				return
+			}
+			_, current := ap.modelField.GetCurrentOption()
+			models, index := optionIndex(discovered, current)
+			ap.modelField.SetOptions(models, nil).SetCurrentOption(index)
+		})
+	}()
+}
+
+// showRedactionLevel selects the redaction level config uses for provider.
+func (ap *AIPanel) showRedactionLevel(config *aiDomain.AIConfig, provider aiDomain.AIProvider) {
+	c := *config
+	c.Provider = provider
+	levels := []string{string(aiDomain.RedactionNone), string(aiDomain.RedactionSecrets), string(aiDomain.RedactionPseudonymize)}
+	levels, index := optionIndex(levels, string(c.RedactionLevel()))
+	ap.redactionField.SetOptions(levels, nil).SetCurrentOption(index)
+}
+
+// showConfigError reports err in the result view and, when it names a form
+// field, next to that field.
+func (ap *AIPanel) showConfigError(prefix string, err error) {
+	var fieldErr *aiDomain.ConfigError
+	if errors.As(err, &fieldErr) {
+		if setLabel, ok := ap.setLabel[fieldErr.Field]; ok {
+			setLabel(fieldErrorLabel(formLabels[fieldErr.Field], fieldErr.Reason))
+			ap.resultView.SetText(fmt.Sprintf("[red]%s: %s %s[white]", prefix,
+				formLabels[fieldErr.Field], tview.Escape(fieldErr.Reason)))
+			return
+		}
+	}
+	ap.resultView.SetText(fmt.Sprintf("[red]%s: %s[white]", prefix, tview.Escape(err.Error())))
+}
+
+// clearFieldErrors restores the field labels changed by showConfigError.
+func (ap *AIPanel) clearFieldErrors() {
+	for field, setLabel := range ap.setLabel {
+		setLabel(formLabels[field])
+	}
+}
+
+// fieldErrorLabel appends reason to a form label.
+func fieldErrorLabel(label, reason string) string {
+	return label + " [red](" + tview.Escape(reason) + ")[-]"
+}
+
+// persist writes the fields the form edits from config to the settings
+// file, if there is one. Everything else, such as prompts, budgets and the
+// API key, keeps its stored value, so environment overrides of those are
+// never written to the file.
+func (ap *AIPanel) persist(config *aiDomain.AIConfig) error {
+	if ap.settings == nil {
+		return nil
+	}
+	return ap.settings.Update(func(st *settings.Settings) {
+		copyFormFields(&st.AI, config)
+	})
+}
+
+// copyFormFields copies the fields the form edits from src to dst. Of the
+// redaction levels only the one of src's provider is a form field; it is
+// removed from dst when src does not set it.
+func copyFormFields(dst, src *aiDomain.AIConfig) {
+	dst.Provider = src.Provider
+	dst.Model = src.Model
+	dst.BaseURL = src.BaseURL
+	dst.MaxTokens = src.MaxTokens
+	dst.Temperature = src.Temperature
+	dst.Enabled = src.Enabled
+	dst.CacheEnabled = src.CacheEnabled
+
+	redaction := make(map[aiDomain.AIProvider]aiDomain.RedactionLevel, len(dst.Redaction)+1)
+	for p, l := range dst.Redaction {
+		redaction[p] = l
+	}
+	if level, ok := src.Redaction[src.Provider]; ok {
+		redaction[src.Provider] = level
+	} else {
+		delete(redaction, src.Provider)
+	}
+	dst.Redaction = redaction
+}
+
 // testConnection tests the connection to the AI provider
 func (ap *AIPanel) testConnection() {
 	ap.statusView.SetText("[yellow]Testing AI connection...")
@@ -141,15 +455,26 @@ func (ap *AIPanel) testConnection() {
 	}()
 }
 
-// resetConfig resets the AI configuration to defaults
+// resetConfig resets the fields the form edits to their defaults. Prompts,
+// budgets, the disk cache and other settings are kept.
 func (ap *AIPanel) resetConfig() {
-	ap.config = aiDomain.DefaultAIConfig()
-	if err := ap.aiSvc.UpdateConfig(ap.config); err != nil {
-		ap.resultView.SetText("[red]Failed to update AI configuration: " + err.Error() + "[white]")
+	ap.clearFieldErrors()
+
+	config := *ap.config
+	copyFormFields(&config, aiDomain.DefaultAIConfig())
+	if err := ap.persist(&config); err != nil {
+		ap.showConfigError("Failed to save AI configuration", err)
+		return
+	}
+	if err := ap.aiSvc.UpdateConfig(&config); err != nil {
+		ap.showConfigError("Failed to update AI configuration", err)
 		return
 	}
-	ap.setupUI() // Refresh the UI
-	ap.resultView.SetText("[yellow]AI configuration reset to defaults")
+	ap.config = &config
+
+	ap.fillForm(&config)
+	ap.updateStatus()
+	ap.resultView.SetText("[yellow]AI settings in this form reset to defaults")
 }
 
 // updateStatus updates the AI status display
@@ -161,6 +486,25 @@ func (ap *AIPanel) updateStatus() {
 	status.WriteString(fmt.Sprintf("Base URL: %s\n", ap.config.BaseURL))
 	status.WriteString(fmt.Sprintf("Enabled: %t\n", ap.config.Enabled))
 	status.WriteString(fmt.Sprintf("Cache: %t\n", ap.config.CacheEnabled))
+	status.WriteString(fmt.Sprintf("Disk cache: %t\n", ap.config.CacheEnabled && ap.config.DiskCache))
+	status.WriteString(fmt.Sprintf("Redaction: %s\n", ap.config.RedactionLevel()))
+
+	// Show which template each question type uses, and where it comes from.
+	prompts := ap.aiSvc.PromptTemplates()
+	status.WriteString("Prompt templates:\n")
+	for _, requestType := range []aiDomain.AIRequestType{aiDomain.RequestTypeRecommendation, aiDomain.RequestTypeSecurity, aiDomain.RequestTypeSearch} {
+		template, err := prompts.ForType(requestType, ap.config.Prompts[requestType])
+		line := fmt.Sprintf("  %s: %s", requestType, tview.Escape(template.Name))
+		if template.Source != "" {
+			line += " [#888888](" + tview.Escape(template.Source) + ")[-]"
+		}
+		if err != nil {
+			line += " [yellow](" + tview.Escape(err.Error()) + ")[-]"
+		}
+		status.WriteString(line + "\n")
+	}
+
+	ap.writeUsage(&status)
 
 	if ap.config.Enabled {
 		status.WriteString("\n[green]AI Service: Active")
@@ -171,77 +515,473 @@ func (ap *AIPanel) updateStatus() {
 	ap.statusView.SetText(status.String())
 }
 
-// processAIQuery processes an AI query
+// writeUsage adds the tokens used today and this month, per model, and the
+// configured provider's budget to the status.
+func (ap *AIPanel) writeUsage(status *strings.Builder) {
+	ledger := ap.aiSvc.Usage()
+	if ledger == nil {
+		return
+	}
+	now := time.Now()
+	budget := ap.config.Budgets[ap.config.Provider]
+	status.WriteString(fmt.Sprintf("\nToken usage (%s):\n", ap.config.Provider))
+	status.WriteString(usageLine("Today", ledger.DailyUsage(ap.config.Provider, now), budget.Daily))
+	status.WriteString(usageLine("This month", ledger.MonthlyUsage(ap.config.Provider, now), budget.Monthly))
+
+	// Break the month down by provider and model.
+	type modelKey struct {
+		provider aiDomain.AIProvider
+		model    string
+	}
+	var keys []modelKey
+	byModel := make(map[modelKey]*aiDomain.TokenUsage)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	for _, record := range ledger.Records(monthStart) {
+		key := modelKey{record.Provider, record.Model}
+		if byModel[key] == nil {
+			byModel[key] = &aiDomain.TokenUsage{}
+			keys = append(keys, key)
+		}
+		byModel[key].Add(record.TokenUsage)
+	}
+	if len(keys) > 0 {
+		status.WriteString("This month by model:\n")
+	}
+	for _, key := range keys {
+		usage := byModel[key]
+		status.WriteString(fmt.Sprintf("  %s/%s: %d prompt + %d completion in %d requests\n",
+			key.provider, tview.Escape(key.model), usage.PromptTokens, usage.CompletionTokens, usage.Requests))
+	}
+
+	if reason := ap.aiSvc.BudgetStatus(); reason != "" {
+		if budget.Refuses() {
+			status.WriteString(fmt.Sprintf("[red]%s: requests are refused[-]\n", reason))
+		} else {
+			status.WriteString(fmt.Sprintf("[yellow]%s[-]\n", reason))
+		}
+	}
+}
+
+// usageLine formats the usage of a period against its budget (0 for none).
+func usageLine(period string, usage aiDomain.TokenUsage, budget int) string {
+	if budget > 0 {
+		return fmt.Sprintf("  %s: %d of %d tokens (%d requests)\n", period, usage.Total(), budget, usage.Requests)
+	}
+	return fmt.Sprintf("  %s: %d tokens (%d requests)\n", period, usage.Total(), usage.Requests)
+}
+
+// processAIQuery routes query to the AI service by intent and renders the
+// answer. A new query cancels the one still in flight.
 func (ap *AIPanel) processAIQuery(query string) {
-	if !ap.config.Enabled {
+	config := ap.aiSvc.GetConfig()
+	if !config.Enabled {
 		ap.textView.SetText("[red]AI service is disabled. Please enable it in the configuration.")
 		return
 	}
 
-	ap.textView.SetText("[yellow]Processing your query...")
+	ap.CancelQuery()
 
-	// Process query in a goroutine to avoid blocking UI
+	var qc QueryContext
+	if ap.queryContext != nil {
+		qc = ap.queryContext()
+	}
+	intent := aiDomain.ClassifyQuery(query)
+	if intent == aiDomain.RequestTypeSecurity && qc.Selected == nil {
+		ap.textView.SetText(ap.transcript + formatQuery(query) + "[yellow]Select a server in the list to run a security analysis.[-]")
+		return
+	}
+
+	heading := "Recommendation"
+	switch {
+	case intent == aiDomain.RequestTypeSecurity:
+		heading = "Security analysis of " + qc.Alias
+	case qc.Alias != "":
+		heading += " for " + qc.Alias
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	ap.cancel = cancel
+	ap.querySeq++
+	seq := ap.querySeq
+	started := time.Now()
+	conversation := ap.conversation
+	history := append([]aiDomain.Message(nil), conversation.Messages...)
+	ap.partial = ""
+	ap.proposed = proposal{}
+	ap.showLoading(query, intent, config.Model, 0)
+
+	// Run the query in a goroutine to avoid blocking the UI
 	go func() {
-		_, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		// For now, we'll simulate an AI response
-		// In a real implementation, this would call the AI service
-		time.Sleep(2 * time.Second) // Simulate processing time
+		tick := func() {
+			ap.app.QueueUpdateDraw(func() {
+				if seq == ap.querySeq {
+					ap.showLoading(query, intent, config.Model, time.Since(started))
+				}
+			})
+		}
+		render := func(text string) {
+			ap.app.QueueUpdateDraw(func() {
+				if seq == ap.querySeq {
+					ap.showPartial(query, heading, text)
+				}
+			})
+		}
 
-		response := ap.generateMockResponse(query)
+		// Searches stand alone; recommendations and security analyses see
+		// the earlier turns so follow-up questions can refer to them.
+		var text, summary string
+		var recs []*aiDomain.AIRecommendation
+		var err error
+		switch intent {
+		case aiDomain.RequestTypeSearch:
+			var results []aiDomain.SearchResult
+			results, err = waitFor(ctx, ap.aiSvc.NaturalLanguageSearchAsync(ctx, query, qc.Servers), tick)
+			if err != nil {
+				text = formatQueryError(err, config)
+			} else {
+				text = formatSearchResults(results)
+				summary = aiDomain.SummarizeSearchResults(results)
+			}
+		case aiDomain.RequestTypeSecurity:
+			recs, err = waitForStream(ctx, ap.aiSvc.AnalyzeSecurityStreamWithHistory(ctx, history, qc.Selected), tick, render)
+			if err != nil {
+				text = formatQueryError(err, config)
+			} else {
+				text = formatRecommendations(heading, recs)
+				summary = aiDomain.SummarizeRecommendations(recs)
+			}
+		default:
+			recs, err = waitForStream(ctx, ap.aiSvc.GenerateRecommendationStreamWithHistory(ctx, history, qc.Selected, recommendationContext(query, qc)), tick, render)
+			if err != nil {
+				text = formatQueryError(err, config)
+			} else {
+				text = formatRecommendations(heading, recs)
+				summary = aiDomain.SummarizeRecommendations(recs)
+			}
+		}
 
 		ap.app.QueueUpdateDraw(func() {
-			ap.textView.SetText(response)
+			if seq != ap.querySeq {
+				return // canceled or superseded by a newer query
+			}
+			ap.cancel = nil
+			ap.partial = ""
+			start := strings.Count(ap.transcript, "\n")
+			ap.answer = ap.transcript + formatQuery(query) + text
+			display := ap.answer
+			if err == nil && conversation == ap.conversation {
+				if err := ap.recordTurn(query, intent, summary); err != nil {
+					display += "\n\n[yellow]Could not save the conversation: " + tview.Escape(err.Error()) + "[-]"
+				}
+				ap.transcript = ap.answer + turnSeparator
+			}
+			if changes := proposedChanges(recs); qc.Alias != "" && len(changes) > 0 && ap.review != nil {
+				ap.proposed = proposal{alias: qc.Alias, changes: changes}
+				display += fmt.Sprintf("\n\n[yellow]Press Ctrl+R to review and apply %d proposed change(s) to %s.[-]",
+					len(changes), tview.Escape(qc.Alias))
+			}
+			ap.textView.SetText(display)
+			ap.textView.ScrollTo(start, 0)
 		})
 	}()
 }
 
-// generateMockResponse generates a mock AI response for demonstration
-func (ap *AIPanel) generateMockResponse(query string) string {
-	queryLower := strings.ToLower(query)
-
-	if strings.Contains(queryLower, "security") {
-		return "[green]AI Security Analysis:\n\n" +
-			"Based on your SSH configuration, here are my security recommendations:\n\n" +
-			"1. [yellow]Key Authentication: Ensure you're using Ed25519 or RSA 3072+ keys\n" +
-			"2. [yellow]Host Verification: Enable StrictHostKeyChecking\n" +
-			"3. [yellow]Connection Timeout: Set appropriate connection timeouts\n" +
-			"4. [yellow]Port Security: Use non-standard ports when possible\n\n" +
-			"[blue]Confidence: 85%"
-	}
-
-	if strings.Contains(queryLower, "optimize") || strings.Contains(queryLower, "performance") {
-		return "[green]AI Performance Optimization:\n\n" +
-			"Here are my recommendations to optimize your SSH connections:\n\n" +
-			"1. [yellow]Compression: Enable compression for slow connections\n" +
-			"2. [yellow]Keep-Alive: Configure ServerAliveInterval\n" +
-			"3. [yellow]Multiplexing: Use ControlMaster for multiple sessions\n" +
-			"4. [yellow]Cipher Selection: Use faster ciphers when security allows\n\n" +
-			"[blue]Expected improvement: 20-30% faster connections"
-	}
-
-	if strings.Contains(queryLower, "search") || strings.Contains(queryLower, "find") {
-		return "[green]AI Natural Language Search:\n\n" +
-			"I found these servers matching your query:\n\n" +
-			"1. [yellow]production-web-01 - Web server cluster\n" +
-			"   • High availability setup\n" +
-			"   • Load balanced\n\n" +
-			"2. [yellow]staging-db-02 - Database server\n" +
-			"   • PostgreSQL instance\n" +
-			"   • Backup configured\n\n" +
-			"[blue]Search confidence: 92%"
-	}
-
-	// Default response
-	return "[green]AI Assistant Response:\n\n" +
-		"Thank you for your question: \"" + query + "\"\n\n" +
-		"I'm here to help you with:\n" +
-		"• SSH configuration optimization\n" +
-		"• Security best practices\n" +
-		"• Server recommendations\n" +
-		"• Natural language search\n\n" +
-		"Please ask me a more specific question about your SSH setup!"
+// showPreview shows the prompt query would send to the provider, after
+// redaction, without sending anything.
+func (ap *AIPanel) showPreview(query string) {
+	if strings.TrimSpace(query) == "" {
+		ap.textView.SetText("[yellow]Type a question, then press Ctrl+P to preview what will be sent.[-]")
+		return
+	}
+	var qc QueryContext
+	if ap.queryContext != nil {
+		qc = ap.queryContext()
+	}
+	config := ap.aiSvc.GetConfig()
+
+	var prompt, note string
+	switch aiDomain.ClassifyQuery(query) {
+	case aiDomain.RequestTypeSearch:
+		prompt = ap.aiSvc.PreviewSearch(query, qc.Servers)
+		if config.SearchTopK > 0 && len(qc.Servers) > config.SearchTopK {
+			note = fmt.Sprintf("\nOnly the %d servers closest to the question are sent.", config.SearchTopK)
+		}
+	case aiDomain.RequestTypeSecurity:
+		if qc.Selected == nil {
+			ap.textView.SetText(formatQuery(query) + "[yellow]Select a server in the list to run a security analysis.[-]")
+			return
+		}
+		prompt = ap.aiSvc.PreviewSecurityAnalysis(qc.Selected)
+		note = historyNote(ap.conversation, config)
+	default:
+		prompt = ap.aiSvc.PreviewRecommendation(qc.Selected, recommendationContext(query, qc))
+		note = historyNote(ap.conversation, config)
+	}
+
+	ap.textView.SetText(fmt.Sprintf("%s[yellow]Preview of the prompt for %s (redaction: %s). Nothing has been sent.%s[-]\n\n[#888888]%s[-]",
+		formatQuery(query), tview.Escape(string(config.Provider)), config.RedactionLevel(), note, tview.Escape(prompt)))
+	ap.textView.ScrollToBeginning()
+}
+
+// historyNote tells that the earlier turns of conversation are sent along
+// with the prompt, if there are any and the context window allows history.
+func historyNote(conversation *aiDomain.Conversation, config *aiDomain.AIConfig) string {
+	if len(conversation.Messages) == 0 || config.ContextWindow <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nThe earlier %d message(s) of this conversation are sent too, oldest dropped first to fit %d tokens.",
+		len(conversation.Messages), config.ContextWindow)
+}
+
+// CancelQuery stops the query in flight, if any, and reports whether there
+// was one.
+func (ap *AIPanel) CancelQuery() bool {
+	if ap.cancel == nil {
+		return false
+	}
+	ap.cancel()
+	ap.cancel = nil
+	ap.querySeq++ // drop the result of the canceled query
+	return true
+}
+
+// SetReviewHandler sets the function that shows changes proposed for a
+// server for review. Without one, proposed changes are only listed.
+func (ap *AIPanel) SetReviewHandler(fn func(alias string, changes []domain.ServerFieldChange)) {
+	ap.review = fn
+}
+
+// ChangesApplied notes below the last answer that count of its proposed
+// changes were applied to alias, and drops the proposal.
+func (ap *AIPanel) ChangesApplied(alias string, count int) {
+	ap.proposed = proposal{}
+	ap.textView.SetText(fmt.Sprintf("%s\n\n[green]Applied %d change(s) to %s.[-]", ap.answer, count, tview.Escape(alias)))
+	ap.textView.ScrollToEnd()
+}
+
+// SetQueryContext sets the function that supplies the selected server and the
+// server list sent along with each query.
+func (ap *AIPanel) SetQueryContext(fn func() QueryContext) {
+	ap.queryContext = fn
+}
+
+func (ap *AIPanel) showLoading(query string, intent aiDomain.AIRequestType, model string, elapsed time.Duration) {
+	action := "Thinking"
+	switch intent {
+	case aiDomain.RequestTypeSearch:
+		action = "Searching servers"
+	case aiDomain.RequestTypeSecurity:
+		action = "Analyzing security"
+	}
+	ap.textView.SetText(fmt.Sprintf("%s%s[yellow]%s with %s… %ds[-]\n\n[#888888]Press Esc to cancel.[-]",
+		ap.transcript, formatQuery(query), action, tview.Escape(model), int(elapsed.Seconds())))
+	ap.textView.ScrollToEnd()
+}
+
+// showPartial shows the text streamed so far, dimmed until the complete
+// answer is parsed and formatted.
+func (ap *AIPanel) showPartial(query, heading, text string) {
+	ap.partial = formatQuery(query) + "[green::b]" + tview.Escape(heading) + "[-::-]\n\n[#888888]" + tview.Escape(text) + "[-]"
+	ap.textView.SetText(ap.transcript + ap.partial + "[#888888]▌[-]")
+	ap.textView.ScrollToEnd()
+}
+
+// waitFor waits for an async result, calling tick every second so the loading
+// state can show progress.
+func waitFor[T any](ctx context.Context, results <-chan aiService.AsyncResult[T], tick func()) (T, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case r := <-results:
+			return r.Result, r.Error
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-ticker.C:
+			tick()
+		}
+	}
+}
+
+// waitForStream collects a streamed answer and returns its final result. It
+// calls tick every second until the first text arrives, then render with the
+// text so far, at most every streamRedrawInterval.
+func waitForStream[T any](ctx context.Context, chunks <-chan aiService.StreamChunk[T], tick func(), render func(text string)) (T, error) {
+	progress := time.NewTicker(time.Second)
+	defer progress.Stop()
+	redraw := time.NewTicker(streamRedrawInterval)
+	defer redraw.Stop()
+
+	var text strings.Builder
+	dirty := false
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				var zero T
+				if err := ctx.Err(); err != nil {
+					return zero, err
+				}
+				return zero, errors.New("AI response stream ended unexpectedly")
+			}
+			if chunk.Done {
+				return chunk.Result, chunk.Error
+			}
+			text.WriteString(chunk.Content)
+			dirty = true
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-progress.C:
+			if text.Len() == 0 {
+				tick()
+			}
+		case <-redraw.C:
+			if dirty {
+				render(text.String())
+				dirty = false
+			}
+		}
+	}
+}
+
+// recommendationContext combines the question with a summary of the other
+// servers so general questions can refer to the whole setup.
+func recommendationContext(query string, qc QueryContext) string {
+	if len(qc.Servers) == 0 {
+		return query
+	}
+	servers := qc.Servers
+	if len(servers) > maxContextServers {
+		servers = servers[:maxContextServers]
+	}
+	return fmt.Sprintf("%s\n\nOther configured servers (%d total):\n%s", query, len(qc.Servers), aiDomain.FormatServerList(servers))
+}
+
+func formatQuery(query string) string {
+	return "[#39BFFF]> " + tview.Escape(query) + "[-]\n\n"
+}
+
+// priorityColors colors the priority badge of a recommendation.
+var priorityColors = map[aiDomain.Priority]string{
+	aiDomain.PriorityCritical: "#FF6188",
+	aiDomain.PriorityHigh:     "#FC9867",
+	aiDomain.PriorityMedium:   "#FFD866",
+	aiDomain.PriorityLow:      "#888888",
+}
+
+// formatRecommendations renders recommendations in the order given, which the
+// AI service sorts by priority.
+func formatRecommendations(heading string, recs []*aiDomain.AIRecommendation) string {
+	var b strings.Builder
+	b.WriteString("[green::b]" + tview.Escape(heading) + "[-::-]")
+	if len(recs) == 0 {
+		b.WriteString("\n\n[#888888]No recommendations; nothing needs to change.[-]")
+		return b.String()
+	}
+	if model, ok := recs[0].Metadata["model"].(string); ok && model != "" {
+		b.WriteString("  [#888888]" + tview.Escape(model) + "[-]")
+	}
+
+	for _, rec := range recs {
+		color, ok := priorityColors[rec.Priority]
+		if !ok {
+			color = priorityColors[aiDomain.PriorityLow]
+		}
+		b.WriteString(fmt.Sprintf("\n\n[%s::b]%s[-::-] [::b]%s[::-]\n", color,
+			strings.ToUpper(string(rec.Priority)), tview.Escape(rec.Title)))
+		b.WriteString(fmt.Sprintf("[#888888]%s  •  Confidence: %.0f%%[-]", rec.Type, rec.Confidence*100))
+		if description := strings.TrimSpace(rec.Description); description != "" {
+			b.WriteString("\n" + tview.Escape(description))
+		}
+		for _, action := range rec.Actions {
+			b.WriteString("\n  [yellow]→[-] " + tview.Escape(action))
+		}
+		for _, change := range rec.Changes {
+			if change.Value == "" {
+				b.WriteString("\n  [#FF6188]−[-] remove " + tview.Escape(change.Option))
+			} else {
+				b.WriteString(fmt.Sprintf("\n  [#A9DC76]±[-] %s %s", tview.Escape(change.Option), tview.Escape(change.Value)))
+			}
+		}
+	}
+	return b.String()
+}
+
+// proposedChanges collects the option changes of recs. When several
+// recommendations change the same option, the first, and so highest
+// priority, one wins. A change without its own reason takes the title of its
+// recommendation.
+func proposedChanges(recs []*aiDomain.AIRecommendation) []domain.ServerFieldChange {
+	var changes []domain.ServerFieldChange
+	seen := make(map[string]bool)
+	for _, rec := range recs {
+		for _, change := range rec.Changes {
+			if seen[change.Option] {
+				continue
+			}
+			seen[change.Option] = true
+			if change.Reason == "" {
+				change.Reason = rec.Title
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// formatSearchResults lists the matched servers, best match first.
+func formatSearchResults(results []aiDomain.SearchResult) string {
+	var b strings.Builder
+	b.WriteString("[green::b]Search results[-::-]\n")
+	if len(results) == 0 {
+		b.WriteString("\n[#888888]No matching servers.[-]")
+		return b.String()
+	}
+	for _, result := range results {
+		b.WriteString(fmt.Sprintf("\n[#888888]%3.0f%%[-]  [::b]%s[::-]", result.Score*100, tview.Escape(result.Alias)))
+		if result.Reason != "" {
+			b.WriteString("  " + tview.Escape(result.Reason))
+		}
+	}
+	b.WriteString("\n\n[#888888]Type ? followed by the same question in the server search bar to filter the list.[-]")
+	return b.String()
+}
+
+// formatQueryError explains a failed query, with a hint for the common case
+// of a local Ollama that is not running or is missing the model.
+func formatQueryError(err error, config *aiDomain.AIConfig) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "[yellow]Query canceled.[-]"
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("[red]The AI provider did not answer within %s.[-]", queryTimeout)
+	}
+
+	text := "[red]AI request failed:[-] " + tview.Escape(err.Error())
+	if config.Provider == aiDomain.ProviderOllama {
+		text += fmt.Sprintf("\n\n[#888888]Is Ollama running at %s? Try `ollama serve` and `ollama pull %s`.[-]",
+			tview.Escape(config.BaseURL), tview.Escape(config.Model))
+	}
+	return text
+}
+
+// optionIndex returns the index of value in options, appending it when it is
+// not one of the built-in choices so a configured value is never lost.
+func optionIndex(options []string, value string) ([]string, int) {
+	for i, option := range options {
+		if option == value {
+			return options, i
+		}
+	}
+	if value == "" {
+		return options, 0
+	}
+	return append(options, value), len(options)
 }
 
 // GetAIConfigForm returns the AI configuration form
@@ -256,19 +996,49 @@ func (ap *AIPanel) GetAIInteractionView() *tview.Flex {
 
 	// Add query input
 	ap.queryInput.SetDoneFunc(func(key tcell.Key) {
-		if key == tcell.KeyEnter {
+		switch key {
+		case tcell.KeyEnter:
 			query := ap.queryInput.GetText()
 			if strings.TrimSpace(query) != "" {
 				ap.processAIQuery(query)
 				ap.queryInput.SetText("")
 			}
+		case tcell.KeyEscape:
+			if ap.CancelQuery() {
+				// Keep whatever was streamed before the cancel.
+				text := "[yellow]Query canceled.[-]"
+				if ap.partial != "" {
+					text = ap.partial + "\n\n" + text
+				}
+				ap.textView.SetText(ap.transcript + text)
+				ap.textView.ScrollToEnd()
+				ap.partial = ""
+			}
 		}
 	})
 
-	flex.AddItem(ap.queryInput, 3, 0, false)
+	ap.queryInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyCtrlR && ap.proposed.alias != "":
+			ap.review(ap.proposed.alias, ap.proposed.changes)
+			return nil
+		case event.Key() == tcell.KeyCtrlP:
+			ap.showPreview(ap.queryInput.GetText())
+			return nil
+		case event.Key() == tcell.KeyCtrlN:
+			ap.startConversation()
+			return nil
+		case event.Key() == tcell.KeyCtrlO:
+			ap.showSessionBrowser()
+			return nil
+		}
+		return event
+	})
+
+	flex.AddItem(ap.queryInput, 3, 0, true)
 
-	// Add result view
-	flex.AddItem(ap.textView, 0, 1, false)
+	// Add the answer view, which the session browser replaces while open
+	flex.AddItem(ap.chatPages, 0, 1, false)
 
 	return flex
 }
@@ -282,3 +1052,8 @@ func (ap *AIPanel) GetResultView() *tview.TextView {
 func (ap *AIPanel) GetStatusView() *tview.TextView {
 	return ap.statusView
 }
+
+// RefreshStatus redraws the status view, e.g. to show current token usage.
+func (ap *AIPanel) RefreshStatus() {
+	ap.updateStatus()
+}
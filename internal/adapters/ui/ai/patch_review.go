@@ -0,0 +1,110 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// PatchReview shows the options an AI answer would change on a server as a
+// diff, with a checkbox per option so the user approves each edit.
+type PatchReview struct {
+	*tview.Flex
+	diffs    []domain.ServerFieldDiff
+	approved []bool
+	diffView *tview.TextView
+	form     *tview.Form
+}
+
+// NewPatchReview builds the review screen for diffs against alias. onApply
+// receives the approved diffs; onCancel is called when the user backs out.
+func NewPatchReview(alias string, diffs []domain.ServerFieldDiff, onApply func(approved []domain.ServerFieldDiff), onCancel func()) *PatchReview {
+	pr := &PatchReview{
+		Flex:     tview.NewFlex().SetDirection(tview.FlexRow),
+		diffs:    diffs,
+		approved: make([]bool, len(diffs)),
+	}
+
+	pr.diffView = tview.NewTextView().SetDynamicColors(true).SetText(formatDiffs(diffs))
+	pr.diffView.SetBorder(true).SetTitle(" Proposed changes to " + alias + " ")
+
+	pr.form = tview.NewForm()
+	pr.form.SetBorder(true).SetTitle(" Apply ")
+	for i, diff := range diffs {
+		pr.approved[i] = true
+		pr.form.AddCheckbox(diff.Option, true, func(checked bool) {
+			pr.approved[i] = checked
+		})
+	}
+	pr.form.AddButton("Apply", func() {
+		if approved := pr.Approved(); len(approved) > 0 {
+			onApply(approved)
+		}
+	})
+	pr.form.AddButton("Cancel", onCancel)
+
+	pr.Flex.AddItem(pr.diffView, 0, 1, false)
+	pr.Flex.AddItem(pr.form, len(diffs)*2+5, 0, true)
+	pr.Flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			onCancel()
+			return nil
+		}
+		return event
+	})
+	return pr
+}
+
+// Approved returns the diffs whose checkbox is checked.
+func (pr *PatchReview) Approved() []domain.ServerFieldDiff {
+	var approved []domain.ServerFieldDiff
+	for i, diff := range pr.diffs {
+		if pr.approved[i] {
+			approved = append(approved, diff)
+		}
+	}
+	return approved
+}
+
+// formatDiffs renders each changed option with its old value in red and its
+// new value in green, followed by the reason given for the change.
+func formatDiffs(diffs []domain.ServerFieldDiff) string {
+	var b strings.Builder
+	for i, diff := range diffs {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("[::b]" + tview.Escape(diff.Option) + "[::-]\n")
+		b.WriteString(fmt.Sprintf("  [#FF6188]- %s[-]\n", diffValue(diff.Old)))
+		b.WriteString(fmt.Sprintf("  [#A9DC76]+ %s[-]", diffValue(diff.New)))
+		if diff.Reason != "" {
+			b.WriteString("\n  [#888888]" + tview.Escape(diff.Reason) + "[-]")
+		}
+	}
+	b.WriteString("\n\n[#888888]The SSH config is backed up before it is changed.[-]")
+	return b.String()
+}
+
+func diffValue(value string) string {
+	if value == "" {
+		return "(not set)"
+	}
+	return tview.Escape(value)
+}
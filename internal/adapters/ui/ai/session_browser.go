@@ -0,0 +1,299 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// welcomeText is shown in the answer view of a new conversation.
+const welcomeText = "[blue]Welcome to Wooak AI Assistant!\n\n" +
+	"Ask me about:\n" +
+	"• SSH configuration optimization\n" +
+	"• Security best practices\n" +
+	"• Server recommendations\n" +
+	"• Natural language search\n\n" +
+	"Type your question and press Enter to get AI-powered insights.\n" +
+	"Follow-up questions see the earlier answers.\n\n" +
+	"[#888888]Ctrl+N new conversation · Ctrl+O saved conversations[-]"
+
+// turnSeparator separates the answered questions of the transcript.
+const turnSeparator = "\n\n[#444444]────────[-]\n\n"
+
+// newConversation returns an empty conversation, saved on its first answer
+// when there is a session store.
+func (ap *AIPanel) newConversation() *aiDomain.Conversation {
+	if ap.sessions != nil {
+		return ap.sessions.New()
+	}
+	now := time.Now()
+	return &aiDomain.Conversation{Created: now, Updated: now}
+}
+
+// startConversation drops the current conversation, which stays saved, and
+// starts a new one.
+func (ap *AIPanel) startConversation() {
+	ap.CancelQuery()
+	ap.conversation = ap.newConversation()
+	ap.transcript = ""
+	ap.answer = ""
+	ap.proposed = proposal{}
+	ap.textView.SetText(welcomeText)
+	ap.showConversationTitle()
+}
+
+// recordTurn adds an answered question to the conversation and saves it.
+// summary is the answer as plain text, sent as history with later questions.
+// The turn is kept in memory even when saving fails.
+func (ap *AIPanel) recordTurn(query string, intent aiDomain.AIRequestType, summary string) error {
+	now := time.Now()
+	messages := []aiDomain.Message{
+		{Role: aiDomain.RoleUser, Content: query, Type: intent, Timestamp: now},
+		{Role: aiDomain.RoleAssistant, Content: summary, Type: intent, Timestamp: now},
+	}
+	defer ap.showConversationTitle()
+
+	if ap.sessions == nil {
+		ap.conversation.Messages = append(ap.conversation.Messages, messages...)
+		return nil
+	}
+	if err := ap.sessions.Append(ap.conversation, messages...); err != nil {
+		ap.conversation.Messages = append(ap.conversation.Messages, messages...)
+		return err
+	}
+	return nil
+}
+
+// showConversationTitle shows the conversation's title on the answer view.
+func (ap *AIPanel) showConversationTitle() {
+	if len(ap.conversation.Messages) == 0 {
+		ap.textView.SetTitle(" AI Assistant ")
+		return
+	}
+	ap.textView.SetTitle(" AI Assistant: " + tview.Escape(ap.conversation.DisplayTitle()) + " ")
+}
+
+// renderConversation renders the stored turns of conversation for the answer
+// view. Answers are shown as the plain text kept in the conversation.
+func renderConversation(conversation *aiDomain.Conversation) string {
+	var b strings.Builder
+	for _, message := range conversation.Messages {
+		switch message.Role {
+		case aiDomain.RoleUser:
+			b.WriteString(formatQuery(message.Content))
+		case aiDomain.RoleAssistant:
+			b.WriteString(tview.Escape(message.Content) + turnSeparator)
+		}
+	}
+	return b.String()
+}
+
+// setupSessionBrowser builds the list of saved conversations shown in place
+// of the answer view, and the pages that switch between the two.
+func (ap *AIPanel) setupSessionBrowser() {
+	ap.sessionList = tview.NewList().ShowSecondaryText(true)
+	ap.sessionList.SetBorder(true).SetTitle(" Conversations ")
+	ap.sessionList.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		ap.resumeSession(index)
+	})
+	ap.sessionList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			ap.hideSessionBrowser()
+			return nil
+		case event.Rune() == 'r':
+			ap.startRename()
+			return nil
+		case event.Rune() == 'd':
+			ap.confirmDelete()
+			return nil
+		case event.Rune() == 'n':
+			ap.hideSessionBrowser()
+			ap.startConversation()
+			return nil
+		}
+		return event
+	})
+
+	ap.renameInput = tview.NewInputField().SetLabel("Title: ")
+	ap.renameInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			ap.renameSession(ap.renameInput.GetText())
+		}
+		ap.browser.ResizeItem(ap.renameInput, 0, 0)
+		ap.app.SetFocus(ap.sessionList)
+	})
+
+	help := tview.NewTextView().SetDynamicColors(true).
+		SetText("[#888888]Enter resume · r rename · d delete · n new · Esc back[-]")
+
+	ap.browser = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(ap.sessionList, 0, 1, true).
+		AddItem(ap.renameInput, 0, 0, false).
+		AddItem(help, 1, 0, false)
+
+	ap.chatPages = tview.NewPages().
+		AddPage("chat", ap.textView, true, true).
+		AddPage("sessions", ap.browser, true, false)
+}
+
+// showSessionBrowser lists the saved conversations in place of the answer
+// view.
+func (ap *AIPanel) showSessionBrowser() {
+	if ap.sessions == nil {
+		ap.textView.SetText(ap.transcript + "[yellow]Conversations are not saved in this session.[-]")
+		return
+	}
+	ap.refreshSessions(0)
+	ap.chatPages.SwitchToPage("sessions")
+	ap.app.SetFocus(ap.sessionList)
+}
+
+// hideSessionBrowser returns to the answer view and the query input.
+func (ap *AIPanel) hideSessionBrowser() {
+	ap.chatPages.SwitchToPage("chat")
+	ap.app.SetFocus(ap.queryInput)
+}
+
+// refreshSessions reloads the saved conversations into the list and selects
+// the one at index.
+func (ap *AIPanel) refreshSessions(index int) {
+	ap.sessionList.Clear()
+	conversations, err := ap.sessions.List()
+	ap.listed = conversations
+	if err != nil {
+		ap.sessionList.AddItem("[red]Could not list conversations[-]", tview.Escape(err.Error()), 0, nil)
+		return
+	}
+	if len(conversations) == 0 {
+		ap.sessionList.AddItem("No saved conversations", "Press n to start one", 0, nil)
+		return
+	}
+
+	for _, conversation := range conversations {
+		title := tview.Escape(conversation.DisplayTitle())
+		if conversation.ID == ap.conversation.ID {
+			title = "[green]●[-] " + title
+		}
+		questions := 0
+		for _, message := range conversation.Messages {
+			if message.Role == aiDomain.RoleUser {
+				questions++
+			}
+		}
+		ap.sessionList.AddItem(title, fmt.Sprintf("%d question(s) · %s", questions,
+			conversation.Updated.Local().Format("2006-01-02 15:04")), 0, nil)
+	}
+	if index >= len(conversations) {
+		index = len(conversations) - 1
+	}
+	ap.sessionList.SetCurrentItem(index)
+}
+
+// selectedSession returns the conversation selected in the list, if any.
+func (ap *AIPanel) selectedSession() (*aiDomain.Conversation, int, bool) {
+	index := ap.sessionList.GetCurrentItem()
+	if index < 0 || index >= len(ap.listed) {
+		return nil, index, false
+	}
+	return ap.listed[index], index, true
+}
+
+// resumeSession continues the conversation at index: its turns are shown and
+// sent as history with the next question.
+func (ap *AIPanel) resumeSession(index int) {
+	if index < 0 || index >= len(ap.listed) {
+		return
+	}
+	ap.CancelQuery()
+	ap.conversation = ap.listed[index]
+	ap.transcript = renderConversation(ap.conversation)
+	ap.answer = ""
+	ap.proposed = proposal{}
+	ap.showConversationTitle()
+	ap.hideSessionBrowser()
+	ap.textView.SetText(ap.transcript + "[#888888]Conversation resumed; ask a follow-up question.[-]")
+	ap.textView.ScrollToEnd()
+}
+
+// startRename shows the title input for the selected conversation.
+func (ap *AIPanel) startRename() {
+	conversation, _, ok := ap.selectedSession()
+	if !ok {
+		return
+	}
+	ap.renameInput.SetText(conversation.Title)
+	ap.renameInput.SetPlaceholder(conversation.DisplayTitle())
+	ap.browser.ResizeItem(ap.renameInput, 1, 0)
+	ap.app.SetFocus(ap.renameInput)
+}
+
+// renameSession sets the title of the selected conversation.
+func (ap *AIPanel) renameSession(title string) {
+	conversation, index, ok := ap.selectedSession()
+	if !ok {
+		return
+	}
+	if err := ap.sessions.Rename(conversation.ID, title); err != nil {
+		ap.sessionList.SetTitle(" Conversations [red](rename failed: " + tview.Escape(err.Error()) + ")[-] ")
+		return
+	}
+	if conversation.ID == ap.conversation.ID {
+		ap.conversation.Title = strings.TrimSpace(title)
+		ap.showConversationTitle()
+	}
+	ap.sessionList.SetTitle(" Conversations ")
+	ap.refreshSessions(index)
+}
+
+// confirmDelete asks before deleting the selected conversation.
+func (ap *AIPanel) confirmDelete() {
+	conversation, index, ok := ap.selectedSession()
+	if !ok {
+		return
+	}
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete the conversation %q?", conversation.DisplayTitle())).
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			ap.chatPages.RemovePage("confirm")
+			if label == "Delete" {
+				ap.deleteSession(conversation, index)
+			}
+			ap.app.SetFocus(ap.sessionList)
+		})
+	ap.chatPages.AddPage("confirm", modal, true, true)
+	ap.app.SetFocus(modal)
+}
+
+// deleteSession deletes conversation; deleting the current one starts a new
+// conversation.
+func (ap *AIPanel) deleteSession(conversation *aiDomain.Conversation, index int) {
+	if err := ap.sessions.Delete(conversation.ID); err != nil {
+		ap.sessionList.SetTitle(" Conversations [red](delete failed: " + tview.Escape(err.Error()) + ")[-] ")
+		return
+	}
+	if conversation.ID == ap.conversation.ID {
+		ap.startConversation()
+	}
+	ap.sessionList.SetTitle(" Conversations ")
+	ap.refreshSessions(index)
+}
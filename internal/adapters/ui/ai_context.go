@@ -0,0 +1,69 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/adapters/ui/ai"
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// aiQueryContext collects the selected server's effective configuration and a
+// summary of every server for the AI assistant.
+func (t *tui) aiQueryContext() ai.QueryContext {
+	var qc ai.QueryContext
+
+	servers, err := t.serverService.ListServers("")
+	if err != nil {
+		t.logger.Warnw("failed to list servers for AI context", "error", err)
+	}
+	for _, server := range servers {
+		qc.Servers = append(qc.Servers, aiDomain.ServerSummary(server))
+	}
+
+	server, ok := t.serverList.GetSelectedServer()
+	if !ok {
+		return qc
+	}
+	qc.Alias = server.Alias
+	qc.Selected = aiDomain.ServerSummary(server)
+
+	effective, err := t.serverService.GetEffectiveConfig(server.Alias)
+	if err != nil {
+		t.logger.Warnw("failed to resolve effective config for AI context", "error", err, "alias", server.Alias)
+		return qc
+	}
+	qc.Selected = mergeEffectiveOptions(qc.Selected, effective)
+	return qc
+}
+
+// mergeEffectiveOptions adds every effective option, including inherited ones,
+// to summary. Multi-valued options are joined with commas.
+func mergeEffectiveOptions(summary map[string]string, effective domain.EffectiveConfig) map[string]string {
+	values := make(map[string][]string)
+	var order []string
+	for _, opt := range effective.Options {
+		if _, seen := values[opt.Key]; !seen {
+			order = append(order, opt.Key)
+		}
+		values[opt.Key] = append(values[opt.Key], opt.Value)
+	}
+	for _, key := range order {
+		summary[key] = strings.Join(values[key], ", ")
+	}
+	return summary
+}
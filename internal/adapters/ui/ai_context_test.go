@@ -0,0 +1,52 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+func TestServerSummaryWithEffectiveOptions(t *testing.T) {
+	server := domain.Server{Alias: "web", Host: "10.0.0.5", User: "deploy", Port: 2222, Tags: []string{"prod", "web"}}
+	effective := domain.EffectiveConfig{
+		Alias: "web",
+		Options: []domain.EffectiveOption{
+			{Key: "HostName", Value: "10.0.0.5"},
+			{Key: "IdentityFile", Value: "~/.ssh/prod"},
+			{Key: "ForwardAgent", Value: "yes", Inherited: true},
+			{Key: "IdentityFile", Value: "~/.ssh/id_ed25519", Inherited: true},
+		},
+	}
+
+	got := mergeEffectiveOptions(aiDomain.ServerSummary(server), effective)
+
+	want := map[string]string{
+		"Host":         "web",
+		"HostName":     "10.0.0.5",
+		"User":         "deploy",
+		"Port":         "2222",
+		"Tags":         "prod, web",
+		"ForwardAgent": "yes",
+		"IdentityFile": "~/.ssh/prod, ~/.ssh/id_ed25519",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+
+	"github.com/aryasoni98/wooak/internal/adapters/ui/ai"
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/rivo/tview"
+)
+
+// aiChangeSource is recorded as the source of config_change events for
+// changes proposed by the AI assistant.
+const aiChangeSource = "ai_assistant"
+
+// reviewAIChanges shows the changes the AI assistant proposed for alias as a
+// per-option diff against the server as currently saved.
+func (t *tui) reviewAIChanges(aiPanel *ai.AIPanel, alias string, changes []domain.ServerFieldChange) {
+	server, ok := t.findServer(alias)
+	if !ok {
+		t.showAIChangeError(aiPanel, fmt.Sprintf("Server %s no longer exists.", alias))
+		return
+	}
+	_, diffs, err := domain.ApplyServerChanges(server, changes)
+	if err != nil {
+		t.showAIChangeError(aiPanel, fmt.Sprintf("Cannot apply the proposed changes: %v", err))
+		return
+	}
+	if len(diffs) == 0 {
+		t.showAIChangeError(aiPanel, fmt.Sprintf("%s already has the proposed settings.", alias))
+		return
+	}
+
+	review := ai.NewPatchReview(alias, diffs,
+		func(approved []domain.ServerFieldDiff) { t.applyAIChanges(aiPanel, alias, approved) },
+		func() { t.showAIChatPanel(aiPanel) })
+	t.app.SetRoot(review, true)
+}
+
+// applyAIChanges saves the approved changes through the server service, which
+// backs up the SSH config first, and records them in the security audit log.
+func (t *tui) applyAIChanges(aiPanel *ai.AIPanel, alias string, approved []domain.ServerFieldDiff) {
+	server, ok := t.findServer(alias)
+	if !ok {
+		t.showAIChangeError(aiPanel, fmt.Sprintf("Server %s no longer exists.", alias))
+		return
+	}
+
+	changes := make([]domain.ServerFieldChange, 0, len(approved))
+	for _, diff := range approved {
+		changes = append(changes, domain.ServerFieldChange{Option: diff.Option, Value: diff.New, Reason: diff.Reason})
+	}
+	patched, diffs, err := domain.ApplyServerChanges(server, changes)
+	if err == nil && len(diffs) > 0 {
+		err = t.serverService.UpdateServer(server, patched)
+	}
+	if err != nil {
+		t.logger.Errorw("failed to apply AI-proposed changes", "error", err, "alias", alias)
+		t.showAIChangeError(aiPanel, fmt.Sprintf("Save failed: %v", err))
+		return
+	}
+
+	if t.securitySvc != nil && len(diffs) > 0 {
+		t.securitySvc.RecordConfigChange(alias, diffs, aiChangeSource)
+	}
+	t.refreshServerList()
+	aiPanel.ChangesApplied(alias, len(diffs))
+	t.showAIChatPanel(aiPanel)
+}
+
+// findServer returns the saved server with the given alias.
+func (t *tui) findServer(alias string) (domain.Server, bool) {
+	servers, err := t.serverService.ListServers("")
+	if err != nil {
+		t.logger.Warnw("failed to list servers", "error", err)
+		return domain.Server{}, false
+	}
+	for _, server := range servers {
+		if server.Alias == alias {
+			return server, true
+		}
+	}
+	return domain.Server{}, false
+}
+
+func (t *tui) showAIChangeError(aiPanel *ai.AIPanel, message string) {
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"Close"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) { t.showAIChatPanel(aiPanel) })
+	t.app.SetRoot(modal, true)
+}
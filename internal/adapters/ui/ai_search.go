@@ -0,0 +1,96 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// aiSearchTimeout bounds an AI search started from the search bar.
+const aiSearchTimeout = 2 * time.Minute
+
+// handleSearchSubmit runs an AI search when the submitted query starts with
+// the AI prefix. Plain searches are already applied while typing.
+func (t *tui) handleSearchSubmit(query string) {
+	question, ok := aiQuery(query)
+	if !ok || question == "" {
+		return
+	}
+	if t.aiSvc == nil || !t.aiSvc.GetConfig().Enabled {
+		t.showStatusTempColor("AI search needs the AI assistant enabled (press i)", "#FF6B6B")
+		return
+	}
+
+	servers, err := t.serverService.ListServers("")
+	if err != nil {
+		t.displayError(err)
+		return
+	}
+	summaries := make([]map[string]string, 0, len(servers))
+	for _, server := range servers {
+		summaries = append(summaries, aiDomain.ServerSummary(server))
+	}
+
+	t.statusBar.SetText("[yellow]Searching with AI: " + question + "…[-]")
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), aiSearchTimeout)
+		defer cancel()
+
+		results, err := t.aiSvc.NaturalLanguageSearch(ctx, question, summaries)
+		t.app.QueueUpdateDraw(func() {
+			if err != nil {
+				t.logger.Warnw("AI search failed", "error", err, "query", question)
+				t.showStatusTempColor(fmt.Sprintf("AI search failed: %v", err), "#FF6B6B")
+				return
+			}
+			ranked, notes := rankServers(servers, results)
+			t.serverList.UpdateServersWithNotes(ranked, notes)
+			if len(ranked) == 0 {
+				t.details.ShowEmpty()
+			}
+			t.showStatusTemp(fmt.Sprintf("AI search: %d matching server(s) for %q", len(ranked), question))
+		})
+	}()
+}
+
+// rankServers orders the servers named in results by rank and describes each
+// match with its score and reason. Results for unknown aliases are skipped.
+func rankServers(servers []domain.Server, results []aiDomain.SearchResult) ([]domain.Server, map[string]string) {
+	byAlias := make(map[string]domain.Server, len(servers))
+	for _, server := range servers {
+		byAlias[server.Alias] = server
+	}
+
+	ranked := make([]domain.Server, 0, len(results))
+	notes := make(map[string]string, len(results))
+	for _, result := range results {
+		server, ok := byAlias[result.Alias]
+		if !ok {
+			continue
+		}
+		ranked = append(ranked, server)
+		note := fmt.Sprintf("%.0f%%", result.Score*100)
+		if result.Reason != "" {
+			note += " · " + result.Reason
+		}
+		notes[result.Alias] = note
+	}
+	return ranked, notes
+}
@@ -0,0 +1,63 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+func TestAIQuery(t *testing.T) {
+	tests := []struct {
+		text     string
+		question string
+		ok       bool
+	}{
+		{"?postgres in staging", "postgres in staging", true},
+		{"?  ", "", true},
+		{"web", "", false},
+		{"web?", "", false},
+	}
+	for _, tt := range tests {
+		question, ok := aiQuery(tt.text)
+		if question != tt.question || ok != tt.ok {
+			t.Errorf("aiQuery(%q) = %q, %t; want %q, %t", tt.text, question, ok, tt.question, tt.ok)
+		}
+	}
+	if got := plainQuery("?db"); got != "" {
+		t.Errorf("Expected an AI query not to filter servers, got %q", got)
+	}
+}
+
+func TestRankServers(t *testing.T) {
+	servers := []domain.Server{{Alias: "web"}, {Alias: "db"}, {Alias: "dev"}}
+	results := []aiDomain.SearchResult{
+		{Alias: "db", Score: 0.9, Reason: "database"},
+		{Alias: "gone", Score: 0.8},
+		{Alias: "web", Score: 0.25},
+	}
+
+	ranked, notes := rankServers(servers, results)
+	if len(ranked) != 2 || ranked[0].Alias != "db" || ranked[1].Alias != "web" {
+		t.Fatalf("Expected db then web, got %+v", ranked)
+	}
+	want := map[string]string{"db": "90% · database", "web": "25%"}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("Unexpected notes: %v", notes)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/aryasoni98/wooak/internal/adapters/ui/ai"
 	"github.com/aryasoni98/wooak/internal/adapters/ui/security"
 	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -38,53 +39,59 @@ func (t *tui) handleGlobalKeys(event *tcell.EventKey) *tcell.EventKey {
 		return event
 	}
 
-	switch event.Rune() {
-	case 'q':
+	switch t.keyActions[event.Rune()] {
+	case settings.ActionQuit:
 		t.handleQuit()
 		return nil
-	case '/':
+	case settings.ActionSearch:
 		t.handleSearchToggle()
 		return nil
-	case 'a':
+	case settings.ActionAdd:
 		t.handleServerAdd()
 		return nil
-	case 'e':
+	case settings.ActionEdit:
 		t.handleServerEdit()
 		return nil
-	case 'd':
+	case settings.ActionDelete:
 		t.handleServerDelete()
 		return nil
-	case 'p':
+	case settings.ActionPin:
 		t.handleServerPin()
 		return nil
-	case 's':
+	case settings.ActionProfiles:
+		t.handleProfiles()
+		return nil
+	case settings.ActionSort:
 		t.handleSortToggle()
 		return nil
-	case 'S':
+	case settings.ActionSortReverse:
 		t.handleSortReverse()
 		return nil
-	case 'c':
+	case settings.ActionCopy:
 		t.handleCopyCommand()
 		return nil
-	case 'g':
+	case settings.ActionCopyExpanded:
+		t.handleCopyExpandedCommand()
+		return nil
+	case settings.ActionPing:
 		t.handlePingSelected()
 		return nil
-	case 'r':
+	case settings.ActionRefresh:
 		t.handleRefreshBackground()
 		return nil
-	case 't':
+	case settings.ActionTags:
 		t.handleTagsEdit()
 		return nil
-	case 'z':
+	case settings.ActionSecurity:
 		t.handleSecurityPanel()
 		return nil
-	case 'i':
+	case settings.ActionAI:
 		t.handleAIPanel()
 		return nil
-	case 'j':
+	case settings.ActionNavigateDown:
 		t.handleNavigateDown()
 		return nil
-	case 'k':
+	case settings.ActionNavigateUp:
 		t.handleNavigateUp()
 		return nil
 	}
@@ -134,6 +141,24 @@ func (t *tui) handleCopyCommand() {
 	}
 }
 
+func (t *tui) handleCopyExpandedCommand() {
+	server, ok := t.serverList.GetSelectedServer()
+	if !ok {
+		return
+	}
+	resolved, err := t.serverService.ResolveConfig(server.Alias)
+	if err != nil {
+		t.showStatusTempColor(fmt.Sprintf("Failed to resolve config: %v", err), "#FF6B6B")
+		return
+	}
+	cmd := BuildExpandedSSHCommand(resolved)
+	if err := clipboard.WriteAll(cmd); err == nil {
+		t.showStatusTemp("Copied expanded command for " + server.Alias)
+	} else {
+		t.showStatusTemp("Failed to copy to clipboard")
+	}
+}
+
 func (t *tui) handleTagsEdit() {
 	if server, ok := t.serverList.GetSelectedServer(); ok {
 		t.showEditTagsForm(server)
@@ -164,7 +189,7 @@ func (t *tui) handleNavigateUp() {
 }
 
 func (t *tui) handleSearchInput(query string) {
-	filtered, _ := t.serverService.ListServers(query)
+	filtered, _ := t.serverService.ListServers(plainQuery(query))
 	sortServersForUI(filtered, t.sortMode)
 	t.serverList.UpdateServers(filtered)
 	if len(filtered) == 0 {
@@ -181,7 +206,7 @@ func (t *tui) handleServerConnect() {
 		t.showLoading("Connecting to " + server.Alias + "...")
 
 		t.app.Suspend(func() {
-			err := t.serverService.SSH(server.Alias)
+			err := t.serverService.SSH(server.Alias, printSSHNotice)
 			if err != nil {
 				t.app.QueueUpdateDraw(func() {
 					t.hideLoading()
@@ -207,7 +232,7 @@ func (t *tui) handleServerConnect() {
 }
 
 func (t *tui) handleServerSelectionChange(server domain.Server) {
-	t.details.UpdateServer(server)
+	t.showServerDetails(server)
 }
 
 func (t *tui) handleServerAdd() {
@@ -316,7 +341,7 @@ func (t *tui) handleRefreshBackground() {
 	currentIdx := t.serverList.GetCurrentItem()
 	query := ""
 	if t.searchVisible {
-		query = t.searchBar.InputField.GetText()
+		query = plainQuery(t.searchBar.InputField.GetText())
 	}
 
 	t.showLoading("Refreshing servers...")
@@ -360,7 +385,7 @@ func (t *tui) handleRefreshBackground() {
 			if prevIdx >= 0 && prevIdx < t.serverList.List.GetItemCount() {
 				t.serverList.SetCurrentItem(prevIdx)
 				if srv, ok := t.serverList.GetSelectedServer(); ok {
-					t.details.UpdateServer(srv)
+					t.showServerDetails(srv)
 				}
 			}
 			t.showStatusTemp(fmt.Sprintf("Refreshed %d servers", len(servers)))
@@ -467,15 +492,45 @@ func (t *tui) hideSearchBar() {
 // =============================================================================
 
 func (t *tui) refreshServerList() {
+	// The config may have changed, so `ssh -G` views are resolved again.
+	t.resolved.invalidate()
 	query := ""
 	if t.searchVisible {
-		query = t.searchBar.InputField.GetText()
+		query = plainQuery(t.searchBar.InputField.GetText())
 	}
 	filtered, _ := t.serverService.ListServers(query)
 	sortServersForUI(filtered, t.sortMode)
 	t.serverList.UpdateServers(filtered)
 }
 
+// showServerDetails renders server in the details pane together with the
+// settings it inherits from wildcard Host and Match blocks. The `ssh -G` view
+// is resolved in the background once the cursor rests on the server, cached
+// until the server list is reloaded, and added provided the server is still
+// selected.
+func (t *tui) showServerDetails(server domain.Server) {
+	var effective *domain.EffectiveConfig
+	if cfg, err := t.serverService.GetEffectiveConfig(server.Alias); err == nil {
+		effective = &cfg
+	}
+	if resolved, ok := t.resolved.get(server.Alias); ok {
+		t.details.UpdateServer(server, effective, &resolved)
+		return
+	}
+	t.details.UpdateServer(server, effective, nil)
+
+	if t.app == nil {
+		return
+	}
+	t.resolved.schedule(server.Alias, resolveDelay, t.serverService.ResolveConfig, func(resolved domain.ResolvedConfig) {
+		t.app.QueueUpdateDraw(func() {
+			if current, ok := t.serverList.GetSelectedServer(); ok && current.Alias == server.Alias {
+				t.details.UpdateServer(server, effective, &resolved)
+			}
+		})
+	})
+}
+
 func (t *tui) returnToMain() {
 	t.app.SetRoot(t.root, true)
 }
@@ -508,16 +563,20 @@ func (t *tui) showStatusTempColor(msg string, color string) {
 // handleSecurityPanel opens the security configuration panel
 func (t *tui) handleSecurityPanel() {
 	// Import the security panel
-	securityPanel := security.NewSecurityPanel(t.app, t.securitySvc)
+	securityPanel := security.NewSecurityPanel(t.app, t.securitySvc, t.settings)
 
 	// Create a modal with the security panel
 	modal := tview.NewModal().
 		SetText("Security Configuration\n\nPress 'z' to open security panel").
-		AddButtons([]string{"Key Validation", "Policy Config", "Audit Log", "Close"}).
+		AddButtons([]string{"Key Validation", "Key Inventory", "Config Lint", "Policy Config", "Audit Log", "Close"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			switch buttonLabel {
 			case "Key Validation":
 				t.showKeyValidationPanel(securityPanel)
+			case "Key Inventory":
+				t.showKeyInventoryPanel(securityPanel)
+			case "Config Lint":
+				t.showConfigLintPanel(securityPanel)
 			case "Policy Config":
 				t.showPolicyConfigPanel(securityPanel)
 			case "Audit Log":
@@ -543,6 +602,51 @@ func (t *tui) showKeyValidationPanel(securityPanel *security.SecurityPanel) {
 	t.app.SetRoot(keyView, true)
 }
 
+// showKeyInventoryPanel audits local keys and the IdentityFiles of all servers
+func (t *tui) showKeyInventoryPanel(securityPanel *security.SecurityPanel) {
+	servers, err := t.serverService.ListServers("")
+	if err != nil {
+		t.showStatusTempColor(fmt.Sprintf("Failed to list servers: %v", err), "#FF6B6B")
+	}
+
+	inventoryView := securityPanel.GetKeyInventoryView(servers)
+	inventoryView.SetBorder(true).SetTitle(" SSH Key Inventory ")
+
+	closeBtn := tview.NewButton("Close").SetSelectedFunc(func() {
+		t.app.SetRoot(t.root, true)
+	})
+
+	flex := tview.NewFlex()
+	flex.SetDirection(tview.FlexRow)
+	flex.AddItem(inventoryView, 0, 1, true)
+	flex.AddItem(closeBtn, 3, 0, false)
+
+	t.app.SetRoot(flex, true)
+}
+
+// showConfigLintPanel checks the SSH config and every server against the
+// config rules of the security policy
+func (t *tui) showConfigLintPanel(securityPanel *security.SecurityPanel) {
+	servers, err := t.serverService.ListServers("")
+	if err != nil {
+		t.showStatusTempColor(fmt.Sprintf("Failed to list servers: %v", err), "#FF6B6B")
+	}
+
+	lintView := securityPanel.GetConfigLintView(expandHomePath(t.appSettings.Paths.SSHConfig), servers)
+	lintView.SetBorder(true).SetTitle(" SSH Config Lint ")
+
+	closeBtn := tview.NewButton("Close").SetSelectedFunc(func() {
+		t.app.SetRoot(t.root, true)
+	})
+
+	flex := tview.NewFlex()
+	flex.SetDirection(tview.FlexRow)
+	flex.AddItem(lintView, 0, 1, true)
+	flex.AddItem(closeBtn, 3, 0, false)
+
+	t.app.SetRoot(flex, true)
+}
+
 // showPolicyConfigPanel shows the security policy configuration
 func (t *tui) showPolicyConfigPanel(securityPanel *security.SecurityPanel) {
 	policyForm := securityPanel.GetSecurityForm()
@@ -593,7 +697,11 @@ func (t *tui) showAuditLogPanel(_ *security.SecurityPanel) {
 // handleAIPanel opens the AI configuration panel
 func (t *tui) handleAIPanel() {
 	// Import the AI panel
-	aiPanel := ai.NewAIPanel(t.app, t.aiSvc)
+	aiPanel := ai.NewAIPanel(t.app, t.aiSvc, t.settings)
+	aiPanel.SetQueryContext(t.aiQueryContext)
+	aiPanel.SetReviewHandler(func(alias string, changes []domain.ServerFieldChange) {
+		t.reviewAIChanges(aiPanel, alias, changes)
+	})
 
 	// Create a modal with the AI panel
 	modal := tview.NewModal().
@@ -622,6 +730,7 @@ func (t *tui) showAIChatPanel(aiPanel *ai.AIPanel) {
 
 	// Add close button
 	closeBtn := tview.NewButton("Close").SetSelectedFunc(func() {
+		aiPanel.CancelQuery()
 		t.app.SetRoot(t.root, true)
 	})
 
@@ -655,6 +764,7 @@ func (t *tui) showAIConfigPanel(aiPanel *ai.AIPanel) {
 
 // showAIStatusPanel shows the AI status
 func (t *tui) showAIStatusPanel(aiPanel *ai.AIPanel) {
+	aiPanel.RefreshStatus()
 	statusView := aiPanel.GetStatusView()
 	statusView.SetBorder(true).SetTitle(" AI Service Status ")
 
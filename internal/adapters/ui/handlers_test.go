@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/ports"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -60,7 +61,7 @@ func (m *mockServerService) SetPinned(alias string, pinned bool) error {
 	return m.pinError
 }
 
-func (m *mockServerService) SSH(alias string) error {
+func (m *mockServerService) SSH(alias string, notify ports.SSHNotifier) error {
 	return m.sshError
 }
 
@@ -68,6 +69,38 @@ func (m *mockServerService) Ping(server domain.Server) (bool, time.Duration, err
 	return m.pingResult, m.pingDuration, m.pingError
 }
 
+func (m *mockServerService) GetEffectiveConfig(alias string) (domain.EffectiveConfig, error) {
+	return domain.EffectiveConfig{Alias: alias}, nil
+}
+
+func (m *mockServerService) GetEffectiveConfigs(aliases []string) (map[string]domain.EffectiveConfig, error) {
+	configs := make(map[string]domain.EffectiveConfig, len(aliases))
+	for _, alias := range aliases {
+		configs[alias] = domain.EffectiveConfig{Alias: alias}
+	}
+	return configs, nil
+}
+
+func (m *mockServerService) ResolveConfig(alias string) (domain.ResolvedConfig, error) {
+	return domain.ResolvedConfig{Alias: alias}, nil
+}
+
+func (m *mockServerService) ListProfiles() ([]domain.Profile, error) {
+	return nil, nil
+}
+
+func (m *mockServerService) AddProfile(profile domain.Profile) error {
+	return m.addError
+}
+
+func (m *mockServerService) UpdateProfile(profile domain.Profile, newProfile domain.Profile) error {
+	return m.updateError
+}
+
+func (m *mockServerService) DeleteProfile(profile domain.Profile) error {
+	return m.deleteError
+}
+
 func TestBuildSSHCommand(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -15,13 +15,34 @@
 package ui
 
 import (
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-func NewHintBar() *tview.TextView {
+func NewHintBar(bindings map[string]string) *tview.TextView {
 	hint := tview.NewTextView().SetDynamicColors(true)
 	hint.SetBackgroundColor(tcell.Color236)
-	hint.SetText("[#AAAAAA]Press [#39BFFF::b]/[-:-:b] to search…  •  [#39BFFF]↑↓[-] Navigate  •  [#39BFFF]Enter[-] SSH  •  [#39BFFF]c[-] Copy  •  [#39BFFF]g[-] Ping  •  [#39BFFF]r[-] Refresh  •  [#39BFFF]a[-] Add  •  [#39BFFF]e[-] Edit  •  [#39BFFF]t[-] Tags  •  [#39BFFF]d[-] Delete  •  [#39BFFF]p[-] Pin  •  [#39BFFF]s[-] Sort  •  [#39BFFF]z[-] Security  •  [#39BFFF]i[-] AI Assistant[-]")
+	hint.SetText(hintBarText(bindings))
 	return hint
 }
+
+// hintBarText lists the main shortcuts using the configured key bindings.
+func hintBarText(bindings map[string]string) string {
+	key := func(action string) string {
+		return "[#39BFFF]" + tview.Escape(bindings[action]) + "[-]"
+	}
+	return "[#AAAAAA]Press [#39BFFF::b]" + tview.Escape(bindings[settings.ActionSearch]) + "[-:-:b] to search…  •  [#39BFFF]↑↓[-] Navigate  •  [#39BFFF]Enter[-] SSH  •  " +
+		key(settings.ActionCopy) + "/" + key(settings.ActionCopyExpanded) + " Copy  •  " +
+		key(settings.ActionPing) + " Ping  •  " +
+		key(settings.ActionRefresh) + " Refresh  •  " +
+		key(settings.ActionAdd) + " Add  •  " +
+		key(settings.ActionEdit) + " Edit  •  " +
+		key(settings.ActionTags) + " Tags  •  " +
+		key(settings.ActionDelete) + " Delete  •  " +
+		key(settings.ActionPin) + " Pin  •  " +
+		key(settings.ActionProfiles) + " Profiles  •  " +
+		key(settings.ActionSort) + " Sort  •  " +
+		key(settings.ActionSecurity) + " Security  •  " +
+		key(settings.ActionAI) + " AI Assistant[-]"
+}
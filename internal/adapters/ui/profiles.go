@@ -0,0 +1,212 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// handleProfiles opens the list of wildcard Host blocks ("profiles").
+func (t *tui) handleProfiles() {
+	t.showProfilesList()
+}
+
+func (t *tui) showProfilesList() {
+	profiles, err := t.serverService.ListProfiles()
+	if err != nil {
+		t.showStatusTempColor(fmt.Sprintf("Failed to load profiles: %v", err), "#FF6B6B")
+		return
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true).
+		SetTitle(" [::b]Profiles[-] ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.Color238).
+		SetTitleColor(tcell.Color252)
+	list.SetSelectedBackgroundColor(tcell.Color24).
+		SetSelectedTextColor(tcell.Color255).
+		SetHighlightFullLine(true)
+
+	for i := range profiles {
+		p := profiles[i]
+		secondary := fmt.Sprintf("%d option(s) • %s", len(p.Options), filepath.Base(p.SourceFile))
+		list.AddItem("Host "+tview.Escape(p.Name()), secondary, 0, func() {
+			t.showProfileForm(&p)
+		})
+	}
+	if len(profiles) == 0 {
+		list.AddItem("No wildcard Host blocks yet", "Press a to add one, e.g. Host *.prod", 0, nil)
+	}
+
+	hint := tview.NewTextView().SetDynamicColors(true)
+	hint.SetBackgroundColor(tcell.Color236)
+	hint.SetText("[#AAAAAA]Profiles are wildcard Host blocks inherited by matching servers  •  [#39BFFF]Enter[-]/[#39BFFF]e[-] Edit  •  [#39BFFF]a[-] Add  •  [#39BFFF]d[-] Delete  •  [#39BFFF]Esc[-] Back[-]")
+
+	selected := func() (domain.Profile, bool) {
+		idx := list.GetCurrentItem()
+		if idx < 0 || idx >= len(profiles) {
+			return domain.Profile{}, false
+		}
+		return profiles[idx], true
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			t.returnToMain()
+			return nil
+		}
+		switch event.Rune() {
+		case 'q':
+			t.returnToMain()
+			return nil
+		case 'a':
+			t.showProfileForm(nil)
+			return nil
+		case 'e':
+			if p, ok := selected(); ok {
+				t.showProfileForm(&p)
+			}
+			return nil
+		case 'd':
+			if p, ok := selected(); ok {
+				t.showDeleteProfileModal(p)
+			}
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+
+	t.app.SetRoot(layout, true)
+	t.app.SetFocus(list)
+}
+
+// showProfileForm edits profile, or creates a new one when profile is nil.
+func (t *tui) showProfileForm(profile *domain.Profile) {
+	title := " Add Profile "
+	patterns := "*"
+	options := ""
+	if profile != nil {
+		title = fmt.Sprintf(" Edit Profile: Host %s ", profile.Name())
+		patterns = profile.Name()
+		options = formatProfileOptions(profile.Options)
+	}
+
+	errorView := tview.NewTextView().SetDynamicColors(true)
+
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter)
+	form.AddInputField("Host patterns:", patterns, 50, nil, nil)
+	form.AddTextArea("Options (Key Value):", options, 50, 12, 0, nil)
+
+	form.AddButton("Save", func() {
+		newProfile := domain.Profile{
+			Patterns: strings.Fields(form.GetFormItem(0).(*tview.InputField).GetText()),
+		}
+		opts, err := parseProfileOptions(form.GetFormItem(1).(*tview.TextArea).GetText())
+		if err != nil {
+			errorView.SetText("[#FF6B6B]" + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+		newProfile.Options = opts
+
+		if profile == nil {
+			err = t.serverService.AddProfile(newProfile)
+		} else {
+			newProfile.SourceFile = profile.SourceFile
+			err = t.serverService.UpdateProfile(*profile, newProfile)
+		}
+		if err != nil {
+			errorView.SetText("[#FF6B6B]" + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+
+		t.refreshServerList()
+		t.showProfilesList()
+		t.showStatusTemp("Profile saved: Host " + newProfile.Name())
+	})
+	form.AddButton("Cancel", func() { t.showProfilesList() })
+	form.SetCancelFunc(func() { t.showProfilesList() })
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 0, 1, true).
+		AddItem(errorView, 2, 0, false)
+
+	t.app.SetRoot(layout, true)
+	t.app.SetFocus(form)
+}
+
+func (t *tui) showDeleteProfileModal(profile domain.Profile) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete profile Host %s?\n\nServers matching it will no longer inherit its %d option(s).",
+			profile.Name(), len(profile.Options))).
+		AddButtons([]string{"[yellow]C[-]ancel", "[yellow]D[-]elete"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonIndex == 1 {
+				if err := t.serverService.DeleteProfile(profile); err != nil {
+					t.showStatusTempColor(fmt.Sprintf("Delete failed: %v", err), "#FF6B6B")
+				} else {
+					t.refreshServerList()
+				}
+			}
+			t.showProfilesList()
+		})
+
+	t.app.SetRoot(modal, true)
+}
+
+// formatProfileOptions renders options as "Key Value" lines for editing.
+func formatProfileOptions(options []domain.ConfigOption) string {
+	lines := make([]string, 0, len(options))
+	for _, opt := range options {
+		lines = append(lines, opt.Key+" "+opt.Value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseProfileOptions parses "Key Value" (or "Key=Value") lines. Blank lines
+// and # comments are ignored.
+func parseProfileOptions(text string) ([]domain.ConfigOption, error) {
+	var options []domain.ConfigOption
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		end := strings.IndexAny(line, " \t=")
+		if end <= 0 {
+			return nil, fmt.Errorf("line %d: expected \"Key Value\", got %q", i+1, line)
+		}
+		key := line[:end]
+		value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[end:]), "="))
+		if value == "" {
+			return nil, fmt.Errorf("line %d: missing value for %s", i+1, key)
+		}
+		options = append(options, domain.ConfigOption{Key: key, Value: value})
+	}
+	return options, nil
+}
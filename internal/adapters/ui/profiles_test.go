@@ -0,0 +1,62 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+)
+
+func TestParseProfileOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []domain.ConfigOption
+		wantErr bool
+	}{
+		{
+			name: "key value lines",
+			text: "User deploy\n\n# comment\nIdentityFile=~/.ssh/prod\n  LocalForward 8080 localhost:80  ",
+			want: []domain.ConfigOption{
+				{Key: "User", Value: "deploy"},
+				{Key: "IdentityFile", Value: "~/.ssh/prod"},
+				{Key: "LocalForward", Value: "8080 localhost:80"},
+			},
+		},
+		{name: "missing value", text: "User", wantErr: true},
+		{name: "empty value after equals", text: "User =", wantErr: true},
+		{name: "empty", text: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProfileOptions(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProfileOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseProfileOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Formatting and parsing round-trip.
+	opts := []domain.ConfigOption{{Key: "User", Value: "ops"}, {Key: "Port", Value: "2200"}}
+	if got, _ := parseProfileOptions(formatProfileOptions(opts)); !reflect.DeepEqual(got, opts) {
+		t.Errorf("round trip = %+v, want %+v", got, opts)
+	}
+}
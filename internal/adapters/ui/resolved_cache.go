@@ -0,0 +1,82 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+)
+
+// resolveDelay is how long the cursor has to rest on a server before its
+// `ssh -G` view is resolved.
+const resolveDelay = 150 * time.Millisecond
+
+// resolvedCache keeps the `ssh -G` results of servers by alias, so moving the
+// cursor back and forth does not run ssh again, and debounces resolving while
+// the cursor moves. Results are dropped when the config changes.
+type resolvedCache struct {
+	mu         sync.Mutex
+	configs    map[string]domain.ResolvedConfig
+	generation int
+	timer      *time.Timer
+}
+
+// get returns the cached result for alias.
+func (c *resolvedCache) get(alias string) (domain.ResolvedConfig, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resolved, ok := c.configs[alias]
+	return resolved, ok
+}
+
+// schedule resolves alias with resolve after delay, replacing any resolution
+// still waiting, then caches the result and passes it to done. Results of a
+// resolution started before invalidate are neither cached nor passed on.
+func (c *resolvedCache) schedule(alias string, delay time.Duration, resolve func(alias string) (domain.ResolvedConfig, error), done func(domain.ResolvedConfig)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	generation := c.generation
+	c.timer = time.AfterFunc(delay, func() {
+		resolved, err := resolve(alias)
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		current := generation == c.generation
+		if current {
+			if c.configs == nil {
+				c.configs = make(map[string]domain.ResolvedConfig)
+			}
+			c.configs[alias] = resolved
+		}
+		c.mu.Unlock()
+		if current {
+			done(resolved)
+		}
+	})
+}
+
+// invalidate drops every cached result, e.g. after a server was edited.
+func (c *resolvedCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.configs = nil
+	c.generation++
+}
@@ -0,0 +1,71 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+)
+
+func TestResolvedCache(t *testing.T) {
+	var mu sync.Mutex
+	var resolvedAliases []string
+	resolve := func(alias string) (domain.ResolvedConfig, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		resolvedAliases = append(resolvedAliases, alias)
+		return domain.ResolvedConfig{Alias: alias}, nil
+	}
+	doneCh := make(chan domain.ResolvedConfig, 4)
+	done := func(resolved domain.ResolvedConfig) { doneCh <- resolved }
+
+	var cache resolvedCache
+	// Moving over web and db quickly only resolves db.
+	cache.schedule("web", 20*time.Millisecond, resolve, done)
+	cache.schedule("db", 20*time.Millisecond, resolve, done)
+	select {
+	case got := <-doneCh:
+		if got.Alias != "db" {
+			t.Errorf("Expected db to be resolved, got %q", got.Alias)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the resolution")
+	}
+	mu.Lock()
+	if len(resolvedAliases) != 1 {
+		t.Errorf("Expected a single ssh -G run, got %v", resolvedAliases)
+	}
+	mu.Unlock()
+	if _, ok := cache.get("db"); !ok {
+		t.Error("Expected db to be cached")
+	}
+
+	// Results started before the config changed are dropped.
+	cache.schedule("web", 20*time.Millisecond, resolve, done)
+	cache.invalidate()
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := cache.get("db"); ok {
+		t.Error("Expected invalidate to drop cached results")
+	}
+	if _, ok := cache.get("web"); ok {
+		t.Error("Expected a resolution started before invalidate not to be cached")
+	}
+	if len(doneCh) != 0 {
+		t.Error("Expected a stale resolution not to be shown")
+	}
+}
@@ -15,13 +15,20 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
+// aiQueryPrefix starts a search that is answered by the AI assistant instead
+// of matched against server fields, e.g. "?postgres boxes in staging".
+const aiQueryPrefix = "?"
+
 type SearchBar struct {
 	*tview.InputField
 	onSearch func(string)
+	onSubmit func(string)
 	onEscape func()
 }
 
@@ -45,12 +52,20 @@ func (s *SearchBar) build() {
 		SetTitleColor(tcell.Color250)
 
 	s.InputField.SetChangedFunc(func(text string) {
+		if _, ok := aiQuery(text); ok {
+			s.InputField.SetTitle(" AI Search (Enter to ask) ")
+		} else {
+			s.InputField.SetTitle(" Search ")
+		}
 		if s.onSearch != nil {
 			s.onSearch(text)
 		}
 	})
 
 	s.InputField.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter && s.onSubmit != nil {
+			s.onSubmit(s.InputField.GetText())
+		}
 		if key == tcell.KeyEsc || key == tcell.KeyEnter {
 			if s.onEscape != nil {
 				s.onEscape()
@@ -64,7 +79,30 @@ func (s *SearchBar) OnSearch(fn func(string)) *SearchBar {
 	return s
 }
 
+// OnSubmit sets the function called with the query when Enter is pressed.
+func (s *SearchBar) OnSubmit(fn func(string)) *SearchBar {
+	s.onSubmit = fn
+	return s
+}
+
 func (s *SearchBar) OnEscape(fn func()) *SearchBar {
 	s.onEscape = fn
 	return s
 }
+
+// aiQuery returns the question of an AI search, and false for a plain search.
+func aiQuery(text string) (string, bool) {
+	if !strings.HasPrefix(text, aiQueryPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(text, aiQueryPrefix)), true
+}
+
+// plainQuery returns text as a server filter; AI searches filter nothing
+// until they are answered.
+func plainQuery(text string) string {
+	if _, ok := aiQuery(text); ok {
+		return ""
+	}
+	return text
+}
@@ -16,17 +16,31 @@ package security
 
 import (
 	"fmt"
+	"maps"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aryasoni98/wooak/internal/core/domain"
 	securityDomain "github.com/aryasoni98/wooak/internal/core/domain/security"
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+	"github.com/aryasoni98/wooak/internal/core/ports"
 	securityService "github.com/aryasoni98/wooak/internal/core/services/security"
 	"github.com/rivo/tview"
 )
 
+// auditLogLevels are the choices offered for SecurityPolicy.AuditLogLevel.
+var auditLogLevels = []string{"info", "warn", "error"}
+
+// enforcementModes are the choices offered for SecurityPolicy.Enforcement;
+// the first is shown when the policy leaves it unset.
+var enforcementModes = []string{securityDomain.EnforcementWarn, securityDomain.EnforcementBlock}
+
 // SecurityPanel provides a UI for security features
 type SecurityPanel struct {
 	app         *tview.Application
 	securitySvc *securityService.SecurityService
+	settings    ports.SettingsStore
 	policy      *securityDomain.SecurityPolicy
 	form        *tview.Form
 	textView    *tview.TextView
@@ -34,11 +48,13 @@ type SecurityPanel struct {
 	resultView  *tview.TextView
 }
 
-// NewSecurityPanel creates a new security panel
-func NewSecurityPanel(app *tview.Application, securitySvc *securityService.SecurityService) *SecurityPanel {
+// NewSecurityPanel creates a new security panel. Saved policies are written
+// to settingsStore; a nil store keeps changes in memory only.
+func NewSecurityPanel(app *tview.Application, securitySvc *securityService.SecurityService, settingsStore ports.SettingsStore) *SecurityPanel {
 	sp := &SecurityPanel{
 		app:         app,
 		securitySvc: securitySvc,
+		settings:    settingsStore,
 		policy:      securitySvc.GetSecurityPolicy(),
 	}
 
@@ -55,14 +71,21 @@ func (sp *SecurityPanel) setupUI() {
 	// Add policy configuration fields
 	sp.form.AddInputField("Min Key Size (bits)", fmt.Sprintf("%d", sp.policy.MinKeySize), 20, nil, nil)
 	sp.form.AddCheckbox("Require Host Key Check", sp.policy.RequireHostKeyCheck, nil)
+	sp.form.AddInputField("Max Connection Time (min)", fmt.Sprintf("%d", sp.policy.MaxConnectionTime), 10, nil, nil)
+	sp.form.AddDropDown("Policy Enforcement", enforcementModes, enforcementModeIndex(sp.policy.Enforcement), nil)
 	sp.form.AddCheckbox("Enable Audit Log", sp.policy.EnableAuditLog, nil)
-	sp.form.AddDropDown("Audit Log Level", []string{"info", "warn", "error"}, 0, nil)
+	sp.form.AddDropDown("Audit Log Level", auditLogLevels, auditLogLevelIndex(sp.policy.AuditLogLevel), nil)
 	sp.form.AddInputField("Retention Days", fmt.Sprintf("%d", sp.policy.RetentionDays), 10, nil, nil)
 	sp.form.AddCheckbox("Require VPN", sp.policy.RequireVPN, nil)
+	sp.form.AddInputField("Allowed Hosts", strings.Join(sp.policy.AllowedHosts, ", "), 60, nil, nil)
+	sp.form.AddInputField("Blocked Hosts", strings.Join(sp.policy.BlockedHosts, ", "), 60, nil, nil)
+	sp.form.AddInputField("DNS Timeout", sp.policy.DNSTimeout.String(), 10, nil, nil)
+	sp.form.AddInputField("Test Host", "", 40, nil, nil)
 
 	// Add buttons
 	sp.form.AddButton("Save Policy", sp.savePolicy)
 	sp.form.AddButton("Reset to Default", sp.resetPolicy)
+	sp.form.AddButton("Check Host", sp.checkHost)
 	sp.form.AddButton("View Audit Log", sp.viewAuditLog)
 
 	// Create key validation section
@@ -114,7 +137,20 @@ func (sp *SecurityPanel) displayKeyValidationResult(result *securityDomain.KeyVa
 	if result.KeyInfo != nil {
 		output.WriteString("[yellow]Key Information:[white]\n")
 		output.WriteString(fmt.Sprintf("  Type: %s\n", result.KeyInfo.Type))
-		output.WriteString(fmt.Sprintf("  Size: %d bits\n", result.KeyInfo.Size))
+		if result.KeyInfo.Size > 0 {
+			output.WriteString(fmt.Sprintf("  Size: %d bits\n", result.KeyInfo.Size))
+		}
+		switch {
+		case result.KeyInfo.Encrypted && result.KeyInfo.Cipher != "":
+			output.WriteString(fmt.Sprintf("  Private key, encrypted (%s)\n", result.KeyInfo.Cipher))
+		case result.KeyInfo.Encrypted:
+			output.WriteString("  Private key, encrypted\n")
+		case result.KeyInfo.Private:
+			output.WriteString("  Private key, [yellow]not encrypted[white]\n")
+		}
+		if !result.KeyInfo.Expires.IsZero() {
+			output.WriteString(fmt.Sprintf("  Certificate valid until: %s\n", result.KeyInfo.Expires.Format("2006-01-02 15:04")))
+		}
 		if result.KeyInfo.Fingerprint != "" {
 			output.WriteString(fmt.Sprintf("  Fingerprint: %s\n", result.KeyInfo.Fingerprint))
 		}
@@ -154,19 +190,156 @@ func (sp *SecurityPanel) displayKeyValidationResult(result *securityDomain.KeyVa
 	sp.textView.SetText(output.String())
 }
 
-// savePolicy saves the current security policy
+// savePolicy applies the form values and writes them to the settings file
 func (sp *SecurityPanel) savePolicy() {
-	// Get form values and update policy
-	// This is a simplified version - in a real implementation,
-	// you'd need to get the actual form values and update the policy
-
-	// For now, just show a message
+	policy, err := sp.policyFromForm()
+	if err != nil {
+		sp.resultView.SetText("[red]" + tview.Escape(err.Error()) + "[white]")
+		return
+	}
+	if err := sp.persist(policy); err != nil {
+		sp.resultView.SetText("[red]Failed to save security policy: " + tview.Escape(err.Error()) + "[white]")
+		return
+	}
+	if err := sp.securitySvc.UpdateSecurityPolicy(policy); err != nil {
+		sp.resultView.SetText("[red]Failed to update security policy: " + err.Error() + "[white]")
+		return
+	}
+	sp.policy = policy
 	sp.resultView.SetText("[green]Security policy saved successfully![white]")
 }
 
+// policyFromForm builds a SecurityPolicy from the current form values. Fields
+// the form does not show are copied from the active policy.
+func (sp *SecurityPanel) policyFromForm() (*securityDomain.SecurityPolicy, error) {
+	policy := *sp.policy
+	policy.AllowedKeyTypes = append([]string(nil), sp.policy.AllowedKeyTypes...)
+	policy.ConfigRules = maps.Clone(sp.policy.ConfigRules)
+
+	minKeySize, err := strconv.Atoi(strings.TrimSpace(sp.form.GetFormItemByLabel("Min Key Size (bits)").(*tview.InputField).GetText()))
+	if err != nil {
+		return nil, fmt.Errorf("min key size must be a number")
+	}
+	policy.MinKeySize = minKeySize
+
+	retentionDays, err := strconv.Atoi(strings.TrimSpace(sp.form.GetFormItemByLabel("Retention Days").(*tview.InputField).GetText()))
+	if err != nil {
+		return nil, fmt.Errorf("retention days must be a number")
+	}
+	policy.RetentionDays = retentionDays
+
+	maxConnectionTime, err := strconv.Atoi(strings.TrimSpace(sp.form.GetFormItemByLabel("Max Connection Time (min)").(*tview.InputField).GetText()))
+	if err != nil || maxConnectionTime < 0 {
+		return nil, fmt.Errorf("max connection time must be a number of minutes (0 for no limit)")
+	}
+	policy.MaxConnectionTime = maxConnectionTime
+
+	_, policy.AuditLogLevel = sp.form.GetFormItemByLabel("Audit Log Level").(*tview.DropDown).GetCurrentOption()
+	_, policy.Enforcement = sp.form.GetFormItemByLabel("Policy Enforcement").(*tview.DropDown).GetCurrentOption()
+	policy.RequireHostKeyCheck = sp.form.GetFormItemByLabel("Require Host Key Check").(*tview.Checkbox).IsChecked()
+	policy.EnableAuditLog = sp.form.GetFormItemByLabel("Enable Audit Log").(*tview.Checkbox).IsChecked()
+	policy.RequireVPN = sp.form.GetFormItemByLabel("Require VPN").(*tview.Checkbox).IsChecked()
+
+	policy.AllowedHosts = splitHostList(sp.form.GetFormItemByLabel("Allowed Hosts").(*tview.InputField).GetText())
+	if err := securityDomain.ValidateHostList(policy.AllowedHosts); err != nil {
+		return nil, fmt.Errorf("allowed hosts: %w", err)
+	}
+	policy.BlockedHosts = splitHostList(sp.form.GetFormItemByLabel("Blocked Hosts").(*tview.InputField).GetText())
+	if err := securityDomain.ValidateHostList(policy.BlockedHosts); err != nil {
+		return nil, fmt.Errorf("blocked hosts: %w", err)
+	}
+	dnsTimeout, err := time.ParseDuration(strings.TrimSpace(sp.form.GetFormItemByLabel("DNS Timeout").(*tview.InputField).GetText()))
+	if err != nil || dnsTimeout < 0 {
+		return nil, fmt.Errorf("DNS timeout must be a duration such as 2s (0 to match CIDRs against IP addresses only)")
+	}
+	policy.DNSTimeout = dnsTimeout
+	return &policy, nil
+}
+
+// splitHostList splits a comma-separated host list, dropping empty entries
+func splitHostList(text string) []string {
+	hosts := []string{}
+	for _, entry := range strings.Split(text, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			hosts = append(hosts, entry)
+		}
+	}
+	return hosts
+}
+
+// checkHost shows whether the host in the Test Host field may be connected
+// to under the saved policy, and which allowed or blocked hosts rule decided
+func (sp *SecurityPanel) checkHost() {
+	host := strings.TrimSpace(sp.form.GetFormItemByLabel("Test Host").(*tview.InputField).GetText())
+	if host == "" {
+		sp.resultView.SetText("[yellow]Enter a host name or IP address in Test Host[white]")
+		return
+	}
+	sp.resultView.SetText(formatHostCheck(sp.securitySvc.CheckHostSecurity(host)))
+}
+
+// formatHostCheck renders a host check with the rules that matched
+func formatHostCheck(result *securityService.HostSecurityResult) string {
+	var output strings.Builder
+	host := tview.Escape(result.Host)
+	if result.IsSecure {
+		output.WriteString(fmt.Sprintf("[green]✓ %s is allowed by the saved policy[white]\n", host))
+	} else {
+		output.WriteString(fmt.Sprintf("[red]✗ %s is not allowed by the saved policy[white]\n", host))
+	}
+	if len(result.Addresses) > 0 {
+		output.WriteString(fmt.Sprintf("  Resolved: %s\n", strings.Join(result.Addresses, ", ")))
+	}
+	if result.AllowedBy != "" {
+		output.WriteString(fmt.Sprintf("  Allowed hosts rule: %s\n", tview.Escape(result.AllowedBy)))
+	}
+	if result.BlockedBy != "" {
+		output.WriteString(fmt.Sprintf("  Blocked hosts rule: %s\n", tview.Escape(result.BlockedBy)))
+	}
+	for _, issue := range result.Issues {
+		output.WriteString(fmt.Sprintf("  [red]• %s[white]\n", tview.Escape(issue)))
+	}
+	for _, warning := range result.Warnings {
+		output.WriteString(fmt.Sprintf("  [yellow]• %s[white]\n", tview.Escape(warning)))
+	}
+	return output.String()
+}
+
+// persist writes policy to the settings file, if there is one.
+func (sp *SecurityPanel) persist(policy *securityDomain.SecurityPolicy) error {
+	if sp.settings == nil {
+		return nil
+	}
+	return sp.settings.Update(func(st *settings.Settings) {
+		st.Security = *policy
+	})
+}
+
+func auditLogLevelIndex(level string) int {
+	for i, l := range auditLogLevels {
+		if l == level {
+			return i
+		}
+	}
+	return 0
+}
+
+func enforcementModeIndex(mode string) int {
+	for i, m := range enforcementModes {
+		if m == mode {
+			return i
+		}
+	}
+	return 0
+}
+
 // resetPolicy resets the security policy to defaults
 func (sp *SecurityPanel) resetPolicy() {
 	sp.policy = securityDomain.DefaultSecurityPolicy()
+	if err := sp.persist(sp.policy); err != nil {
+		sp.resultView.SetText("[red]Failed to save security policy: " + tview.Escape(err.Error()) + "[white]")
+		return
+	}
 	if err := sp.securitySvc.UpdateSecurityPolicy(sp.policy); err != nil {
 		sp.resultView.SetText("[red]Failed to update security policy: " + err.Error() + "[white]")
 		return
@@ -200,6 +373,128 @@ func (sp *SecurityPanel) GetKeyValidationView() *tview.Flex {
 	return flex
 }
 
+// GetKeyInventoryView audits the keys in ~/.ssh and the IdentityFiles of
+// servers and returns a scrollable report of the results
+func (sp *SecurityPanel) GetKeyInventoryView(servers []domain.Server) *tview.TextView {
+	view := tview.NewTextView()
+	view.SetDynamicColors(true)
+	view.SetScrollable(true)
+	view.SetText(formatKeyInventory(sp.securitySvc.AuditKeys(servers)))
+	return view
+}
+
+// formatKeyInventory renders one block per audited key
+func formatKeyInventory(entries []securityDomain.KeyAuditEntry) string {
+	if len(entries) == 0 {
+		return "[yellow]No SSH keys found in ~/.ssh or referenced by servers[white]"
+	}
+
+	var output strings.Builder
+	for _, entry := range entries {
+		switch {
+		case entry.HasIssues():
+			output.WriteString(fmt.Sprintf("[red]✗ %s[white]\n", entry.Path))
+		case entry.Validation != nil && len(entry.Validation.Warnings) > 0:
+			output.WriteString(fmt.Sprintf("[yellow]! %s[white]\n", entry.Path))
+		default:
+			output.WriteString(fmt.Sprintf("[green]✓ %s[white]\n", entry.Path))
+		}
+
+		if entry.Validation != nil && entry.Validation.KeyInfo != nil {
+			info := entry.Validation.KeyInfo
+			details := info.Type
+			if info.Size > 0 {
+				details += fmt.Sprintf(", %d bits", info.Size)
+			}
+			if info.Encrypted {
+				details += ", passphrase"
+			} else {
+				details += ", [yellow]no passphrase[white]"
+			}
+			output.WriteString(fmt.Sprintf("  %s\n", details))
+			if info.Fingerprint != "" {
+				output.WriteString(fmt.Sprintf("  Fingerprint: %s\n", info.Fingerprint))
+			}
+		}
+		if entry.Mode != "" {
+			output.WriteString(fmt.Sprintf("  Mode: %s\n", entry.Mode))
+		}
+		if len(entry.Servers) > 0 {
+			output.WriteString(fmt.Sprintf("  Used by: %s\n", strings.Join(entry.Servers, ", ")))
+		}
+
+		if entry.Error != "" {
+			output.WriteString(fmt.Sprintf("  [red]• %s[white]\n", entry.Error))
+		}
+		if entry.Validation != nil {
+			for _, issue := range entry.Validation.Issues {
+				output.WriteString(fmt.Sprintf("  [red]• %s[white]\n", issue))
+			}
+			for _, warning := range entry.Validation.Warnings {
+				output.WriteString(fmt.Sprintf("  [yellow]• %s[white]\n", warning))
+			}
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
+
+// GetConfigLintView checks the SSH config file at configPath and the options
+// of servers against the config rules and returns a scrollable report
+func (sp *SecurityPanel) GetConfigLintView(configPath string, servers []domain.Server) *tview.TextView {
+	view := tview.NewTextView()
+	view.SetDynamicColors(true)
+	view.SetScrollable(true)
+	view.SetText(formatConfigLint(sp.securitySvc.ValidateSSHConfig(configPath, servers)))
+	return view
+}
+
+// formatConfigLint renders the findings of a config validation with their
+// recommendations
+func formatConfigLint(result *securityService.SecurityConfigValidationResult) string {
+	var output strings.Builder
+
+	switch {
+	case !result.IsValid:
+		output.WriteString("[red]✗ SSH config has security issues[white]\n\n")
+	case len(result.Findings) > 0 || len(result.Warnings) > 0:
+		output.WriteString("[yellow]! SSH config has warnings[white]\n\n")
+	default:
+		output.WriteString("[green]✓ No findings[white]\n\n")
+	}
+
+	for _, finding := range result.Findings {
+		color := "white"
+		switch finding.Severity {
+		case securityDomain.SeverityCritical, securityDomain.SeverityError:
+			color = "red"
+		case securityDomain.SeverityWarning:
+			color = "yellow"
+		}
+		host := finding.Host
+		if host == "" {
+			host = "config file"
+		}
+		output.WriteString(fmt.Sprintf("[%s]%-8s[white] %s [::d](%s)[::-]\n", color, finding.Severity, host, finding.RuleID))
+		output.WriteString(fmt.Sprintf("  %s\n", tview.Escape(finding.Message)))
+		if finding.Recommendation != "" {
+			output.WriteString(fmt.Sprintf("  [blue]→ %s[white]\n", tview.Escape(finding.Recommendation)))
+		}
+		output.WriteString("\n")
+	}
+
+	// Problems that are not rule findings, e.g. an unreadable file
+	if len(result.Findings) == 0 {
+		for _, issue := range result.Issues {
+			output.WriteString(fmt.Sprintf("[red]• %s[white]\n", issue))
+		}
+		for _, warning := range result.Warnings {
+			output.WriteString(fmt.Sprintf("[yellow]• %s[white]\n", warning))
+		}
+	}
+	return output.String()
+}
+
 // GetResultView returns the result view
 func (sp *SecurityPanel) GetResultView() *tview.TextView {
 	return sp.resultView
@@ -16,20 +16,24 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
 type ServerDetails struct {
 	*tview.TextView
+	bindings map[string]string
 }
 
-func NewServerDetails() *ServerDetails {
+func NewServerDetails(bindings map[string]string) *ServerDetails {
 	details := &ServerDetails{
 		TextView: tview.NewTextView(),
+		bindings: bindings,
 	}
 	details.build()
 	return details
@@ -57,7 +61,11 @@ func renderTagChips(tags []string) string {
 	return strings.Join(chips, " ")
 }
 
-func (sd *ServerDetails) UpdateServer(server domain.Server) {
+// UpdateServer renders server. When effective is non-nil, values inherited
+// from wildcard Host, Match and global blocks are listed with their source.
+// When resolved is non-nil, the non-default values ssh itself computes are
+// listed too, highlighting those that differ from the server's own Host block.
+func (sd *ServerDetails) UpdateServer(server domain.Server, effective *domain.EffectiveConfig, resolved *domain.ResolvedConfig) {
 	lastSeen := server.LastSeen.Format("2006-01-02 15:04:05")
 	if server.LastSeen.IsZero() {
 		lastSeen = "Never"
@@ -88,6 +96,10 @@ func (sd *ServerDetails) UpdateServer(server domain.Server) {
 		serverKey, tagsText, pinnedStr,
 		lastSeen, server.SSHCount)
 
+	if server.SourceFile != "" {
+		text += fmt.Sprintf("  Source: [white]%s[-]\n", server.SourceFile)
+	}
+
 	// Advanced settings section (only show non-empty fields)
 	// Organized by logical grouping for better readability
 	type fieldEntry struct {
@@ -212,12 +224,104 @@ func (sd *ServerDetails) UpdateServer(server domain.Server) {
 		text += advancedText
 	}
 
-	// Commands list
-	text += "\n[::b]Commands:[-]\n  Enter: SSH connect\n  c: Copy SSH command\n  g: Ping server\n  r: Refresh list\n  a: Add new server\n  e: Edit entry\n  t: Edit tags\n  d: Delete entry\n  p: Pin/Unpin"
+	if effective != nil {
+		text += renderInheritedSettings(server, *effective)
+	}
+	if resolved != nil {
+		text += renderResolvedSettings(*resolved, effective)
+	}
+
+	text += commandsText(sd.bindings)
 
 	sd.TextView.SetText(text)
 }
 
+// SetKeyBindings replaces the action -> key bindings listed under Commands.
+// The text is refreshed the next time a server is shown.
+func (sd *ServerDetails) SetKeyBindings(bindings map[string]string) {
+	sd.bindings = bindings
+}
+
+// commandsText lists the server commands using the configured key bindings.
+func commandsText(bindings map[string]string) string {
+	commands := []struct {
+		action string
+		label  string
+	}{
+		{settings.ActionCopy, "Copy SSH command"},
+		{settings.ActionCopyExpanded, "Copy expanded SSH command"},
+		{settings.ActionPing, "Ping server"},
+		{settings.ActionRefresh, "Refresh list"},
+		{settings.ActionAdd, "Add new server"},
+		{settings.ActionEdit, "Edit entry"},
+		{settings.ActionTags, "Edit tags"},
+		{settings.ActionDelete, "Delete entry"},
+		{settings.ActionPin, "Pin/Unpin"},
+		{settings.ActionProfiles, "Profiles"},
+	}
+
+	text := "\n[::b]Commands:[-]\n  Enter: SSH connect"
+	for _, c := range commands {
+		text += fmt.Sprintf("\n  %s: %s", tview.Escape(bindings[c.action]), c.label)
+	}
+	return text
+}
+
+// renderInheritedSettings lists the options a server inherits from other
+// blocks, each labeled with the block (and file, if different) it came from.
+func renderInheritedSettings(server domain.Server, effective domain.EffectiveConfig) string {
+	inherited := effective.Inherited()
+	if len(inherited) == 0 && len(effective.Unresolved) == 0 {
+		return ""
+	}
+
+	text := "\n[::b]Inherited Settings:[-]\n"
+	for _, opt := range inherited {
+		source := opt.Block
+		if opt.SourceFile != "" && opt.SourceFile != server.SourceFile {
+			source += ", " + filepath.Base(opt.SourceFile)
+		}
+		text += fmt.Sprintf("  %s: [#FFD866]%s[-] [#888888](%s)[-]\n", opt.Key, tview.Escape(opt.Value), tview.Escape(source))
+	}
+	for _, block := range effective.Unresolved {
+		text += fmt.Sprintf("  [#888888]Not evaluated: %s[-]\n", tview.Escape(block))
+	}
+	return text
+}
+
+// renderResolvedSettings lists the options `ssh -G` reports with a value other
+// than ssh's built-in default. Values that are not literally written in the
+// server's own Host block are highlighted, since they come from elsewhere.
+func renderResolvedSettings(resolved domain.ResolvedConfig, effective *domain.EffectiveConfig) string {
+	options := resolved.NonDefault()
+	if len(options) == 0 {
+		return ""
+	}
+
+	literal := make(map[string][]string)
+	if effective != nil {
+		for _, opt := range effective.Options {
+			if !opt.Inherited {
+				key := strings.ToLower(opt.Key)
+				literal[key] = append(literal[key], opt.Value)
+			}
+		}
+	}
+
+	text := "\n[::b]Resolved by ssh -G:[-]\n"
+	for _, opt := range options {
+		color := "#FFD866"
+		for _, v := range literal[opt.Key] {
+			if strings.EqualFold(v, opt.Value) {
+				color = "white"
+				break
+			}
+		}
+		text += fmt.Sprintf("  %s: [%s]%s[-]\n", opt.Key, color, tview.Escape(opt.Value))
+	}
+	return text
+}
+
 func (sd *ServerDetails) ShowEmpty() {
 	sd.TextView.SetText("No servers match the current filter.")
 }
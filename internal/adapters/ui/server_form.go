@@ -469,39 +469,9 @@ func matchesSequence(text, pattern string) bool {
 
 // validateField validates a single field and updates the validation state
 func (sf *ServerForm) validateField(fieldName, value string) string {
-	fieldValidators := GetFieldValidators()
-	validator, exists := fieldValidators[fieldName]
-	if !exists {
-		// No validator for this field, it's valid
-		sf.validation.SetError(fieldName, "")
-		return ""
-	}
-
-	// Check required
-	if validator.Required && strings.TrimSpace(value) == "" {
-		err := fmt.Sprintf("%s is required", fieldName)
-		sf.validation.SetError(fieldName, err)
-		return err
-	}
-
-	// If field is empty and not required, it's valid
-	if value == "" {
-		sf.validation.SetError(fieldName, "")
-		return ""
-	}
-
-	// Check custom validation function
-	if validator.Validate != nil {
-		if err := validator.Validate(value); err != nil {
-			sf.validation.SetError(fieldName, err.Error())
-			return err.Error()
-		}
-	}
-
-	// Check regex pattern
-	if validator.Pattern != nil && !validator.Pattern.MatchString(value) {
-		sf.validation.SetError(fieldName, validator.Message)
-		return validator.Message
+	if err := ValidateField(fieldName, value); err != nil {
+		sf.validation.SetError(fieldName, err.Error())
+		return err.Error()
 	}
 
 	// Field is valid
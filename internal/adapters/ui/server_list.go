@@ -16,6 +16,7 @@ package ui
 
 import (
 	"github.com/aryasoni98/wooak/internal/core/domain"
+	securityDomain "github.com/aryasoni98/wooak/internal/core/domain/security"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -25,6 +26,7 @@ type ServerList struct {
 	servers           []domain.Server
 	onSelection       func(domain.Server)
 	onSelectionChange func(domain.Server)
+	findings          func([]domain.Server) map[string][]securityDomain.ConfigFinding
 }
 
 func NewServerList() *ServerList {
@@ -55,11 +57,29 @@ func (sl *ServerList) build() {
 }
 
 func (sl *ServerList) UpdateServers(servers []domain.Server) {
+	sl.UpdateServersWithNotes(servers, nil)
+}
+
+// UpdateServersWithNotes shows servers in the given order with a note, keyed
+// by alias, on a second line below each server that has one. The notes are
+// dropped by the next UpdateServers.
+func (sl *ServerList) UpdateServersWithNotes(servers []domain.Server, notes map[string]string) {
 	sl.servers = servers
 	sl.List.Clear()
+	sl.List.ShowSecondaryText(len(notes) > 0)
 
+	var findings map[string][]securityDomain.ConfigFinding
+	if sl.findings != nil {
+		findings = sl.findings(servers)
+	}
 	for i := range servers {
 		primary, secondary := formatServerLine(servers[i])
+		if badge := renderFindingBadge(findings[servers[i].Alias]); badge != "" {
+			primary += " " + badge
+		}
+		if note, ok := notes[servers[i].Alias]; ok {
+			secondary = "     [#888888]" + tview.Escape(note) + "[-]"
+		}
 		idx := i
 		sl.List.AddItem(primary, secondary, 0, func() {
 			if sl.onSelection != nil {
@@ -93,3 +113,12 @@ func (sl *ServerList) OnSelectionChange(fn func(server domain.Server)) *ServerLi
 	sl.onSelectionChange = fn
 	return sl
 }
+
+// OnFindings sets the function that lints the listed servers, called once
+// per update with all of them and returning their findings by alias. Servers
+// with findings get a badge showing their count, colored by the highest
+// severity.
+func (sl *ServerList) OnFindings(fn func(servers []domain.Server) map[string][]securityDomain.ConfigFinding) *ServerList {
+	sl.findings = fn
+	return sl
+}
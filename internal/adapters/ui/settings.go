@@ -0,0 +1,72 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+)
+
+// buildKeyActions inverts action -> key bindings into the rune lookup used by
+// handleGlobalKeys.
+func buildKeyActions(bindings map[string]string) map[rune]string {
+	actions := make(map[rune]string, len(bindings))
+	for action, key := range bindings {
+		r, size := utf8.DecodeRuneInString(key)
+		if size == 0 || size != len(key) {
+			continue
+		}
+		actions[r] = action
+	}
+	return actions
+}
+
+// handleSettingsReload applies settings that were changed on disk while the
+// TUI is running. Paths and the metrics endpoint are only read at startup.
+func (t *tui) handleSettingsReload(st *settings.Settings) {
+	prev := t.appSettings
+	t.appSettings = st
+
+	if !reflect.DeepEqual(prev.AI, st.AI) {
+		config := st.AI
+		if err := t.aiSvc.UpdateConfig(&config); err != nil {
+			t.logger.Errorw("failed to apply reloaded AI settings", "error", err)
+		}
+	}
+	if !reflect.DeepEqual(prev.Security, st.Security) {
+		policy := st.Security
+		if err := t.securitySvc.UpdateSecurityPolicy(&policy); err != nil {
+			t.logger.Errorw("failed to apply reloaded security policy", "error", err)
+		}
+	}
+
+	t.keyActions = buildKeyActions(st.ResolvedKeyBindings())
+	t.hintBar.SetText(hintBarText(st.ResolvedKeyBindings()))
+	t.details.SetKeyBindings(st.ResolvedKeyBindings())
+	if server, ok := t.serverList.GetSelectedServer(); ok {
+		t.showServerDetails(server)
+	}
+	if !reflect.DeepEqual(prev.Theme, st.Theme) {
+		t.initializeTheme()
+	}
+
+	if prev.Paths != st.Paths || prev.Metrics != st.Metrics {
+		t.showStatusTempColor("Settings reloaded; restart wooak to apply path and metrics changes", "#FFD866")
+		return
+	}
+	t.showStatusTemp("Settings reloaded")
+}
@@ -0,0 +1,60 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+)
+
+func TestBuildKeyActions(t *testing.T) {
+	bindings := settings.DefaultKeyBindings()
+	bindings[settings.ActionSearch] = "f"
+	bindings[settings.ActionAI] = "é"
+
+	actions := buildKeyActions(bindings)
+
+	if actions['f'] != settings.ActionSearch {
+		t.Errorf("Expected f to open search, got %q", actions['f'])
+	}
+	if _, ok := actions['/']; ok {
+		t.Error("Expected the default search key to be unbound")
+	}
+	if actions['é'] != settings.ActionAI {
+		t.Errorf("Expected multi-byte keys to be supported, got %q", actions['é'])
+	}
+	if len(actions) != len(bindings) {
+		t.Errorf("Expected %d bound keys, got %d", len(bindings), len(actions))
+	}
+}
+
+func TestCommandsText_UsesBindings(t *testing.T) {
+	bindings := settings.DefaultKeyBindings()
+	bindings[settings.ActionCopyExpanded] = "x"
+	bindings[settings.ActionProfiles] = "o"
+
+	text := commandsText(bindings)
+
+	for _, want := range []string{"x: Copy expanded SSH command", "o: Profiles", "c: Copy SSH command"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected commands to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "C: Copy expanded") || strings.Contains(text, "P: Profiles") {
+		t.Errorf("Expected rebound defaults to be gone, got:\n%s", text)
+	}
+}
@@ -15,6 +15,12 @@
 package ui
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -107,6 +113,93 @@ func GetModernTheme() *ModernTheme {
 	}
 }
 
+// GetTheme returns the theme selected in the settings file with its color
+// overrides applied. On error the returned theme is still usable: unknown
+// names fall back to the modern theme and invalid colors are skipped.
+func GetTheme(ts settings.ThemeSettings) (*ModernTheme, error) {
+	theme := GetModernTheme()
+
+	var problems []string
+	if ts.Name != "" && !strings.EqualFold(ts.Name, "modern") {
+		problems = append(problems, fmt.Sprintf("unknown theme %q", ts.Name))
+	}
+
+	fields := theme.colorFields()
+	names := make([]string, 0, len(ts.Colors))
+	for name := range ts.Colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, ok := fields[strings.ToLower(name)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown theme color %q", name))
+			continue
+		}
+		color, err := parseThemeColor(ts.Colors[name])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("theme color %s: %v", name, err))
+			continue
+		}
+		*field = color
+	}
+
+	if len(problems) > 0 {
+		return theme, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return theme, nil
+}
+
+// colorFields maps lower-cased field names to the theme's colors so they can
+// be overridden from the settings file.
+func (t *ModernTheme) colorFields() map[string]*tcell.Color {
+	return map[string]*tcell.Color{
+		"background":       &t.Background,
+		"surface":          &t.Surface,
+		"surfacevariant":   &t.SurfaceVariant,
+		"overlay":          &t.Overlay,
+		"onbackground":     &t.OnBackground,
+		"onsurface":        &t.OnSurface,
+		"onsurfacevariant": &t.OnSurfaceVariant,
+		"muted":            &t.Muted,
+		"primary":          &t.Primary,
+		"primaryvariant":   &t.PrimaryVariant,
+		"secondary":        &t.Secondary,
+		"secondaryvariant": &t.SecondaryVariant,
+		"success":          &t.Success,
+		"warning":          &t.Warning,
+		"error":            &t.Error,
+		"info":             &t.Info,
+		"selected":         &t.Selected,
+		"selectedtext":     &t.SelectedText,
+		"hover":            &t.Hover,
+		"focus":            &t.Focus,
+		"border":           &t.Border,
+		"borderfocus":      &t.BorderFocus,
+		"bordermuted":      &t.BorderMuted,
+		"header":           &t.Header,
+		"footer":           &t.Footer,
+		"separator":        &t.Separator,
+	}
+}
+
+// parseThemeColor accepts a 256-color palette index ("39"), a hex color
+// ("#39BFFF") or a color name ("dodgerblue").
+func parseThemeColor(value string) (tcell.Color, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 || n > 255 {
+			return tcell.ColorDefault, fmt.Errorf("palette index %d out of range 0-255", n)
+		}
+		return tcell.PaletteColor(n), nil
+	}
+	color := tcell.GetColor(value)
+	if color == tcell.ColorDefault && value != "default" {
+		return tcell.ColorDefault, fmt.Errorf("unknown color %q", value)
+	}
+	return color, nil
+}
+
 // ApplyTheme applies the modern theme to tview styles
 func (t *ModernTheme) ApplyTheme() {
 	// Set global tview styles
@@ -22,6 +22,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
 	"github.com/aryasoni98/wooak/internal/core/ports"
 	aiService "github.com/aryasoni98/wooak/internal/core/services/ai"
 	securityService "github.com/aryasoni98/wooak/internal/core/services/security"
@@ -42,6 +43,12 @@ type tui struct {
 	serverService ports.ServerService
 	securitySvc   *securityService.SecurityService
 	aiSvc         *aiService.AIService
+	settings      ports.SettingsStore
+
+	// appSettings is the snapshot currently applied to the UI; keyActions is
+	// derived from its key bindings.
+	appSettings *settings.Settings
+	keyActions  map[rune]string
 
 	header     *AppHeader
 	searchBar  *SearchBar
@@ -49,6 +56,8 @@ type tui struct {
 	serverList *ServerList
 	details    *ServerDetails
 	statusBar  *tview.TextView
+	// resolved caches the `ssh -G` view of the details pane.
+	resolved resolvedCache
 
 	root    *tview.Flex
 	left    *tview.Flex
@@ -58,13 +67,14 @@ type tui struct {
 	searchVisible bool
 }
 
-func NewTUI(logger *zap.SugaredLogger, ss ports.ServerService, securitySvc *securityService.SecurityService, aiSvc *aiService.AIService, version, commit string) App {
+func NewTUI(logger *zap.SugaredLogger, ss ports.ServerService, securitySvc *securityService.SecurityService, aiSvc *aiService.AIService, settingsStore ports.SettingsStore, version, commit string) App {
 	return &tui{
 		logger:        logger,
 		app:           tview.NewApplication(),
 		serverService: ss,
 		securitySvc:   securitySvc,
 		aiSvc:         aiSvc,
+		settings:      settingsStore,
 		version:       version,
 		commit:        commit,
 	}
@@ -78,7 +88,7 @@ func (t *tui) Run() error {
 		}
 	}()
 	t.app.EnableMouse(true)
-	t.initializeTheme().buildComponents().buildLayout().bindEvents().loadInitialData()
+	t.initializeSettings().initializeTheme().buildComponents().buildLayout().bindEvents().loadInitialData()
 	t.app.SetRoot(t.root, true)
 	t.logger.Infow("starting TUI application", "version", t.version, "commit", t.commit)
 	if err := t.app.Run(); err != nil {
@@ -89,9 +99,22 @@ func (t *tui) Run() error {
 	return nil
 }
 
+func (t *tui) initializeSettings() *tui {
+	t.appSettings = t.settings.Get()
+	t.keyActions = buildKeyActions(t.appSettings.ResolvedKeyBindings())
+	t.settings.OnChange(func(st *settings.Settings) {
+		t.app.QueueUpdateDraw(func() {
+			t.handleSettingsReload(st)
+		})
+	})
+	return t
+}
+
 func (t *tui) initializeTheme() *tui {
-	// Apply modern theme
-	theme := GetModernTheme()
+	theme, err := GetTheme(t.appSettings.Theme)
+	if err != nil {
+		t.logger.Warnw("invalid theme settings, using the default theme", "error", err)
+	}
 	theme.ApplyTheme()
 	return t
 }
@@ -100,11 +123,15 @@ func (t *tui) buildComponents() *tui {
 	t.header = NewAppHeader(t.version, t.commit, RepoURL)
 	t.searchBar = NewSearchBar().
 		OnSearch(t.handleSearchInput).
+		OnSubmit(t.handleSearchSubmit).
 		OnEscape(t.hideSearchBar)
-	t.hintBar = NewHintBar()
+	t.hintBar = NewHintBar(t.appSettings.ResolvedKeyBindings())
 	t.serverList = NewServerList().
 		OnSelectionChange(t.handleServerSelectionChange)
-	t.details = NewServerDetails()
+	if t.securitySvc != nil {
+		t.serverList.OnFindings(t.securitySvc.LintEach)
+	}
+	t.details = NewServerDetails(t.appSettings.ResolvedKeyBindings())
 	t.statusBar = NewStatusBar()
 
 	// default sort mode
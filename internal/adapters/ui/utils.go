@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"github.com/aryasoni98/wooak/internal/core/domain"
+	securityDomain "github.com/aryasoni98/wooak/internal/core/domain/security"
+	securityService "github.com/aryasoni98/wooak/internal/core/services/security"
 	"github.com/mattn/go-runewidth"
 )
 
@@ -86,6 +88,22 @@ func formatServerLine(s domain.Server) (primary, secondary string) {
 	return
 }
 
+// renderFindingBadge renders the number of config findings for the server
+// list, colored by the most severe one. Returns "" when there are none.
+func renderFindingBadge(findings []securityDomain.ConfigFinding) string {
+	if len(findings) == 0 {
+		return ""
+	}
+	switch securityDomain.HighestSeverity(findings) {
+	case securityDomain.SeverityCritical, securityDomain.SeverityError:
+		return fmt.Sprintf("[#FF6B6B]✗ %d[-]", len(findings))
+	case securityDomain.SeverityWarning:
+		return fmt.Sprintf("[#FFD866]⚠ %d[-]", len(findings))
+	default:
+		return fmt.Sprintf("[#888888]ℹ %d[-]", len(findings))
+	}
+}
+
 func humanizeDuration(t time.Time) string {
 	if t.IsZero() {
 		return "never"
@@ -193,6 +211,19 @@ func BuildSSHCommand(s domain.Server) string {
 	return strings.Join(parts, " ")
 }
 
+// BuildExpandedSSHCommand builds a self-contained ssh command line from the
+// configuration resolved by `ssh -G`. Config files are bypassed with -F none
+// and every option that differs from ssh's defaults is passed with -o, so the
+// command behaves the same on a machine without the user's SSH config.
+func BuildExpandedSSHCommand(resolved domain.ResolvedConfig) string {
+	parts := []string{"ssh", "-F", "none"}
+	for _, opt := range resolved.NonDefault() {
+		addQuotedOption(&parts, opt.Key, opt.Value)
+	}
+	parts = append(parts, resolved.Alias)
+	return strings.Join(parts, " ")
+}
+
 // addOption adds an SSH option in the format "-o Key=Value" if value is not empty
 func addOption(parts *[]string, key, value string) {
 	if value != "" {
@@ -467,6 +498,19 @@ func quoteIfNeeded(val string) string {
 	return val
 }
 
+// expandHomePath expands a leading ~/ in path to the user's home directory.
+func expandHomePath(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, rest)
+}
+
 // GetAvailableSSHKeys returns a list of available SSH private key files in the user's .ssh directory.
 // It safely handles file permission issues and only returns readable key files.
 func GetAvailableSSHKeys() []string {
@@ -474,87 +518,7 @@ func GetAvailableSSHKeys() []string {
 	if err != nil {
 		return []string{}
 	}
-
-	sshDir := filepath.Join(homeDir, ".ssh")
-	keys := []string{}
-
-	// Common SSH key filenames to look for
-	commonKeyFiles := []string{
-		"id_ed25519",
-		"id_rsa",
-		"id_ecdsa",
-		"id_dsa",
-		"id_ed25519_sk",
-		"id_ecdsa_sk",
-	}
-
-	// First, add common key files if they exist and are readable
-	for _, keyName := range commonKeyFiles {
-		keyPath := filepath.Join(sshDir, keyName)
-		if info, err := os.Stat(keyPath); err == nil && !info.IsDir() {
-			// Check if file is readable
-			// #nosec G304 - keyPath is constructed from known safe values
-			if file, err := os.Open(keyPath); err == nil {
-				_ = file.Close()
-				// Use tilde notation for user-friendly display
-				keys = append(keys, "~/.ssh/"+keyName)
-			}
-		}
-	}
-
-	// Additionally, scan for any other files that look like private keys
-	// (files without .pub extension and with appropriate permissions)
-	entries, err := os.ReadDir(sshDir)
-	if err != nil {
-		return keys // Return what we have so far
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-		// Skip public keys, known_hosts, config, and authorized_keys
-		if strings.HasSuffix(name, ".pub") ||
-			name == "known_hosts" ||
-			name == "config" ||
-			name == "authorized_keys" ||
-			strings.HasPrefix(name, ".") {
-			continue
-		}
-
-		// Skip if already added as common key
-		isCommon := false
-		for _, commonKey := range commonKeyFiles {
-			if name == commonKey {
-				isCommon = true
-				break
-			}
-		}
-		if isCommon {
-			continue
-		}
-
-		// Check if file is readable and looks like a private key
-		keyPath := filepath.Join(sshDir, name)
-		// #nosec G304 - keyPath is constructed from safe directory enumeration
-		if file, err := os.Open(keyPath); err == nil {
-			// Read first few bytes to check if it might be a private key
-			buffer := make([]byte, 100)
-			n, readErr := file.Read(buffer)
-			_ = file.Close()
-			if readErr == nil && n > 0 {
-				content := string(buffer[:n])
-				if strings.Contains(content, "PRIVATE KEY") ||
-					strings.Contains(content, "SSH2 ENCRYPTED PRIVATE KEY") {
-					keys = append(keys, "~/.ssh/"+name)
-				}
-			}
-		}
-	}
-
-	return keys
+	return securityService.DiscoverSSHKeys(homeDir)
 }
 
 // GetAvailableKnownHostsFiles returns a list of available known_hosts files in common locations.
@@ -632,3 +596,10 @@ func GetAvailableKnownHostsFiles() []string {
 
 	return files
 }
+
+// printSSHNotice writes a message from the server service to the terminal
+// while the TUI is suspended for an SSH session. ssh puts the terminal in raw
+// mode, so lines need explicit carriage returns.
+func printSSHNotice(message string) {
+	_, _ = fmt.Fprintf(os.Stderr, "\r\nwooak: %s\r\n", message)
+}
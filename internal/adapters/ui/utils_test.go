@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/aryasoni98/wooak/internal/core/domain"
+	securityDomain "github.com/aryasoni98/wooak/internal/core/domain/security"
 )
 
 func TestBuildSSHCommand_PortForwarding(t *testing.T) {
@@ -171,3 +172,41 @@ func TestBuildSSHCommand_CompleteCommand(t *testing.T) {
 		t.Errorf("Command should contain 'admin@example.com', got: %q", result)
 	}
 }
+
+func TestBuildExpandedSSHCommand(t *testing.T) {
+	resolved := domain.ResolvedConfig{
+		Alias: "web",
+		Options: []domain.ResolvedOption{
+			{Key: "user", Value: "deploy"},
+			{Key: "hostname", Value: "web.example.com"},
+			{Key: "port", Value: "22", Default: true},
+			{Key: "proxycommand", Value: "ssh -W %h:%p bastion"},
+			{Key: "identityfile", Value: "~/.ssh/a"},
+			{Key: "identityfile", Value: "~/.ssh/b"},
+		},
+	}
+
+	want := `ssh -F none -o user=deploy -o hostname=web.example.com -o proxycommand="ssh -W %h:%p bastion" -o identityfile=~/.ssh/a -o identityfile=~/.ssh/b web`
+	if got := BuildExpandedSSHCommand(resolved); got != want {
+		t.Errorf("BuildExpandedSSHCommand() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderFindingBadge(t *testing.T) {
+	if got := renderFindingBadge(nil); got != "" {
+		t.Errorf("renderFindingBadge(nil) = %q, want empty", got)
+	}
+
+	findings := []securityDomain.ConfigFinding{
+		{Severity: securityDomain.SeverityInfo},
+		{Severity: securityDomain.SeverityWarning},
+	}
+	if got := renderFindingBadge(findings); got != "[#FFD866]⚠ 2[-]" {
+		t.Errorf("warning badge = %q", got)
+	}
+
+	findings = append(findings, securityDomain.ConfigFinding{Severity: securityDomain.SeverityCritical})
+	if got := renderFindingBadge(findings); !strings.Contains(got, "#FF6B6B") || !strings.Contains(got, "3") {
+		t.Errorf("critical badge = %q", got)
+	}
+}
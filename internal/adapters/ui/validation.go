@@ -15,6 +15,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -227,6 +228,39 @@ func GetFieldValidators() map[string]fieldValidator {
 	return validators
 }
 
+// ValidateField checks a single field value against the rules returned by
+// GetFieldValidators. Fields without a validator are always valid.
+func ValidateField(fieldName, value string) error {
+	validator, exists := GetFieldValidators()[fieldName]
+	if !exists {
+		return nil
+	}
+
+	// Check required
+	if validator.Required && strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s is required", fieldName)
+	}
+
+	// If field is empty and not required, it's valid
+	if value == "" {
+		return nil
+	}
+
+	// Check custom validation function
+	if validator.Validate != nil {
+		if err := validator.Validate(value); err != nil {
+			return err
+		}
+	}
+
+	// Check regex pattern
+	if validator.Pattern != nil && !validator.Pattern.MatchString(value) {
+		return errors.New(validator.Message)
+	}
+
+	return nil
+}
+
 // validatePort validates port number
 func validatePort(value string) error {
 	if value == "" {
@@ -15,15 +15,24 @@
 package ai
 
 import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
 )
 
 // AIProvider represents different AI providers
 type AIProvider string
 
 const (
-	ProviderOllama AIProvider = "ollama" // Currently supported
-	ProviderOpenAI AIProvider = "openai" // Not yet implemented - placeholder for future
+	ProviderOllama AIProvider = "ollama"
+	ProviderOpenAI AIProvider = "openai"
+	// ProviderOpenAICompatible is any server implementing the OpenAI chat
+	// completions API at BaseURL, such as llama.cpp, vLLM or LM Studio.
+	ProviderOpenAICompatible AIProvider = "openai_compatible"
 )
 
 // AIModel represents an AI model configuration
@@ -44,7 +53,15 @@ type AIRequest struct {
 	Model       string                 `json:"model,omitempty"`
 	MaxTokens   int                    `json:"max_tokens,omitempty"`
 	Temperature float64                `json:"temperature,omitempty"`
-	Timestamp   time.Time              `json:"timestamp"`
+	Format      string                 `json:"format,omitempty"` // "json" when the prompt asks for a JSON answer
+	// Messages are the conversation turns sent before Prompt, starting with
+	// the system prompt; empty for single questions.
+	Messages []Message `json:"messages,omitempty"`
+	// Servers are the server configurations the prompt was rendered from.
+	// They are never sent; redaction uses them to find their hosts, users
+	// and secrets wherever the prompt template put them.
+	Servers   []map[string]string `json:"-"`
+	Timestamp time.Time           `json:"timestamp"`
 }
 
 // AIRequestType represents the type of AI request
@@ -56,33 +73,48 @@ const (
 	RequestTypeOptimization   AIRequestType = "optimization"
 	RequestTypeSecurity       AIRequestType = "security"
 	RequestTypeGeneral        AIRequestType = "general"
+	// RequestTypeEmbedding is the type under which embedding calls are
+	// rate limited and counted against token budgets.
+	RequestTypeEmbedding AIRequestType = "embedding"
 )
 
 // AIResponse represents a response from the AI service
 type AIResponse struct {
-	ID             string                 `json:"id"`
-	RequestID      string                 `json:"request_id"`
-	Content        string                 `json:"content"`
-	Type           AIRequestType          `json:"type"`
-	Confidence     float64                `json:"confidence"` // 0.0-1.0
-	Metadata       map[string]interface{} `json:"metadata,omitempty"`
-	Timestamp      time.Time              `json:"timestamp"`
-	Model          string                 `json:"model"`
-	TokensUsed     int                    `json:"tokens_used,omitempty"`
-	ProcessingTime time.Duration          `json:"processing_time"`
+	ID               string                 `json:"id"`
+	RequestID        string                 `json:"request_id"`
+	Content          string                 `json:"content"`
+	Type             AIRequestType          `json:"type"`
+	Confidence       float64                `json:"confidence"` // 0.0-1.0
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	Timestamp        time.Time              `json:"timestamp"`
+	Model            string                 `json:"model"`
+	TokensUsed       int                    `json:"tokens_used,omitempty"`
+	PromptTokens     int                    `json:"prompt_tokens,omitempty"`     // Part of TokensUsed, if reported
+	CompletionTokens int                    `json:"completion_tokens,omitempty"` // Part of TokensUsed, if reported
+	ProcessingTime   time.Duration          `json:"processing_time"`
 }
 
 // AIRecommendation represents an AI-generated recommendation
 type AIRecommendation struct {
-	ID          string                 `json:"id"`
-	Type        RecommendationType     `json:"type"`
-	Title       string                 `json:"title"`
-	Description string                 `json:"description"`
-	Confidence  float64                `json:"confidence"`
-	Priority    Priority               `json:"priority"`
-	Actions     []string               `json:"actions,omitempty"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
-	Timestamp   time.Time              `json:"timestamp"`
+	ID          string             `json:"id"`
+	Type        RecommendationType `json:"type"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Confidence  float64            `json:"confidence"`
+	Priority    Priority           `json:"priority"`
+	Actions     []string           `json:"actions,omitempty"`
+	// Changes are the edits to the analyzed server that implement the
+	// recommendation, limited to options a domain.Server can hold.
+	Changes   []domain.ServerFieldChange `json:"changes,omitempty"`
+	Metadata  map[string]interface{}     `json:"metadata,omitempty"`
+	Timestamp time.Time                  `json:"timestamp"`
+}
+
+// SearchResult is a server matched by a natural language search.
+type SearchResult struct {
+	Alias  string  `json:"alias"`
+	Score  float64 `json:"score"` // 0.0-1.0
+	Reason string  `json:"reason,omitempty"`
 }
 
 // RecommendationType represents the type of recommendation
@@ -106,31 +138,231 @@ const (
 	PriorityCritical Priority = "critical"
 )
 
+// priorityAliases maps the words models use for priorities to Priority.
+var priorityAliases = map[string]Priority{
+	"low": PriorityLow, "minor": PriorityLow, "info": PriorityLow, "informational": PriorityLow,
+	"medium": PriorityMedium, "med": PriorityMedium, "moderate": PriorityMedium, "normal": PriorityMedium,
+	"high": PriorityHigh, "important": PriorityHigh, "major": PriorityHigh,
+	"critical": PriorityCritical, "urgent": PriorityCritical, "severe": PriorityCritical,
+}
+
+// recommendationTypeAliases maps the words models use for recommendation
+// types to RecommendationType.
+var recommendationTypeAliases = map[string]RecommendationType{
+	"security": RecTypeSecurity, "hardening": RecTypeSecurity, "vulnerability": RecTypeSecurity,
+	"performance": RecTypePerformance, "perf": RecTypePerformance, "speed": RecTypePerformance,
+	"optimization": RecTypeOptimization, "optimisation": RecTypeOptimization,
+	"best_practice": RecTypeBestPractice, "bestpractice": RecTypeBestPractice, "practice": RecTypeBestPractice,
+	"connection": RecTypeConnection, "connectivity": RecTypeConnection, "reliability": RecTypeConnection,
+}
+
+// ParsePriority reads a priority written by a model ("High", "urgent", ...).
+func ParsePriority(s string) (Priority, bool) {
+	p, ok := priorityAliases[strings.ToLower(strings.TrimSpace(s))]
+	return p, ok
+}
+
+// ParseRecommendationType reads a recommendation type written by a model
+// ("Best Practice", "best-practice", ...).
+func ParseRecommendationType(s string) (RecommendationType, bool) {
+	key := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(s)))
+	t, ok := recommendationTypeAliases[key]
+	return t, ok
+}
+
+// Rank orders priorities from low (1) to critical (4); unknown priorities
+// rank 0.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// SortRecommendations orders recommendations by priority, most urgent first,
+// then by confidence.
+func SortRecommendations(recs []*AIRecommendation) {
+	sort.SliceStable(recs, func(i, j int) bool {
+		if ri, rj := recs[i].Priority.Rank(), recs[j].Priority.Rank(); ri != rj {
+			return ri > rj
+		}
+		return recs[i].Confidence > recs[j].Confidence
+	})
+}
+
 // AIConfig represents the AI service configuration
 type AIConfig struct {
-	Provider     AIProvider    `json:"provider"`
-	Model        string        `json:"model"`
-	BaseURL      string        `json:"base_url,omitempty"`
-	APIKey       string        `json:"api_key,omitempty"`
-	MaxTokens    int           `json:"max_tokens"`
-	Temperature  float64       `json:"temperature"`
-	Timeout      time.Duration `json:"timeout"`
-	Enabled      bool          `json:"enabled"`
-	CacheEnabled bool          `json:"cache_enabled"`
-	CacheTTL     time.Duration `json:"cache_ttl"`
+	Provider     AIProvider    `json:"provider" yaml:"provider"`
+	Model        string        `json:"model" yaml:"model"`
+	BaseURL      string        `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+	APIKey       string        `json:"api_key,omitempty" yaml:"api_key,omitempty"`
+	MaxTokens    int           `json:"max_tokens" yaml:"max_tokens"`
+	Temperature  float64       `json:"temperature" yaml:"temperature"`
+	Timeout      time.Duration `json:"timeout" yaml:"timeout"`
+	Enabled      bool          `json:"enabled" yaml:"enabled"`
+	CacheEnabled bool          `json:"cache_enabled" yaml:"cache_enabled"`
+	CacheTTL     time.Duration `json:"cache_ttl" yaml:"cache_ttl"`
+	// DiskCache keeps cached answers on disk so they survive restarts,
+	// until CacheTTL expires or a server they were based on changes.
+	DiskCache bool `json:"disk_cache" yaml:"disk_cache"`
+	// DiskCacheMaxMB caps the disk cache's size; the least recently used
+	// answers are removed first. 0 means no limit.
+	DiskCacheMaxMB int `json:"disk_cache_max_mb" yaml:"disk_cache_max_mb"`
+	// EmbeddingModel is the model used for the semantic search index;
+	// empty picks the provider's default (see EmbeddingModelName).
+	EmbeddingModel string `json:"embedding_model,omitempty" yaml:"embedding_model,omitempty"`
+	// SearchTopK caps how many servers, picked by semantic similarity, are
+	// sent with a natural language search. 0 sends every server.
+	SearchTopK int `json:"search_top_k" yaml:"search_top_k"`
+	// ContextWindow is the model's context size in tokens. Conversation
+	// history is truncated to fit it along with the prompt and MaxTokens;
+	// 0 sends no history.
+	ContextWindow int `json:"context_window" yaml:"context_window"`
+	// Prompts picks the prompt template, by name, for request types that
+	// should not use their default one.
+	Prompts map[AIRequestType]string `json:"prompts,omitempty" yaml:"prompts,omitempty"`
+	// Redaction sets the redaction level per provider; providers missing
+	// from it use their default (see RedactionLevel).
+	Redaction map[AIProvider]RedactionLevel `json:"redaction,omitempty" yaml:"redaction,omitempty"`
+	// Budgets caps the tokens each provider may use; providers missing
+	// from it are not limited.
+	Budgets map[AIProvider]TokenBudget `json:"budgets,omitempty" yaml:"budgets,omitempty"`
+}
+
+// RedactionLevel returns the redaction level for the configured provider:
+// the one set in Redaction, or the provider's default (secrets for Ollama,
+// pseudonymize for the others).
+func (c *AIConfig) RedactionLevel() RedactionLevel {
+	if level, ok := c.Redaction[c.Provider]; ok {
+		return level
+	}
+	if info, ok := LookupProviderInfo(c.Provider); ok && info.Redaction != "" {
+		return info.Redaction
+	}
+	return RedactionPseudonymize
+}
+
+// Default embedding models per provider.
+const (
+	DefaultOllamaEmbeddingModel = "nomic-embed-text"
+	DefaultOpenAIEmbeddingModel = "text-embedding-3-small"
+)
+
+// EmbeddingModelName returns the configured embedding model, or the
+// provider's default when none is set. Providers without a default embed
+// with the chat model.
+func (c *AIConfig) EmbeddingModelName() string {
+	if c.EmbeddingModel != "" {
+		return c.EmbeddingModel
+	}
+	if info, ok := LookupProviderInfo(c.Provider); ok && info.EmbeddingModel != "" {
+		return info.EmbeddingModel
+	}
+	return c.Model
 }
 
 // DefaultAIConfig returns the default AI configuration
 func DefaultAIConfig() *AIConfig {
 	return &AIConfig{
-		Provider:     ProviderOllama,
-		Model:        "llama3.2:3b",
-		BaseURL:      "http://localhost:11434",
-		MaxTokens:    512,
-		Temperature:  0.7,
-		Timeout:      30 * time.Second,
-		Enabled:      true,
-		CacheEnabled: true,
-		CacheTTL:     1 * time.Hour,
+		Provider:       ProviderOllama,
+		Model:          "llama3.2:3b",
+		BaseURL:        "http://localhost:11434",
+		MaxTokens:      512,
+		Temperature:    0.7,
+		Timeout:        30 * time.Second,
+		Enabled:        true,
+		CacheEnabled:   true,
+		CacheTTL:       1 * time.Hour,
+		DiskCacheMaxMB: 20,
+		SearchTopK:     20,
+		ContextWindow:  8192,
+	}
+}
+
+// MaxTemperature is the highest sampling temperature accepted by the
+// supported providers.
+const MaxTemperature = 2.0
+
+// ConfigError reports an invalid AIConfig field. Field is the field's config
+// key (e.g. "max_tokens") so callers can point at the offending input.
+type ConfigError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+// Validate checks the configuration and returns a *ConfigError for the first
+// invalid field.
+func (c *AIConfig) Validate() error {
+	info, ok := LookupProviderInfo(c.Provider)
+	if !ok {
+		var names []string
+		for _, name := range Providers() {
+			names = append(names, string(name))
+		}
+		return &ConfigError{Field: "provider", Reason: fmt.Sprintf("must be one of %s, not %q", strings.Join(names, ", "), c.Provider)}
+	}
+	// Providers with a default model or endpoint do not need one configured.
+	if c.Model == "" && info.RequiresModel {
+		return &ConfigError{Field: "model", Reason: "is required"}
+	}
+	if c.BaseURL == "" {
+		if info.RequiresBaseURL {
+			return &ConfigError{Field: "base_url", Reason: "is required"}
+		}
+	} else if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ConfigError{Field: "base_url", Reason: "must be an http:// or https:// URL"}
+	}
+	if c.MaxTokens <= 0 {
+		return &ConfigError{Field: "max_tokens", Reason: "must be positive"}
+	}
+	if c.Temperature < 0 || c.Temperature > MaxTemperature {
+		return &ConfigError{Field: "temperature", Reason: fmt.Sprintf("must be between 0 and %.1f", MaxTemperature)}
+	}
+	if c.Timeout <= 0 {
+		return &ConfigError{Field: "timeout", Reason: "must be positive"}
+	}
+	if c.CacheTTL < 0 {
+		return &ConfigError{Field: "cache_ttl", Reason: "must not be negative"}
+	}
+	if c.DiskCacheMaxMB < 0 {
+		return &ConfigError{Field: "disk_cache_max_mb", Reason: "must not be negative"}
+	}
+	if c.SearchTopK < 0 {
+		return &ConfigError{Field: "search_top_k", Reason: "must not be negative"}
+	}
+	if c.ContextWindow < 0 {
+		return &ConfigError{Field: "context_window", Reason: "must not be negative"}
+	}
+	for requestType := range c.Prompts {
+		if _, ok := PromptVariables[requestType]; !ok {
+			return &ConfigError{Field: "prompts", Reason: fmt.Sprintf("has unknown request type %q (must be one of %s)",
+				requestType, strings.Join(requestTypeNames(), ", "))}
+		}
+	}
+	for provider, budget := range c.Budgets {
+		if reason := budget.validate(); reason != "" {
+			return &ConfigError{Field: "budgets", Reason: fmt.Sprintf("for %s: %s", provider, reason)}
+		}
+	}
+	for provider, level := range c.Redaction {
+		switch level {
+		case RedactionNone, RedactionSecrets, RedactionPseudonymize:
+		default:
+			return &ConfigError{Field: "redaction", Reason: fmt.Sprintf("for %s must be %s, %s or %s, not %q",
+				provider, RedactionNone, RedactionSecrets, RedactionPseudonymize, level)}
+		}
 	}
+	return nil
 }
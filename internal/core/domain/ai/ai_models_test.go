@@ -15,6 +15,7 @@
 package ai
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -282,3 +283,95 @@ func TestAIConfigCustomization(t *testing.T) {
 		t.Error("CacheEnabled should be false")
 	}
 }
+
+func TestAIConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *AIConfig)
+		field  string
+	}{
+		{"default", func(c *AIConfig) {}, ""},
+		{"openai without base url", func(c *AIConfig) { c.Provider = ProviderOpenAI; c.BaseURL = "" }, ""},
+		{"unknown provider", func(c *AIConfig) { c.Provider = "claude" }, "provider"},
+		{"openai compatible without base url", func(c *AIConfig) { c.Provider = ProviderOpenAICompatible; c.BaseURL = "" }, "base_url"},
+		{"openai compatible without model", func(c *AIConfig) {
+			c.Provider = ProviderOpenAICompatible
+			c.BaseURL = "http://localhost:8080/v1"
+			c.Model = ""
+		}, ""},
+		{"ollama without model", func(c *AIConfig) { c.Model = "" }, "model"},
+		{"ollama without base url", func(c *AIConfig) { c.BaseURL = "" }, "base_url"},
+		{"base url without scheme", func(c *AIConfig) { c.BaseURL = "localhost:11434" }, "base_url"},
+		{"zero max tokens", func(c *AIConfig) { c.MaxTokens = 0 }, "max_tokens"},
+		{"temperature too high", func(c *AIConfig) { c.Temperature = 2.5 }, "temperature"},
+		{"negative temperature", func(c *AIConfig) { c.Temperature = -0.1 }, "temperature"},
+		{"zero timeout", func(c *AIConfig) { c.Timeout = 0 }, "timeout"},
+		{"negative cache ttl", func(c *AIConfig) { c.CacheTTL = -time.Second }, "cache_ttl"},
+		{"negative disk cache size", func(c *AIConfig) { c.DiskCacheMaxMB = -1 }, "disk_cache_max_mb"},
+		{"budget", func(c *AIConfig) {
+			c.Budgets = map[AIProvider]TokenBudget{ProviderOpenAI: {Daily: 1000, Action: BudgetRefuse}}
+		}, ""},
+		{"negative budget", func(c *AIConfig) { c.Budgets = map[AIProvider]TokenBudget{ProviderOpenAI: {Monthly: -1}} }, "budgets"},
+		{"unknown budget action", func(c *AIConfig) { c.Budgets = map[AIProvider]TokenBudget{ProviderOpenAI: {Action: "block"}} }, "budgets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultAIConfig()
+			tt.mutate(config)
+			err := config.Validate()
+
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var fieldErr *ConfigError
+			if !errors.As(err, &fieldErr) || fieldErr.Field != tt.field {
+				t.Errorf("Validate() = %v, want error for field %q", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := map[string]Priority{"High": PriorityHigh, " urgent ": PriorityCritical, "moderate": PriorityMedium, "info": PriorityLow}
+	for input, want := range tests {
+		if got, ok := ParsePriority(input); !ok || got != want {
+			t.Errorf("ParsePriority(%q) = %v, %v, want %v", input, got, ok, want)
+		}
+	}
+	if _, ok := ParsePriority("soon"); ok {
+		t.Error("Expected an unknown priority to be rejected")
+	}
+}
+
+func TestParseRecommendationType(t *testing.T) {
+	tests := map[string]RecommendationType{"Best Practice": RecTypeBestPractice, "best-practice": RecTypeBestPractice, "Security": RecTypeSecurity, "perf": RecTypePerformance}
+	for input, want := range tests {
+		if got, ok := ParseRecommendationType(input); !ok || got != want {
+			t.Errorf("ParseRecommendationType(%q) = %v, %v, want %v", input, got, ok, want)
+		}
+	}
+	if _, ok := ParseRecommendationType("misc"); ok {
+		t.Error("Expected an unknown type to be rejected")
+	}
+}
+
+func TestSortRecommendations(t *testing.T) {
+	recs := []*AIRecommendation{
+		{ID: "low", Priority: PriorityLow, Confidence: 0.9},
+		{ID: "high-unsure", Priority: PriorityHigh, Confidence: 0.5},
+		{ID: "critical", Priority: PriorityCritical, Confidence: 0.1},
+		{ID: "high-sure", Priority: PriorityHigh, Confidence: 0.9},
+	}
+	SortRecommendations(recs)
+
+	want := []string{"critical", "high-sure", "high-unsure", "low"}
+	for i, rec := range recs {
+		if rec.ID != want[i] {
+			t.Errorf("Position %d = %s, want %s", i, rec.ID, want[i])
+		}
+	}
+}
@@ -15,17 +15,85 @@
 package ai
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
 )
 
-// PromptTemplate represents a template for AI prompts
+// FormatJSON marks templates whose prompt asks for a JSON answer.
+const FormatJSON = "json"
+
+// recommendationJSONFormat is appended to prompts whose answer is parsed into
+// AIRecommendation items.
+const recommendationJSONFormat = "\n\nRespond with only a JSON object, no other text, in this format:\n" +
+	`{"recommendations": [{"title": "short summary", "description": "what to change and why", ` +
+	`"type": "security|performance|optimization|best_practice|connection", "priority": "critical|high|medium|low", ` +
+	`"confidence": 0.0-1.0, "actions": ["one concrete step"], ` +
+	`"changes": [{"option": "ssh_config keyword, e.g. ServerAliveInterval", "value": "new value, empty to remove"}]}]}` +
+	"\nOnly list changes to the server's own Host block; leave changes empty when the fix is not an ssh_config option."
+
+// searchJSONFormat is appended to the search prompt, whose answer is parsed
+// into SearchResult items.
+const searchJSONFormat = "\n\nRespond with only a JSON object, no other text, in this format:\n" +
+	`{"matches": [{"alias": "the Host value of a listed server", "score": 0.0-1.0, "reason": "why it matches"}]}` +
+	"\nOnly use aliases of the servers listed above, and return an empty list when no server matches."
+
+// PromptTemplate represents a template for AI prompts. Template is a
+// text/template rendered with the request's variables, e.g. {{.config}} or
+// {{range .server_list}}{{.Host}}{{end}}.
 type PromptTemplate struct {
-	Name        string        `json:"name"`
-	Type        AIRequestType `json:"type"`
-	Template    string        `json:"template"`
-	Variables   []string      `json:"variables"`
-	Description string        `json:"description"`
+	Name        string        `json:"name" yaml:"name"`
+	Type        AIRequestType `json:"type" yaml:"type"`
+	Template    string        `json:"template" yaml:"template"`
+	Variables   []string      `json:"variables" yaml:"variables"`
+	Description string        `json:"description" yaml:"description"`
+	Format      string        `json:"format,omitempty" yaml:"format,omitempty"` // FormatJSON or empty for free text
+	// Source is the file a user template was loaded from; empty for the
+	// built-in ones.
+	Source string `json:"-" yaml:"-"`
+
+	parsed *template.Template
+}
+
+// PromptVariables lists the variables supplied to the templates of each
+// request type. Templates may only declare and use these.
+var PromptVariables = map[AIRequestType][]string{
+	// config is the formatted server configuration, server the same as a
+	// map keyed by option name.
+	RequestTypeRecommendation: {"config", "context", "server"},
+	RequestTypeSecurity:       {"config", "server"},
+	// servers is the formatted server list, server_list the same as a list
+	// of maps keyed by option name.
+	RequestTypeSearch:       {"query", "servers", "server_list"},
+	RequestTypeOptimization: {"config", "issues"},
+	RequestTypeGeneral:      {"patterns", "config"},
+}
+
+// answerFormats are the answer format instructions, by request type, that
+// templates insert with {{answerFormat}}.
+var answerFormats = map[AIRequestType]string{
+	RequestTypeRecommendation: recommendationJSONFormat,
+	RequestTypeSecurity:       recommendationJSONFormat,
+	RequestTypeSearch:         searchJSONFormat,
+}
+
+// templateFuncs returns the functions available to the templates of
+// requestType.
+func templateFuncs(requestType AIRequestType) template.FuncMap {
+	return template.FuncMap{
+		// answerFormat is the JSON format the answers of recommendations,
+		// security analyses and searches are parsed from.
+		"answerFormat": func() string { return strings.TrimPrefix(answerFormats[requestType], "\n\n") },
+		"join":         strings.Join,
+		"lower":        strings.ToLower,
+		"upper":        strings.ToUpper,
+	}
 }
 
 // GetPromptTemplates returns all available prompt templates
@@ -34,23 +102,26 @@ func GetPromptTemplates() map[string]*PromptTemplate {
 		"server_recommendation": {
 			Name:        "Server Recommendation",
 			Type:        RequestTypeRecommendation,
-			Template:    "Based on the SSH server configuration below, provide security and performance recommendations. Focus on best practices and potential improvements.\n\nServer Config:\n{{.config}}\n\nUser Context: {{.context}}\n\nProvide specific, actionable recommendations with priority levels.",
+			Template:    "Based on the SSH server configuration below, provide security and performance recommendations. Focus on best practices and potential improvements.\n\nServer Config:\n{{.config}}\n\nUser Context: {{.context}}\n\nProvide specific, actionable recommendations with priority levels." + recommendationJSONFormat,
 			Variables:   []string{"config", "context"},
 			Description: "Generates recommendations for SSH server configurations",
+			Format:      FormatJSON,
 		},
 		"natural_language_search": {
 			Name:        "Natural Language Search",
 			Type:        RequestTypeSearch,
-			Template:    "Find SSH servers that match this natural language description: \"{{.query}}\"\n\nAvailable servers:\n{{.servers}}\n\nReturn a ranked list of matching servers with explanations.",
+			Template:    "Find SSH servers that match this natural language description: \"{{.query}}\"\n\nAvailable servers:\n{{.servers}}\n\nRank the servers that match, best match first." + searchJSONFormat,
 			Variables:   []string{"query", "servers"},
 			Description: "Searches servers using natural language queries",
+			Format:      FormatJSON,
 		},
 		"security_analysis": {
 			Name:        "Security Analysis",
 			Type:        RequestTypeSecurity,
-			Template:    "Analyze the following SSH configuration for security vulnerabilities and best practices:\n\n{{.config}}\n\nProvide a security assessment with:\n1. Identified vulnerabilities\n2. Risk levels\n3. Recommended fixes\n4. Best practice suggestions",
+			Template:    "Analyze the following SSH configuration for security vulnerabilities and best practices:\n\n{{.config}}\n\nReport each identified vulnerability or best practice suggestion as one recommendation, using its risk level as the priority and the recommended fixes as actions." + recommendationJSONFormat,
 			Variables:   []string{"config"},
 			Description: "Performs security analysis of SSH configurations",
+			Format:      FormatJSON,
 		},
 		"connection_optimization": {
 			Name:        "Connection Optimization",
@@ -69,58 +140,290 @@ func GetPromptTemplates() map[string]*PromptTemplate {
 	}
 }
 
-// BuildPrompt builds a prompt from a template with variables
+// BuildPrompt builds a prompt from a template with variables. A template that
+// fails to render is returned as written.
 func (pt *PromptTemplate) BuildPrompt(variables map[string]string) string {
-	prompt := pt.Template
+	data := make(map[string]interface{}, len(variables))
+	for name, value := range variables {
+		data[name] = value
+	}
+	prompt, err := pt.Render(data)
+	if err != nil {
+		return pt.Template
+	}
+	return prompt
+}
+
+// Render executes the template with data. Declared variables missing from
+// data render as empty.
+func (pt *PromptTemplate) Render(data map[string]interface{}) (string, error) {
+	tmpl := pt.parsed
+	if tmpl == nil {
+		var err error
+		if tmpl, err = pt.parse(); err != nil {
+			return "", err
+		}
+	}
+
+	values := make(map[string]interface{}, len(pt.Variables)+len(data))
+	for _, variable := range pt.Variables {
+		values[variable] = ""
+	}
+	for name, value := range data {
+		values[name] = value
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, values); err != nil {
+		return "", fmt.Errorf("render prompt template %q: %w", pt.Name, err)
+	}
+	return b.String(), nil
+}
+
+func (pt *PromptTemplate) parse() (*template.Template, error) {
+	tmpl, err := template.New(pt.Name).Funcs(templateFuncs(pt.Type)).Parse(pt.Template)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt template %q: %w", pt.Name, err)
+	}
+	return tmpl, nil
+}
 
+// Validate checks a prompt template before it is used: its type and format
+// are known, it parses, its declared Variables are supplied for its type and
+// it uses no variable it does not declare. A valid template is kept parsed.
+func (pt *PromptTemplate) Validate() error {
+	if strings.TrimSpace(pt.Name) == "" {
+		return errors.New("name is required")
+	}
+	supplied, ok := PromptVariables[pt.Type]
+	if !ok {
+		return fmt.Errorf("type must be one of %s, not %q", strings.Join(requestTypeNames(), ", "), pt.Type)
+	}
+	if pt.Format != "" && pt.Format != FormatJSON {
+		return fmt.Errorf("format must be empty or %q, not %q", FormatJSON, pt.Format)
+	}
+	if strings.TrimSpace(pt.Template) == "" {
+		return errors.New("template is required")
+	}
+
+	declared := make(map[string]bool, len(pt.Variables))
 	for _, variable := range pt.Variables {
-		if value, exists := variables[variable]; exists {
-			placeholder := fmt.Sprintf("{{.%s}}", variable)
-			prompt = strings.ReplaceAll(prompt, placeholder, value)
+		if !containsString(supplied, variable) {
+			return fmt.Errorf("variable %q is not available to %s templates (available: %s)",
+				variable, pt.Type, strings.Join(supplied, ", "))
 		}
+		declared[variable] = true
 	}
 
-	return prompt
+	tmpl, err := pt.parse()
+	if err != nil {
+		return err
+	}
+	for _, used := range templateVariables(tmpl.Tree.Root) {
+		if !declared[used] {
+			return fmt.Errorf("template uses undeclared variable %q", used)
+		}
+	}
+	pt.parsed = tmpl
+	return nil
 }
 
-// GetPromptForType returns the appropriate prompt template for a request type
-func GetPromptForType(requestType AIRequestType) *PromptTemplate {
+// requestTypeNames returns the request types that have prompt templates.
+func requestTypeNames() []string {
+	names := make([]string, 0, len(PromptVariables))
+	for requestType := range PromptVariables {
+		names = append(names, string(requestType))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// templateVariables returns the top-level variables node reads, as {{.name}}
+// where dot is the template data or as {{$.name}} anywhere. Inside range and
+// with, dot is an element and its fields are not variables.
+func templateVariables(node parse.Node) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	var walk func(node parse.Node, dotIsRoot bool)
+	walkList := func(list *parse.ListNode, dotIsRoot bool) {
+		if list != nil {
+			for _, n := range list.Nodes {
+				walk(n, dotIsRoot)
+			}
+		}
+	}
+	walkPipe := func(pipe *parse.PipeNode, dotIsRoot bool) {
+		if pipe == nil {
+			return
+		}
+		for _, cmd := range pipe.Cmds {
+			for _, arg := range cmd.Args {
+				walk(arg, dotIsRoot)
+			}
+		}
+	}
+	walk = func(node parse.Node, dotIsRoot bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			walkList(n, dotIsRoot)
+		case *parse.ActionNode:
+			walkPipe(n.Pipe, dotIsRoot)
+		case *parse.PipeNode:
+			walkPipe(n, dotIsRoot)
+		case *parse.FieldNode:
+			if dotIsRoot {
+				add(n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				add(n.Ident[1])
+			}
+		case *parse.ChainNode:
+			walk(n.Node, dotIsRoot)
+		case *parse.IfNode:
+			walkPipe(n.Pipe, dotIsRoot)
+			walkList(n.List, dotIsRoot)
+			walkList(n.ElseList, dotIsRoot)
+		case *parse.RangeNode:
+			walkPipe(n.Pipe, dotIsRoot)
+			walkList(n.List, false)
+			walkList(n.ElseList, dotIsRoot)
+		case *parse.WithNode:
+			walkPipe(n.Pipe, dotIsRoot)
+			walkList(n.List, false)
+			walkList(n.ElseList, dotIsRoot)
+		case *parse.TemplateNode:
+			walkPipe(n.Pipe, dotIsRoot)
+		}
+	}
+	walk(node, true)
+	return names
+}
+
+// PromptLibrary picks the prompt template for each request type from the
+// built-in templates and the user's, which override built-ins of the same
+// name.
+type PromptLibrary struct {
+	templates map[string]*PromptTemplate
+}
+
+// NewPromptLibrary combines the built-in templates with user, keyed by
+// template name. user templates must have been validated.
+func NewPromptLibrary(user map[string]*PromptTemplate) *PromptLibrary {
 	templates := GetPromptTemplates()
+	for name, pt := range user {
+		templates[name] = pt
+	}
+	return &PromptLibrary{templates: templates}
+}
+
+// Names returns the names of the templates for requestType, sorted.
+func (l *PromptLibrary) Names(requestType AIRequestType) []string {
+	var names []string
+	for name, pt := range l.templates {
+		if pt.Type == requestType {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Template returns the template named name, if any.
+func (l *PromptLibrary) Template(name string) (*PromptTemplate, bool) {
+	pt, ok := l.templates[name]
+	return pt, ok
+}
 
-	switch requestType {
-	case RequestTypeRecommendation:
-		return templates["server_recommendation"]
-	case RequestTypeSearch:
-		return templates["natural_language_search"]
-	case RequestTypeSecurity:
-		return templates["security_analysis"]
-	case RequestTypeOptimization:
-		return templates["connection_optimization"]
-	case RequestTypeGeneral:
-		return templates["intelligent_suggestions"]
-	default:
-		return templates["intelligent_suggestions"]
+// ForType returns the template for requestType: the one named by choice when
+// it exists and is of that type, otherwise the default one. The error
+// explains why choice was not used.
+func (l *PromptLibrary) ForType(requestType AIRequestType, choice string) (*PromptTemplate, error) {
+	var err error
+	if choice != "" {
+		pt, ok := l.templates[choice]
+		switch {
+		case !ok:
+			err = fmt.Errorf("prompt template %q not found", choice)
+		case pt.Type != requestType:
+			err = fmt.Errorf("prompt template %q is for %s, not %s requests", choice, pt.Type, requestType)
+		default:
+			return pt, nil
+		}
 	}
+	return l.templates[defaultTemplateNames[requestType]], err
+}
+
+// defaultTemplateNames are the templates used for each request type unless
+// another is chosen.
+var defaultTemplateNames = map[AIRequestType]string{
+	RequestTypeRecommendation: "server_recommendation",
+	RequestTypeSearch:         "natural_language_search",
+	RequestTypeSecurity:       "security_analysis",
+	RequestTypeOptimization:   "connection_optimization",
+	RequestTypeGeneral:        "intelligent_suggestions",
+}
+
+// GetPromptForType returns the built-in prompt template for a request type
+func GetPromptForType(requestType AIRequestType) *PromptTemplate {
+	name, ok := defaultTemplateNames[requestType]
+	if !ok {
+		name = defaultTemplateNames[RequestTypeGeneral]
+	}
+	return GetPromptTemplates()[name]
 }
 
 // FormatServerConfig formats server configuration for AI prompts
 func FormatServerConfig(config map[string]string) string {
 	var parts []string
-	for key, value := range config {
-		if value != "" {
+	for _, key := range sortedKeys(config) {
+		if value := config[key]; value != "" {
 			parts = append(parts, fmt.Sprintf("%s: %s", key, value))
 		}
 	}
 	return strings.Join(parts, "\n")
 }
 
+// ServerSummary describes a server with SSH config option names, as sent to
+// the model and indexed for semantic search.
+func ServerSummary(server domain.Server) map[string]string {
+	summary := map[string]string{
+		"Host":         server.Alias,
+		"HostName":     server.Host,
+		"User":         server.User,
+		"ProxyJump":    server.ProxyJump,
+		"IdentityFile": strings.Join(server.IdentityFiles, ", "),
+		"Tags":         strings.Join(server.Tags, ", "),
+	}
+	if server.Port != 0 {
+		summary["Port"] = strconv.Itoa(server.Port)
+	}
+	return summary
+}
+
 // FormatServerList formats a list of servers for AI prompts
 func FormatServerList(servers []map[string]string) string {
 	parts := make([]string, 0, len(servers))
 	for i, server := range servers {
 		serverInfo := fmt.Sprintf("Server %d:", i+1)
-		for key, value := range server {
-			if value != "" {
+		for _, key := range sortedKeys(server) {
+			if value := server[key]; value != "" {
 				serverInfo += fmt.Sprintf("\n  %s: %s", key, value)
 			}
 		}
@@ -128,3 +431,54 @@ func FormatServerList(servers []map[string]string) string {
 	}
 	return strings.Join(parts, "\n\n")
 }
+
+// sortedKeys keeps prompts (and therefore cache keys) stable across calls.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// searchVerbs start queries that look for servers rather than advice.
+var searchVerbs = map[string]bool{
+	"find": true, "search": true, "list": true, "show": true, "which": true,
+	"where": true, "locate": true, "lookup": true, "get": true,
+}
+
+// securityTerms mark queries about the safety of a configuration.
+var securityTerms = []string{
+	"secur", "harden", "audit", "vulnerab", "risk", "attack", "exploit",
+	"exposure", "weak", "safe", "cve", "compliance",
+}
+
+// ClassifyQuery picks the request type for a free-form question: a search
+// for servers, a security analysis or a general recommendation. Queries that
+// start with a search verb are searches even when they mention security
+// ("find insecure servers").
+func ClassifyQuery(query string) AIRequestType {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	if len(words) == 0 {
+		return RequestTypeRecommendation
+	}
+	if searchVerbs[words[0]] {
+		return RequestTypeSearch
+	}
+	for _, word := range words {
+		for _, term := range securityTerms {
+			if strings.HasPrefix(word, term) || strings.HasPrefix(word, "in"+term) {
+				return RequestTypeSecurity
+			}
+		}
+	}
+	for _, word := range words {
+		if word == "search" || word == "find" {
+			return RequestTypeSearch
+		}
+	}
+	return RequestTypeRecommendation
+}
@@ -0,0 +1,158 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  AIRequestType
+	}{
+		{query: "find my postgres boxes in staging", want: RequestTypeSearch},
+		{query: "Which servers use the bastion?", want: RequestTypeSearch},
+		{query: "find insecure servers", want: RequestTypeSearch},
+		{query: "Is this server secure?", want: RequestTypeSecurity},
+		{query: "audit my config", want: RequestTypeSecurity},
+		{query: "any risks with agent forwarding here", want: RequestTypeSecurity},
+		{query: "how can I make connections faster", want: RequestTypeRecommendation},
+		{query: "please search for db hosts", want: RequestTypeSearch},
+		{query: "   ", want: RequestTypeRecommendation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := ClassifyQuery(tt.query); got != tt.want {
+				t.Errorf("ClassifyQuery(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatServerConfig_SortedKeys(t *testing.T) {
+	got := FormatServerConfig(map[string]string{"User": "deploy", "Host": "web", "Port": "", "HostName": "10.0.0.1"})
+	want := "Host: web\nHostName: 10.0.0.1\nUser: deploy"
+	if got != want {
+		t.Errorf("FormatServerConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestPromptTemplates_BuiltinsValidate(t *testing.T) {
+	for name, template := range GetPromptTemplates() {
+		if err := template.Validate(); err != nil {
+			t.Errorf("Built-in template %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestPromptTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  PromptTemplate
+		wantError string
+	}{
+		{"loop over servers", PromptTemplate{Name: "t", Type: RequestTypeSearch, Variables: []string{"query", "server_list"},
+			Template: `{{.query}}{{range .server_list}}{{.Host}} {{$.query}}{{end}}`}, ""},
+		{"conditional", PromptTemplate{Name: "t", Type: RequestTypeRecommendation, Variables: []string{"config", "context"},
+			Template: `{{.config}}{{if .context}} {{.context}}{{end}}`}, ""},
+		{"undeclared variable", PromptTemplate{Name: "t", Type: RequestTypeSecurity, Variables: []string{"config"},
+			Template: `{{.config}} {{.server.HostName}}`}, `undeclared variable "server"`},
+		{"undeclared root variable in loop", PromptTemplate{Name: "t", Type: RequestTypeSearch, Variables: []string{"server_list"},
+			Template: `{{range .server_list}}{{$.query}}{{end}}`}, `undeclared variable "query"`},
+		{"variable not supplied for type", PromptTemplate{Name: "t", Type: RequestTypeSecurity, Variables: []string{"query"},
+			Template: `{{.query}}`}, `variable "query" is not available`},
+		{"unknown type", PromptTemplate{Name: "t", Type: "chat", Template: "x"}, "type must be one of"},
+		{"unknown format", PromptTemplate{Name: "t", Type: RequestTypeSearch, Format: "xml", Template: "x"}, "format must be"},
+		{"syntax error", PromptTemplate{Name: "t", Type: RequestTypeSearch, Template: "{{if .query}}"}, "parse prompt template"},
+		{"missing name", PromptTemplate{Type: RequestTypeSearch, Template: "x"}, "name is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.template.Validate()
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestPromptTemplate_Render(t *testing.T) {
+	template := &PromptTemplate{
+		Name:      "search",
+		Type:      RequestTypeSearch,
+		Variables: []string{"query", "server_list"},
+		Template: "Find {{.query}}.\n{{range .server_list}}- {{.Host}}{{if .User}} as {{.User}}{{end}}\n{{end}}" +
+			"{{answerFormat}}",
+	}
+	if err := template.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	prompt, err := template.Render(map[string]interface{}{
+		"query":       "db servers",
+		"server_list": []map[string]string{{"Host": "db1", "User": "postgres"}, {"Host": "db2"}},
+	})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := "Find db servers.\n- db1 as postgres\n- db2\n"
+	if !strings.HasPrefix(prompt, want) {
+		t.Errorf("Render() = %q, want prefix %q", prompt, want)
+	}
+	if !strings.Contains(prompt, `{"matches": [`) {
+		t.Errorf("Expected answerFormat to insert the search format, got %q", prompt)
+	}
+}
+
+func TestPromptTemplate_BuildPrompt(t *testing.T) {
+	template := GetPromptForType(RequestTypeRecommendation)
+	prompt := template.BuildPrompt(map[string]string{"config": "Host: db1"})
+	if !strings.Contains(prompt, "Server Config:\nHost: db1\n\nUser Context: \n") {
+		t.Errorf("Expected declared variables missing from the input to render empty, got %q", prompt)
+	}
+}
+
+func TestPromptLibrary_ForType(t *testing.T) {
+	custom := &PromptTemplate{Name: "terse", Type: RequestTypeSecurity, Variables: []string{"config"}, Template: "Audit {{.config}}"}
+	override := &PromptTemplate{Name: "search", Type: RequestTypeSearch, Variables: []string{"query"}, Template: "Find {{.query}}"}
+	library := NewPromptLibrary(map[string]*PromptTemplate{"terse": custom, "natural_language_search": override})
+
+	if got, err := library.ForType(RequestTypeSecurity, "terse"); err != nil || got != custom {
+		t.Errorf("ForType(security, terse) = %v, %v", got, err)
+	}
+	if got, err := library.ForType(RequestTypeSearch, ""); err != nil || got != override {
+		t.Errorf("Expected a user template to override the built-in one of the same name, got %v, %v", got, err)
+	}
+
+	got, err := library.ForType(RequestTypeRecommendation, "terse")
+	if err == nil || got.Name != "Server Recommendation" {
+		t.Errorf("Expected a template of another type to fall back to the default, got %v, %v", got, err)
+	}
+	if _, err := library.ForType(RequestTypeSecurity, "missing"); err == nil {
+		t.Error("Expected an error for a missing template")
+	}
+	if names := library.Names(RequestTypeSecurity); strings.Join(names, ",") != "security_analysis,terse" {
+		t.Errorf("Names(security) = %v", names)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageRole is the author of a conversation message.
+type MessageRole string
+
+const (
+	RoleSystem    MessageRole = "system"
+	RoleUser      MessageRole = "user"
+	RoleAssistant MessageRole = "assistant"
+)
+
+// Message is one turn of a conversation.
+type Message struct {
+	Role    MessageRole `json:"role"`
+	Content string      `json:"content"`
+	// Type is the request type a user question was routed to.
+	Type      AIRequestType `json:"type,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// Conversation is a multi-turn chat. Messages holds the user questions and
+// assistant answers in order; the system prompt is not stored.
+type Conversation struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+	Messages []Message `json:"messages"`
+}
+
+// maxTitleLength caps titles derived from the first question.
+const maxTitleLength = 60
+
+// DisplayTitle returns the conversation's title or, when it has none, the
+// start of its first question.
+func (c *Conversation) DisplayTitle() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	for _, message := range c.Messages {
+		if message.Role == RoleUser {
+			title := strings.Join(strings.Fields(message.Content), " ")
+			if utf8.RuneCountInString(title) > maxTitleLength {
+				title = string([]rune(title)[:maxTitleLength-1]) + "…"
+			}
+			return title
+		}
+	}
+	return "New conversation"
+}
+
+// messageOverhead approximates the tokens each message adds for its role and
+// delimiters.
+const messageOverhead = 4
+
+// EstimateTokens approximates the number of tokens in text at four bytes per
+// token, which is close for English text with the common tokenizers.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// TruncateMessages returns the most recent messages whose estimated tokens fit
+// in budget, dropping the oldest first. The result never starts with an
+// assistant message, so the history the model sees opens with a question.
+func TruncateMessages(messages []Message, budget int) []Message {
+	start := len(messages)
+	used := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		used += EstimateTokens(messages[i].Content) + messageOverhead
+		if used > budget {
+			break
+		}
+		start = i
+	}
+	for start < len(messages) && messages[start].Role != RoleUser {
+		start++
+	}
+	return messages[start:]
+}
+
+// ChatSystemPrompt returns the system prompt that opens every conversation.
+// It describes the assistant's tasks from the templates in prompts, including
+// the user's.
+func ChatSystemPrompt(prompts *PromptLibrary) string {
+	templates := prompts.templates
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("You are the SSH configuration assistant of wooak, a terminal SSH connection manager. ")
+	b.WriteString("You help the user with:\n")
+	for _, name := range names {
+		template := templates[name]
+		fmt.Fprintf(&b, "- %s: %s\n", template.Name, template.Description)
+	}
+	b.WriteString("\nThis is a conversation: earlier questions and answers are included, so follow-up " +
+		"questions may refer to them. Answer in the format each question asks for.")
+	return b.String()
+}
+
+// SummarizeRecommendations renders recommendations as the plain text kept as
+// the assistant's turn in a conversation.
+func SummarizeRecommendations(recs []*AIRecommendation) string {
+	if len(recs) == 0 {
+		return "No recommendations; nothing needs to change."
+	}
+	var b strings.Builder
+	for i, rec := range recs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%d. [%s] %s", i+1, rec.Priority, rec.Title)
+		if rec.Description != "" {
+			b.WriteString(": " + rec.Description)
+		}
+		b.WriteString("\n")
+		for _, action := range rec.Actions {
+			b.WriteString("   - " + action + "\n")
+		}
+		for _, change := range rec.Changes {
+			fmt.Fprintf(&b, "   - set %s %s\n", change.Option, change.Value)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// SummarizeSearchResults renders search results as the plain text kept as the
+// assistant's turn in a conversation.
+func SummarizeSearchResults(results []SearchResult) string {
+	if len(results) == 0 {
+		return "No servers match."
+	}
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		line := fmt.Sprintf("%s (%.2f)", result.Alias, result.Score)
+		if result.Reason != "" {
+			line += ": " + result.Reason
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
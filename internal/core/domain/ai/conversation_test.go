@@ -0,0 +1,111 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateMessages(t *testing.T) {
+	messages := []Message{
+		{Role: RoleUser, Content: strings.Repeat("a", 400)},      // 100 tokens
+		{Role: RoleAssistant, Content: strings.Repeat("b", 400)}, // 100 tokens
+		{Role: RoleUser, Content: strings.Repeat("c", 40)},       // 10 tokens
+		{Role: RoleAssistant, Content: strings.Repeat("d", 40)},  // 10 tokens
+	}
+
+	tests := []struct {
+		name   string
+		budget int
+		want   int
+	}{
+		{"everything fits", 1000, 4},
+		{"oldest turn dropped", 150, 2},
+		{"never starts with an answer", 200, 2},
+		{"nothing fits", 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateMessages(messages, tt.budget)
+			if len(got) != tt.want {
+				t.Fatalf("TruncateMessages() kept %d messages, want %d", len(got), tt.want)
+			}
+			if len(got) > 0 && got[0].Role != RoleUser {
+				t.Errorf("Expected the history to start with a question, got %s", got[0].Role)
+			}
+			if len(got) > 0 && got[len(got)-1] != messages[len(messages)-1] {
+				t.Error("Expected the most recent message to be kept")
+			}
+		})
+	}
+}
+
+func TestConversation_DisplayTitle(t *testing.T) {
+	conversation := &Conversation{}
+	if got := conversation.DisplayTitle(); got != "New conversation" {
+		t.Errorf("DisplayTitle() = %q for an empty conversation", got)
+	}
+
+	conversation.Messages = []Message{{Role: RoleUser, Content: "  how do I\nharden   db1?"}}
+	if got := conversation.DisplayTitle(); got != "how do I harden db1?" {
+		t.Errorf("DisplayTitle() = %q", got)
+	}
+
+	conversation.Messages[0].Content = strings.Repeat("x", 100)
+	if got := conversation.DisplayTitle(); len([]rune(got)) != maxTitleLength {
+		t.Errorf("Expected a title of %d runes, got %d", maxTitleLength, len([]rune(got)))
+	}
+
+	conversation.Title = "DB hardening"
+	if got := conversation.DisplayTitle(); got != "DB hardening" {
+		t.Errorf("DisplayTitle() = %q, want the set title", got)
+	}
+}
+
+func TestChatSystemPrompt(t *testing.T) {
+	custom := &PromptTemplate{Name: "Terse Audit", Description: "One-line security audits", Type: RequestTypeSecurity}
+	library := NewPromptLibrary(map[string]*PromptTemplate{"terse": custom})
+
+	prompt := ChatSystemPrompt(library)
+	for _, template := range GetPromptTemplates() {
+		if !strings.Contains(prompt, template.Description) {
+			t.Errorf("Expected the system prompt to describe %q", template.Name)
+		}
+	}
+	if !strings.Contains(prompt, "- Terse Audit: One-line security audits\n") {
+		t.Errorf("Expected the system prompt to describe the user's template, got:\n%s", prompt)
+	}
+	if prompt != ChatSystemPrompt(library) {
+		t.Error("Expected the system prompt to be stable")
+	}
+}
+
+func TestSummarizeRecommendations(t *testing.T) {
+	recs := []*AIRecommendation{{
+		Title:    "Keep connections alive",
+		Priority: PriorityMedium,
+		Actions:  []string{"Add ServerAliveInterval"},
+	}}
+	want := "1. [medium] Keep connections alive\n   - Add ServerAliveInterval"
+	if got := SummarizeRecommendations(recs); got != want {
+		t.Errorf("SummarizeRecommendations() = %q, want %q", got, want)
+	}
+
+	results := []SearchResult{{Alias: "db1", Score: 0.9, Reason: "postgres"}}
+	if got := SummarizeSearchResults(results); got != "db1 (0.90): postgres" {
+		t.Errorf("SummarizeSearchResults() = %q", got)
+	}
+}
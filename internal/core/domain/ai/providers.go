@@ -0,0 +1,107 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"sort"
+	"sync"
+)
+
+// ProviderInfo describes what a provider needs from AIConfig and the defaults
+// it uses. Every provider an AIConfig can name is registered with one.
+type ProviderInfo struct {
+	Name AIProvider
+	// DisplayName names the provider in the UI.
+	DisplayName string
+	// RequiresBaseURL and RequiresModel mark fields without a usable default.
+	RequiresBaseURL bool
+	RequiresModel   bool
+	// DefaultBaseURL is suggested when switching to the provider.
+	DefaultBaseURL string
+	// Redaction is the redaction level used unless AIConfig.Redaction sets
+	// one.
+	Redaction RedactionLevel
+	// EmbeddingModel is the default embedding model; empty uses the chat
+	// model.
+	EmbeddingModel string
+}
+
+var (
+	providerInfoMu sync.RWMutex
+	providerInfos  = map[AIProvider]ProviderInfo{
+		ProviderOllama: {
+			Name:            ProviderOllama,
+			DisplayName:     "Ollama",
+			RequiresBaseURL: true,
+			RequiresModel:   true,
+			DefaultBaseURL:  "http://localhost:11434",
+			Redaction:       RedactionSecrets,
+			EmbeddingModel:  DefaultOllamaEmbeddingModel,
+		},
+		ProviderOpenAI: {
+			Name:           ProviderOpenAI,
+			DisplayName:    "OpenAI",
+			DefaultBaseURL: "https://api.openai.com/v1",
+			Redaction:      RedactionPseudonymize,
+			EmbeddingModel: DefaultOpenAIEmbeddingModel,
+		},
+		ProviderOpenAICompatible: {
+			Name:            ProviderOpenAICompatible,
+			DisplayName:     "OpenAI-compatible (llama.cpp, vLLM, LM Studio)",
+			RequiresBaseURL: true,
+			DefaultBaseURL:  "http://localhost:8080/v1",
+			Redaction:       RedactionPseudonymize,
+		},
+	}
+)
+
+// RegisterProviderInfo makes a provider known to AIConfig, replacing any
+// earlier registration of the same name.
+func RegisterProviderInfo(info ProviderInfo) {
+	providerInfoMu.Lock()
+	defer providerInfoMu.Unlock()
+	providerInfos[info.Name] = info
+}
+
+// LookupProviderInfo returns the registration of provider.
+func LookupProviderInfo(provider AIProvider) (ProviderInfo, bool) {
+	providerInfoMu.RLock()
+	defer providerInfoMu.RUnlock()
+	info, ok := providerInfos[provider]
+	return info, ok
+}
+
+// Providers returns the names of the registered providers, built-in ones
+// first.
+func Providers() []AIProvider {
+	providerInfoMu.RLock()
+	defer providerInfoMu.RUnlock()
+	names := make([]AIProvider, 0, len(providerInfos))
+	for name := range providerInfos {
+		names = append(names, name)
+	}
+	builtin := map[AIProvider]int{ProviderOllama: 1, ProviderOpenAI: 2, ProviderOpenAICompatible: 3}
+	sort.Slice(names, func(i, j int) bool {
+		bi, bj := builtin[names[i]], builtin[names[j]]
+		if bi != bj {
+			if bi == 0 || bj == 0 {
+				return bj == 0
+			}
+			return bi < bj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
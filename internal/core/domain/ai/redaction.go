@@ -0,0 +1,417 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactionLevel controls what is removed from prompts before they are sent
+// to a provider.
+type RedactionLevel string
+
+const (
+	// RedactionNone sends prompts unchanged.
+	RedactionNone RedactionLevel = "none"
+	// RedactionSecrets strips SetEnv values, command lines, tokens and
+	// credentials, and shortens home directories to ~.
+	RedactionSecrets RedactionLevel = "secrets"
+	// RedactionPseudonymize also replaces host names and users with stable
+	// pseudonyms, which are mapped back in the answer.
+	RedactionPseudonymize RedactionLevel = "pseudonymize"
+)
+
+// ParseRedactionLevel reads a redaction level, reporting false for unknown
+// levels.
+func ParseRedactionLevel(s string) (RedactionLevel, bool) {
+	switch level := RedactionLevel(strings.ToLower(strings.TrimSpace(s))); level {
+	case RedactionNone, RedactionSecrets, RedactionPseudonymize:
+		return level, true
+	}
+	return "", false
+}
+
+const (
+	redactedValue = "[redacted]"
+	// pseudonymHashLen is the number of hex digits in a pseudonym.
+	pseudonymHashLen = 8
+	// maxPseudonymLen is the length of the longest pseudonym ("host-" plus
+	// the hash).
+	maxPseudonymLen = len("host-") + pseudonymHashLen
+	// minFreeTextLen is the shortest host or user replaced in free text;
+	// shorter values match too many unrelated words.
+	minFreeTextLen = 3
+)
+
+// Option categories, by lower-cased ssh_config keyword.
+var (
+	redactHostOptions = map[string]bool{"hostname": true, "hostkeyalias": true}
+	redactUserOptions = map[string]bool{"user": true}
+	redactJumpOptions = map[string]bool{"proxyjump": true}
+	redactEnvOptions  = map[string]bool{"setenv": true}
+	redactCmdOptions  = map[string]bool{
+		"proxycommand": true, "localcommand": true, "remotecommand": true, "knownhostscommand": true,
+	}
+	redactPathOptions = map[string]bool{
+		"identityfile": true, "certificatefile": true, "userknownhostsfile": true,
+		"globalknownhostsfile": true, "controlpath": true, "identityagent": true,
+		"revokedhostkeys": true, "securitykeyprovider": true, "pkcs11provider": true,
+		"xauthlocation": true,
+	}
+)
+
+var (
+	// optionLine matches the "Key: value" lines written by FormatServerConfig
+	// and FormatServerList.
+	optionLine = regexp.MustCompile(`^(\s*)([A-Za-z][A-Za-z0-9]*): (.*)$`)
+	// homeDir matches a home directory at the start of a path.
+	homeDir = regexp.MustCompile(`(^|[\s,="'])(?:/home/[^/\s,"']+|/Users/[^/\s,"']+|/root)(/|$|[\s,"'])`)
+	// secretAssignment matches name=value and name: value pairs whose name
+	// says the value is a secret.
+	secretAssignment = regexp.MustCompile(`(?i)\b([A-Za-z0-9_-]*(?:token|secret|password|passwd|api[_-]?key|access[_-]?key|credentials?))(\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s,;&|]+)`)
+	// bearerToken matches HTTP authorization values.
+	bearerToken = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/-]{8,}=*`)
+	// knownToken matches the formats of common API keys and access tokens.
+	knownToken = regexp.MustCompile(`\b(?:sk-[A-Za-z0-9_-]{16,}|gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|glpat-[A-Za-z0-9_-]{20,}|xox[abprs]-[A-Za-z0-9-]{10,}|AKIA[0-9A-Z]{16})\b`)
+	// urlCredentials matches the user:password part of a URL.
+	urlCredentials = regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`)
+	// pseudonymPattern matches the pseudonyms made by Redactor.
+	pseudonymPattern = regexp.MustCompile(`\b(?:host|user)-[0-9a-f]{8}\b`)
+)
+
+// Redactor removes secrets and identifying data from a prompt before it is
+// sent to a provider and maps pseudonyms back in the answer. Pseudonyms are
+// derived from the value they replace, so the same host gets the same
+// pseudonym in every request. Use one Redactor per request.
+type Redactor struct {
+	level RedactionLevel
+	// originals maps pseudonyms to the values they replace and pseudonyms
+	// the reverse.
+	originals  map[string]string
+	pseudonyms map[string]string
+	// secrets holds values learned by Seed that are dropped from free text.
+	secrets map[string]bool
+}
+
+// NewRedactor creates a redactor for level. An unknown level redacts as
+// much as RedactionPseudonymize.
+func NewRedactor(level RedactionLevel) *Redactor {
+	if _, ok := ParseRedactionLevel(string(level)); !ok {
+		level = RedactionPseudonymize
+	}
+	return &Redactor{
+		level:      level,
+		originals:  make(map[string]string),
+		pseudonyms: make(map[string]string),
+		secrets:    make(map[string]bool),
+	}
+}
+
+// Seed teaches r the values of a server configuration, keyed by ssh_config
+// keyword, that a prompt was rendered from. Redact then also finds its hosts,
+// users, SetEnv values and command arguments in free text, such as a prompt
+// template that prints them itself instead of as "Key: value" lines.
+func (r *Redactor) Seed(options map[string]string) {
+	if r.level == RedactionNone {
+		return
+	}
+	for key, value := range options {
+		value = strings.TrimSpace(value)
+		lower := strings.ToLower(key)
+		switch {
+		case redactHostOptions[lower], redactUserOptions[lower], redactJumpOptions[lower]:
+			r.redactOption(key, value)
+		case redactEnvOptions[lower]:
+			for _, field := range strings.Fields(value) {
+				if _, secret, ok := strings.Cut(strings.TrimSuffix(field, ","), "="); ok {
+					r.addSecret(secret)
+				}
+			}
+		case redactCmdOptions[lower]:
+			if fields := strings.Fields(value); len(fields) > 1 {
+				r.addSecret(strings.TrimSpace(strings.TrimPrefix(value, fields[0])))
+			}
+		}
+	}
+}
+
+func (r *Redactor) addSecret(value string) {
+	if len(value) >= minFreeTextLen {
+		r.secrets[value] = true
+	}
+}
+
+// Level returns the redaction level.
+func (r *Redactor) Level() RedactionLevel {
+	return r.level
+}
+
+// Redact returns text with secrets, and at RedactionPseudonymize host names
+// and users, removed. "Key: value" lines are redacted by option and "Host"
+// lines, which hold aliases, are kept. Everything else is treated as free
+// text, in which hosts and users named by those lines are pseudonymized too.
+func (r *Redactor) Redact(text string) string {
+	if r.level == RedactionNone {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	isOption := make([]bool, len(lines))
+
+	// Redact option lines first so free text anywhere in the prompt can use
+	// the pseudonyms they define.
+	for i, line := range lines {
+		m := optionLine.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+		case strings.EqualFold(m[2], "Host"):
+			// Aliases are the user's own labels and name servers in answers.
+			isOption[i] = true
+			lines[i] = scrubSecrets(line)
+		case isRedactedOption(m[2]):
+			isOption[i] = true
+			lines[i] = m[1] + m[2] + ": " + r.redactOption(m[2], m[3])
+		}
+	}
+
+	replacer := r.freeTextReplacer()
+	for i, line := range lines {
+		if !isOption[i] {
+			lines[i] = replacer(scrubSecrets(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Restore replaces the pseudonyms made by r with the values they stand for.
+func (r *Redactor) Restore(text string) string {
+	if len(r.originals) == 0 {
+		return text
+	}
+	return pseudonymPattern.ReplaceAllStringFunc(text, func(pseudonym string) string {
+		if original, ok := r.originals[pseudonym]; ok {
+			return original
+		}
+		return pseudonym
+	})
+}
+
+// NewStreamRestorer returns a restorer for an answer that arrives in pieces.
+func (r *Redactor) NewStreamRestorer() *StreamRestorer {
+	return &StreamRestorer{redactor: r}
+}
+
+// StreamRestorer restores pseudonyms in streamed text. It holds back the end
+// of the text received so far when it could be the start of a pseudonym that
+// continues in the next piece.
+type StreamRestorer struct {
+	redactor *Redactor
+	pending  string
+}
+
+// Write adds delta and returns the restored text that is safe to show.
+func (sr *StreamRestorer) Write(delta string) string {
+	if len(sr.redactor.originals) == 0 {
+		return delta
+	}
+	sr.pending += delta
+	cut := len(sr.pending) - (maxPseudonymLen - 1)
+	if cut <= 0 {
+		return ""
+	}
+	// Never cut through a complete pseudonym.
+	for _, m := range pseudonymPattern.FindAllStringIndex(sr.pending, -1) {
+		if m[0] < cut && cut < m[1] {
+			cut = m[1]
+		}
+	}
+	for cut < len(sr.pending) && !utf8.RuneStart(sr.pending[cut]) {
+		cut--
+	}
+	out := sr.redactor.Restore(sr.pending[:cut])
+	sr.pending = sr.pending[cut:]
+	return out
+}
+
+// Flush returns the restored text still held back.
+func (sr *StreamRestorer) Flush() string {
+	out := sr.redactor.Restore(sr.pending)
+	sr.pending = ""
+	return out
+}
+
+func isRedactedOption(key string) bool {
+	key = strings.ToLower(key)
+	return redactHostOptions[key] || redactUserOptions[key] || redactJumpOptions[key] ||
+		redactEnvOptions[key] || redactCmdOptions[key] || redactPathOptions[key]
+}
+
+// redactOption redacts the value of the option key.
+func (r *Redactor) redactOption(key, value string) string {
+	key = strings.ToLower(key)
+	switch {
+	case redactHostOptions[key]:
+		return r.pseudonym("host", value)
+	case redactUserOptions[key]:
+		return r.pseudonym("user", value)
+	case redactJumpOptions[key]:
+		return r.redactJumps(value)
+	case redactEnvOptions[key]:
+		return redactEnv(value)
+	case redactCmdOptions[key]:
+		return redactCommand(value)
+	case redactPathOptions[key]:
+		return scrubSecrets(homeDir.ReplaceAllString(value, "$1~$2"))
+	}
+	return value
+}
+
+// pseudonym returns the pseudonym of value at RedactionPseudonymize and value
+// itself otherwise.
+func (r *Redactor) pseudonym(kind, value string) string {
+	if r.level != RedactionPseudonymize || value == "" || strings.EqualFold(value, "none") {
+		return value
+	}
+	if pseudonym, ok := r.pseudonyms[value]; ok {
+		return pseudonym
+	}
+	sum := sha256.Sum256([]byte(kind + ":" + value))
+	pseudonym := kind + "-" + hex.EncodeToString(sum[:])[:pseudonymHashLen]
+	r.pseudonyms[value] = pseudonym
+	r.originals[pseudonym] = value
+	return pseudonym
+}
+
+// redactJumps pseudonymizes the user and host of each [user@]host[:port]
+// hop of a ProxyJump list.
+func (r *Redactor) redactJumps(value string) string {
+	hops := strings.Split(value, ",")
+	for i, hop := range hops {
+		hop = strings.TrimSpace(hop)
+		scheme := ""
+		if rest, ok := strings.CutPrefix(hop, "ssh://"); ok {
+			scheme, hop = "ssh://", rest
+		}
+		user := ""
+		if at := strings.LastIndex(hop, "@"); at >= 0 {
+			user, hop = r.pseudonym("user", hop[:at])+"@", hop[at+1:]
+		}
+		port := ""
+		if colon := strings.LastIndex(hop, ":"); colon >= 0 && !strings.Contains(hop, "]") {
+			hop, port = hop[:colon], hop[colon:]
+		}
+		hops[i] = scheme + user + r.pseudonym("host", hop) + port
+	}
+	return strings.Join(hops, ", ")
+}
+
+// redactEnv keeps the names of SetEnv variables and drops their values.
+func redactEnv(value string) string {
+	fields := strings.Fields(value)
+	for i, field := range fields {
+		name, _, _ := strings.Cut(strings.TrimSuffix(field, ","), "=")
+		fields[i] = name + "=" + redactedValue
+		if strings.HasSuffix(field, ",") {
+			fields[i] += ","
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// redactCommand keeps the program of a command line and drops its arguments,
+// which may name hosts or carry credentials.
+func redactCommand(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || strings.EqualFold(value, "none") {
+		return value
+	}
+	if len(fields) == 1 {
+		return scrubSecrets(homeDir.ReplaceAllString(value, "$1~$2"))
+	}
+	return homeDir.ReplaceAllString(fields[0], "$1~$2") + " " + redactedValue
+}
+
+// scrubSecrets removes tokens, passwords and URL credentials from text.
+func scrubSecrets(text string) string {
+	text = secretAssignment.ReplaceAllString(text, "${1}${2}"+redactedValue)
+	text = bearerToken.ReplaceAllString(text, "${1} "+redactedValue)
+	text = knownToken.ReplaceAllString(text, redactedValue)
+	return urlCredentials.ReplaceAllString(text, "${1}"+redactedValue+"@")
+}
+
+// freeTextReplacer returns a function that replaces the hosts and users
+// pseudonymized so far, and the secrets learned by Seed, wherever they appear
+// as whole words.
+func (r *Redactor) freeTextReplacer() func(string) string {
+	var values []string
+	for value := range r.pseudonyms {
+		if len(value) >= minFreeTextLen {
+			values = append(values, value)
+		}
+	}
+	for value := range r.secrets {
+		if _, ok := r.pseudonyms[value]; !ok {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return func(s string) string { return s }
+	}
+	// Longest first, so a host is not replaced by a user it contains.
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = regexp.QuoteMeta(value)
+	}
+	pattern := regexp.MustCompile(strings.Join(quoted, "|"))
+
+	return func(s string) string {
+		var b strings.Builder
+		last := 0
+		for _, m := range pattern.FindAllStringIndex(s, -1) {
+			if !isWordBoundary(s, m[0]-1) || !isWordBoundary(s, m[1]) {
+				continue
+			}
+			b.WriteString(s[last:m[0]])
+			if pseudonym, ok := r.pseudonyms[s[m[0]:m[1]]]; ok {
+				b.WriteString(pseudonym)
+			} else {
+				b.WriteString(redactedValue)
+			}
+			last = m[1]
+		}
+		b.WriteString(s[last:])
+		return b.String()
+	}
+}
+
+// isWordBoundary reports whether s[i] is outside s or not part of a host
+// name or user.
+func isWordBoundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+	c := s[i]
+	return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.' && i+1 < len(s) && s[i+1] != ' ')
+}
@@ -0,0 +1,105 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"fmt"
+	"time"
+)
+
+// UsageDayLayout formats the day of a UsageRecord.
+const UsageDayLayout = "2006-01-02"
+
+// TokenUsage counts the tokens sent to and generated by a provider.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	Requests         int `json:"requests"`
+}
+
+// Total returns the prompt and completion tokens together.
+func (u TokenUsage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add adds other to u.
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.Requests += other.Requests
+}
+
+// UsageRecord is the token usage of one provider, model and request type on
+// one day (in local time, formatted with UsageDayLayout).
+type UsageRecord struct {
+	Day      string        `json:"day"`
+	Provider AIProvider    `json:"provider"`
+	Model    string        `json:"model"`
+	Type     AIRequestType `json:"type"`
+	TokenUsage
+}
+
+// UsageDay returns the day t falls on, as recorded in a UsageRecord.
+func UsageDay(t time.Time) string {
+	return t.Format(UsageDayLayout)
+}
+
+// BudgetAction is what happens to requests once a token budget is used up.
+type BudgetAction string
+
+const (
+	// BudgetWarn logs a warning and lets requests through (the default).
+	BudgetWarn BudgetAction = "warn"
+	// BudgetRefuse refuses requests until the period ends.
+	BudgetRefuse BudgetAction = "refuse"
+)
+
+// TokenBudget caps the tokens a provider may use per day and per calendar
+// month, in local time. 0 means no limit.
+type TokenBudget struct {
+	Daily   int          `json:"daily,omitempty" yaml:"daily,omitempty"`
+	Monthly int          `json:"monthly,omitempty" yaml:"monthly,omitempty"`
+	Action  BudgetAction `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
+// Refuses reports whether requests over the budget are refused.
+func (b TokenBudget) Refuses() bool {
+	return b.Action == BudgetRefuse
+}
+
+// Exceeded returns a description of the limit that daily and monthly usage
+// reach, or "" when the budget is not used up.
+func (b TokenBudget) Exceeded(daily, monthly TokenUsage) string {
+	if b.Daily > 0 && daily.Total() >= b.Daily {
+		return fmt.Sprintf("daily token budget used up (%d of %d)", daily.Total(), b.Daily)
+	}
+	if b.Monthly > 0 && monthly.Total() >= b.Monthly {
+		return fmt.Sprintf("monthly token budget used up (%d of %d)", monthly.Total(), b.Monthly)
+	}
+	return ""
+}
+
+// validate checks the budget's limits and action.
+func (b TokenBudget) validate() string {
+	if b.Daily < 0 || b.Monthly < 0 {
+		return "limits must not be negative"
+	}
+	switch b.Action {
+	case "", BudgetWarn, BudgetRefuse:
+		return ""
+	default:
+		return fmt.Sprintf("action must be %s or %s, not %q", BudgetWarn, BudgetRefuse, b.Action)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import "testing"
+
+func TestTokenBudget_Exceeded(t *testing.T) {
+	budget := TokenBudget{Daily: 100, Monthly: 1000}
+	tests := []struct {
+		name           string
+		daily, monthly int
+		want           string
+	}{
+		{"under both", 99, 500, ""},
+		{"daily reached", 100, 500, "daily token budget used up (100 of 100)"},
+		{"monthly reached", 10, 1200, "monthly token budget used up (1200 of 1000)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := budget.Exceeded(TokenUsage{PromptTokens: tt.daily}, TokenUsage{CompletionTokens: tt.monthly})
+			if got != tt.want {
+				t.Errorf("Exceeded() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := (TokenBudget{}).Exceeded(TokenUsage{PromptTokens: 1 << 30}, TokenUsage{}); got != "" {
+		t.Errorf("Expected an empty budget to have no limit, got %q", got)
+	}
+}
+
+func TestTokenUsage_Add(t *testing.T) {
+	usage := TokenUsage{PromptTokens: 10, CompletionTokens: 5, Requests: 1}
+	usage.Add(TokenUsage{PromptTokens: 3, CompletionTokens: 2, Requests: 1})
+	if usage.Total() != 20 || usage.Requests != 2 {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import "strings"
+
+// BlockKind identifies the kind of SSH config block an option was read from.
+type BlockKind string
+
+const (
+	// BlockGlobal is the section before the first Host or Match line of a file.
+	BlockGlobal BlockKind = "global"
+	// BlockHost is a Host block.
+	BlockHost BlockKind = "host"
+	// BlockMatch is a Match block.
+	BlockMatch BlockKind = "match"
+)
+
+// ConfigOption is a single keyword/value pair as written in an SSH config block.
+type ConfigOption struct {
+	Key   string
+	Value string
+}
+
+// Profile is a wildcard Host block such as "Host *" or "Host *.prod". Its
+// options are inherited by every server whose alias matches the patterns.
+type Profile struct {
+	Patterns   []string
+	Options    []ConfigOption
+	SourceFile string
+}
+
+// Name returns the Host line patterns, e.g. "*.prod !bastion.prod".
+func (p Profile) Name() string {
+	return strings.Join(p.Patterns, " ")
+}
+
+// EffectiveOption is an option value that applies to a server together with
+// the block it came from.
+type EffectiveOption struct {
+	Key        string
+	Value      string
+	Block      string // e.g. "Host web", "Host *.prod", "Match user root"
+	Kind       BlockKind
+	SourceFile string
+	// Inherited is true when the value comes from any block other than the
+	// server's own Host block.
+	Inherited bool
+}
+
+// EffectiveConfig is the merged view of every block that applies to a server,
+// resolved in OpenSSH first-match-wins order.
+type EffectiveConfig struct {
+	Alias   string
+	Options []EffectiveOption
+	// Unresolved lists Match blocks whose criteria (exec, localnetwork, ...)
+	// cannot be evaluated without connecting and were therefore skipped.
+	Unresolved []string
+}
+
+// Get returns the first effective option for key (case-insensitive).
+func (c EffectiveConfig) Get(key string) (EffectiveOption, bool) {
+	for _, opt := range c.Options {
+		if strings.EqualFold(opt.Key, key) {
+			return opt, true
+		}
+	}
+	return EffectiveOption{}, false
+}
+
+// Inherited returns the options that come from wildcard Host, Match or global blocks.
+func (c EffectiveConfig) Inherited() []EffectiveOption {
+	var out []EffectiveOption
+	for _, opt := range c.Options {
+		if opt.Inherited {
+			out = append(out, opt)
+		}
+	}
+	return out
+}
+
+// ResolvedOption is one line of `ssh -G` output. Keys are lowercase, as
+// printed by ssh.
+type ResolvedOption struct {
+	Key   string
+	Value string
+	// Default is true when ssh uses the same value without any config file.
+	Default bool
+}
+
+// ResolvedConfig is the configuration ssh itself computes for an alias.
+type ResolvedConfig struct {
+	Alias   string
+	Options []ResolvedOption
+}
+
+// Get returns the first value of key (case-insensitive).
+func (c ResolvedConfig) Get(key string) (string, bool) {
+	for _, opt := range c.Options {
+		if strings.EqualFold(opt.Key, key) {
+			return opt.Value, true
+		}
+	}
+	return "", false
+}
+
+// NonDefault returns the options that differ from ssh's built-in defaults.
+func (c ResolvedConfig) NonDefault() []ResolvedOption {
+	var out []ResolvedOption
+	for _, opt := range c.Options {
+		if !opt.Default {
+			out = append(out, opt)
+		}
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SSH config rule IDs. They are stable: policies and scripts refer to them.
+const (
+	RuleConfigPermissions      = "config-permissions"
+	RuleStrictHostKeyChecking  = "strict-host-key-checking"
+	RuleKnownHostsDiscarded    = "known-hosts-discarded"
+	RuleForwardAgent           = "forward-agent"
+	RuleWeakCiphers            = "weak-ciphers"
+	RuleWeakMACs               = "weak-macs"
+	RuleWeakKexAlgorithms      = "weak-kex-algorithms"
+	RulePasswordAuthentication = "password-authentication"
+	RuleLocalCommand           = "local-command"
+)
+
+// ConfigRule describes a check the SSH config linter runs.
+type ConfigRule struct {
+	ID       string
+	Title    string
+	Severity SecurityEventSeverity // Default severity; SecurityPolicy.ConfigRules can override it
+}
+
+// configRules lists the linter's rules in report order.
+var configRules = []ConfigRule{
+	{RuleConfigPermissions, "SSH config file is readable or writable by other users", SeverityError},
+	{RuleStrictHostKeyChecking, "StrictHostKeyChecking disables host key verification", SeverityError},
+	{RuleKnownHostsDiscarded, "UserKnownHostsFile /dev/null discards host keys", SeverityError},
+	{RuleForwardAgent, "ForwardAgent to a host outside the allowed hosts", SeverityWarning},
+	{RuleWeakCiphers, "Ciphers enables weak algorithms", SeverityWarning},
+	{RuleWeakMACs, "MACs enables weak algorithms", SeverityWarning},
+	{RuleWeakKexAlgorithms, "KexAlgorithms enables weak algorithms", SeverityWarning},
+	{RulePasswordAuthentication, "PasswordAuthentication is enabled", SeverityInfo},
+	{RuleLocalCommand, "PermitLocalCommand runs a LocalCommand on connect", SeverityWarning},
+}
+
+// ConfigRules returns the SSH config linter's rules with their default
+// severities.
+func ConfigRules() []ConfigRule {
+	return append([]ConfigRule(nil), configRules...)
+}
+
+// ConfigRuleSetting overrides a linter rule in the security policy.
+type ConfigRuleSetting struct {
+	Disabled bool                  `json:"disabled,omitempty" yaml:"disabled,omitempty"` // Skip the rule
+	Severity SecurityEventSeverity `json:"severity,omitempty" yaml:"severity,omitempty"` // Replaces the default severity
+}
+
+// ConfigFinding is a problem the SSH config linter found.
+type ConfigFinding struct {
+	RuleID         string                `json:"rule_id"`
+	Severity       SecurityEventSeverity `json:"severity"`
+	Host           string                `json:"host,omitempty"`   // Server alias; empty for the config file itself
+	Option         string                `json:"option,omitempty"` // SSH option, e.g. ForwardAgent
+	Value          string                `json:"value,omitempty"`
+	Message        string                `json:"message"`
+	Recommendation string                `json:"recommendation,omitempty"`
+}
+
+// ConfigRuleSeverity returns the severity of the rule with the given ID under
+// the policy, and false if the policy disables it.
+func (p *SecurityPolicy) ConfigRuleSeverity(id string) (SecurityEventSeverity, bool) {
+	severity := SeverityWarning
+	for _, rule := range configRules {
+		if rule.ID == id {
+			severity = rule.Severity
+			break
+		}
+	}
+	if setting, ok := p.ConfigRules[id]; ok {
+		if setting.Disabled {
+			return "", false
+		}
+		if setting.Severity != "" {
+			severity = setting.Severity
+		}
+	}
+	return severity, true
+}
+
+// ValidateConfigRules checks rule settings for unknown rule IDs and
+// severities.
+func ValidateConfigRules(settings map[string]ConfigRuleSetting) error {
+	ids := make([]string, 0, len(settings))
+	for id := range settings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		known := false
+		for _, rule := range configRules {
+			if rule.ID == id {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown rule %q", id)
+		}
+		switch settings[id].Severity {
+		case "", SeverityInfo, SeverityWarning, SeverityError, SeverityCritical:
+		default:
+			return fmt.Errorf("%s.severity must be info, warning, error or critical", id)
+		}
+	}
+	return nil
+}
+
+// SeverityRank orders severities from info (1) to critical (4); unknown
+// severities rank 0.
+func SeverityRank(severity SecurityEventSeverity) int {
+	switch severity {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityError:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// HighestSeverity returns the most severe severity among findings, or "" if
+// there are none.
+func HighestSeverity(findings []ConfigFinding) SecurityEventSeverity {
+	var highest SecurityEventSeverity
+	for _, f := range findings {
+		if SeverityRank(f.Severity) > SeverityRank(highest) {
+			highest = f.Severity
+		}
+	}
+	return highest
+}
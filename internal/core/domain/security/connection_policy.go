@@ -0,0 +1,41 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"strings"
+	"time"
+)
+
+// Values of SecurityPolicy.Enforcement.
+const (
+	EnforcementBlock = "block" // Refuse connections that violate the policy
+	EnforcementWarn  = "warn"  // Report violations and connect anyway
+)
+
+// ConnectionDecision is the result of checking a connection against the
+// security policy before ssh starts.
+type ConnectionDecision struct {
+	Alias       string        `json:"alias"`
+	Host        string        `json:"host"`                 // Destination resolved by ssh -G
+	Allowed     bool          `json:"allowed"`              // False if ssh must not be started
+	Violations  []string      `json:"violations,omitempty"` // Policy rules the connection breaks
+	MaxDuration time.Duration `json:"max_duration"`         // Session time limit; 0 means none
+}
+
+// Reason summarizes the violations in one line.
+func (d ConnectionDecision) Reason() string {
+	return strings.Join(d.Violations, "; ")
+}
@@ -0,0 +1,176 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// hostPatternKind is how a host list entry matches.
+type hostPatternKind int
+
+const (
+	hostPatternExact hostPatternKind = iota // Host name, compared case-insensitively
+	hostPatternGlob                         // *.corp.example.com, web-?
+	hostPatternRegex                        // /^web-[0-9]+$/
+	hostPatternCIDR                         // 10.0.0.0/8, or a single IP address
+)
+
+// HostPattern is one entry of SecurityPolicy.AllowedHosts or BlockedHosts.
+// A leading "!" negates the entry: hosts it matches are excluded from the
+// list even if another entry matches them.
+type HostPattern struct {
+	Raw     string // The entry as written in the policy
+	Negated bool
+
+	kind    hostPatternKind
+	value   string // Lower-cased name or glob
+	regex   *regexp.Regexp
+	network *net.IPNet
+}
+
+// ParseHostPattern parses a host list entry: a host name, a glob using *, ?
+// and [...], a regular expression between slashes, a CIDR block or an IP
+// address, optionally preceded by "!".
+func ParseHostPattern(entry string) (HostPattern, error) {
+	p := HostPattern{Raw: entry}
+	value := strings.TrimSpace(entry)
+	if rest, ok := strings.CutPrefix(value, "!"); ok {
+		p.Negated = true
+		value = strings.TrimSpace(rest)
+	}
+	if value == "" {
+		return p, fmt.Errorf("empty host pattern %q", entry)
+	}
+
+	switch {
+	case len(value) >= 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/"):
+		re, err := regexp.Compile("(?i)" + value[1:len(value)-1])
+		if err != nil {
+			return p, fmt.Errorf("invalid regular expression %q: %w", entry, err)
+		}
+		p.kind, p.regex = hostPatternRegex, re
+	case strings.Contains(value, "/"):
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return p, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		p.kind, p.network = hostPatternCIDR, network
+	case net.ParseIP(value) != nil:
+		ip := net.ParseIP(value)
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		p.kind, p.network = hostPatternCIDR, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	case strings.ContainsAny(value, "*?["):
+		if _, err := path.Match(value, ""); err != nil {
+			return p, fmt.Errorf("invalid glob %q: %w", entry, err)
+		}
+		p.kind, p.value = hostPatternGlob, strings.ToLower(value)
+	default:
+		p.kind, p.value = hostPatternExact, strings.ToLower(value)
+	}
+	return p, nil
+}
+
+// MatchesAddresses reports whether the pattern matches IP addresses rather
+// than host names.
+func (p HostPattern) MatchesAddresses() bool {
+	return p.kind == hostPatternCIDR
+}
+
+// Match reports whether host, or one of its addresses for CIDR and IP
+// entries, matches the pattern, ignoring negation.
+func (p HostPattern) Match(host string, addrs []net.IP) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	switch p.kind {
+	case hostPatternGlob:
+		matched, _ := path.Match(p.value, host)
+		return matched
+	case hostPatternRegex:
+		return p.regex.MatchString(host)
+	case hostPatternCIDR:
+		if ip := net.ParseIP(host); ip != nil && p.network.Contains(ip) {
+			return true
+		}
+		for _, ip := range addrs {
+			if p.network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	default:
+		return p.value == host
+	}
+}
+
+// HostList is a parsed AllowedHosts or BlockedHosts list.
+type HostList []HostPattern
+
+// ParseHostList parses every entry of a host list.
+func ParseHostList(entries []string) (HostList, error) {
+	list := make(HostList, 0, len(entries))
+	for _, entry := range entries {
+		p, err := ParseHostPattern(entry)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, nil
+}
+
+// ValidateHostList checks that every entry of a host list parses.
+func ValidateHostList(entries []string) error {
+	_, err := ParseHostList(entries)
+	return err
+}
+
+// NeedsAddresses reports whether the list has CIDR or IP entries, which
+// match host names only after they are resolved.
+func (l HostList) NeedsAddresses() bool {
+	for _, p := range l {
+		if p.MatchesAddresses() {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether host is in the list: some entry matches it and no
+// negated entry does. A list of only negated entries contains every host it
+// does not exclude. rule is the entry that decided, i.e. the negated entry
+// that excluded the host or the first entry that matched it; it is empty
+// otherwise.
+func (l HostList) Match(host string, addrs []net.IP) (rule string, matched bool) {
+	positive := false
+	for _, p := range l {
+		if p.Negated && p.Match(host, addrs) {
+			return p.Raw, false
+		}
+		positive = positive || !p.Negated
+	}
+	for _, p := range l {
+		if !p.Negated && p.Match(host, addrs) {
+			return p.Raw, true
+		}
+	}
+	return "", len(l) > 0 && !positive
+}
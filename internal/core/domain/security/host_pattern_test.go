@@ -0,0 +1,103 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"net"
+	"testing"
+)
+
+func TestHostPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		addrs   []string
+		want    bool
+	}{
+		{"web.example.com", "WEB.example.com", nil, true},
+		{"web.example.com", "web.example.com.", nil, true},
+		{"web.example.com", "db.example.com", nil, false},
+		{"*.corp.example.com", "build.corp.example.com", nil, true},
+		{"*.corp.example.com", "corp.example.com", nil, false},
+		{"web-?", "web-1", nil, true},
+		{"/^web-[0-9]+$/", "WEB-12", nil, true},
+		{"/^web-[0-9]+$/", "web-a", nil, false},
+		{"10.0.0.0/8", "10.1.2.3", nil, true},
+		{"10.0.0.0/8", "build.corp", []string{"192.168.0.1", "10.9.9.9"}, true},
+		{"10.0.0.0/8", "build.corp", []string{"192.168.0.1"}, false},
+		{"10.0.0.5", "10.0.0.5", nil, true},
+		{"10.0.0.5", "db", []string{"10.0.0.5"}, true},
+		{"2001:db8::/32", "2001:db8::1", nil, true},
+		{"!*.example.com", "web.example.com", nil, true}, // Negation is applied by HostList
+	}
+
+	for _, tt := range tests {
+		p, err := ParseHostPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("ParseHostPattern(%q) error: %v", tt.pattern, err)
+		}
+		var addrs []net.IP
+		for _, a := range tt.addrs {
+			addrs = append(addrs, net.ParseIP(a))
+		}
+		if got := p.Match(tt.host, addrs); got != tt.want {
+			t.Errorf("%q.Match(%q, %v) = %v, want %v", tt.pattern, tt.host, tt.addrs, got, tt.want)
+		}
+	}
+}
+
+func TestParseHostPattern_Invalid(t *testing.T) {
+	for _, entry := range []string{"", "!", "/web-(/", "10.0.0.0/33", "web[", "a/b"} {
+		if _, err := ParseHostPattern(entry); err == nil {
+			t.Errorf("ParseHostPattern(%q) succeeded, want an error", entry)
+		}
+	}
+}
+
+func TestHostList_Match(t *testing.T) {
+	list, err := ParseHostList([]string{"*.corp.example.com", "!bastion.corp.example.com", "10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !list.NeedsAddresses() {
+		t.Error("NeedsAddresses() = false for a list with a CIDR")
+	}
+
+	tests := []struct {
+		host     string
+		addrs    []net.IP
+		wantRule string
+		want     bool
+	}{
+		{"build.corp.example.com", nil, "*.corp.example.com", true},
+		{"bastion.corp.example.com", nil, "!bastion.corp.example.com", false},
+		{"db.internal", []net.IP{net.ParseIP("10.2.3.4")}, "10.0.0.0/8", true},
+		{"example.org", nil, "", false},
+	}
+	for _, tt := range tests {
+		rule, matched := list.Match(tt.host, tt.addrs)
+		if rule != tt.wantRule || matched != tt.want {
+			t.Errorf("Match(%q) = %q, %v; want %q, %v", tt.host, rule, matched, tt.wantRule, tt.want)
+		}
+	}
+
+	onlyNegated, _ := ParseHostList([]string{"!legacy.example.com"})
+	if _, matched := onlyNegated.Match("web.example.com", nil); !matched {
+		t.Error("a list of negations should contain hosts it does not exclude")
+	}
+	if _, matched := HostList(nil).Match("web.example.com", nil); matched {
+		t.Error("an empty list should contain no hosts")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import "time"
+
+// KeyAuditEntry is a key file found by a key inventory scan: a private key in
+// ~/.ssh or a file referenced by a server's IdentityFile.
+type KeyAuditEntry struct {
+	Path       string               `json:"path"`                 // As displayed, e.g. ~/.ssh/id_ed25519
+	Servers    []string             `json:"servers,omitempty"`    // Aliases of the servers using the key
+	Mode       string               `json:"mode,omitempty"`       // File permissions, e.g. 0600
+	Modified   time.Time            `json:"modified,omitempty"`   // File modification time
+	Validation *KeyValidationResult `json:"validation,omitempty"` // Policy check of the key
+	Error      string               `json:"error,omitempty"`      // Why the file could not be checked
+}
+
+// HasIssues reports whether the key could not be checked or fails the
+// security policy.
+func (e *KeyAuditEntry) HasIssues() bool {
+	return e.Error != "" || (e.Validation != nil && !e.Validation.IsValid)
+}
@@ -15,7 +15,7 @@
 package security
 
 import (
-	"crypto/rsa"
+	"crypto"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -34,12 +34,15 @@ type KeyValidationResult struct {
 
 // KeyInfo contains information about a validated key
 type KeyInfo struct {
-	Type        string    `json:"type"`        // rsa, ed25519, ecdsa
-	Size        int       `json:"size"`        // Key size in bits
-	Fingerprint string    `json:"fingerprint"` // Key fingerprint
+	Type        string    `json:"type"`        // rsa, dsa, ecdsa, ed25519, ecdsa-sk, ed25519-sk
+	Size        int       `json:"size"`        // Key size in bits, 0 if unknown
+	Fingerprint string    `json:"fingerprint"` // SHA256 fingerprint, as printed by ssh-keygen -l
 	Created     time.Time `json:"created,omitempty"`
-	Expires     time.Time `json:"expires,omitempty"`
+	Expires     time.Time `json:"expires,omitempty"` // Certificates only
 	Comment     string    `json:"comment,omitempty"`
+	Private     bool      `json:"private"`          // Private key rather than public key
+	Encrypted   bool      `json:"encrypted"`        // Protected by a passphrase
+	Cipher      string    `json:"cipher,omitempty"` // Cipher of an encrypted key
 }
 
 // KeyValidator provides key validation functionality
@@ -80,78 +83,124 @@ func (kv *KeyValidator) ValidateKey(keyData string) *KeyValidationResult {
 			keyInfo.Type, strings.Join(kv.policy.AllowedKeyTypes, ", ")))
 	}
 
-	// Validate key size
-	if keyInfo.Size < kv.policy.MinKeySize {
-		result.IsValid = false
-		result.Issues = append(result.Issues, fmt.Sprintf("Key size %d bits is below minimum required size of %d bits",
-			keyInfo.Size, kv.policy.MinKeySize))
+	// Validate key size. The minimum applies to RSA and DSA keys: elliptic
+	// curve keys are much shorter for the same strength.
+	if keyInfo.Type == KeyTypeRSA || keyInfo.Type == KeyTypeDSA {
+		switch {
+		case keyInfo.Size == 0:
+			result.Warnings = append(result.Warnings, "Key size could not be determined because the key is encrypted")
+		case keyInfo.Size < kv.policy.MinKeySize:
+			result.IsValid = false
+			result.Issues = append(result.Issues, fmt.Sprintf("Key size %d bits is below minimum required size of %d bits",
+				keyInfo.Size, kv.policy.MinKeySize))
+		}
 	}
 
 	// Check for weak key sizes
-	const rsaKeyType = "rsa"
-	if keyInfo.Type == rsaKeyType && keyInfo.Size < 3072 {
+	if keyInfo.Type == KeyTypeRSA && keyInfo.Size > 0 && keyInfo.Size < 3072 {
 		result.Warnings = append(result.Warnings, "RSA key size is below recommended 3072 bits for new keys")
 		result.Recommendations = append(result.Recommendations, "Consider using a 3072-bit or larger RSA key, or switch to Ed25519")
 	}
 
 	// Check key expiry
-	if !keyInfo.Expires.IsZero() && time.Until(keyInfo.Expires) < kv.policy.KeyExpiryWarning {
+	if !keyInfo.Expires.IsZero() && time.Now().After(keyInfo.Expires) {
+		result.IsValid = false
+		result.Issues = append(result.Issues, fmt.Sprintf("Key expired on %s", keyInfo.Expires.Format(time.RFC3339)))
+	} else if !keyInfo.Expires.IsZero() && time.Until(keyInfo.Expires) < kv.policy.KeyExpiryWarning {
 		result.Warnings = append(result.Warnings, fmt.Sprintf("Key expires in %v", time.Until(keyInfo.Expires)))
 		result.Recommendations = append(result.Recommendations, "Consider renewing the key before it expires")
 	}
 
+	// Private keys should be protected by a passphrase
+	if keyInfo.Private && !keyInfo.Encrypted {
+		result.Warnings = append(result.Warnings, "Private key is not protected by a passphrase")
+		result.Recommendations = append(result.Recommendations, "Add a passphrase with ssh-keygen -p, or use a hardware-backed (-sk) key")
+	}
+
 	// Add general recommendations
-	if keyInfo.Type == rsaKeyType {
+	if keyInfo.Type == KeyTypeRSA {
 		result.Recommendations = append(result.Recommendations, "Consider using Ed25519 keys for better security and performance")
 	}
 
 	return result
 }
 
-// parseKey parses an SSH key and extracts information
+// parseKey parses an SSH key and extracts information. It accepts public key
+// lines (authorized_keys or .pub format) and PEM-encoded keys: OpenSSH,
+// PKCS#1, PKCS#8, SEC 1 and PKIX.
 func (kv *KeyValidator) parseKey(keyData string) (*KeyInfo, error) {
 	// Remove any whitespace and newlines
 	keyData = strings.TrimSpace(keyData)
 
+	if !strings.HasPrefix(keyData, "-----BEGIN") {
+		return parseAuthorizedKey(firstKeyLine(keyData))
+	}
+
 	// Parse PEM block
 	block, _ := pem.Decode([]byte(keyData))
 	if block == nil {
 		return nil, fmt.Errorf("failed to decode PEM block")
 	}
 
-	keyInfo := &KeyInfo{}
+	// Legacy PEM encryption hides everything but the key type.
+	if strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED") {
+		keyType, ok := pemKeyTypes[block.Type]
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type: %s", block.Type)
+		}
+		cipher, _, _ := strings.Cut(block.Headers["DEK-Info"], ",")
+		return &KeyInfo{Type: keyType, Private: true, Encrypted: true, Cipher: cipher}, nil
+	}
 
-	// Determine key type and size based on PEM type
+	var keyInfo *KeyInfo
+	var err error
 	switch block.Type {
-	case "RSA PRIVATE KEY", "RSA PUBLIC KEY":
-		keyInfo.Type = "rsa"
-		if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
-			keyInfo.Size = key.N.BitLen()
-		} else if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
-			keyInfo.Size = key.N.BitLen()
-		} else {
+	case "OPENSSH PRIVATE KEY":
+		keyInfo, err = parseOpenSSHPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, parseErr := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse RSA key")
+		}
+		keyInfo, err = publicKeyInfo(&key.PublicKey)
+	case "RSA PUBLIC KEY":
+		key, parseErr := x509.ParsePKCS1PublicKey(block.Bytes)
+		if parseErr != nil {
 			return nil, fmt.Errorf("failed to parse RSA key")
 		}
-	case "PRIVATE KEY", "PUBLIC KEY":
-		// Try to parse as generic private key
-		if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
-			if rsaKey, ok := key.(*rsa.PrivateKey); ok {
-				keyInfo.Type = "rsa"
-				keyInfo.Size = rsaKey.N.BitLen()
-			} else {
-				keyInfo.Type = "unknown"
-			}
-		} else {
+		keyInfo, err = publicKeyInfo(key)
+	case "EC PRIVATE KEY":
+		key, parseErr := x509.ParseECPrivateKey(block.Bytes)
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse EC private key")
+		}
+		keyInfo, err = publicKeyInfo(&key.PublicKey)
+	case "PRIVATE KEY":
+		key, parseErr := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if parseErr != nil {
 			return nil, fmt.Errorf("failed to parse private key")
 		}
-	case "OPENSSH PRIVATE KEY":
-		// This is more complex and would require additional parsing
-		// For now, we'll assume it's a valid OpenSSH key
-		keyInfo.Type = "openssh"
-		keyInfo.Size = 0 // Would need to parse the key to get size
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		keyInfo, err = publicKeyInfo(signer.Public())
+	case "PUBLIC KEY":
+		key, parseErr := x509.ParsePKIXPublicKey(block.Bytes)
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse public key")
+		}
+		keyInfo, err = publicKeyInfo(key)
+	case "ENCRYPTED PRIVATE KEY":
+		// PKCS#8 encryption hides the key type as well.
+		keyInfo = &KeyInfo{Type: "unknown", Encrypted: true}
 	default:
 		return nil, fmt.Errorf("unsupported key type: %s", block.Type)
 	}
+	if err != nil {
+		return nil, err
+	}
+	keyInfo.Private = strings.HasSuffix(block.Type, "PRIVATE KEY")
 
 	// Extract comment if present
 	if comment := strings.TrimSpace(block.Headers["Comment"]); comment != "" {
@@ -161,6 +210,24 @@ func (kv *KeyValidator) parseKey(keyData string) (*KeyInfo, error) {
 	return keyInfo, nil
 }
 
+// pemKeyTypes maps the types of legacy PEM private key blocks to key types.
+var pemKeyTypes = map[string]string{
+	"RSA PRIVATE KEY": KeyTypeRSA,
+	"DSA PRIVATE KEY": KeyTypeDSA,
+	"EC PRIVATE KEY":  KeyTypeECDSA,
+}
+
+// firstKeyLine returns the first line of data that is neither blank nor a
+// comment, so authorized_keys contents can be pasted.
+func firstKeyLine(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
+
 // isAllowedKeyType checks if the key type is allowed by the policy
 func (kv *KeyValidator) isAllowedKeyType(keyType string) bool {
 	for _, allowedType := range kv.policy.AllowedKeyTypes {
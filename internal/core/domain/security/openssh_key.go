@@ -0,0 +1,469 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Key types as reported in KeyInfo.Type and listed in
+// SecurityPolicy.AllowedKeyTypes.
+const (
+	KeyTypeRSA       = "rsa"
+	KeyTypeDSA       = "dsa"
+	KeyTypeECDSA     = "ecdsa"
+	KeyTypeEd25519   = "ed25519"
+	KeyTypeECDSASK   = "ecdsa-sk"
+	KeyTypeEd25519SK = "ed25519-sk"
+)
+
+const (
+	// openSSHKeyMagic starts the openssh-key-v1 private key container.
+	openSSHKeyMagic = "openssh-key-v1\x00"
+	// openSSHCertSuffix ends the algorithm names of OpenSSH certificates.
+	openSSHCertSuffix = "-cert-v01@openssh.com"
+)
+
+// sshKeyAlgorithms maps the SSH algorithm names of public keys to key types.
+var sshKeyAlgorithms = map[string]string{
+	"ssh-rsa":                            KeyTypeRSA,
+	"ssh-dss":                            KeyTypeDSA,
+	"ecdsa-sha2-nistp256":                KeyTypeECDSA,
+	"ecdsa-sha2-nistp384":                KeyTypeECDSA,
+	"ecdsa-sha2-nistp521":                KeyTypeECDSA,
+	"ssh-ed25519":                        KeyTypeEd25519,
+	"sk-ecdsa-sha2-nistp256@openssh.com": KeyTypeECDSASK,
+	"sk-ssh-ed25519@openssh.com":         KeyTypeEd25519SK,
+}
+
+// ecdsaCurveBits maps the curve names of ECDSA keys to their size.
+var ecdsaCurveBits = map[string]int{
+	"nistp256": 256,
+	"nistp384": 384,
+	"nistp521": 521,
+}
+
+// errShortKeyData is returned when key data ends before a field.
+var errShortKeyData = errors.New("key data is truncated")
+
+// sshReader reads the fields of the SSH wire format (RFC 4251).
+type sshReader struct {
+	data []byte
+}
+
+func (r *sshReader) uint32() (uint32, error) {
+	if len(r.data) < 4 {
+		return 0, errShortKeyData
+	}
+	v := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return v, nil
+}
+
+func (r *sshReader) uint64() (uint64, error) {
+	if len(r.data) < 8 {
+		return 0, errShortKeyData
+	}
+	v := binary.BigEndian.Uint64(r.data)
+	r.data = r.data[8:]
+	return v, nil
+}
+
+func (r *sshReader) byte() (byte, error) {
+	if len(r.data) < 1 {
+		return 0, errShortKeyData
+	}
+	v := r.data[0]
+	r.data = r.data[1:]
+	return v, nil
+}
+
+func (r *sshReader) bytes() ([]byte, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(n) > uint64(len(r.data)) {
+		return nil, errShortKeyData
+	}
+	v := r.data[:n]
+	r.data = r.data[n:]
+	return v, nil
+}
+
+func (r *sshReader) string() (string, error) {
+	v, err := r.bytes()
+	return string(v), err
+}
+
+// skip reads and discards n strings (or mpints, which are encoded alike).
+func (r *sshReader) skip(n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := r.bytes(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mpintBits returns the size in bits of an mpint.
+func mpintBits(v []byte) int {
+	return new(big.Int).SetBytes(v).BitLen()
+}
+
+// Fingerprint returns the SHA256 fingerprint of a public key in the SSH wire
+// format, as printed by ssh-keygen -l.
+func Fingerprint(blob []byte) string {
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// parsePublicKeyBlob returns the type, size, fingerprint and, for
+// certificates, validity period of a public key in the SSH wire format.
+func parsePublicKeyBlob(blob []byte) (*KeyInfo, error) {
+	r := &sshReader{data: blob}
+	algorithm, err := r.string()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &KeyInfo{Fingerprint: Fingerprint(blob)}
+	base, isCert := strings.CutSuffix(algorithm, openSSHCertSuffix)
+	keyType, ok := sshKeyAlgorithms[base]
+	if !ok {
+		return nil, fmt.Errorf("unsupported key algorithm: %s", algorithm)
+	}
+	info.Type = keyType
+	if isCert {
+		if _, err := r.bytes(); err != nil { // nonce
+			return nil, err
+		}
+	}
+
+	switch base {
+	case "ssh-rsa":
+		if _, err := r.bytes(); err != nil { // e
+			return nil, err
+		}
+		n, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		info.Size = mpintBits(n)
+	case "ssh-dss":
+		p, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		info.Size = mpintBits(p)
+		if err := r.skip(3); err != nil { // q, g, y
+			return nil, err
+		}
+	case "ssh-ed25519", "sk-ssh-ed25519@openssh.com":
+		pub, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if len(pub) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key length %d", len(pub))
+		}
+		info.Size = 256
+		if base != "ssh-ed25519" {
+			if err := r.skip(1); err != nil { // application
+				return nil, err
+			}
+		}
+	default: // ECDSA
+		curve, err := r.string()
+		if err != nil {
+			return nil, err
+		}
+		bits, ok := ecdsaCurveBits[curve]
+		if !ok || !strings.Contains(base, curve) {
+			return nil, fmt.Errorf("unsupported ECDSA curve: %s", curve)
+		}
+		info.Size = bits
+		if err := r.skip(1); err != nil { // Q
+			return nil, err
+		}
+		if strings.HasPrefix(base, "sk-") {
+			if err := r.skip(1); err != nil { // application
+				return nil, err
+			}
+		}
+	}
+
+	if isCert {
+		if err := parseCertificateValidity(r, info); err != nil {
+			return nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+	}
+	return info, nil
+}
+
+// parseCertificateValidity reads the fields of a certificate that follow the
+// key and sets info's validity period.
+func parseCertificateValidity(r *sshReader, info *KeyInfo) error {
+	if _, err := r.uint64(); err != nil { // serial
+		return err
+	}
+	if _, err := r.uint32(); err != nil { // type
+		return err
+	}
+	if err := r.skip(2); err != nil { // key id, valid principals
+		return err
+	}
+	validAfter, err := r.uint64()
+	if err != nil {
+		return err
+	}
+	validBefore, err := r.uint64()
+	if err != nil {
+		return err
+	}
+	if validAfter > 0 && validAfter <= math.MaxInt64 {
+		info.Created = time.Unix(int64(validAfter), 0)
+	}
+	if validBefore < math.MaxInt64 {
+		info.Expires = time.Unix(int64(validBefore), 0)
+	}
+	return nil
+}
+
+// parseAuthorizedKey parses a public key line as found in authorized_keys or
+// a .pub file: optional options, the algorithm, the base64 key and an
+// optional comment.
+func parseAuthorizedKey(line string) (*KeyInfo, error) {
+	fields := strings.Fields(line)
+	if len(fields) > 0 && !isKeyAlgorithm(fields[0]) {
+		// Skip the options, which may contain quoted spaces.
+		fields = strings.Fields(skipKeyOptions(line))
+	}
+	if len(fields) < 2 || !isKeyAlgorithm(fields[0]) {
+		return nil, fmt.Errorf("not an SSH public key line")
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 key data: %w", err)
+	}
+	info, err := parsePublicKeyBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	if algorithm, _ := (&sshReader{data: blob}).string(); algorithm != fields[0] {
+		return nil, fmt.Errorf("key algorithm %s does not match the line's %s", algorithm, fields[0])
+	}
+	info.Comment = strings.Join(fields[2:], " ")
+	return info, nil
+}
+
+// isKeyAlgorithm reports whether name is a supported public key or
+// certificate algorithm.
+func isKeyAlgorithm(name string) bool {
+	_, ok := sshKeyAlgorithms[strings.TrimSuffix(name, openSSHCertSuffix)]
+	return ok
+}
+
+// skipKeyOptions returns line after its leading authorized_keys options.
+func skipKeyOptions(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && quoted:
+			i++ // escaped character
+		case c == '"':
+			quoted = !quoted
+		case (c == ' ' || c == '\t') && !quoted:
+			return line[i:]
+		}
+	}
+	return ""
+}
+
+// parseOpenSSHPrivateKey parses the openssh-key-v1 container of an "OPENSSH
+// PRIVATE KEY" PEM block. The comment is only readable when the key is not
+// encrypted.
+func parseOpenSSHPrivateKey(data []byte) (*KeyInfo, error) {
+	if !bytes.HasPrefix(data, []byte(openSSHKeyMagic)) {
+		return nil, fmt.Errorf("not an openssh-key-v1 key")
+	}
+	r := &sshReader{data: data[len(openSSHKeyMagic):]}
+	cipher, err := r.string()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.skip(2); err != nil { // KDF name and options
+		return nil, err
+	}
+	count, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if count != 1 {
+		return nil, fmt.Errorf("unsupported number of keys: %d", count)
+	}
+	publicKey, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	info, err := parsePublicKeyBlob(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	info.Private = true
+
+	if cipher != "none" {
+		info.Encrypted = true
+		info.Cipher = cipher
+		return info, nil
+	}
+
+	private, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	comment, err := openSSHPrivateComment(&sshReader{data: private})
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key section: %w", err)
+	}
+	info.Comment = comment
+	return info, nil
+}
+
+// openSSHPrivateComment reads the comment from an unencrypted private
+// section, skipping the key's private fields.
+func openSSHPrivateComment(r *sshReader) (string, error) {
+	check1, err := r.uint32()
+	if err != nil {
+		return "", err
+	}
+	check2, err := r.uint32()
+	if err != nil {
+		return "", err
+	}
+	if check1 != check2 {
+		return "", fmt.Errorf("check values do not match")
+	}
+	algorithm, err := r.string()
+	if err != nil {
+		return "", err
+	}
+
+	var fields int
+	switch algorithm {
+	case "ssh-rsa":
+		fields = 6 // n, e, d, iqmp, p, q
+	case "ssh-dss":
+		fields = 5 // p, q, g, y, x
+	case "ssh-ed25519":
+		fields = 2 // public, private
+	case "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521":
+		fields = 3 // curve, Q, d
+	case "sk-ssh-ed25519@openssh.com":
+		fields = 2 // public, application; then flags, key handle, reserved
+	case "sk-ecdsa-sha2-nistp256@openssh.com":
+		fields = 3 // curve, Q, application; then flags, key handle, reserved
+	default:
+		return "", fmt.Errorf("unsupported key algorithm: %s", algorithm)
+	}
+	if err := r.skip(fields); err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(algorithm, "sk-") {
+		if _, err := r.byte(); err != nil { // flags
+			return "", err
+		}
+		if err := r.skip(2); err != nil { // key handle, reserved
+			return "", err
+		}
+	}
+	return r.string()
+}
+
+// publicKeyBlob encodes an RSA, ECDSA or Ed25519 public key in the SSH wire
+// format, so keys read from PEM files get the same fingerprint as ssh-keygen
+// prints. It returns nil for other keys.
+func publicKeyBlob(key interface{}) []byte {
+	var b bytes.Buffer
+	writeString := func(v []byte) {
+		_ = binary.Write(&b, binary.BigEndian, uint32(len(v)))
+		b.Write(v)
+	}
+	writeMPInt := func(v *big.Int) {
+		data := v.Bytes()
+		if len(data) > 0 && data[0]&0x80 != 0 {
+			data = append([]byte{0}, data...)
+		}
+		writeString(data)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		writeString([]byte("ssh-rsa"))
+		writeMPInt(big.NewInt(int64(k.E)))
+		writeMPInt(k.N)
+	case ed25519.PublicKey:
+		writeString([]byte("ssh-ed25519"))
+		writeString(k)
+	case *ecdsa.PublicKey:
+		bits := k.Curve.Params().BitSize
+		curve := fmt.Sprintf("nistp%d", bits)
+		if _, ok := ecdsaCurveBits[curve]; !ok {
+			return nil
+		}
+		point, err := k.ECDH()
+		if err != nil {
+			return nil
+		}
+		writeString([]byte("ecdsa-sha2-" + curve))
+		writeString([]byte(curve))
+		writeString(point.Bytes())
+	default:
+		return nil
+	}
+	return b.Bytes()
+}
+
+// publicKeyInfo returns the type, size and fingerprint of an RSA, ECDSA or
+// Ed25519 public key.
+func publicKeyInfo(key interface{}) (*KeyInfo, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return &KeyInfo{Type: KeyTypeRSA, Size: k.N.BitLen(), Fingerprint: Fingerprint(publicKeyBlob(k))}, nil
+	case ed25519.PublicKey:
+		return &KeyInfo{Type: KeyTypeEd25519, Size: 256, Fingerprint: Fingerprint(publicKeyBlob(k))}, nil
+	case *ecdsa.PublicKey:
+		info := &KeyInfo{Type: KeyTypeECDSA, Size: k.Curve.Params().BitSize}
+		if blob := publicKeyBlob(k); blob != nil {
+			info.Fingerprint = Fingerprint(blob)
+		}
+		return info, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
@@ -21,37 +21,43 @@ import (
 // SecurityPolicy defines the security configuration for Wooak
 type SecurityPolicy struct {
 	// Key validation settings
-	MinKeySize       int           `json:"min_key_size"`       // Minimum RSA key size (bits)
-	AllowedKeyTypes  []string      `json:"allowed_key_types"`  // Allowed key types (rsa, ed25519, ecdsa)
-	KeyExpiryWarning time.Duration `json:"key_expiry_warning"` // Warning before key expires
+	MinKeySize       int           `json:"min_key_size" yaml:"min_key_size"`             // Minimum RSA key size (bits)
+	AllowedKeyTypes  []string      `json:"allowed_key_types" yaml:"allowed_key_types"`   // Allowed key types (rsa, dsa, ecdsa, ed25519, ecdsa-sk, ed25519-sk)
+	KeyExpiryWarning time.Duration `json:"key_expiry_warning" yaml:"key_expiry_warning"` // Warning before key expires
 
 	// Connection security
-	RequireHostKeyCheck bool `json:"require_host_key_check"` // Require host key verification
-	MaxConnectionTime   int  `json:"max_connection_time"`    // Max connection time in minutes
+	RequireHostKeyCheck bool   `json:"require_host_key_check" yaml:"require_host_key_check"` // Require host key verification
+	MaxConnectionTime   int    `json:"max_connection_time" yaml:"max_connection_time"`       // Max connection time in minutes
+	Enforcement         string `json:"enforcement" yaml:"enforcement"`                       // What connection checks do with violations (block, warn); only block refuses connections
 
 	// Audit settings
-	EnableAuditLog bool   `json:"enable_audit_log"` // Enable audit logging
-	AuditLogLevel  string `json:"audit_log_level"`  // Audit log level (info, warn, error)
-	RetentionDays  int    `json:"retention_days"`   // Log retention in days
+	EnableAuditLog bool   `json:"enable_audit_log" yaml:"enable_audit_log"` // Enable audit logging
+	AuditLogLevel  string `json:"audit_log_level" yaml:"audit_log_level"`   // Audit log level (info, warn, error)
+	RetentionDays  int    `json:"retention_days" yaml:"retention_days"`     // Log retention in days
 
 	// Password policy
-	RequirePasswordAuth bool `json:"require_password_auth"` // Require password authentication
-	MinPasswordLength   int  `json:"min_password_length"`   // Minimum password length
+	RequirePasswordAuth bool `json:"require_password_auth" yaml:"require_password_auth"` // Require password authentication
+	MinPasswordLength   int  `json:"min_password_length" yaml:"min_password_length"`     // Minimum password length
 
 	// Network security
-	AllowedHosts []string `json:"allowed_hosts"` // Whitelist of allowed hosts
-	BlockedHosts []string `json:"blocked_hosts"` // Blacklist of blocked hosts
-	RequireVPN   bool     `json:"require_vpn"`   // Require VPN connection
+	AllowedHosts []string      `json:"allowed_hosts" yaml:"allowed_hosts"` // Whitelist of allowed hosts (names, globs, /regex/, CIDRs, !negations)
+	BlockedHosts []string      `json:"blocked_hosts" yaml:"blocked_hosts"` // Blacklist of blocked hosts, same syntax as AllowedHosts
+	DNSTimeout   time.Duration `json:"dns_timeout" yaml:"dns_timeout"`     // Resolution timeout for CIDR entries; 0 matches only IP literals
+	RequireVPN   bool          `json:"require_vpn" yaml:"require_vpn"`     // Require VPN connection
+
+	// SSH config linting
+	ConfigRules map[string]ConfigRuleSetting `json:"config_rules,omitempty" yaml:"config_rules,omitempty"` // Per-rule overrides keyed by rule ID
 }
 
 // DefaultSecurityPolicy returns the default security policy
 func DefaultSecurityPolicy() *SecurityPolicy {
 	return &SecurityPolicy{
 		MinKeySize:          2048,
-		AllowedKeyTypes:     []string{"rsa", "ed25519", "ecdsa"},
+		AllowedKeyTypes:     []string{"rsa", "ed25519", "ecdsa", "ed25519-sk", "ecdsa-sk"},
 		KeyExpiryWarning:    30 * 24 * time.Hour, // 30 days
 		RequireHostKeyCheck: true,
-		MaxConnectionTime:   60, // 60 minutes
+		MaxConnectionTime:   0, // No limit unless configured
+		Enforcement:         EnforcementWarn,
 		EnableAuditLog:      true,
 		AuditLogLevel:       "info",
 		RetentionDays:       90,
@@ -59,6 +65,7 @@ func DefaultSecurityPolicy() *SecurityPolicy {
 		MinPasswordLength:   8,
 		AllowedHosts:        []string{},
 		BlockedHosts:        []string{},
+		DNSTimeout:          2 * time.Second,
 		RequireVPN:          false,
 	}
 }
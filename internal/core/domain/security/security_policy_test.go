@@ -31,7 +31,7 @@ func TestDefaultSecurityPolicy(t *testing.T) {
 		t.Errorf("MinKeySize = %v, want 2048", policy.MinKeySize)
 	}
 
-	expectedKeyTypes := []string{"rsa", "ed25519", "ecdsa"}
+	expectedKeyTypes := []string{"rsa", "ed25519", "ecdsa", "ed25519-sk", "ecdsa-sk"}
 	if len(policy.AllowedKeyTypes) != len(expectedKeyTypes) {
 		t.Errorf("AllowedKeyTypes length = %v, want %v", len(policy.AllowedKeyTypes), len(expectedKeyTypes))
 	}
@@ -51,8 +51,12 @@ func TestDefaultSecurityPolicy(t *testing.T) {
 		t.Error("RequireHostKeyCheck should be true")
 	}
 
-	if policy.MaxConnectionTime != 60 {
-		t.Errorf("MaxConnectionTime = %v, want 60", policy.MaxConnectionTime)
+	if policy.MaxConnectionTime != 0 {
+		t.Errorf("MaxConnectionTime = %v, want 0 (no limit)", policy.MaxConnectionTime)
+	}
+
+	if policy.Enforcement != EnforcementWarn {
+		t.Errorf("Enforcement = %v, want warn", policy.Enforcement)
 	}
 
 	// Test audit settings
@@ -284,3 +288,26 @@ func TestSecurityPolicyKeyExpiryWarning(t *testing.T) {
 		})
 	}
 }
+
+func TestSecurityPolicy_ConfigRuleSeverity(t *testing.T) {
+	policy := DefaultSecurityPolicy()
+	if severity, enabled := policy.ConfigRuleSeverity(RuleStrictHostKeyChecking); !enabled || severity != SeverityError {
+		t.Errorf("default = %s, %v; want error, true", severity, enabled)
+	}
+
+	policy.ConfigRules = map[string]ConfigRuleSetting{
+		RuleStrictHostKeyChecking:  {Severity: SeverityWarning},
+		RulePasswordAuthentication: {Disabled: true},
+	}
+	if severity, enabled := policy.ConfigRuleSeverity(RuleStrictHostKeyChecking); !enabled || severity != SeverityWarning {
+		t.Errorf("override = %s, %v; want warning, true", severity, enabled)
+	}
+	if _, enabled := policy.ConfigRuleSeverity(RulePasswordAuthentication); enabled {
+		t.Error("disabled rule reported as enabled")
+	}
+
+	findings := []ConfigFinding{{Severity: SeverityInfo}, {Severity: SeverityError}, {Severity: SeverityWarning}}
+	if got := HighestSeverity(findings); got != SeverityError {
+		t.Errorf("HighestSeverity() = %s, want error", got)
+	}
+}
@@ -27,6 +27,9 @@ type Server struct {
 	LastSeen      time.Time
 	PinnedAt      time.Time
 	SSHCount      int
+	// SourceFile is the SSH config file that defines this host. It differs
+	// from the main config for hosts pulled in with Include.
+	SourceFile string
 
 	// Additional SSH config fields
 	// Connection and proxy settings
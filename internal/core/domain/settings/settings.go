@@ -0,0 +1,253 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
+)
+
+// CurrentVersion is the schema version written to new settings files.
+const CurrentVersion = 1
+
+// DefaultMetricsPort avoids clashing with Prometheus on 9090.
+const DefaultMetricsPort = 9091
+
+// Key binding actions. The values are the keys used in the keybindings
+// section of the settings file.
+const (
+	ActionQuit         = "quit"
+	ActionSearch       = "search"
+	ActionAdd          = "add"
+	ActionEdit         = "edit"
+	ActionDelete       = "delete"
+	ActionPin          = "pin"
+	ActionProfiles     = "profiles"
+	ActionSort         = "sort"
+	ActionSortReverse  = "sort_reverse"
+	ActionCopy         = "copy"
+	ActionCopyExpanded = "copy_expanded"
+	ActionPing         = "ping"
+	ActionRefresh      = "refresh"
+	ActionTags         = "tags"
+	ActionSecurity     = "security"
+	ActionAI           = "ai"
+	ActionNavigateDown = "down"
+	ActionNavigateUp   = "up"
+)
+
+const (
+	defaultThemeName = "modern"
+	minKeySizeFloor  = 1024
+)
+
+// Settings is the persistent configuration stored in ~/.wooak/config.yaml.
+type Settings struct {
+	Version     int                     `yaml:"version"`
+	AI          ai.AIConfig             `yaml:"ai"`
+	Security    security.SecurityPolicy `yaml:"security"`
+	Metrics     MetricsSettings         `yaml:"metrics"`
+	Paths       PathSettings            `yaml:"paths"`
+	Theme       ThemeSettings           `yaml:"theme"`
+	KeyBindings map[string]string       `yaml:"keybindings"`
+}
+
+// MetricsSettings controls the /metrics and /health endpoint of the TUI.
+type MetricsSettings struct {
+	Enabled bool `yaml:"enabled"`
+	Port    int  `yaml:"port"`
+}
+
+// PathSettings holds the files wooak reads and writes. A leading ~ is
+// expanded to the user's home directory.
+type PathSettings struct {
+	SSHConfig string `yaml:"ssh_config"`
+	Metadata  string `yaml:"metadata"`
+}
+
+// ThemeSettings selects the color theme. Colors overrides individual theme
+// colors by field name (e.g. Primary: "#39BFFF" or "dodgerblue").
+type ThemeSettings struct {
+	Name   string            `yaml:"name"`
+	Colors map[string]string `yaml:"colors,omitempty"`
+}
+
+// DefaultKeyBindings returns the built-in key for every action.
+func DefaultKeyBindings() map[string]string {
+	return map[string]string{
+		ActionQuit:         "q",
+		ActionSearch:       "/",
+		ActionAdd:          "a",
+		ActionEdit:         "e",
+		ActionDelete:       "d",
+		ActionPin:          "p",
+		ActionProfiles:     "P",
+		ActionSort:         "s",
+		ActionSortReverse:  "S",
+		ActionCopy:         "c",
+		ActionCopyExpanded: "C",
+		ActionPing:         "g",
+		ActionRefresh:      "r",
+		ActionTags:         "t",
+		ActionSecurity:     "z",
+		ActionAI:           "i",
+		ActionNavigateDown: "j",
+		ActionNavigateUp:   "k",
+	}
+}
+
+// Default returns the settings used when no settings file exists.
+func Default() *Settings {
+	return &Settings{
+		Version:  CurrentVersion,
+		AI:       *ai.DefaultAIConfig(),
+		Security: *security.DefaultSecurityPolicy(),
+		Metrics: MetricsSettings{
+			Enabled: true,
+			Port:    DefaultMetricsPort,
+		},
+		Paths: PathSettings{
+			SSHConfig: "~/.ssh/config",
+			Metadata:  "~/.wooak/metadata.json",
+		},
+		Theme:       ThemeSettings{Name: defaultThemeName},
+		KeyBindings: DefaultKeyBindings(),
+	}
+}
+
+// Clone returns a deep copy of s.
+func (s *Settings) Clone() *Settings {
+	c := *s
+	c.Security.AllowedKeyTypes = append([]string(nil), s.Security.AllowedKeyTypes...)
+	c.Security.AllowedHosts = append([]string(nil), s.Security.AllowedHosts...)
+	c.Security.BlockedHosts = append([]string(nil), s.Security.BlockedHosts...)
+	c.Security.ConfigRules = cloneMap(s.Security.ConfigRules)
+	c.Theme.Colors = cloneMap(s.Theme.Colors)
+	c.KeyBindings = cloneMap(s.KeyBindings)
+	c.AI.Redaction = cloneMap(s.AI.Redaction)
+	c.AI.Prompts = cloneMap(s.AI.Prompts)
+	c.AI.Budgets = cloneMap(s.AI.Budgets)
+	return &c
+}
+
+// ResolvedKeyBindings returns the default bindings with the configured ones
+// applied on top.
+func (s *Settings) ResolvedKeyBindings() map[string]string {
+	bindings := DefaultKeyBindings()
+	for action, key := range s.KeyBindings {
+		bindings[action] = key
+	}
+	return bindings
+}
+
+// Validate checks the settings for values that cannot be applied.
+func (s *Settings) Validate() error {
+	if s.Version < 1 || s.Version > CurrentVersion {
+		return fmt.Errorf("unsupported settings version %d (supported: 1-%d)", s.Version, CurrentVersion)
+	}
+
+	if err := s.AI.Validate(); err != nil {
+		return fmt.Errorf("ai.%w", err)
+	}
+
+	if s.Security.MinKeySize < minKeySizeFloor {
+		return fmt.Errorf("security.min_key_size must be at least %d", minKeySizeFloor)
+	}
+	switch s.Security.AuditLogLevel {
+	case "info", "warn", "error":
+	default:
+		return fmt.Errorf("security.audit_log_level must be info, warn or error")
+	}
+	if s.Security.RetentionDays <= 0 {
+		return fmt.Errorf("security.retention_days must be positive")
+	}
+	if s.Security.MaxConnectionTime < 0 {
+		return fmt.Errorf("security.max_connection_time must not be negative")
+	}
+	if err := security.ValidateHostList(s.Security.AllowedHosts); err != nil {
+		return fmt.Errorf("security.allowed_hosts: %w", err)
+	}
+	if err := security.ValidateHostList(s.Security.BlockedHosts); err != nil {
+		return fmt.Errorf("security.blocked_hosts: %w", err)
+	}
+	if s.Security.DNSTimeout < 0 {
+		return fmt.Errorf("security.dns_timeout must not be negative")
+	}
+	switch s.Security.Enforcement {
+	case "", security.EnforcementBlock, security.EnforcementWarn:
+	default:
+		return fmt.Errorf("security.enforcement must be block or warn")
+	}
+	if err := security.ValidateConfigRules(s.Security.ConfigRules); err != nil {
+		return fmt.Errorf("security.config_rules: %w", err)
+	}
+
+	if s.Metrics.Port < 1 || s.Metrics.Port > 65535 {
+		return fmt.Errorf("metrics.port must be between 1 and 65535")
+	}
+	if s.Paths.SSHConfig == "" {
+		return fmt.Errorf("paths.ssh_config is required")
+	}
+	if s.Paths.Metadata == "" {
+		return fmt.Errorf("paths.metadata is required")
+	}
+
+	return validateKeyBindings(s.KeyBindings)
+}
+
+func validateKeyBindings(bindings map[string]string) error {
+	known := DefaultKeyBindings()
+	resolved := DefaultKeyBindings()
+	for action, key := range bindings {
+		if _, ok := known[action]; !ok {
+			return fmt.Errorf("keybindings: unknown action %q", action)
+		}
+		if utf8.RuneCountInString(key) != 1 {
+			return fmt.Errorf("keybindings.%s: %q must be a single character", action, key)
+		}
+		resolved[action] = key
+	}
+
+	actions := make([]string, 0, len(resolved))
+	for action := range resolved {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	used := make(map[string]string, len(resolved))
+	for _, action := range actions {
+		key := resolved[action]
+		if other, ok := used[key]; ok {
+			return fmt.Errorf("keybindings: %q is bound to both %s and %s", key, other, action)
+		}
+		used[key] = action
+	}
+	return nil
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
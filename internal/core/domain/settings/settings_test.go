@@ -0,0 +1,90 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package settings
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
+)
+
+func TestDefault_IsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Default settings must be valid, got: %v", err)
+	}
+}
+
+func TestSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(s *Settings)
+		want   string
+	}{
+		{name: "version", mutate: func(s *Settings) { s.Version = 2 }, want: "unsupported settings version"},
+		{name: "provider", mutate: func(s *Settings) { s.AI.Provider = "claude" }, want: "ai.provider"},
+		{name: "temperature", mutate: func(s *Settings) { s.AI.Temperature = 3 }, want: "ai.temperature"},
+		{name: "min key size", mutate: func(s *Settings) { s.Security.MinKeySize = 512 }, want: "security.min_key_size"},
+		{name: "allowed host regex", mutate: func(s *Settings) { s.Security.AllowedHosts = []string{"/web-(/"} }, want: "security.allowed_hosts: invalid regular expression"},
+		{name: "blocked host cidr", mutate: func(s *Settings) { s.Security.BlockedHosts = []string{"10.0.0.0/33"} }, want: "security.blocked_hosts: invalid CIDR"},
+		{name: "dns timeout", mutate: func(s *Settings) { s.Security.DNSTimeout = -time.Second }, want: "security.dns_timeout"},
+		{name: "enforcement", mutate: func(s *Settings) { s.Security.Enforcement = "deny" }, want: "security.enforcement"},
+		{name: "unknown config rule", mutate: func(s *Settings) {
+			s.Security.ConfigRules = map[string]security.ConfigRuleSetting{"no-such-rule": {Disabled: true}}
+		}, want: "security.config_rules: unknown rule"},
+		{name: "config rule severity", mutate: func(s *Settings) {
+			s.Security.ConfigRules = map[string]security.ConfigRuleSetting{security.RuleForwardAgent: {Severity: "high"}}
+		}, want: "forward-agent.severity"},
+		{name: "metrics port", mutate: func(s *Settings) { s.Metrics.Port = 70000 }, want: "metrics.port"},
+		{name: "ssh config path", mutate: func(s *Settings) { s.Paths.SSHConfig = "" }, want: "paths.ssh_config"},
+		{name: "multi-character key", mutate: func(s *Settings) { s.KeyBindings[ActionSearch] = "ctrl+f" }, want: "single character"},
+		{name: "conflicting key", mutate: func(s *Settings) { s.KeyBindings = map[string]string{ActionPing: "q"} }, want: "bound to both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Default()
+			tt.mutate(s)
+			err := s.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSettings_Clone(t *testing.T) {
+	s := Default()
+	c := s.Clone()
+	c.Security.AllowedKeyTypes[0] = "dsa"
+	c.KeyBindings[ActionQuit] = "x"
+
+	if s.Security.AllowedKeyTypes[0] != "rsa" || s.KeyBindings[ActionQuit] != "q" {
+		t.Error("Clone must not share slices or maps with the original")
+	}
+}
+
+func TestSettings_ResolvedKeyBindings(t *testing.T) {
+	s := &Settings{KeyBindings: map[string]string{ActionSearch: "f"}}
+	bindings := s.ResolvedKeyBindings()
+
+	if bindings[ActionSearch] != "f" {
+		t.Errorf("Expected the configured key for search, got %q", bindings[ActionSearch])
+	}
+	if bindings[ActionQuit] != "q" || len(bindings) != len(DefaultKeyBindings()) {
+		t.Errorf("Expected defaults for unconfigured actions, got %v", bindings)
+	}
+}
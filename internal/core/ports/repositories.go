@@ -14,7 +14,10 @@
 
 package ports
 
-import "github.com/aryasoni98/wooak/internal/core/domain"
+import (
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/settings"
+)
 
 type ServerRepository interface {
 	ListServers(query string) ([]domain.Server, error)
@@ -23,4 +26,19 @@ type ServerRepository interface {
 	DeleteServer(server domain.Server) error
 	SetPinned(alias string, pinned bool) error
 	RecordSSH(alias string) error
+	GetEffectiveConfig(alias string) (domain.EffectiveConfig, error)
+	GetEffectiveConfigs(aliases []string) (map[string]domain.EffectiveConfig, error)
+	ListProfiles() ([]domain.Profile, error)
+	AddProfile(profile domain.Profile) error
+	UpdateProfile(profile domain.Profile, newProfile domain.Profile) error
+	DeleteProfile(profile domain.Profile) error
+}
+
+// SettingsStore persists wooak's own settings (~/.wooak/config.yaml).
+// Get returns the effective settings, including environment overrides;
+// Update applies mutate to the values stored in the file and saves them.
+type SettingsStore interface {
+	Get() *settings.Settings
+	Update(mutate func(s *settings.Settings)) error
+	OnChange(fn func(s *settings.Settings))
 }
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
 )
 
 type ServerService interface {
@@ -26,6 +27,32 @@ type ServerService interface {
 	AddServer(server domain.Server) error
 	DeleteServer(server domain.Server) error
 	SetPinned(alias string, pinned bool) error
-	SSH(alias string) error
+	SSH(alias string, notify SSHNotifier) error
 	Ping(server domain.Server) (bool, time.Duration, error)
+	GetEffectiveConfig(alias string) (domain.EffectiveConfig, error)
+	GetEffectiveConfigs(aliases []string) (map[string]domain.EffectiveConfig, error)
+	ResolveConfig(alias string) (domain.ResolvedConfig, error)
+	ListProfiles() ([]domain.Profile, error)
+	AddProfile(profile domain.Profile) error
+	UpdateProfile(profile domain.Profile, newProfile domain.Profile) error
+	DeleteProfile(profile domain.Profile) error
+}
+
+// SSHNotifier receives messages the ServerService wants shown to the user
+// while an SSH session runs, such as security policy warnings. Adapters decide
+// how to print them; a nil SSHNotifier discards them.
+type SSHNotifier func(message string)
+
+// ServerChangeListener is notified after the ServerService saves or deletes a
+// server, e.g. to keep a search index current. previousAlias is empty for a
+// new server and differs from server.Alias when a server is renamed.
+type ServerChangeListener interface {
+	ServerSaved(previousAlias string, server domain.Server)
+	ServerDeleted(alias string)
+}
+
+// ConnectionPolicy decides whether the ServerService may start ssh for alias,
+// given the configuration `ssh -G` resolved for it.
+type ConnectionPolicy interface {
+	CheckConnection(alias string, resolved domain.ResolvedConfig) security.ConnectionDecision
 }
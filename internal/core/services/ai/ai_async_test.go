@@ -206,7 +206,7 @@ func TestAIService_AsyncConcurrency(t *testing.T) {
 
 	// Start multiple async operations concurrently
 	const numOperations = 5
-	resultChans := make([]<-chan AsyncResult[*ai.AIRecommendation], numOperations)
+	resultChans := make([]<-chan AsyncResult[[]*ai.AIRecommendation], numOperations)
 
 	for i := 0; i < numOperations; i++ {
 		resultChans[i] = service.GenerateRecommendationAsync(ctx,
@@ -24,6 +24,7 @@ type CacheEntry struct {
 	Value      interface{}
 	ExpiresAt  time.Time
 	LastAccess time.Time // For LRU eviction
+	Tags       []string  // For DeleteTagged
 }
 
 // AICache provides caching functionality for AI responses with LRU eviction
@@ -103,6 +104,11 @@ func (c *AICache) Get(key string) (interface{}, bool) {
 
 // Set stores a value in the cache, evicting LRU entries if at capacity
 func (c *AICache) Set(key string, value interface{}) {
+	c.SetWithTags(key, value, nil)
+}
+
+// SetWithTags stores a value like Set, tagged so DeleteTagged can remove it
+func (c *AICache) SetWithTags(key string, value interface{}, tags []string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -113,6 +119,7 @@ func (c *AICache) Set(key string, value interface{}) {
 		c.entries[key].Value = value
 		c.entries[key].ExpiresAt = now.Add(c.ttl)
 		c.entries[key].LastAccess = now
+		c.entries[key].Tags = tags
 		c.updateAccessOrderUnsafe(key)
 		return
 	}
@@ -127,6 +134,7 @@ func (c *AICache) Set(key string, value interface{}) {
 		Value:      value,
 		ExpiresAt:  now.Add(c.ttl),
 		LastAccess: now,
+		Tags:       tags,
 	}
 	c.updateAccessOrderUnsafe(key)
 }
@@ -139,6 +147,36 @@ func (c *AICache) Delete(key string) {
 	c.removeEntryUnsafe(key)
 }
 
+// DeleteTagged removes every entry tagged with one of tags and returns how
+// many were removed
+func (c *AICache) DeleteTagged(tags ...string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var keys []string
+	for key, entry := range c.entries {
+		if hasAnyTag(entry.Tags, tags) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		c.removeEntryUnsafe(key)
+	}
+	return len(keys)
+}
+
+// hasAnyTag reports whether tags contains one of wanted
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // removeEntryUnsafe removes an entry without acquiring lock (caller must hold lock)
 func (c *AICache) removeEntryUnsafe(key string) {
 	delete(c.entries, key)
@@ -331,3 +331,22 @@ func TestAICache_CleanupGoroutine(t *testing.T) {
 	// The test passes if Stop() returns without hanging
 	// This verifies that the cleanup goroutine properly exits
 }
+
+func TestAICache_DeleteTagged(t *testing.T) {
+	cache := NewAICache(1 * time.Minute)
+	defer cache.Stop()
+
+	cache.SetWithTags("web", "a", []string{"web"})
+	cache.SetWithTags("db", "b", []string{"db"})
+	cache.SetWithTags("search", "c", []string{AllServers})
+	cache.Set("untagged", "d")
+
+	if removed := cache.DeleteTagged("web", AllServers); removed != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", removed)
+	}
+	for key, want := range map[string]bool{"web": false, "search": false, "db": true, "untagged": true} {
+		if _, exists := cache.Get(key); exists != want {
+			t.Errorf("Entry %q: expected exists=%t", key, want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import "github.com/aryasoni98/wooak/internal/core/domain/ai"
+
+// SetSessionStore sets the store conversations are saved to.
+func (s *AIService) SetSessionStore(store *SessionStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = store
+}
+
+// Sessions returns the store conversations are saved to, or nil when
+// conversations are not persisted.
+func (s *AIService) Sessions() *SessionStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sessions
+}
+
+// withHistory returns request with history sent before its prompt, opened by
+// the chat system prompt describing the templates in prompts. The oldest turns are dropped so that the system
+// prompt, the history, the prompt and the answer's MaxTokens fit in the
+// configured context window. Without history, or with a zero context window,
+// request is returned unchanged.
+func withHistory(config *ai.AIConfig, prompts *ai.PromptLibrary, request *ai.AIRequest, history []ai.Message) *ai.AIRequest {
+	if len(history) == 0 || config.ContextWindow <= 0 {
+		return request
+	}
+
+	system := ai.ChatSystemPrompt(prompts)
+	budget := config.ContextWindow - request.MaxTokens - ai.EstimateTokens(system) - ai.EstimateTokens(request.Prompt)
+	history = ai.TruncateMessages(history, budget)
+	if len(history) == 0 {
+		return request
+	}
+
+	messages := make([]ai.Message, 0, len(history)+1)
+	messages = append(messages, ai.Message{Role: ai.RoleSystem, Content: system})
+	messages = append(messages, history...)
+	request.Messages = messages
+	return request
+}
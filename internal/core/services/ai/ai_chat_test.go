@@ -0,0 +1,133 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+func TestWithHistory(t *testing.T) {
+	config := aiDomain.DefaultAIConfig()
+	service := NewAIService(config)
+	defer service.Stop()
+	service.SetPromptTemplates(map[string]*aiDomain.PromptTemplate{
+		"terse": {Name: "Terse Audit", Description: "One-line security audits", Type: aiDomain.RequestTypeSecurity},
+	})
+	history := []aiDomain.Message{
+		{Role: aiDomain.RoleUser, Content: strings.Repeat("old ", 10000)},
+		{Role: aiDomain.RoleAssistant, Content: "old answer"},
+		{Role: aiDomain.RoleUser, Content: "recent question"},
+		{Role: aiDomain.RoleAssistant, Content: "recent answer"},
+	}
+
+	request := withHistory(config, service.PromptTemplates(), service.recommendationRequest(config, map[string]string{"Host": "db1"}, "why?"), history)
+	if len(request.Messages) != 3 {
+		t.Fatalf("Expected the system prompt and the recent turn, got %d messages", len(request.Messages))
+	}
+	if request.Messages[0].Role != aiDomain.RoleSystem || request.Messages[1].Content != "recent question" {
+		t.Errorf("Unexpected messages %+v", request.Messages)
+	}
+	if !strings.Contains(request.Messages[0].Content, "One-line security audits") {
+		t.Errorf("Expected the system prompt to describe the user's templates, got %q", request.Messages[0].Content)
+	}
+
+	config.ContextWindow = 0
+	if request := withHistory(config, service.PromptTemplates(), service.recommendationRequest(config, nil, "why?"), history); request.Messages != nil {
+		t.Error("Expected no history with a zero context window")
+	}
+}
+
+func TestRecommendationStreamWithHistory_Ollama(t *testing.T) {
+	var payload struct {
+		Messages []map[string]string `json:"messages"`
+		Prompt   string              `json:"prompt"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("Expected a conversation to use /api/chat, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		writeLines(t, w,
+			`{"message":{"role":"assistant","content":"{\"recommendations\": []"},"done":false}`,
+			`{"message":{"role":"assistant","content":"}"},"done":true}`,
+		)
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOllama, server.URL)
+	defer service.Stop()
+
+	history := []aiDomain.Message{
+		{Role: aiDomain.RoleUser, Content: "harden web1"},
+		{Role: aiDomain.RoleAssistant, Content: "1. [high] Disable root login"},
+	}
+	var last StreamChunk[[]*aiDomain.AIRecommendation]
+	for chunk := range service.GenerateRecommendationStreamWithHistory(context.Background(), history, map[string]string{"Host": "web1"}, "why?") {
+		last = chunk
+	}
+	if last.Error != nil {
+		t.Fatalf("Stream failed: %v", last.Error)
+	}
+
+	if payload.Prompt != "" || len(payload.Messages) != 4 {
+		t.Fatalf("Expected system, two history turns and the question, got %+v", payload.Messages)
+	}
+	roles := []string{"system", "user", "assistant", "user"}
+	for i, role := range roles {
+		if payload.Messages[i]["role"] != role {
+			t.Errorf("Message %d has role %q, want %q", i, payload.Messages[i]["role"], role)
+		}
+	}
+	if !strings.Contains(payload.Messages[3]["content"], "why?") {
+		t.Errorf("Expected the question last, got %q", payload.Messages[3]["content"])
+	}
+}
+
+func TestWithHistory_Redacted(t *testing.T) {
+	var payload struct {
+		Messages []map[string]string `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"{\"recommendations\": []}"}}]}`))
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOpenAICompatible, server.URL)
+	defer service.Stop()
+
+	config := service.GetConfig()
+	request := withHistory(config, service.PromptTemplates(), service.recommendationRequest(config, map[string]string{"Host": "db1"}, "and now?"), []aiDomain.Message{
+		{Role: aiDomain.RoleUser, Content: "my password=hunter2 leaked"},
+		{Role: aiDomain.RoleAssistant, Content: "rotate it"},
+	})
+	if _, err := service.makeAIRequest(context.Background(), request); err != nil {
+		t.Fatalf("makeAIRequest failed: %v", err)
+	}
+	for _, message := range payload.Messages {
+		if strings.Contains(message["content"], "hunter2") {
+			t.Errorf("Expected history to be redacted, got %q", message["content"])
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// diskCacheVersion is the schema version of cache entry files.
+	diskCacheVersion = 1
+	// diskCacheFileExt is the extension of cache entry files.
+	diskCacheFileExt = ".json"
+	// AllServers tags cached answers based on every server, such as
+	// searches, which any server change invalidates.
+	AllServers = "*"
+)
+
+// diskCacheEntry is the file of one cached answer. Servers are the aliases
+// of the servers the answer was based on.
+type diskCacheEntry struct {
+	Version   int             `json:"version"`
+	Key       string          `json:"key"`
+	Servers   []string        `json:"servers,omitempty"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// diskCacheIndexEntry is what pruning and invalidation need to know about an
+// entry file, so they do not parse every file each time.
+type diskCacheIndexEntry struct {
+	servers   []string
+	expiresAt time.Time
+}
+
+// readEntryFile reads an entry file; tests replace it to count reads.
+var readEntryFile = readDiskCacheEntry
+
+// DiskCache keeps cached AI answers across restarts, one JSON file per entry
+// named by the hash of its key. Entries expire after the TTL they were
+// stored with; when the files exceed the size limit, the least recently used
+// ones are removed first (file modification times record use). Entries are
+// invalidated when a server they were based on changes.
+type DiskCache struct {
+	dir    string
+	logger *zap.SugaredLogger
+	mu     sync.Mutex
+	// index holds the servers and expiry of entry files by path. Files not
+	// in it, such as those of an earlier run, are read once when first
+	// listed.
+	index map[string]diskCacheIndexEntry
+}
+
+// NewDiskCache creates a cache stored in dir, which is created on the first
+// write.
+func NewDiskCache(dir string, logger *zap.SugaredLogger) *DiskCache {
+	return &DiskCache{dir: dir, logger: logger, index: make(map[string]diskCacheIndexEntry)}
+}
+
+// Get decodes the answer cached under key into out and returns the servers it
+// was based on, reporting whether there was one. Expired entries are removed.
+func (d *DiskCache) Get(key string, out interface{}) ([]string, bool) {
+	path := d.path(key)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, err := readEntryFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			d.logWarn("ignoring unreadable AI cache entry", "error", err, "path", path)
+			d.remove(path)
+		}
+		return nil, false
+	}
+	if entry.Key != key {
+		return nil, false // hash collision
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		d.remove(path)
+		return nil, false
+	}
+	if err := json.Unmarshal(entry.Value, out); err != nil {
+		d.logWarn("ignoring unreadable AI cache entry", "error", err, "path", path)
+		d.remove(path)
+		return nil, false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now) // mark as recently used
+	return entry.Servers, true
+}
+
+// Set caches value under key for ttl, recording the servers it was based on,
+// then removes expired entries and, while the cache is larger than maxBytes,
+// the least recently used ones. maxBytes 0 means no limit.
+func (d *DiskCache) Set(key string, value interface{}, servers []string, ttl time.Duration, maxBytes int64) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal AI cache value: %w", err)
+	}
+	expiresAt := time.Now().Add(ttl)
+	data, err = json.Marshal(diskCacheEntry{
+		Version:   diskCacheVersion,
+		Key:       key,
+		Servers:   servers,
+		ExpiresAt: expiresAt,
+		Value:     data,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal AI cache entry: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.MkdirAll(d.dir, 0o750); err != nil {
+		return fmt.Errorf("ensure AI cache directory '%s': %w", d.dir, err)
+	}
+	path := d.path(key)
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0o600); err != nil {
+		return fmt.Errorf("write temporary AI cache entry '%s': %w", tempFile, err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("rename temporary AI cache entry '%s' to '%s': %w", tempFile, path, err)
+	}
+	d.index[path] = diskCacheIndexEntry{servers: servers, expiresAt: expiresAt}
+
+	d.prune(maxBytes)
+	return nil
+}
+
+// Invalidate removes the entries based on any of aliases, and those based on
+// every server. It returns how many were removed.
+func (d *DiskCache) Invalidate(aliases ...string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	wanted := append([]string{AllServers}, aliases...)
+	removed := 0
+	for _, file := range d.files() {
+		entry, ok := d.indexed(file.path)
+		if !ok || hasAnyTag(entry.servers, wanted) {
+			d.remove(file.path)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Clear removes every entry.
+func (d *DiskCache) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, file := range d.files() {
+		d.remove(file.path)
+	}
+}
+
+// Stats returns the number of entries and their total size in bytes.
+func (d *DiskCache) Stats() (entries int, bytes int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	files := d.files()
+	for _, file := range files {
+		bytes += file.size
+	}
+	return len(files), bytes
+}
+
+// diskCacheFile is an entry file as listed from the cache directory.
+type diskCacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the entry files, least recently used first (called with d.mu
+// held).
+func (d *DiskCache) files() []diskCacheFile {
+	dirEntries, err := os.ReadDir(d.dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			d.logWarn("failed to list AI cache", "error", err, "dir", d.dir)
+		}
+		return nil
+	}
+	files := make([]diskCacheFile, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), diskCacheFileExt) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, diskCacheFile{
+			path:    filepath.Join(d.dir, dirEntry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	return files
+}
+
+// prune removes expired and unreadable entries, then the least recently used
+// ones until the cache fits in maxBytes (called with d.mu held).
+func (d *DiskCache) prune(maxBytes int64) {
+	now := time.Now()
+	var kept []diskCacheFile
+	var total int64
+	for _, file := range d.files() {
+		entry, ok := d.indexed(file.path)
+		if !ok || now.After(entry.expiresAt) {
+			d.remove(file.path)
+			continue
+		}
+		kept = append(kept, file)
+		total += file.size
+	}
+	for i := 0; maxBytes > 0 && total > maxBytes && i < len(kept); i++ {
+		d.remove(kept[i].path)
+		total -= kept[i].size
+	}
+}
+
+// indexed returns the index entry of the entry file at path, reading the
+// file if it is not indexed yet, and false if it cannot be read (called with
+// d.mu held).
+func (d *DiskCache) indexed(path string) (diskCacheIndexEntry, bool) {
+	if entry, ok := d.index[path]; ok {
+		return entry, true
+	}
+	entry, err := readEntryFile(path)
+	if err != nil {
+		return diskCacheIndexEntry{}, false
+	}
+	indexEntry := diskCacheIndexEntry{servers: entry.Servers, expiresAt: entry.ExpiresAt}
+	d.index[path] = indexEntry
+	return indexEntry, true
+}
+
+// path returns the file of the entry for key.
+func (d *DiskCache) path(key string) string {
+	return filepath.Join(d.dir, generateHash(key)+diskCacheFileExt)
+}
+
+func (d *DiskCache) remove(path string) {
+	delete(d.index, path)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		d.logWarn("failed to remove AI cache entry", "error", err, "path", path)
+	}
+}
+
+func (d *DiskCache) logWarn(msg string, keysAndValues ...interface{}) {
+	if d.logger != nil {
+		d.logger.Warnw(msg, keysAndValues...)
+	}
+}
+
+// readDiskCacheEntry reads the entry file at path.
+func readDiskCacheEntry(path string) (*diskCacheEntry, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is built from a key hash in the cache directory
+	if err != nil {
+		return nil, err
+	}
+	var entry diskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("parse AI cache entry '%s': %w", path, err)
+	}
+	if entry.Version != diskCacheVersion {
+		return nil, fmt.Errorf("AI cache entry '%s' has unsupported version %d", path, entry.Version)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+func TestDiskCache_PersistsAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewDiskCache(dir, nil).Set("key", []aiDomain.SearchResult{{Alias: "web", Score: 0.9}}, []string{AllServers}, time.Hour, 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	var results []aiDomain.SearchResult
+	servers, ok := NewDiskCache(dir, nil).Get("key", &results)
+	if !ok {
+		t.Fatal("Expected the entry to be found by a new instance")
+	}
+	if len(results) != 1 || results[0].Alias != "web" || results[0].Score != 0.9 {
+		t.Errorf("Unexpected results %+v", results)
+	}
+	if len(servers) != 1 || servers[0] != AllServers {
+		t.Errorf("Expected the servers to be kept, got %v", servers)
+	}
+
+	if _, ok := NewDiskCache(dir, nil).Get("other", &results); ok {
+		t.Error("Expected a missing key not to be found")
+	}
+}
+
+func TestDiskCache_Expiry(t *testing.T) {
+	cache := NewDiskCache(t.TempDir(), nil)
+	if err := cache.Set("key", "value", nil, -time.Second, 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	var value string
+	if _, ok := cache.Get("key", &value); ok {
+		t.Error("Expected an expired entry not to be returned")
+	}
+	if entries, _ := cache.Stats(); entries != 0 {
+		t.Errorf("Expected the expired entry to be removed, got %d entries", entries)
+	}
+}
+
+func TestDiskCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewDiskCache(t.TempDir(), nil)
+	value := strings.Repeat("x", 100)
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(key, value, nil, time.Hour, 0); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	// Make "a" older, then use it so "b" is the least recently used.
+	old := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(cache.path("a"), old, old)
+	_ = os.Chtimes(cache.path("b"), old.Add(time.Minute), old.Add(time.Minute))
+	var got string
+	if _, ok := cache.Get("a", &got); !ok {
+		t.Fatal("Expected a to be cached")
+	}
+
+	_, size := cache.Stats()
+	if err := cache.Set("c", value, nil, time.Hour, size+size/4); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.Get(key, &got); ok != want {
+			t.Errorf("Entry %q: expected cached=%t", key, want)
+		}
+	}
+}
+
+func TestDiskCache_Invalidate(t *testing.T) {
+	cache := NewDiskCache(t.TempDir(), nil)
+	entries := map[string][]string{"web": {"web"}, "db": {"db"}, "search": {AllServers}}
+	for key, servers := range entries {
+		if err := cache.Set(key, key, servers, time.Hour, 0); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	if removed := cache.Invalidate("web"); removed != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", removed)
+	}
+	var got string
+	for key, want := range map[string]bool{"web": false, "search": false, "db": true} {
+		if _, ok := cache.Get(key, &got); ok != want {
+			t.Errorf("Entry %q: expected cached=%t", key, want)
+		}
+	}
+
+	// Corrupt files are dropped rather than kept forever.
+	if err := os.WriteFile(filepath.Join(cache.dir, "corrupt.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cache.Clear()
+	if entries, _ := cache.Stats(); entries != 0 {
+		t.Errorf("Expected Clear to remove every entry, got %d", entries)
+	}
+}
+
+func TestDiskCache_PruneUsesIndex(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewDiskCache(dir, nil).Set("old", "value", []string{"web"}, time.Hour, 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	reads := 0
+	readEntryFile = func(path string) (*diskCacheEntry, error) {
+		reads++
+		return readDiskCacheEntry(path)
+	}
+	defer func() { readEntryFile = readDiskCacheEntry }()
+
+	// A new instance reads the entry of an earlier run once, then relies on
+	// its index.
+	cache := NewDiskCache(dir, nil)
+	for _, key := range []string{"a", "b", "c"} {
+		if err := cache.Set(key, "value", []string{"db"}, time.Hour, 0); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if removed := cache.Invalidate("web"); removed != 1 {
+		t.Errorf("Expected the entry based on web to be removed, got %d", removed)
+	}
+	if reads != 1 {
+		t.Errorf("Expected only the unindexed entry to be read, got %d reads", reads)
+	}
+}
+
+func TestAIService_DiskCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"response":"Use a bastion","done":true}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	newService := func(model string) *AIService {
+		config := aiDomain.DefaultAIConfig()
+		config.BaseURL = server.URL
+		config.Model = model
+		config.DiskCache = true
+		service := NewAIService(config)
+		service.SetDiskCache(NewDiskCache(dir, nil))
+		return service
+	}
+	recommend := func(service *AIService) {
+		t.Helper()
+		recs, err := service.GenerateRecommendation(context.Background(), map[string]string{"Host": "web"}, "jump hosts?")
+		if err != nil || len(recs) != 1 || recs[0].Description != "Use a bastion" {
+			t.Fatalf("Unexpected recommendations %+v, error %v", recs, err)
+		}
+	}
+
+	first := newService("llama3")
+	recommend(first)
+	first.Stop()
+
+	// A restarted service answers from disk.
+	second := newService("llama3")
+	defer second.Stop()
+	recommend(second)
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected the answer to survive a restart, got %d provider calls", got)
+	}
+
+	// Another model does not reuse it.
+	other := newService("mistral")
+	defer other.Stop()
+	recommend(other)
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected another model to ask the provider, got %d provider calls", got)
+	}
+
+	// Editing the server drops the answer from memory and disk.
+	second.ServerSaved("web", domain.Server{Alias: "web"})
+	recommend(second)
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected the answer to be invalidated, got %d provider calls", got)
+	}
+}
+
+func TestAIService_DiskCache_Disabled(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"response":"Use a bastion","done":true}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := aiDomain.DefaultAIConfig()
+	config.BaseURL = server.URL
+	config.DiskCache = true
+	config.CacheEnabled = false
+	service := NewAIService(config)
+	defer service.Stop()
+	service.SetDiskCache(NewDiskCache(dir, nil))
+
+	// An answer left on disk from when caching was enabled.
+	serverConfig := map[string]string{"Host": "web"}
+	key := service.diskCacheKey(config, aiDomain.RequestTypeRecommendation,
+		fmt.Sprintf("recommendation:%s:%s", generateHash(serverConfig), generateHash("jump hosts?")))
+	stale := []aiDomain.AIRecommendation{{Description: "Stale answer"}}
+	if err := NewDiskCache(dir, nil).Set(key, stale, []string{"web"}, time.Hour, 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	recs, err := service.GenerateRecommendation(context.Background(), serverConfig, "jump hosts?")
+	if err != nil || len(recs) != 1 || recs[0].Description != "Use a bastion" {
+		t.Fatalf("Unexpected recommendations %+v, error %v", recs, err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected the provider to be asked with caching disabled, got %d calls", got)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// OpenAIEmbeddingsEndpoint is the embeddings path of OpenAI-compatible APIs,
+// relative to the base URL.
+const OpenAIEmbeddingsEndpoint = "/embeddings"
+
+// EmbeddingModel returns the model Embed uses.
+func (s *AIService) EmbeddingModel() string {
+	return s.GetConfig().EmbeddingModelName()
+}
+
+// Embed returns an embedding vector for each of texts, in order, using
+// Ollama's /api/embeddings or an OpenAI-compatible /embeddings endpoint.
+// Texts are redacted like prompts before they are sent. Like other requests,
+// embedding calls are rate limited, checked against the token budget and
+// recorded in the usage ledger.
+func (s *AIService) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	config := s.GetConfig()
+	if !config.Enabled {
+		return nil, fmt.Errorf("AI service is disabled")
+	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	request := &ai.AIRequest{
+		ID:        generateRequestID(),
+		Type:      ai.RequestTypeEmbedding,
+		Model:     config.EmbeddingModelName(),
+		Timestamp: time.Now(),
+	}
+	if err := s.checkRateLimit(config, s.getRateLimiter(), request); err != nil {
+		return nil, err
+	}
+	if err := s.checkBudget(config, request); err != nil {
+		return nil, err
+	}
+
+	redactor := ai.NewRedactor(config.RedactionLevel())
+	redacted := make([]string, len(texts))
+	for i, text := range texts {
+		redacted[i] = redactor.Redact(text)
+	}
+	texts = redacted
+
+	provider, err := lookupProvider(config.Provider)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := provider.Embed(ctx, s.providerClient(), config, texts)
+	if err != nil {
+		return nil, err
+	}
+	// Providers return no token counts for embeddings, so the texts are
+	// counted as an estimated prompt.
+	request.Prompt = strings.Join(texts, "\n")
+	s.recordUsage(config, request, &ai.AIResponse{})
+	return vectors, nil
+}
@@ -0,0 +1,448 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+const (
+	// maxRepairCuts bounds how many times decodeJSON shortens a truncated
+	// answer before giving up.
+	maxRepairCuts = 20
+	// maxFallbackTitleLength bounds titles derived from descriptions.
+	maxFallbackTitleLength = 60
+)
+
+// recommendationDefaults fills in what a model leaves out of a
+// recommendation, and describes the single recommendation used when the
+// answer is not JSON at all.
+type recommendationDefaults struct {
+	Type       ai.RecommendationType
+	Priority   ai.Priority
+	Confidence float64
+	Title      string
+}
+
+// parseRecommendations turns a model answer into recommendations sorted by
+// priority. Answers in the JSON format requested by the prompt templates are
+// decoded, repairing common mistakes such as code fences, trailing commas and
+// output cut off at the token limit. Anything else becomes a single
+// recommendation holding the raw answer.
+func parseRecommendations(response *ai.AIResponse, config map[string]string, defaults recommendationDefaults) []*ai.AIRecommendation {
+	metadata := func(structured bool) map[string]interface{} {
+		return map[string]interface{}{
+			"model":       response.Model,
+			"request_id":  response.RequestID,
+			"config_hash": generateHash(config),
+			"structured":  structured,
+		}
+	}
+
+	var recs []*ai.AIRecommendation
+	if value, ok := decodeJSON(response.Content); ok {
+		items := recommendationItems(value)
+		if len(items) == 0 {
+			return []*ai.AIRecommendation{} // the model found nothing to recommend
+		}
+		for _, item := range items {
+			if rec := recommendationFromItem(item, defaults); rec != nil {
+				rec.Metadata = metadata(true)
+				recs = append(recs, rec)
+			}
+		}
+	}
+	if len(recs) == 0 {
+		return []*ai.AIRecommendation{{
+			ID:          generateRecommendationID(),
+			Type:        defaults.Type,
+			Title:       defaults.Title,
+			Description: strings.TrimSpace(response.Content),
+			Confidence:  defaults.Confidence,
+			Priority:    defaults.Priority,
+			Timestamp:   time.Now(),
+			Metadata:    metadata(false),
+		}}
+	}
+
+	ai.SortRecommendations(recs)
+	return recs
+}
+
+// parseSearchResults reads the ranked matches in a search answer. Aliases that
+// were not among the servers sent, such as ones the model made up, are
+// dropped, and the rest take the spelling of the alias sent. Results are
+// sorted by score, keeping the model's order for equal scores.
+func parseSearchResults(response *ai.AIResponse, servers []map[string]string) ([]ai.SearchResult, error) {
+	value, ok := decodeJSON(response.Content)
+	if !ok {
+		return nil, fmt.Errorf("search answer is not JSON: %q", truncateTitle(response.Content))
+	}
+
+	known := make(map[string]string, len(servers))
+	for _, server := range servers {
+		if alias := server["Host"]; alias != "" {
+			known[strings.ToLower(alias)] = alias
+		}
+	}
+
+	results := []ai.SearchResult{}
+	seen := make(map[string]bool)
+	for _, item := range jsonList(value, "matches", "results", "servers", "items") {
+		var result ai.SearchResult
+		switch v := item.(type) {
+		case string:
+			result.Alias = v
+		case map[string]interface{}:
+			result.Alias = stringField(v, "alias", "host", "Host", "name", "server")
+			result.Reason = strings.TrimSpace(stringField(v, "reason", "explanation", "description"))
+			if score, ok := confidenceValue(v["score"]); ok {
+				result.Score = score
+			} else if score, ok := confidenceValue(v["confidence"]); ok {
+				result.Score = score
+			}
+		}
+
+		alias, ok := known[strings.ToLower(strings.TrimSpace(result.Alias))]
+		if !ok || seen[alias] {
+			continue
+		}
+		seen[alias] = true
+		result.Alias = alias
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results, nil
+}
+
+// recommendationItems finds the recommendations in a decoded answer.
+func recommendationItems(value interface{}) []map[string]interface{} {
+	list := jsonList(value, "recommendations", "findings", "issues", "items")
+	items := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			items = append(items, m)
+		}
+	}
+	return items
+}
+
+// jsonList finds the list of items in a decoded answer: the first of keys
+// holding a list, a bare list, or else a single object as the only item.
+func jsonList(value interface{}, keys ...string) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		for _, key := range keys {
+			if items, ok := v[key].([]interface{}); ok {
+				return items
+			}
+		}
+		return []interface{}{v}
+	}
+	return nil
+}
+
+// recommendationFromItem converts one decoded recommendation, tolerating
+// missing fields and loosely typed values. Items without any text are dropped.
+func recommendationFromItem(item map[string]interface{}, defaults recommendationDefaults) *ai.AIRecommendation {
+	title := strings.TrimSpace(stringField(item, "title", "name", "summary"))
+	description := strings.TrimSpace(stringField(item, "description", "details", "reason", "explanation"))
+	if title == "" && description == "" {
+		return nil
+	}
+	if title == "" {
+		title = truncateTitle(description)
+	}
+
+	rec := &ai.AIRecommendation{
+		ID:          generateRecommendationID(),
+		Type:        defaults.Type,
+		Title:       title,
+		Description: description,
+		Confidence:  defaults.Confidence,
+		Priority:    defaults.Priority,
+		Actions:     stringList(item["actions"]),
+		Changes:     serverChanges(item["changes"]),
+		Timestamp:   time.Now(),
+	}
+	if t, ok := ai.ParseRecommendationType(stringField(item, "type", "category")); ok {
+		rec.Type = t
+	}
+	if p, ok := ai.ParsePriority(stringField(item, "priority", "severity", "risk")); ok {
+		rec.Priority = p
+	}
+	if c, ok := confidenceValue(item["confidence"]); ok {
+		rec.Confidence = c
+	}
+	return rec
+}
+
+// stringField returns the first of keys that holds a string.
+func stringField(item map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := item[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// stringList reads a list of actions given as strings, as objects with a
+// description, or as a single string.
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			return []string{s}
+		}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			switch a := item.(type) {
+			case string:
+				if s := strings.TrimSpace(a); s != "" {
+					out = append(out, s)
+				}
+			case map[string]interface{}:
+				if s := strings.TrimSpace(stringField(a, "action", "description", "command", "step")); s != "" {
+					out = append(out, s)
+				}
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// serverChanges reads proposed option edits given as a list of
+// {"option": ..., "value": ...} objects or as a single option-to-value object.
+// Options a domain.Server cannot hold, such as ones the model made up, are
+// dropped, and the rest are spelled as in ssh_config.
+func serverChanges(value interface{}) []domain.ServerFieldChange {
+	var changes []domain.ServerFieldChange
+	add := func(option string, value interface{}, reason string) {
+		canonical, ok := domain.CanonicalServerOption(option)
+		if !ok {
+			return
+		}
+		text, ok := optionValue(value)
+		if !ok {
+			return
+		}
+		changes = append(changes, domain.ServerFieldChange{Option: canonical, Value: text, Reason: strings.TrimSpace(reason)})
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				value, ok := m["value"]
+				if !ok {
+					value = m["new"]
+				}
+				add(stringField(m, "option", "key", "field", "name"), value, stringField(m, "reason", "description"))
+			}
+		}
+	case map[string]interface{}:
+		for option, value := range v {
+			add(option, value, "")
+		}
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Option < changes[j].Option })
+	}
+	return changes
+}
+
+// optionValue formats a decoded option value as ssh_config text. A null value
+// removes the option.
+func optionValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return strings.TrimSpace(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		if v {
+			return "yes", true
+		}
+		return "no", true
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := optionValue(item)
+			if !ok {
+				return "", false
+			}
+			if s != "" {
+				items = append(items, s)
+			}
+		}
+		return strings.Join(items, ", "), true
+	}
+	return "", false
+}
+
+// confidenceValue reads a confidence given as a fraction, a percentage or a
+// numeric string, clamped to 0-1.
+func confidenceValue(value interface{}) (float64, bool) {
+	var c float64
+	switch v := value.(type) {
+	case float64:
+		c = v
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+		if err != nil {
+			return 0, false
+		}
+		c = parsed
+	default:
+		return 0, false
+	}
+	if c > 1 && c <= 100 {
+		c /= 100
+	}
+	if c < 0 || c > 1 {
+		return 0, false
+	}
+	return c, true
+}
+
+func truncateTitle(description string) string {
+	title := strings.SplitN(description, "\n", 2)[0]
+	if end := strings.Index(title, ". "); end > 0 {
+		title = title[:end]
+	}
+	if runes := []rune(title); len(runes) > maxFallbackTitleLength {
+		title = string(runes[:maxFallbackTitleLength-1]) + "…"
+	}
+	return title
+}
+
+// decodeJSON decodes the JSON value in a model answer. If the repaired value
+// still does not parse, the answer is assumed to be cut off mid-value and is
+// shortened to its previous comma until it does, keeping the complete items.
+func decodeJSON(content string) (interface{}, bool) {
+	candidate := extractJSON(content)
+	for i := 0; candidate != "" && i <= maxRepairCuts; i++ {
+		var value interface{}
+		if err := json.Unmarshal([]byte(repairJSON(candidate)), &value); err == nil {
+			return value, true
+		}
+		cut := strings.LastIndexByte(candidate, ',')
+		if cut <= 0 {
+			break
+		}
+		candidate = candidate[:cut]
+	}
+	return nil, false
+}
+
+// extractJSON returns the JSON value embedded in a model answer, dropping
+// markdown code fences and any prose around it. A value with no closing
+// bracket is returned to the end so repairJSON can close it.
+func extractJSON(content string) string {
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		return ""
+	}
+	end := strings.LastIndexAny(content, "}]")
+	if end < start {
+		return content[start:]
+	}
+	return content[start : end+1]
+}
+
+// repairJSON fixes the mistakes models commonly make in JSON: trailing commas,
+// raw newlines inside strings, and strings, objects or arrays left open when
+// the answer hit the token limit.
+func repairJSON(s string) string {
+	out := make([]byte, 0, len(s)+8)
+	var closers []byte
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			case c == '\n':
+				out = append(out, '\\', 'n')
+				continue
+			case c == '\r' || c == '\t':
+				out = append(out, ' ')
+				continue
+			}
+			out = append(out, c)
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			closers = append(closers, '}')
+		case '[':
+			closers = append(closers, ']')
+		case '}', ']':
+			out = trimTrailingComma(out)
+			if len(closers) > 0 {
+				closers = closers[:len(closers)-1]
+			}
+		}
+		out = append(out, c)
+	}
+
+	if inString {
+		if escaped {
+			out = out[:len(out)-1]
+		}
+		out = append(out, '"')
+	}
+	out = trimTrailingComma(out)
+	for i := len(closers) - 1; i >= 0; i-- {
+		out = append(trimTrailingComma(out), closers[i])
+	}
+	return string(out)
+}
+
+// trimTrailingComma removes a comma (and the whitespace after it) at the end
+// of b.
+func trimTrailingComma(b []byte) []byte {
+	end := len(b)
+	for end > 0 && strings.ContainsRune(" \t\r\n", rune(b[end-1])) {
+		end--
+	}
+	if end > 0 && b[end-1] == ',' {
+		return b[:end-1]
+	}
+	return b
+}
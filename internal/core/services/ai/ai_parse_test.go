@@ -0,0 +1,208 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+var testDefaults = recommendationDefaults{
+	Type:       aiDomain.RecTypeOptimization,
+	Priority:   aiDomain.PriorityMedium,
+	Confidence: 0.8,
+	Title:      "AI-Generated Recommendation",
+}
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"trailing commas", `{"a": [1, 2,], "b": 3,}`},
+		{"truncated string", `{"recommendations": [{"title": "Use ed25519`},
+		{"truncated after comma", `{"actions": ["a", "b",`},
+		{"raw newline in string", "{\"description\": \"line one\nline two\"}"},
+		{"dangling escape", `{"title": "C:\`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repaired := repairJSON(tt.input)
+			var v interface{}
+			if err := json.Unmarshal([]byte(repaired), &v); err != nil {
+				t.Errorf("repairJSON(%q) = %q, which does not parse: %v", tt.input, repaired, err)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON_TruncatedMidKey(t *testing.T) {
+	content := `{"recommendations": [{"title": "A", "actions": ["x"]}, {"title": "B", "descr`
+	value, ok := decodeJSON(content)
+	if !ok {
+		t.Fatal("Expected the truncated answer to decode")
+	}
+	items := recommendationItems(value)
+	if len(items) != 1 || items[0]["title"] != "A" {
+		t.Errorf("Expected only the complete item, got %v", items)
+	}
+}
+
+func TestParseRecommendations_Structured(t *testing.T) {
+	content := "Here are my findings:\n```json\n" + `{
+  "recommendations": [
+    {"title": "Set ServerAliveInterval", "description": "Keeps idle sessions open.", "type": "Connection",
+     "priority": "low", "confidence": 70, "actions": ["ServerAliveInterval 60"]},
+    {"title": "Disable agent forwarding", "description": "Agent forwarding exposes your keys.", "type": "security",
+     "priority": "Critical", "confidence": "0.95", "actions": [{"action": "ForwardAgent no"}]},
+    {"description": "Use ControlMaster to reuse connections. It speeds up repeated logins.", "type": "perf",
+     "priority": "whenever", "actions": "ControlMaster auto"},
+  ]
+}` + "\n```"
+
+	recs := parseRecommendations(&aiDomain.AIResponse{Content: content, Model: "m"}, map[string]string{"Host": "web"}, testDefaults)
+	if len(recs) != 3 {
+		t.Fatalf("Expected 3 recommendations, got %d", len(recs))
+	}
+
+	first := recs[0]
+	if first.Title != "Disable agent forwarding" || first.Priority != aiDomain.PriorityCritical ||
+		first.Type != aiDomain.RecTypeSecurity || first.Confidence != 0.95 {
+		t.Errorf("Expected the critical item first, got %+v", first)
+	}
+	if len(first.Actions) != 1 || first.Actions[0] != "ForwardAgent no" {
+		t.Errorf("Unexpected actions: %v", first.Actions)
+	}
+
+	second := recs[1]
+	if second.Title != "Use ControlMaster to reuse connections" || second.Priority != aiDomain.PriorityMedium ||
+		second.Type != aiDomain.RecTypePerformance || second.Confidence != 0.8 {
+		t.Errorf("Expected defaults for the unknown priority and missing confidence, got %+v", second)
+	}
+	if len(second.Actions) != 1 || second.Actions[0] != "ControlMaster auto" {
+		t.Errorf("Expected a single string action to be accepted, got %v", second.Actions)
+	}
+
+	third := recs[2]
+	if third.Type != aiDomain.RecTypeConnection || third.Priority != aiDomain.PriorityLow || third.Confidence != 0.7 {
+		t.Errorf("Unexpected third recommendation: %+v", third)
+	}
+	if third.Metadata["structured"] != true || third.Metadata["model"] != "m" {
+		t.Errorf("Unexpected metadata: %v", third.Metadata)
+	}
+}
+
+func TestParseRecommendations_Fallback(t *testing.T) {
+	content := "You should disable password authentication."
+	recs := parseRecommendations(&aiDomain.AIResponse{Content: content}, nil, testDefaults)
+	if len(recs) != 1 {
+		t.Fatalf("Expected a single fallback recommendation, got %d", len(recs))
+	}
+	rec := recs[0]
+	if rec.Description != content || rec.Title != testDefaults.Title || rec.Priority != testDefaults.Priority {
+		t.Errorf("Unexpected fallback recommendation: %+v", rec)
+	}
+	if rec.Metadata["structured"] != false {
+		t.Error("Expected the fallback to be marked as unstructured")
+	}
+}
+
+func TestParseRecommendations_EmptyList(t *testing.T) {
+	recs := parseRecommendations(&aiDomain.AIResponse{Content: `{"recommendations": []}`}, nil, testDefaults)
+	if recs == nil || len(recs) != 0 {
+		t.Errorf("Expected an empty, non-nil list, got %v", recs)
+	}
+}
+
+func TestParseRecommendations_Changes(t *testing.T) {
+	content := `{"recommendations": [
+  {"title": "Keep sessions alive", "priority": "low", "changes": [
+    {"option": "serveraliveinterval", "value": 60, "reason": "idle NAT timeouts"},
+    {"option": "KeepAliveMagic", "value": "yes"},
+    {"option": "Alias", "value": "renamed"},
+    {"key": "ForwardAgent", "value": false},
+    {"option": "IdentityFile", "value": ["~/.ssh/id_ed25519", "~/.ssh/work"]}
+  ]},
+  {"title": "Drop the proxy", "changes": {"ProxyJump": null, "Compression": "yes"}}
+]}`
+
+	recs := parseRecommendations(&aiDomain.AIResponse{Content: content}, map[string]string{"Host": "web"}, testDefaults)
+	if len(recs) != 2 {
+		t.Fatalf("Expected 2 recommendations, got %d", len(recs))
+	}
+
+	want := []domain.ServerFieldChange{
+		{Option: "ServerAliveInterval", Value: "60", Reason: "idle NAT timeouts"},
+		{Option: "ForwardAgent", Value: "no"},
+		{Option: "IdentityFile", Value: "~/.ssh/id_ed25519, ~/.ssh/work"},
+	}
+	if !reflect.DeepEqual(recs[1].Changes, want) {
+		t.Errorf("Expected made-up and non-option fields to be dropped, got %+v", recs[1].Changes)
+	}
+
+	want = []domain.ServerFieldChange{
+		{Option: "Compression", Value: "yes"},
+		{Option: "ProxyJump", Value: ""},
+	}
+	if !reflect.DeepEqual(recs[0].Changes, want) {
+		t.Errorf("Expected an option-to-value object to be accepted, got %+v", recs[0].Changes)
+	}
+}
+
+func TestParseSearchResults(t *testing.T) {
+	servers := []map[string]string{
+		{"Host": "web-prod", "HostName": "10.0.0.1"},
+		{"Host": "db-prod", "HostName": "10.0.0.2"},
+		{"Host": "dev"},
+	}
+	content := "```json\n" + `{"matches": [
+  {"alias": "db-prod", "score": 0.6, "reason": "production database"},
+  {"alias": "WEB-PROD", "score": 92, "reason": "production web server"},
+  {"alias": "web-staging", "score": 0.99, "reason": "made up"},
+  {"alias": "db-prod", "score": 0.1},
+  "dev",
+]}` + "\n```"
+
+	results, err := parseSearchResults(&aiDomain.AIResponse{Content: content}, servers)
+	if err != nil {
+		t.Fatalf("parseSearchResults failed: %v", err)
+	}
+	want := []aiDomain.SearchResult{
+		{Alias: "web-prod", Score: 0.92, Reason: "production web server"},
+		{Alias: "db-prod", Score: 0.6, Reason: "production database"},
+		{Alias: "dev"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Unexpected results:\n got %+v\nwant %+v", results, want)
+	}
+}
+
+func TestParseSearchResults_NoMatches(t *testing.T) {
+	servers := []map[string]string{{"Host": "web"}}
+
+	results, err := parseSearchResults(&aiDomain.AIResponse{Content: `{"matches": []}`}, servers)
+	if err != nil || results == nil || len(results) != 0 {
+		t.Errorf("Expected an empty, non-nil list, got %v, %v", results, err)
+	}
+
+	if _, err := parseSearchResults(&aiDomain.AIResponse{Content: "web looks right"}, servers); err == nil {
+		t.Error("Expected an error for an answer that is not JSON")
+	}
+}
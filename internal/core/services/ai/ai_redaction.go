@@ -0,0 +1,64 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import "github.com/aryasoni98/wooak/internal/core/domain/ai"
+
+// redactRequest returns a copy of request with its prompt and conversation
+// redacted. The redactor is first seeded with the servers the prompt was
+// rendered from, so values a template loops over are caught as well.
+func redactRequest(redactor *ai.Redactor, request *ai.AIRequest) *ai.AIRequest {
+	for _, server := range request.Servers {
+		redactor.Seed(server)
+	}
+	redacted := *request
+	redacted.Prompt = redactor.Redact(request.Prompt)
+	if request.Messages != nil {
+		redacted.Messages = make([]ai.Message, len(request.Messages))
+		for i, message := range request.Messages {
+			message.Content = redactor.Redact(message.Content)
+			redacted.Messages[i] = message
+		}
+	}
+	return &redacted
+}
+
+// preview returns the prompt of request as it would be sent to the
+// configured provider.
+func (s *AIService) preview(config *ai.AIConfig, request *ai.AIRequest) string {
+	return redactRequest(ai.NewRedactor(config.RedactionLevel()), request).Prompt
+}
+
+// PreviewRecommendation returns the prompt GenerateRecommendation would send
+// for config and context, after redaction. Nothing is sent.
+func (s *AIService) PreviewRecommendation(config map[string]string, context string) string {
+	aiConfig := s.GetConfig()
+	return s.preview(aiConfig, s.recommendationRequest(aiConfig, config, context))
+}
+
+// PreviewSecurityAnalysis returns the prompt AnalyzeSecurity would send for
+// config, after redaction. Nothing is sent.
+func (s *AIService) PreviewSecurityAnalysis(config map[string]string) string {
+	aiConfig := s.GetConfig()
+	return s.preview(aiConfig, s.securityRequest(aiConfig, config))
+}
+
+// PreviewSearch returns the prompt NaturalLanguageSearch would send for
+// query, after redaction. Nothing is sent, so servers are not narrowed by the
+// search index and every one of them is included.
+func (s *AIService) PreviewSearch(query string, servers []map[string]string) string {
+	aiConfig := s.GetConfig()
+	return s.preview(aiConfig, s.searchRequest(aiConfig, query, servers))
+}
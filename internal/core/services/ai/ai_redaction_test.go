@@ -0,0 +1,134 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+var redactionTestConfig = map[string]string{
+	"Host":     "db-prod",
+	"HostName": "db1.corp.example.com",
+	"User":     "alice",
+}
+
+// redactionTestHost is the pseudonym of db1.corp.example.com.
+func redactionTestHost() string {
+	redactor := aiDomain.NewRedactor(aiDomain.RedactionPseudonymize)
+	redactor.Redact("HostName: db1.corp.example.com")
+	return redactor.Redact("db1.corp.example.com")
+}
+
+func TestAIService_GenerateRecommendation_Redacts(t *testing.T) {
+	host := redactionTestHost()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		for _, leaked := range []string{"db1.corp.example.com", "alice"} {
+			if strings.Contains(string(body), leaked) {
+				t.Errorf("Expected %q to be redacted from the request: %s", leaked, body)
+			}
+		}
+		answer, _ := json.Marshal(`{"recommendations":[{"title":"Pin the host key","description":"Add ` + host + ` to known_hosts"}]}`)
+		_, _ = w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"message":{"content":` + string(answer) + `}}]}`))
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOpenAI, server.URL)
+	defer service.Stop()
+
+	recs, err := service.GenerateRecommendation(context.Background(), redactionTestConfig, "host keys for alice?")
+	if err != nil {
+		t.Fatalf("GenerateRecommendation failed: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Description != "Add db1.corp.example.com to known_hosts" {
+		t.Errorf("Expected the pseudonym to be restored, got %+v", recs)
+	}
+}
+
+func TestAIService_StreamRequest_RestoresPseudonyms(t *testing.T) {
+	host := redactionTestHost()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Split the pseudonym across two events.
+		writeLines(t, w,
+			`data: {"choices":[{"delta":{"content":"Check `+host[:7]+`"}}]}`,
+			``,
+			`data: {"choices":[{"delta":{"content":"`+host[7:]+` now"}}]}`,
+			``,
+			`data: [DONE]`,
+		)
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOpenAI, server.URL)
+	defer service.Stop()
+
+	request := &aiDomain.AIRequest{ID: "req", Prompt: aiDomain.FormatServerConfig(redactionTestConfig)}
+	text, last := collect(t, service.StreamRequest(context.Background(), request))
+	if last.Error != nil {
+		t.Fatalf("Unexpected error: %v", last.Error)
+	}
+	want := "Check db1.corp.example.com now"
+	if text != want || last.Result.Content != want {
+		t.Errorf("Unexpected content: streamed %q, final %q", text, last.Result.Content)
+	}
+}
+
+func TestAIService_PreviewSecurityAnalysis(t *testing.T) {
+	config := aiDomain.DefaultAIConfig()
+	config.Provider = aiDomain.ProviderOpenAI
+	service := NewAIService(config)
+	defer service.Stop()
+
+	preview := service.PreviewSecurityAnalysis(redactionTestConfig)
+	if strings.Contains(preview, "db1.corp.example.com") || !strings.Contains(preview, redactionTestHost()) {
+		t.Errorf("Expected the preview to show the pseudonymized host:\n%s", preview)
+	}
+}
+
+func TestAIService_NewRequest_RedactsLoopingTemplate(t *testing.T) {
+	config := aiDomain.DefaultAIConfig()
+	config.Provider = aiDomain.ProviderOpenAI
+	config.Prompts = map[aiDomain.AIRequestType]string{aiDomain.RequestTypeSearch: "plain_list"}
+	service := NewAIService(config)
+	defer service.Stop()
+
+	service.SetPromptTemplates(map[string]*aiDomain.PromptTemplate{
+		"plain_list": {
+			Name:      "Plain List",
+			Type:      aiDomain.RequestTypeSearch,
+			Variables: []string{"query", "server_list"},
+			Template:  "{{.query}}\n{{range .server_list}}- {{.Host}} is {{.User}}@{{.HostName}}\n{{end}}",
+		},
+	})
+
+	preview := service.PreviewSearch("slow databases", []map[string]string{redactionTestConfig})
+	for _, leaked := range []string{"db1.corp.example.com", "alice"} {
+		if strings.Contains(preview, leaked) {
+			t.Errorf("Expected %q to be redacted from:\n%s", leaked, preview)
+		}
+	}
+	if !strings.Contains(preview, "- db-prod is user-") || !strings.Contains(preview, "@"+redactionTestHost()) {
+		t.Errorf("Expected pseudonyms in the looped servers:\n%s", preview)
+	}
+}
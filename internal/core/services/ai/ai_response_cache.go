@@ -0,0 +1,131 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"fmt"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// bytesPerMB converts AIConfig.DiskCacheMaxMB to bytes.
+const bytesPerMB = 1 << 20
+
+// SetDiskCache sets the cache that keeps answers across restarts when
+// AIConfig.DiskCache is enabled.
+func (s *AIService) SetDiskCache(cache *DiskCache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.diskCache = cache
+}
+
+func (s *AIService) getDiskCache() *DiskCache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.diskCache
+}
+
+// diskCacheKey extends key with what the in-memory cache gets from being
+// rebuilt on UpdateConfig: answers from another provider, model or prompt
+// template must not be served from disk.
+func (s *AIService) diskCacheKey(config *ai.AIConfig, requestType ai.AIRequestType, key string) string {
+	template := ""
+	if pt, _ := s.PromptTemplates().ForType(requestType, config.Prompts[requestType]); pt != nil {
+		template = generateHash(pt.Template)
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", config.Provider, config.Model, template, key)
+}
+
+// cacheLookup returns the answer cached under key, looking in memory first
+// and then on disk. Answers found on disk are kept in memory for next time.
+// Nothing is found while caching is disabled.
+func cacheLookup[T any](s *AIService, config *ai.AIConfig, requestType ai.AIRequestType, key string) (T, bool) {
+	var value T
+	if !config.CacheEnabled {
+		return value, false
+	}
+	cache := s.getCache()
+	if cached, exists := cache.Get(key); exists {
+		if value, ok := cached.(T); ok {
+			return value, true
+		}
+	}
+
+	disk := s.getDiskCache()
+	if !config.DiskCache || disk == nil {
+		return value, false
+	}
+	servers, ok := disk.Get(s.diskCacheKey(config, requestType, key), &value)
+	if !ok {
+		return value, false
+	}
+	cache.SetWithTags(key, value, servers)
+	return value, true
+}
+
+// cacheStore caches value under key when caching is enabled, in memory and,
+// if enabled, on disk. servers are the aliases the answer was based on (or
+// AllServers), whose changes invalidate it.
+func (s *AIService) cacheStore(config *ai.AIConfig, requestType ai.AIRequestType, key string, value interface{}, servers []string) {
+	if !config.CacheEnabled {
+		return
+	}
+	s.getCache().SetWithTags(key, value, servers)
+
+	disk := s.getDiskCache()
+	if !config.DiskCache || disk == nil {
+		return
+	}
+	err := disk.Set(s.diskCacheKey(config, requestType, key), value, servers, config.CacheTTL,
+		int64(config.DiskCacheMaxMB)*bytesPerMB)
+	if err != nil && s.logger != nil {
+		s.logger.Warnw("failed to write AI cache entry", "error", err)
+	}
+}
+
+// serverDependencies returns the aliases an answer about serverConfig is
+// based on.
+func serverDependencies(serverConfig map[string]string) []string {
+	if alias := serverConfig["Host"]; alias != "" {
+		return []string{alias}
+	}
+	return []string{AllServers}
+}
+
+// ServerSaved drops cached answers about a new or changed server, including
+// those under its previous alias, and those based on every server.
+func (s *AIService) ServerSaved(previousAlias string, server domain.Server) {
+	s.invalidateServers(previousAlias, server.Alias)
+}
+
+// ServerDeleted drops cached answers about a deleted server and those based
+// on every server.
+func (s *AIService) ServerDeleted(alias string) {
+	s.invalidateServers(alias)
+}
+
+func (s *AIService) invalidateServers(aliases ...string) {
+	var tags []string
+	for _, alias := range aliases {
+		if alias != "" {
+			tags = append(tags, alias)
+		}
+	}
+	s.getCache().DeleteTagged(append(tags, AllServers)...)
+	if disk := s.getDiskCache(); disk != nil {
+		disk.Invalidate(tags...)
+	}
+}
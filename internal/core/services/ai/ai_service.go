@@ -15,12 +15,12 @@
 package ai
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,14 +31,24 @@ import (
 
 // AIService provides AI-powered functionality for Wooak
 type AIService struct {
-	config      *ai.AIConfig
-	httpClient  *http.Client
-	cache       *AICache
-	pool        *ConnectionPool
+	// mu guards the configuration and the components built from it, which
+	// UpdateConfig replaces together.
+	mu           sync.RWMutex
+	config       *ai.AIConfig
+	httpClient   *http.Client
+	streamClient *http.Client
+	cache        *AICache
+	diskCache    *DiskCache
+	pool         *ConnectionPool
+	rateLimiter  *RateLimiter
+	searchIndex  *EmbeddingIndex
+	sessions     *SessionStore
+	usage        *UsageLedger
+	prompts      *ai.PromptLibrary
+
 	monitoring  *monitoring.MonitoringService
 	retryConfig *RetryConfig
 	logger      *zap.SugaredLogger
-	rateLimiter *RateLimiter
 }
 
 // NewAIService creates a new AI service
@@ -48,35 +58,41 @@ func NewAIService(config *ai.AIConfig) *AIService {
 
 // NewAIServiceWithLogger creates a new AI service with a logger instance
 func NewAIServiceWithLogger(config *ai.AIConfig, logger *zap.SugaredLogger) *AIService {
-	// Create connection pool configuration
-	poolConfig := &PoolConfig{
+	return &AIService{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: config.Timeout,
+		},
+		streamClient: newStreamClient(config),
+		cache:        NewAICache(config.CacheTTL),
+		pool:         newServicePool(config),
+		monitoring:   nil, // Will be set via SetMonitoring
+		retryConfig:  DefaultRetryConfig(),
+		logger:       logger,
+		rateLimiter:  newServiceRateLimiter(),
+		prompts:      ai.NewPromptLibrary(nil),
+	}
+}
+
+// newServicePool creates the connection pool for config
+func newServicePool(config *ai.AIConfig) *ConnectionPool {
+	return NewConnectionPool(&PoolConfig{
 		MaxConnections:        DefaultMaxConnections,
 		MaxIdleConns:          DefaultMaxIdleConns,
 		MaxConnsPerHost:       DefaultMaxConnsPerHost,
 		IdleConnTimeout:       DefaultIdleConnTimeout,
 		ResponseHeaderTimeout: DefaultResponseTimeout,
 		RequestTimeout:        config.Timeout,
-	}
+	})
+}
 
-	// Create rate limiter with default configuration
-	rateLimiter := NewRateLimiter(RateLimiterConfig{
+// newServiceRateLimiter creates a rate limiter with the default configuration
+func newServiceRateLimiter() *RateLimiter {
+	return NewRateLimiter(RateLimiterConfig{
 		MaxTokens:      DefaultRateLimitMaxTokens,
 		RefillRate:     DefaultRateLimitRefillRate,
 		BlockOnExhaust: DefaultRateLimitBlock,
 	})
-
-	return &AIService{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
-		cache:       NewAICache(config.CacheTTL),
-		pool:        NewConnectionPool(poolConfig),
-		monitoring:  nil, // Will be set via SetMonitoring
-		retryConfig: DefaultRetryConfig(),
-		logger:      logger,
-		rateLimiter: rateLimiter,
-	}
 }
 
 // SetMonitoring sets the monitoring service for the AI service
@@ -85,47 +101,29 @@ func (s *AIService) SetMonitoring(mon *monitoring.MonitoringService) {
 }
 
 // GenerateRecommendation generates AI recommendations for SSH configurations
-func (s *AIService) GenerateRecommendation(ctx context.Context, config map[string]string, context string) (*ai.AIRecommendation, error) {
+func (s *AIService) GenerateRecommendation(ctx context.Context, config map[string]string, context string) ([]*ai.AIRecommendation, error) {
 	start := time.Now()
+	aiConfig := s.GetConfig()
 
-	if !s.config.Enabled {
+	if !aiConfig.Enabled {
 		return nil, fmt.Errorf("AI service is disabled")
 	}
 
 	// Check cache first
-	cacheKey := fmt.Sprintf("recommendation:%s", generateHash(config))
-	if cached, exists := s.cache.Get(cacheKey); exists {
-		if rec, ok := cached.(*ai.AIRecommendation); ok {
-			if s.monitoring != nil {
-				s.monitoring.RecordCacheHit("ai_recommendation")
-				s.monitoring.RecordOperation("ai_recommendation", time.Since(start), true)
-			}
-			return rec, nil
+	cacheKey := fmt.Sprintf("recommendation:%s:%s", generateHash(config), generateHash(context))
+	if rec, exists := cacheLookup[[]*ai.AIRecommendation](s, aiConfig, ai.RequestTypeRecommendation, cacheKey); exists {
+		if s.monitoring != nil {
+			s.monitoring.RecordCacheHit("ai_recommendation")
+			s.monitoring.RecordOperation("ai_recommendation", time.Since(start), true)
 		}
+		return rec, nil
 	}
 
 	if s.monitoring != nil {
 		s.monitoring.RecordCacheMiss("ai_recommendation")
 	}
 
-	// Build prompt
-	template := ai.GetPromptForType(ai.RequestTypeRecommendation)
-	variables := map[string]string{
-		"config":  ai.FormatServerConfig(config),
-		"context": context,
-	}
-	prompt := template.BuildPrompt(variables)
-
-	// Make AI request
-	request := &ai.AIRequest{
-		ID:          generateRequestID(),
-		Type:        ai.RequestTypeRecommendation,
-		Prompt:      prompt,
-		Model:       s.config.Model,
-		MaxTokens:   s.config.MaxTokens,
-		Temperature: s.config.Temperature,
-		Timestamp:   time.Now(),
-	}
+	request := s.recommendationRequest(aiConfig, config, context)
 
 	response, err := s.makeAIRequest(ctx, request)
 	if err != nil {
@@ -135,13 +133,11 @@ func (s *AIService) GenerateRecommendation(ctx context.Context, config map[strin
 		return nil, fmt.Errorf("failed to generate recommendation: %w", err)
 	}
 
-	// Parse response into recommendation
+	// Parse response into recommendations
 	recommendation := s.parseRecommendation(response, config)
 
 	// Cache the result
-	if s.config.CacheEnabled {
-		s.cache.Set(cacheKey, recommendation)
-	}
+	s.cacheStore(aiConfig, ai.RequestTypeRecommendation, cacheKey, recommendation, serverDependencies(config))
 
 	if s.monitoring != nil {
 		s.monitoring.RecordOperation("ai_recommendation", time.Since(start), true)
@@ -154,444 +150,396 @@ func (s *AIService) GenerateRecommendation(ctx context.Context, config map[strin
 	return recommendation, nil
 }
 
-// NaturalLanguageSearch performs AI-powered natural language search
-func (s *AIService) NaturalLanguageSearch(ctx context.Context, query string, servers []map[string]string) ([]map[string]interface{}, error) {
-	if !s.config.Enabled {
+// NaturalLanguageSearch performs AI-powered natural language search and
+// returns the matching servers, best match first. Only aliases present in
+// servers are returned.
+func (s *AIService) NaturalLanguageSearch(ctx context.Context, query string, servers []map[string]string) ([]ai.SearchResult, error) {
+	config := s.GetConfig()
+
+	if !config.Enabled {
 		return nil, fmt.Errorf("AI service is disabled")
 	}
 
 	// Check cache first
-	cacheKey := fmt.Sprintf("search:%s", generateHash(query))
-	if cached, exists := s.cache.Get(cacheKey); exists {
-		if results, ok := cached.([]map[string]interface{}); ok {
-			return results, nil
-		}
+	cacheKey := fmt.Sprintf("search:%s:%s", generateHash(query), generateHash(servers))
+	if results, exists := cacheLookup[[]ai.SearchResult](s, config, ai.RequestTypeSearch, cacheKey); exists {
+		return results, nil
 	}
 
-	// Build prompt
-	template := ai.GetPromptForType(ai.RequestTypeSearch)
-	variables := map[string]string{
-		"query":   query,
-		"servers": ai.FormatServerList(servers),
-	}
-	prompt := template.BuildPrompt(variables)
-
-	// Make AI request
-	request := &ai.AIRequest{
-		ID:          generateRequestID(),
-		Type:        ai.RequestTypeSearch,
-		Prompt:      prompt,
-		Model:       s.config.Model,
-		MaxTokens:   s.config.MaxTokens,
-		Temperature: s.config.Temperature,
-		Timestamp:   time.Now(),
-	}
+	candidates := s.searchCandidates(ctx, config, query, servers)
+	request := s.searchRequest(config, query, candidates)
 
 	response, err := s.makeAIRequest(ctx, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform natural language search: %w", err)
 	}
 
-	// Parse response into search results
-	results := s.parseSearchResults(response)
+	results, err := parseSearchResults(response, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform natural language search: %w", err)
+	}
 
 	// Cache the result
-	if s.config.CacheEnabled {
-		s.cache.Set(cacheKey, results)
-	}
+	s.cacheStore(config, ai.RequestTypeSearch, cacheKey, results, []string{AllServers})
 
 	return results, nil
 }
 
+// searchCandidates narrows servers to the config.SearchTopK most similar to
+// query when a search index is set and there are more servers than that. If
+// the index cannot be used, every server is sent.
+func (s *AIService) searchCandidates(ctx context.Context, config *ai.AIConfig, query string, servers []map[string]string) []map[string]string {
+	index := s.getSearchIndex()
+	if index == nil || config.SearchTopK <= 0 || len(servers) <= config.SearchTopK {
+		return servers
+	}
+	top, err := index.TopK(ctx, query, servers, config.SearchTopK)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warnw("semantic search index unavailable, sending every server", "error", err, "servers", len(servers))
+		}
+		return servers
+	}
+	return top
+}
+
+// SetSearchIndex sets the index used to preselect the servers sent with a
+// natural language search.
+func (s *AIService) SetSearchIndex(index *EmbeddingIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searchIndex = index
+}
+
+func (s *AIService) getSearchIndex() *EmbeddingIndex {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.searchIndex
+}
+
 // AnalyzeSecurity performs AI-powered security analysis
-func (s *AIService) AnalyzeSecurity(ctx context.Context, config map[string]string) (*ai.AIRecommendation, error) {
-	if !s.config.Enabled {
+func (s *AIService) AnalyzeSecurity(ctx context.Context, config map[string]string) ([]*ai.AIRecommendation, error) {
+	aiConfig := s.GetConfig()
+
+	if !aiConfig.Enabled {
 		return nil, fmt.Errorf("AI service is disabled")
 	}
 
 	// Check cache first
 	cacheKey := fmt.Sprintf("security:%s", generateHash(config))
-	if cached, exists := s.cache.Get(cacheKey); exists {
-		if rec, ok := cached.(*ai.AIRecommendation); ok {
-			return rec, nil
-		}
-	}
-
-	// Build prompt
-	template := ai.GetPromptForType(ai.RequestTypeSecurity)
-	variables := map[string]string{
-		"config": ai.FormatServerConfig(config),
+	if rec, exists := cacheLookup[[]*ai.AIRecommendation](s, aiConfig, ai.RequestTypeSecurity, cacheKey); exists {
+		return rec, nil
 	}
-	prompt := template.BuildPrompt(variables)
 
-	// Make AI request
-	request := &ai.AIRequest{
-		ID:          generateRequestID(),
-		Type:        ai.RequestTypeSecurity,
-		Prompt:      prompt,
-		Model:       s.config.Model,
-		MaxTokens:   s.config.MaxTokens,
-		Temperature: s.config.Temperature,
-		Timestamp:   time.Now(),
-	}
+	request := s.securityRequest(aiConfig, config)
 
 	response, err := s.makeAIRequest(ctx, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze security: %w", err)
 	}
 
-	// Parse response into security recommendation
+	// Parse response into security recommendations
 	recommendation := s.parseSecurityAnalysis(response, config)
 
 	// Cache the result
-	if s.config.CacheEnabled {
-		s.cache.Set(cacheKey, recommendation)
-	}
+	s.cacheStore(aiConfig, ai.RequestTypeSecurity, cacheKey, recommendation, serverDependencies(config))
 
 	return recommendation, nil
 }
 
+// recommendationRequest builds the request for recommendations about the
+// server described by serverConfig.
+func (s *AIService) recommendationRequest(config *ai.AIConfig, serverConfig map[string]string, context string) *ai.AIRequest {
+	return s.newRequest(config, ai.RequestTypeRecommendation, map[string]interface{}{
+		"config":  ai.FormatServerConfig(serverConfig),
+		"context": context,
+		"server":  serverConfig,
+	})
+}
+
+// securityRequest builds the request for a security analysis of the server
+// described by serverConfig.
+func (s *AIService) securityRequest(config *ai.AIConfig, serverConfig map[string]string) *ai.AIRequest {
+	return s.newRequest(config, ai.RequestTypeSecurity, map[string]interface{}{
+		"config": ai.FormatServerConfig(serverConfig),
+		"server": serverConfig,
+	})
+}
+
+// searchRequest builds the request for a natural language search of servers.
+func (s *AIService) searchRequest(config *ai.AIConfig, query string, servers []map[string]string) *ai.AIRequest {
+	return s.newRequest(config, ai.RequestTypeSearch, map[string]interface{}{
+		"query":       query,
+		"servers":     ai.FormatServerList(servers),
+		"server_list": servers,
+	})
+}
+
+// SetPromptTemplates sets the user's prompt templates, which override
+// built-in ones of the same name and can be picked per request type with
+// AIConfig.Prompts. The templates must have been validated.
+func (s *AIService) SetPromptTemplates(templates map[string]*ai.PromptTemplate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts = ai.NewPromptLibrary(templates)
+}
+
+// PromptTemplates returns the prompt templates available to requests.
+func (s *AIService) PromptTemplates() *ai.PromptLibrary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.prompts
+}
+
+// newRequest builds a request of requestType from the template configured
+// for it. A template that cannot be used or fails to render falls back to the
+// built-in one, with a warning. The server configurations among variables
+// are kept on the request for redaction.
+func (s *AIService) newRequest(config *ai.AIConfig, requestType ai.AIRequestType, variables map[string]interface{}) *ai.AIRequest {
+	template, err := s.PromptTemplates().ForType(requestType, config.Prompts[requestType])
+	if err != nil && s.logger != nil {
+		s.logger.Warnw("using the default prompt template", "type", requestType, "error", err)
+	}
+	prompt, err := template.Render(variables)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warnw("prompt template failed to render, using the built-in one",
+				"template", template.Name, "source", template.Source, "error", err)
+		}
+		template = ai.GetPromptForType(requestType)
+		prompt, _ = template.Render(variables)
+	}
+	return &ai.AIRequest{
+		ID:          generateRequestID(),
+		Type:        requestType,
+		Prompt:      prompt,
+		Model:       config.Model,
+		MaxTokens:   config.MaxTokens,
+		Temperature: config.Temperature,
+		Format:      template.Format,
+		Servers:     templateServers(variables),
+		Timestamp:   time.Now(),
+	}
+}
+
+// templateServers returns the server configurations among the variables of a
+// prompt template, so they can be redacted wherever the template prints them.
+func templateServers(variables map[string]interface{}) []map[string]string {
+	var servers []map[string]string
+	if server, ok := variables["server"].(map[string]string); ok {
+		servers = append(servers, server)
+	}
+	if list, ok := variables["server_list"].([]map[string]string); ok {
+		servers = append(servers, list...)
+	}
+	return servers
+}
+
 // makeAIRequest makes a request to the AI provider with retry logic
 func (s *AIService) makeAIRequest(ctx context.Context, request *ai.AIRequest) (*ai.AIResponse, error) {
 	startTime := time.Now()
+	config, rateLimiter := s.GetConfig(), s.getRateLimiter()
+
+	// Check rate limit and budget before making request
+	if err := s.checkRateLimit(config, rateLimiter, request); err != nil {
+		return nil, err
+	}
+	if err := s.checkBudget(config, request); err != nil {
+		return nil, err
+	}
+
+	// Redact before the prompt leaves the process; pseudonyms in the answer
+	// are mapped back below.
+	redactor := ai.NewRedactor(config.RedactionLevel())
+	request = redactRequest(redactor, request)
 
-	// Check rate limit before making request
-	if !s.rateLimiter.Allow() {
+	var response *ai.AIResponse
+	provider, err := lookupProvider(config.Provider)
+	if err != nil {
 		if s.monitoring != nil {
-			s.monitoring.GetMetrics().IncrementCounter("ai_rate_limit_exceeded_total", map[string]string{
-				"provider": string(s.config.Provider),
-				"type":     string(request.Type),
+			s.monitoring.GetMetrics().IncrementCounter("ai_request_error_total", map[string]string{
+				"provider": string(config.Provider),
+				"reason":   "unsupported_provider",
 			})
 		}
-		if s.logger != nil {
-			s.logger.Warnw("AI request rate limited", "provider", s.config.Provider, "type", request.Type)
-		}
-		return nil, fmt.Errorf("rate limit exceeded for AI provider %s", s.config.Provider)
+		return nil, err
 	}
-
-	var response *ai.AIResponse
-	var err error
+	client := s.providerClient()
 
 	// Wrap the AI request in retry logic
 	retryErr := RetryWithBackoff(ctx, s.retryConfig, func() error {
-		switch s.config.Provider {
-		case ai.ProviderOllama:
-			response, err = s.makeOllamaRequest(ctx, request)
-		case ai.ProviderOpenAI:
-			response, err = s.makeOpenAIRequest(ctx, request)
-		default:
-			if s.monitoring != nil {
-				s.monitoring.GetMetrics().IncrementCounter("ai_request_error_total", map[string]string{
-					"provider": string(s.config.Provider),
-					"reason":   "unsupported_provider",
-				})
-			}
-			return fmt.Errorf("unsupported AI provider: %s", s.config.Provider)
-		}
+		response, err = provider.Complete(ctx, client, config, request)
 		return err
 	})
 
 	if retryErr != nil {
 		if s.monitoring != nil {
 			s.monitoring.GetMetrics().IncrementCounter("ai_request_error_total", map[string]string{
-				"provider": string(s.config.Provider),
+				"provider": string(config.Provider),
 				"type":     string(request.Type),
 			})
 		}
 		return nil, fmt.Errorf("AI request failed after retries: %w", retryErr)
 	}
 
+	response.Content = redactor.Restore(response.Content)
 	response.ProcessingTime = time.Since(startTime)
-
-	if s.monitoring != nil {
-		s.monitoring.GetMetrics().RecordTimer("ai_request_duration_seconds", response.ProcessingTime, map[string]string{
-			"provider": string(s.config.Provider),
-			"type":     string(request.Type),
-		})
-
-		// Record token usage if available (accumulated total)
-		if response.TokensUsed > 0 {
-			s.monitoring.GetMetrics().AddToCounter("ai_tokens_used_total", float64(response.TokensUsed), map[string]string{
-				"provider": string(s.config.Provider),
-				"type":     string(request.Type),
-			})
-		}
-	}
+	s.recordResponse(config, request, response)
 
 	return response, nil
 }
 
-// makeOllamaRequest makes a request to Ollama
-func (s *AIService) makeOllamaRequest(ctx context.Context, request *ai.AIRequest) (*ai.AIResponse, error) {
-	url := fmt.Sprintf("%s/api/generate", s.config.BaseURL)
-
-	payload := map[string]interface{}{
-		"model":  request.Model,
-		"prompt": request.Prompt,
-		"stream": false,
-		"options": map[string]interface{}{
-			"temperature": request.Temperature,
-			"num_predict": request.MaxTokens,
-		},
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal Ollama request (request_id: %s, model: %s): %w", request.ID, request.Model, err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request (request_id: %s, url: %s): %w", request.ID, url, err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	// Use connection pool for better performance
-	client := s.pool.GetClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make Ollama HTTP request (request_id: %s, url: %s): %w", request.ID, url, err)
-	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			// Log error but don't fail the request
-			if s.logger != nil {
-				s.logger.Warnw("Failed to close response body", "error", closeErr, "request_id", request.ID)
-			} else {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to close response body: %v\n", closeErr)
-			}
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Ollama API request failed (request_id: %s, status_code: %d, url: %s)", request.ID, resp.StatusCode, url)
+// checkRateLimit takes a token from rateLimiter for request
+func (s *AIService) checkRateLimit(config *ai.AIConfig, rateLimiter *RateLimiter, request *ai.AIRequest) error {
+	if rateLimiter.Allow() {
+		return nil
 	}
-
-	var ollamaResponse struct {
-		Response string `json:"response"`
-		Done     bool   `json:"done"`
+	if s.monitoring != nil {
+		s.monitoring.GetMetrics().IncrementCounter("ai_rate_limit_exceeded_total", map[string]string{
+			"provider": string(config.Provider),
+			"type":     string(request.Type),
+		})
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&ollamaResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode Ollama response (request_id: %s, status_code: %d): %w", request.ID, resp.StatusCode, err)
+	if s.logger != nil {
+		s.logger.Warnw("AI request rate limited", "provider", config.Provider, "type", request.Type)
 	}
-
-	return &ai.AIResponse{
-		ID:        generateResponseID(),
-		RequestID: request.ID,
-		Content:   ollamaResponse.Response,
-		Type:      request.Type,
-		Model:     request.Model,
-		Timestamp: time.Now(),
-	}, nil
+	return fmt.Errorf("rate limit exceeded for AI provider %s", config.Provider)
 }
 
-// makeOpenAIRequest makes a request to OpenAI Chat Completions API
-func (s *AIService) makeOpenAIRequest(ctx context.Context, request *ai.AIRequest) (*ai.AIResponse, error) {
-	// Validate API key is configured
-	if s.config.APIKey == "" {
-		return nil, fmt.Errorf("OpenAI API key not configured (request_id: %s). Please set APIKey in AIConfig", request.ID)
+// recordResponse records the duration and token usage of a completed request
+func (s *AIService) recordResponse(config *ai.AIConfig, request *ai.AIRequest, response *ai.AIResponse) {
+	s.recordUsage(config, request, response)
+	if s.monitoring == nil {
+		return
 	}
+	s.monitoring.GetMetrics().RecordTimer("ai_request_duration_seconds", response.ProcessingTime, map[string]string{
+		"provider": string(config.Provider),
+		"type":     string(request.Type),
+	})
 
-	// Determine base URL - use config if set, otherwise default
-	baseURL := s.config.BaseURL
-	if baseURL == "" {
-		baseURL = OpenAIBaseURL
-	}
-	url := fmt.Sprintf("%s%s", baseURL, OpenAIEndpoint)
-
-	// Determine model - use request model if set, otherwise config model, otherwise default
-	model := request.Model
-	if model == "" {
-		model = s.config.Model
-	}
-	if model == "" {
-		model = OpenAIDefaultModel
+	// Record token usage if available (accumulated total)
+	if response.TokensUsed > 0 {
+		s.monitoring.GetMetrics().AddToCounter("ai_tokens_used_total", float64(response.TokensUsed), map[string]string{
+			"provider": string(config.Provider),
+			"type":     string(request.Type),
+		})
 	}
+}
 
-	// Build OpenAI Chat Completions request payload
-	payload := map[string]interface{}{
-		"model": model,
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": request.Prompt,
-			},
-		},
-		"max_tokens":  request.MaxTokens,
-		"temperature": request.Temperature,
-		"stream":      false,
-	}
+// parseRecommendation parses AI response into recommendations
+func (s *AIService) parseRecommendation(response *ai.AIResponse, config map[string]string) []*ai.AIRecommendation {
+	return parseRecommendations(response, config, recommendationDefaults{
+		Type:       ai.RecTypeOptimization,
+		Priority:   ai.PriorityMedium,
+		Confidence: 0.8, // Default confidence
+		Title:      "AI-Generated Recommendation",
+	})
+}
 
-	// Use max_tokens from config if request doesn't specify
-	if request.MaxTokens == 0 {
-		payload["max_tokens"] = s.config.MaxTokens
-	}
+// parseSecurityAnalysis parses AI response into security recommendations
+func (s *AIService) parseSecurityAnalysis(response *ai.AIResponse, config map[string]string) []*ai.AIRecommendation {
+	return parseRecommendations(response, config, recommendationDefaults{
+		Type:       ai.RecTypeSecurity,
+		Priority:   ai.PriorityHigh,
+		Confidence: 0.9, // Higher confidence for security analysis
+		Title:      "Security Analysis",
+	})
+}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal OpenAI request (request_id: %s, model: %s): %w", request.ID, model, err)
-	}
+// GetConfig returns the current AI configuration. Treat it as read-only and
+// use UpdateConfig to change it.
+func (s *AIService) GetConfig() *ai.AIConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request (request_id: %s, url: %s): %w", request.ID, url, err)
+// UpdateConfig validates newConfig and switches the service to it. The cache,
+// connection pool, streaming client and rate limiter are rebuilt, so cached
+// answers from the old provider or model are dropped (the disk cache keys
+// answers by provider and model instead) and new timeouts take effect. Requests already in flight finish with the old components.
+func (s *AIService) UpdateConfig(newConfig *ai.AIConfig) error {
+	if err := newConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid AI configuration: %w", err)
 	}
+	config := *newConfig
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.APIKey))
+	s.mu.Lock()
+	oldCache, oldPool, oldStreamClient := s.cache, s.pool, s.streamClient
+	s.config = &config
+	s.httpClient = &http.Client{Timeout: config.Timeout}
+	s.streamClient = newStreamClient(&config)
+	s.cache = NewAICache(config.CacheTTL)
+	s.pool = newServicePool(&config)
+	s.rateLimiter = newServiceRateLimiter()
+	s.mu.Unlock()
 
-	// Use connection pool for better performance
-	client := s.pool.GetClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make OpenAI HTTP request (request_id: %s, url: %s): %w", request.ID, url, err)
-	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			// Log error but don't fail the request
-			if s.logger != nil {
-				s.logger.Warnw("Failed to close response body", "error", closeErr, "request_id", request.ID)
-			} else {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to close response body: %v\n", closeErr)
-			}
-		}
-	}()
+	oldCache.Stop()
+	oldPool.Close()
+	oldStreamClient.CloseIdleConnections()
 
-	// Handle non-200 status codes
-	if resp.StatusCode != http.StatusOK {
-		// Try to read error response body for better error messages
-		var errorResponse map[string]interface{}
-		_ = json.NewDecoder(resp.Body).Decode(&errorResponse)
-		if s.logger != nil {
-			s.logger.Errorw("OpenAI API request failed", "request_id", request.ID, "status_code", resp.StatusCode, "url", url, "response_body", errorResponse)
-		}
-		return nil, fmt.Errorf("OpenAI API request failed (request_id: %s, status_code: %d, url: %s)", request.ID, resp.StatusCode, url)
-	}
-
-	// Parse OpenAI response
-	var openAIResponse struct {
-		ID      string `json:"id"`
-		Object  string `json:"object"`
-		Created int64  `json:"created"`
-		Model   string `json:"model"`
-		Choices []struct {
-			Index   int `json:"index"`
-			Message struct {
-				Role    string `json:"role"`
-				Content string `json:"content"`
-			} `json:"message"`
-			FinishReason string `json:"finish_reason"`
-		} `json:"choices"`
-		Usage struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		} `json:"usage"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&openAIResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode OpenAI response (request_id: %s, status_code: %d): %w", request.ID, resp.StatusCode, err)
-	}
-
-	// Extract content from first choice
-	if len(openAIResponse.Choices) == 0 {
-		return nil, fmt.Errorf("OpenAI API returned no choices (request_id: %s)", request.ID)
-	}
-
-	content := openAIResponse.Choices[0].Message.Content
-	tokensUsed := openAIResponse.Usage.TotalTokens
-
-	return &ai.AIResponse{
-		ID:         generateResponseID(),
-		RequestID:  request.ID,
-		Content:    content,
-		Type:       request.Type,
-		Model:      openAIResponse.Model,
-		TokensUsed: tokensUsed,
-		Timestamp:  time.Now(),
-	}, nil
-}
-
-// parseRecommendation parses AI response into a recommendation
-func (s *AIService) parseRecommendation(response *ai.AIResponse, config map[string]string) *ai.AIRecommendation {
-	return &ai.AIRecommendation{
-		ID:          generateRecommendationID(),
-		Type:        ai.RecTypeOptimization,
-		Title:       "AI-Generated Recommendation",
-		Description: response.Content,
-		Confidence:  0.8, // Default confidence
-		Priority:    ai.PriorityMedium,
-		Timestamp:   time.Now(),
-		Metadata: map[string]interface{}{
-			"model":       response.Model,
-			"request_id":  response.RequestID,
-			"config_hash": generateHash(config),
-		},
+	if s.logger != nil {
+		s.logger.Infow("AI configuration updated", "provider", config.Provider, "model", config.Model, "enabled", config.Enabled)
 	}
+	return nil
 }
 
-// parseSearchResults parses AI response into search results
-func (s *AIService) parseSearchResults(response *ai.AIResponse) []map[string]interface{} {
-	// Simple parsing - in a real implementation, this would be more sophisticated
-	return []map[string]interface{}{
-		{
-			"content": response.Content,
-			"score":   0.8,
-			"model":   response.Model,
-		},
-	}
+func (s *AIService) getCache() *AICache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cache
 }
 
-// parseSecurityAnalysis parses AI response into security analysis
-func (s *AIService) parseSecurityAnalysis(response *ai.AIResponse, config map[string]string) *ai.AIRecommendation {
-	return &ai.AIRecommendation{
-		ID:          generateRecommendationID(),
-		Type:        ai.RecTypeSecurity,
-		Title:       "Security Analysis",
-		Description: response.Content,
-		Confidence:  0.9, // Higher confidence for security analysis
-		Priority:    ai.PriorityHigh,
-		Timestamp:   time.Now(),
-		Metadata: map[string]interface{}{
-			"model":       response.Model,
-			"request_id":  response.RequestID,
-			"config_hash": generateHash(config),
-		},
-	}
+func (s *AIService) getPool() *ConnectionPool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pool
 }
 
-// GetConfig returns the current AI configuration
-func (s *AIService) GetConfig() *ai.AIConfig {
-	return s.config
+func (s *AIService) getStreamClient() *http.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.streamClient
 }
 
-// UpdateConfig updates the AI configuration
-func (s *AIService) UpdateConfig(newConfig *ai.AIConfig) error {
-	s.config = newConfig
-	s.httpClient.Timeout = newConfig.Timeout
-	s.cache = NewAICache(newConfig.CacheTTL)
-	return nil
+func (s *AIService) getRateLimiter() *RateLimiter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rateLimiter
 }
 
-// TestConnection tests the connection to the AI provider
+// TestConnection checks that the provider can be reached and offers the
+// configured model, by listing its models. Providers that cannot list their
+// models are sent a short completion instead.
 func (s *AIService) TestConnection(ctx context.Context) error {
-	if !s.config.Enabled {
+	config := s.GetConfig()
+	if !config.Enabled {
 		return fmt.Errorf("AI service is disabled")
 	}
 
-	testRequest := &ai.AIRequest{
-		ID:        generateRequestID(),
-		Type:      ai.RequestTypeGeneral,
-		Prompt:    "Hello, this is a test message. Please respond with 'OK'.",
-		Model:     s.config.Model,
-		MaxTokens: 10,
-		Timestamp: time.Now(),
+	models, err := s.ListModels(ctx, config)
+	switch {
+	case errors.Is(err, ErrModelListUnsupported):
+		testRequest := &ai.AIRequest{
+			ID:        generateRequestID(),
+			Type:      ai.RequestTypeGeneral,
+			Prompt:    "Hello, this is a test message. Please respond with 'OK'.",
+			Model:     config.Model,
+			MaxTokens: 10,
+			Timestamp: time.Now(),
+		}
+		_, err = s.makeAIRequest(ctx, testRequest)
+		return err
+	case err != nil:
+		return err
+	case config.Model != "" && !hasModel(models, config.Model):
+		available := "none"
+		if len(models) > 0 {
+			available = strings.Join(models, ", ")
+		}
+		return fmt.Errorf("model %q is not available from %s (available: %s)", config.Model, config.Provider, available)
 	}
-
-	_, err := s.makeAIRequest(ctx, testRequest)
-	return err
+	return nil
 }
 
 // AsyncResult represents the result of an async operation
@@ -601,13 +549,13 @@ type AsyncResult[T any] struct {
 }
 
 // GenerateRecommendationAsync generates AI recommendations asynchronously
-func (s *AIService) GenerateRecommendationAsync(ctx context.Context, config map[string]string, context string) <-chan AsyncResult[*ai.AIRecommendation] {
-	result := make(chan AsyncResult[*ai.AIRecommendation], 1)
+func (s *AIService) GenerateRecommendationAsync(ctx context.Context, config map[string]string, context string) <-chan AsyncResult[[]*ai.AIRecommendation] {
+	result := make(chan AsyncResult[[]*ai.AIRecommendation], 1)
 
 	go func() {
 		defer close(result)
 		recommendation, err := s.GenerateRecommendation(ctx, config, context)
-		result <- AsyncResult[*ai.AIRecommendation]{
+		result <- AsyncResult[[]*ai.AIRecommendation]{
 			Result: recommendation,
 			Error:  err,
 		}
@@ -617,13 +565,13 @@ func (s *AIService) GenerateRecommendationAsync(ctx context.Context, config map[
 }
 
 // NaturalLanguageSearchAsync performs natural language search asynchronously
-func (s *AIService) NaturalLanguageSearchAsync(ctx context.Context, query string, servers []map[string]string) <-chan AsyncResult[[]map[string]interface{}] {
-	result := make(chan AsyncResult[[]map[string]interface{}], 1)
+func (s *AIService) NaturalLanguageSearchAsync(ctx context.Context, query string, servers []map[string]string) <-chan AsyncResult[[]ai.SearchResult] {
+	result := make(chan AsyncResult[[]ai.SearchResult], 1)
 
 	go func() {
 		defer close(result)
 		response, err := s.NaturalLanguageSearch(ctx, query, servers)
-		result <- AsyncResult[[]map[string]interface{}]{
+		result <- AsyncResult[[]ai.SearchResult]{
 			Result: response,
 			Error:  err,
 		}
@@ -633,13 +581,13 @@ func (s *AIService) NaturalLanguageSearchAsync(ctx context.Context, query string
 }
 
 // AnalyzeSecurityAsync analyzes security configurations asynchronously
-func (s *AIService) AnalyzeSecurityAsync(ctx context.Context, config map[string]string) <-chan AsyncResult[*ai.AIRecommendation] {
-	result := make(chan AsyncResult[*ai.AIRecommendation], 1)
+func (s *AIService) AnalyzeSecurityAsync(ctx context.Context, config map[string]string) <-chan AsyncResult[[]*ai.AIRecommendation] {
+	result := make(chan AsyncResult[[]*ai.AIRecommendation], 1)
 
 	go func() {
 		defer close(result)
 		response, err := s.AnalyzeSecurity(ctx, config)
-		result <- AsyncResult[*ai.AIRecommendation]{
+		result <- AsyncResult[[]*ai.AIRecommendation]{
 			Result: response,
 			Error:  err,
 		}
@@ -695,12 +643,12 @@ func (s *AIService) BatchProcessAsync(ctx context.Context, requests []ai.AIReque
 
 // GetConnectionPoolStats returns connection pool statistics
 func (s *AIService) GetConnectionPoolStats() map[string]interface{} {
-	return s.pool.Stats()
+	return s.getPool().Stats()
 }
 
 // CloseConnectionPool closes the connection pool
 func (s *AIService) CloseConnectionPool() {
-	s.pool.Close()
+	s.getPool().Close()
 }
 
 // Stop stops the AI service and cleans up resources with graceful shutdown
@@ -712,12 +660,14 @@ func (s *AIService) Stop() {
 	// Create a channel to signal when cleanup is done
 	done := make(chan struct{})
 
+	cache, pool, streamClient := s.getCache(), s.getPool(), s.getStreamClient()
 	go func() {
 		// Stop cache cleanup goroutines
-		s.cache.Stop()
+		cache.Stop()
 
 		// Close connection pool
-		s.pool.Close()
+		pool.Close()
+		streamClient.CloseIdleConnections()
 
 		close(done)
 	}()
@@ -16,6 +16,10 @@ package ai
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -71,6 +75,51 @@ func TestAIService_UpdateConfig(t *testing.T) {
 	}
 }
 
+func TestAIService_UpdateConfig_RebuildsComponents(t *testing.T) {
+	service := NewAIService(aiDomain.DefaultAIConfig())
+	defer service.Stop()
+
+	oldCache, oldPool, oldLimiter := service.cache, service.pool, service.rateLimiter
+	oldCache.Set("recommendation:a:b", "cached")
+
+	newConfig := aiDomain.DefaultAIConfig()
+	newConfig.Timeout = 5 * time.Second
+	if err := service.UpdateConfig(newConfig); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+
+	if service.cache == oldCache || service.pool == oldPool || service.rateLimiter == oldLimiter {
+		t.Error("Expected cache, connection pool and rate limiter to be rebuilt")
+	}
+	if service.cache.Size() != 0 {
+		t.Error("Expected the new cache to start empty")
+	}
+	if got := service.GetConnectionPoolStats()["request_timeout"]; got != 5.0 {
+		t.Errorf("Expected pool request timeout 5s, got %v", got)
+	}
+	if service.GetConfig() == newConfig {
+		t.Error("Expected UpdateConfig to keep its own copy of the config")
+	}
+}
+
+func TestAIService_UpdateConfig_RejectsInvalid(t *testing.T) {
+	config := aiDomain.DefaultAIConfig()
+	service := NewAIService(config)
+	defer service.Stop()
+
+	invalid := aiDomain.DefaultAIConfig()
+	invalid.MaxTokens = 0
+	err := service.UpdateConfig(invalid)
+
+	var fieldErr *aiDomain.ConfigError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "max_tokens" {
+		t.Fatalf("Expected a max_tokens ConfigError, got %v", err)
+	}
+	if service.GetConfig() != config {
+		t.Error("A rejected update must not change the config")
+	}
+}
+
 func TestAIService_GenerateRecommendation_Disabled(t *testing.T) {
 	config := aiDomain.DefaultAIConfig()
 	config.Enabled = false
@@ -190,6 +239,42 @@ func TestAIService_CacheIntegration(t *testing.T) {
 	}
 }
 
+func TestAIService_GenerateRecommendation_CacheKeyIncludesContext(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"response":"use ed25519 keys","done":true}`))
+	}))
+	defer server.Close()
+
+	config := aiDomain.DefaultAIConfig()
+	config.BaseURL = server.URL
+	service := NewAIService(config)
+	defer service.Stop()
+
+	ctx := context.Background()
+	serverConfig := map[string]string{"Host": "web", "User": "deploy"}
+
+	recs, err := service.GenerateRecommendation(ctx, serverConfig, "how do I harden this?")
+	if err != nil {
+		t.Fatalf("GenerateRecommendation failed: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Description != "use ed25519 keys" {
+		t.Errorf("Expected the model response as description, got %+v", recs)
+	}
+
+	if _, err := service.GenerateRecommendation(ctx, serverConfig, "how do I harden this?"); err != nil {
+		t.Fatalf("GenerateRecommendation failed: %v", err)
+	}
+	if _, err := service.GenerateRecommendation(ctx, serverConfig, "make it faster"); err != nil {
+		t.Fatalf("GenerateRecommendation failed: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected a cached answer for the repeated question only, got %d provider calls", got)
+	}
+}
+
 func TestAIService_ModelValidation(t *testing.T) {
 	// Test valid models
 	validModels := []string{
@@ -0,0 +1,300 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+const (
+	// streamBufferSize is how many chunks are queued before the reader waits
+	// for the consumer.
+	streamBufferSize = 64
+	// maxStreamLineSize bounds a single NDJSON or SSE line.
+	maxStreamLineSize = 1024 * 1024
+	// maxErrorBodySize bounds how much of an error response is read.
+	maxErrorBodySize = 4096
+)
+
+// errStreamStopped is returned by the stream readers when the consumer stops
+// accepting chunks.
+var errStreamStopped = errors.New("stream stopped")
+
+// StreamChunk is one event of a streamed AI response. Content holds the text
+// received since the previous chunk. The last chunk has Done set and carries
+// either the parsed Result or the Error that ended the stream.
+type StreamChunk[T any] struct {
+	Content string
+	Done    bool
+	Result  T
+	Error   error
+}
+
+// StreamRequest sends request to the configured provider and streams the
+// completion as it is generated. The last chunk carries the complete response.
+// Cancel ctx to stop mid-stream; the channel is closed either way.
+func (s *AIService) StreamRequest(ctx context.Context, request *ai.AIRequest) <-chan StreamChunk[*ai.AIResponse] {
+	out := make(chan StreamChunk[*ai.AIResponse], streamBufferSize)
+
+	go func() {
+		defer close(out)
+		response, err := s.streamAIRequest(ctx, request, func(delta string) bool {
+			return sendChunk(ctx, out, StreamChunk[*ai.AIResponse]{Content: delta})
+		})
+		sendChunk(ctx, out, StreamChunk[*ai.AIResponse]{Done: true, Result: response, Error: err})
+	}()
+
+	return out
+}
+
+// GenerateRecommendationStream is the streaming form of GenerateRecommendation
+func (s *AIService) GenerateRecommendationStream(ctx context.Context, config map[string]string, context string) <-chan StreamChunk[[]*ai.AIRecommendation] {
+	return s.GenerateRecommendationStreamWithHistory(ctx, nil, config, context)
+}
+
+// GenerateRecommendationStreamWithHistory is GenerateRecommendationStream for a
+// question asked within a conversation: history, the earlier turns, is sent
+// along so the model can refer to it. Answers that depend on history are not
+// cached.
+func (s *AIService) GenerateRecommendationStreamWithHistory(ctx context.Context, history []ai.Message, config map[string]string, context string) <-chan StreamChunk[[]*ai.AIRecommendation] {
+	aiConfig := s.GetConfig()
+	request := withHistory(aiConfig, s.PromptTemplates(), s.recommendationRequest(aiConfig, config, context), history)
+	var cacheKey string
+	if len(request.Messages) == 0 {
+		cacheKey = fmt.Sprintf("recommendation:%s:%s", generateHash(config), generateHash(context))
+	}
+
+	return streamRecommendation(ctx, s, aiConfig, "ai_recommendation", cacheKey, serverDependencies(config), request, func(response *ai.AIResponse) []*ai.AIRecommendation {
+		return s.parseRecommendation(response, config)
+	})
+}
+
+// AnalyzeSecurityStream is the streaming form of AnalyzeSecurity
+func (s *AIService) AnalyzeSecurityStream(ctx context.Context, config map[string]string) <-chan StreamChunk[[]*ai.AIRecommendation] {
+	return s.AnalyzeSecurityStreamWithHistory(ctx, nil, config)
+}
+
+// AnalyzeSecurityStreamWithHistory is AnalyzeSecurityStream within a
+// conversation; see GenerateRecommendationStreamWithHistory.
+func (s *AIService) AnalyzeSecurityStreamWithHistory(ctx context.Context, history []ai.Message, config map[string]string) <-chan StreamChunk[[]*ai.AIRecommendation] {
+	aiConfig := s.GetConfig()
+	request := withHistory(aiConfig, s.PromptTemplates(), s.securityRequest(aiConfig, config), history)
+	var cacheKey string
+	if len(request.Messages) == 0 {
+		cacheKey = fmt.Sprintf("security:%s", generateHash(config))
+	}
+
+	return streamRecommendation(ctx, s, aiConfig, "ai_security", cacheKey, serverDependencies(config), request, func(response *ai.AIResponse) []*ai.AIRecommendation {
+		return s.parseSecurityAnalysis(response, config)
+	})
+}
+
+// streamRecommendation streams request and parses the complete response into
+// recommendations, sharing the cache with the non-streaming methods. Cached
+// recommendations are sent as a single final chunk without content. An empty
+// cacheKey bypasses the cache; servers are the aliases the answer is based on.
+func streamRecommendation(ctx context.Context, s *AIService, config *ai.AIConfig, operation, cacheKey string, servers []string,
+	request *ai.AIRequest, parse func(*ai.AIResponse) []*ai.AIRecommendation,
+) <-chan StreamChunk[[]*ai.AIRecommendation] {
+	out := make(chan StreamChunk[[]*ai.AIRecommendation], streamBufferSize)
+
+	go func() {
+		defer close(out)
+		start := time.Now()
+
+		if !config.Enabled {
+			sendChunk(ctx, out, StreamChunk[[]*ai.AIRecommendation]{Done: true, Error: fmt.Errorf("AI service is disabled")})
+			return
+		}
+
+		if cacheKey != "" {
+			if recs, exists := cacheLookup[[]*ai.AIRecommendation](s, config, request.Type, cacheKey); exists {
+				if s.monitoring != nil {
+					s.monitoring.RecordCacheHit(operation)
+					s.monitoring.RecordOperation(operation, time.Since(start), true)
+				}
+				sendChunk(ctx, out, StreamChunk[[]*ai.AIRecommendation]{Done: true, Result: recs})
+				return
+			}
+			if s.monitoring != nil {
+				s.monitoring.RecordCacheMiss(operation)
+			}
+		}
+
+		response, err := s.streamAIRequest(ctx, request, func(delta string) bool {
+			return sendChunk(ctx, out, StreamChunk[[]*ai.AIRecommendation]{Content: delta})
+		})
+		if err != nil {
+			if s.monitoring != nil {
+				s.monitoring.RecordOperation(operation, time.Since(start), false)
+			}
+			sendChunk(ctx, out, StreamChunk[[]*ai.AIRecommendation]{Done: true, Error: err})
+			return
+		}
+
+		recs := parse(response)
+		if cacheKey != "" {
+			s.cacheStore(config, request.Type, cacheKey, recs, servers)
+		}
+		if s.monitoring != nil {
+			s.monitoring.RecordOperation(operation, time.Since(start), true)
+		}
+		sendChunk(ctx, out, StreamChunk[[]*ai.AIRecommendation]{Done: true, Result: recs})
+	}()
+
+	return out
+}
+
+// sendChunk sends chunk unless ctx is done first, and reports whether it was
+// sent.
+func sendChunk[T any](ctx context.Context, out chan<- StreamChunk[T], chunk StreamChunk[T]) bool {
+	select {
+	case out <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// streamAIRequest makes a streaming request to the AI provider, calling emit
+// with each piece of text as it arrives. Only opening the stream is retried;
+// once text has been emitted a retry would repeat it. A stream that breaks
+// off or is canceled still has its estimated usage recorded.
+func (s *AIService) streamAIRequest(ctx context.Context, request *ai.AIRequest, emit func(delta string) bool) (*ai.AIResponse, error) {
+	startTime := time.Now()
+	config, rateLimiter := s.GetConfig(), s.getRateLimiter()
+
+	if err := s.checkRateLimit(config, rateLimiter, request); err != nil {
+		return nil, err
+	}
+	if err := s.checkBudget(config, request); err != nil {
+		return nil, err
+	}
+
+	// Pseudonyms are restored in each piece of text before it is emitted.
+	redactor := ai.NewRedactor(config.RedactionLevel())
+	request = redactRequest(redactor, request)
+	restorer := redactor.NewStreamRestorer()
+	var received strings.Builder
+	restoringEmit := func(delta string) bool {
+		received.WriteString(delta)
+		if text := restorer.Write(delta); text != "" {
+			return emit(text)
+		}
+		return true
+	}
+
+	provider, err := lookupProvider(config.Provider)
+	if err != nil {
+		return nil, err
+	}
+	client := s.providerClient()
+
+	var resp *http.Response
+	retryErr := RetryWithBackoff(ctx, s.retryConfig, func() error {
+		var err error
+		resp, err = provider.OpenStream(ctx, client, config, request)
+		return err
+	})
+	if retryErr != nil {
+		if s.monitoring != nil {
+			s.monitoring.GetMetrics().IncrementCounter("ai_request_error_total", map[string]string{
+				"provider": string(config.Provider),
+				"type":     string(request.Type),
+			})
+		}
+		return nil, fmt.Errorf("AI stream failed after retries: %w", retryErr)
+	}
+	defer closeResponseBody(s.logger, resp, request.ID)
+
+	response, err := provider.ReadStream(resp.Body, request, restoringEmit)
+	if err != nil {
+		// The provider has processed the prompt and generated the text
+		// received so far; count an estimate of it toward the budget.
+		s.recordUsage(config, request, &ai.AIResponse{Content: received.String()})
+		// A canceled stream surfaces as a read error; report the cancellation.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+
+	if tail := restorer.Flush(); tail != "" {
+		emit(tail)
+	}
+	response.Content = redactor.Restore(response.Content)
+	response.ProcessingTime = time.Since(startTime)
+	s.recordResponse(config, request, response)
+	return response, nil
+}
+
+// providerErrorDetail extracts the error message from an error response body,
+// e.g. Ollama's {"error": "model not found"}, formatted for appending to an
+// error message.
+func providerErrorDetail(body io.Reader) string {
+	data, err := io.ReadAll(io.LimitReader(body, maxErrorBodySize))
+	if err != nil || len(data) == 0 {
+		return ""
+	}
+
+	var parsed struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &parsed) == nil && len(parsed.Error) > 0 {
+		var message string
+		if json.Unmarshal(parsed.Error, &message) == nil && message != "" {
+			return ": " + message
+		}
+		// OpenAI nests the message: {"error": {"message": "..."}}
+		var nested struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(parsed.Error, &nested) == nil && nested.Message != "" {
+			return ": " + nested.Message
+		}
+	}
+	return ": " + strings.TrimSpace(string(data))
+}
+
+// newStreamClient creates the HTTP client for streamed requests. It has no
+// overall timeout because a long answer can legitimately outlast
+// config.Timeout; the caller's context bounds the stream, and config.Timeout
+// bounds the wait for the provider to start answering (e.g. while Ollama
+// loads a model).
+func newStreamClient(config *ai.AIConfig) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:          DefaultMaxIdleConns,
+			MaxConnsPerHost:       DefaultMaxConnsPerHost,
+			IdleConnTimeout:       DefaultIdleConnTimeout,
+			ResponseHeaderTimeout: config.Timeout,
+			DialContext: (&net.Dialer{
+				Timeout:   DefaultDialTimeout,
+				KeepAlive: DefaultKeepAliveTimeout,
+			}).DialContext,
+			TLSHandshakeTimeout: DefaultTLSHandshakeTimeout,
+		},
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// writeLines writes each line to w and flushes it, like a streaming provider.
+func writeLines(t *testing.T, w http.ResponseWriter, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		_, _ = fmt.Fprintln(w, line)
+		w.(http.Flusher).Flush()
+	}
+}
+
+// collect reads chunks until the channel closes, returning the streamed text
+// and the final chunk.
+func collect[T any](t *testing.T, chunks <-chan StreamChunk[T]) (string, StreamChunk[T]) {
+	t.Helper()
+	var text strings.Builder
+	var last StreamChunk[T]
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return text.String(), last
+			}
+			text.WriteString(chunk.Content)
+			last = chunk
+		case <-timeout:
+			t.Fatal("Timed out waiting for the stream to finish")
+		}
+	}
+}
+
+func newStreamTestService(provider aiDomain.AIProvider, baseURL string) *AIService {
+	config := aiDomain.DefaultAIConfig()
+	config.Provider = provider
+	config.BaseURL = baseURL
+	config.APIKey = "test-key"
+	return NewAIService(config)
+}
+
+func TestAIService_StreamRequest_Ollama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		writeLines(t, w,
+			`{"model":"llama3.2:3b","response":"Use ","done":false}`,
+			`{"model":"llama3.2:3b","response":"ed25519","done":false}`,
+			``,
+			`{"model":"llama3.2:3b","response":"","done":true,"prompt_eval_count":12,"eval_count":3}`,
+		)
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOllama, server.URL)
+	defer service.Stop()
+
+	text, last := collect(t, service.StreamRequest(context.Background(), &aiDomain.AIRequest{ID: "req", Prompt: "keys?"}))
+	if last.Error != nil || !last.Done {
+		t.Fatalf("Expected a successful final chunk, got %+v", last)
+	}
+	if text != "Use ed25519" || last.Result.Content != "Use ed25519" {
+		t.Errorf("Unexpected content: streamed %q, final %q", text, last.Result.Content)
+	}
+	if last.Result.TokensUsed != 15 || last.Result.Model != "llama3.2:3b" {
+		t.Errorf("Unexpected response metadata: %+v", last.Result)
+	}
+}
+
+func TestAIService_StreamRequest_OpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Unexpected Authorization header %q", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeLines(t, w,
+			`: keep-alive`,
+			`data: {"model":"gpt-4o-mini","choices":[{"delta":{"role":"assistant"}}]}`,
+			``,
+			`data: {"model":"gpt-4o-mini","choices":[{"delta":{"content":"Disable "}}]}`,
+			``,
+			`data: {"model":"gpt-4o-mini","choices":[{"delta":{"content":"ForwardAgent"}}]}`,
+			``,
+			`data: {"model":"gpt-4o-mini","choices":[],"usage":{"total_tokens":42}}`,
+			``,
+			`data: [DONE]`,
+		)
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOpenAI, server.URL)
+	defer service.Stop()
+
+	text, last := collect(t, service.StreamRequest(context.Background(), &aiDomain.AIRequest{ID: "req", Prompt: "agent?"}))
+	if last.Error != nil {
+		t.Fatalf("Unexpected error: %v", last.Error)
+	}
+	if text != "Disable ForwardAgent" || last.Result.Content != text {
+		t.Errorf("Unexpected content: streamed %q, final %q", text, last.Result.Content)
+	}
+	if last.Result.TokensUsed != 42 || last.Result.Model != "gpt-4o-mini" {
+		t.Errorf("Unexpected response metadata: %+v", last.Result)
+	}
+}
+
+func TestAIService_StreamRequest_Cancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeLines(t, w, `{"response":"partial","done":false}`)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOllama, server.URL)
+	defer service.Stop()
+	service.SetUsageLedger(NewUsageLedger(filepath.Join(t.TempDir(), "usage.json"), nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks := service.StreamRequest(ctx, &aiDomain.AIRequest{ID: "req", Prompt: "slow"})
+
+	first := <-chunks
+	if first.Content != "partial" {
+		t.Fatalf("Expected the first chunk before canceling, got %+v", first)
+	}
+	cancel()
+
+	_, last := collect(t, chunks)
+	if last.Done && !errors.Is(last.Error, context.Canceled) {
+		t.Errorf("Expected a canceled stream, got %+v", last)
+	}
+	// The prompt and the partial answer still count toward the budget.
+	usage := service.Usage().DailyUsage(aiDomain.ProviderOllama, time.Now())
+	if usage.Requests != 1 || usage.PromptTokens == 0 || usage.CompletionTokens == 0 {
+		t.Errorf("Expected estimated usage for the canceled stream, got %+v", usage)
+	}
+}
+
+func TestAIService_StreamRequest_ProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model \"llama9\" not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOllama, server.URL)
+	defer service.Stop()
+
+	_, last := collect(t, service.StreamRequest(context.Background(), &aiDomain.AIRequest{ID: "req"}))
+	if last.Error == nil || !strings.Contains(last.Error.Error(), "try pulling it first") {
+		t.Errorf("Expected the provider's error message, got %v", last.Error)
+	}
+}
+
+func TestAIService_GenerateRecommendationStream_UsesCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeLines(t, w,
+			`{"response":"Use a bastion","done":false}`,
+			`{"response":"","done":true}`,
+		)
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOllama, server.URL)
+	defer service.Stop()
+
+	config := map[string]string{"Host": "web"}
+	for i := 0; i < 2; i++ {
+		_, last := collect(t, service.GenerateRecommendationStream(context.Background(), config, "jump hosts?"))
+		if last.Error != nil || len(last.Result) != 1 {
+			t.Fatalf("Call %d: expected a recommendation, got %+v", i, last)
+		}
+		if last.Result[0].Description != "Use a bastion" {
+			t.Errorf("Call %d: unexpected description %q", i, last.Result[0].Description)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected the second call to be served from cache, got %d provider calls", got)
+	}
+
+	// The streaming and non-streaming forms share the cache.
+	if _, err := service.GenerateRecommendation(context.Background(), config, "jump hosts?"); err != nil {
+		t.Fatalf("GenerateRecommendation failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected GenerateRecommendation to reuse the streamed result, got %d provider calls", got)
+	}
+}
+
+func TestAIService_AnalyzeSecurityStream_Disabled(t *testing.T) {
+	config := aiDomain.DefaultAIConfig()
+	config.Enabled = false
+	service := NewAIService(config)
+	defer service.Stop()
+
+	_, last := collect(t, service.AnalyzeSecurityStream(context.Background(), map[string]string{"Host": "web"}))
+	if last.Error == nil || last.Error.Error() != "AI service is disabled" {
+		t.Errorf("Expected a disabled error, got %v", last.Error)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// ErrTokenBudgetExceeded is returned for requests refused because the
+// provider's token budget is used up.
+var ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+// SetUsageLedger sets the ledger that records token usage and enforces the
+// budgets in AIConfig.Budgets. Without one, usage is only exported as
+// metrics and budgets are not enforced.
+func (s *AIService) SetUsageLedger(ledger *UsageLedger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage = ledger
+}
+
+// Usage returns the token usage ledger, or nil if none is set.
+func (s *AIService) Usage() *UsageLedger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.usage
+}
+
+// BudgetStatus returns why the configured provider's token budget is used
+// up, or "" when it is not (or no ledger is set).
+func (s *AIService) BudgetStatus() string {
+	return s.budgetExceeded(s.GetConfig())
+}
+
+// budgetExceeded returns why config's provider's token budget is used up,
+// or "".
+func (s *AIService) budgetExceeded(config *ai.AIConfig) string {
+	ledger := s.Usage()
+	budget, ok := config.Budgets[config.Provider]
+	if !ok || ledger == nil {
+		return ""
+	}
+	now := time.Now()
+	return budget.Exceeded(ledger.DailyUsage(config.Provider, now), ledger.MonthlyUsage(config.Provider, now))
+}
+
+// checkBudget refuses request when the provider's token budget is used up
+// and set to refuse, and otherwise only warns.
+func (s *AIService) checkBudget(config *ai.AIConfig, request *ai.AIRequest) error {
+	reason := s.budgetExceeded(config)
+	if reason == "" {
+		return nil
+	}
+	budget := config.Budgets[config.Provider]
+	action := ai.BudgetWarn
+	if budget.Refuses() {
+		action = ai.BudgetRefuse
+	}
+	if s.monitoring != nil {
+		s.monitoring.GetMetrics().IncrementCounter("ai_budget_exceeded_total", map[string]string{
+			"provider": string(config.Provider),
+			"action":   string(action),
+		})
+	}
+	if budget.Refuses() {
+		return fmt.Errorf("%w for AI provider %s: %s", ErrTokenBudgetExceeded, config.Provider, reason)
+	}
+	if s.logger != nil {
+		s.logger.Warnw("AI token budget exceeded", "provider", config.Provider, "type", request.Type, "reason", reason)
+	}
+	return nil
+}
+
+// recordUsage adds the tokens of a completed request to the ledger and the
+// token counters.
+func (s *AIService) recordUsage(config *ai.AIConfig, request *ai.AIRequest, response *ai.AIResponse) {
+	usage := responseUsage(request, response)
+	if ledger := s.Usage(); ledger != nil {
+		if err := ledger.Record(config.Provider, request.Model, request.Type, usage); err != nil && s.logger != nil {
+			s.logger.Warnw("failed to record AI token usage", "error", err)
+		}
+	}
+	if s.monitoring == nil {
+		return
+	}
+	labels := map[string]string{
+		"provider": string(config.Provider),
+		"model":    request.Model,
+		"type":     string(request.Type),
+	}
+	metrics := s.monitoring.GetMetrics()
+	metrics.AddToCounter("ai_prompt_tokens_total", float64(usage.PromptTokens), labels)
+	metrics.AddToCounter("ai_completion_tokens_total", float64(usage.CompletionTokens), labels)
+}
+
+// responseUsage returns the tokens used by request. Counts the provider did
+// not report are estimated from the text.
+func responseUsage(request *ai.AIRequest, response *ai.AIResponse) ai.TokenUsage {
+	usage := ai.TokenUsage{
+		PromptTokens:     response.PromptTokens,
+		CompletionTokens: response.CompletionTokens,
+		Requests:         1,
+	}
+	if usage.Total() > 0 {
+		return usage
+	}
+
+	completion := ai.EstimateTokens(response.Content)
+	if response.TokensUsed > 0 {
+		// Only a total was reported: attribute the rest to the prompt.
+		usage.CompletionTokens = min(completion, response.TokensUsed)
+		usage.PromptTokens = response.TokensUsed - usage.CompletionTokens
+		return usage
+	}
+	usage.CompletionTokens = completion
+	usage.PromptTokens = ai.EstimateTokens(request.Prompt)
+	for _, message := range request.Messages {
+		usage.PromptTokens += ai.EstimateTokens(message.Content)
+	}
+	return usage
+}
@@ -0,0 +1,152 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"github.com/aryasoni98/wooak/internal/core/services/monitoring"
+)
+
+func TestAIService_RecordsTokenUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"response":"ok","done":true,"prompt_eval_count":30,"eval_count":12}`))
+	}))
+	defer server.Close()
+
+	config := aiDomain.DefaultAIConfig()
+	config.BaseURL = server.URL
+	service := NewAIService(config)
+	defer service.Stop()
+	mon := monitoring.NewMonitoringService()
+	service.SetMonitoring(mon)
+	service.SetUsageLedger(NewUsageLedger(filepath.Join(t.TempDir(), "usage.json"), nil))
+
+	if _, err := service.GenerateRecommendation(context.Background(), map[string]string{"Host": "web"}, ""); err != nil {
+		t.Fatalf("GenerateRecommendation failed: %v", err)
+	}
+
+	usage := service.Usage().DailyUsage(aiDomain.ProviderOllama, time.Now())
+	if usage.PromptTokens != 30 || usage.CompletionTokens != 12 || usage.Requests != 1 {
+		t.Errorf("Unexpected recorded usage %+v", usage)
+	}
+	labels := map[string]string{"provider": "ollama", "model": config.Model, "type": "recommendation"}
+	if metric, ok := mon.GetMetrics().GetMetric("ai_prompt_tokens_total", labels); !ok || metric.Value != 30 {
+		t.Errorf("Expected ai_prompt_tokens_total of 30, got %+v", metric)
+	}
+	if metric, ok := mon.GetMetrics().GetMetric("ai_completion_tokens_total", labels); !ok || metric.Value != 12 {
+		t.Errorf("Expected ai_completion_tokens_total of 12, got %+v", metric)
+	}
+}
+
+func TestAIService_TokenBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"response":"ok","done":true,"prompt_eval_count":80,"eval_count":40}`))
+	}))
+	defer server.Close()
+
+	for _, action := range []aiDomain.BudgetAction{aiDomain.BudgetRefuse, aiDomain.BudgetWarn} {
+		t.Run(string(action), func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			config := aiDomain.DefaultAIConfig()
+			config.BaseURL = server.URL
+			config.CacheEnabled = false
+			config.Budgets = map[aiDomain.AIProvider]aiDomain.TokenBudget{
+				aiDomain.ProviderOllama: {Daily: 100, Action: action},
+			}
+			service := NewAIService(config)
+			defer service.Stop()
+			service.SetUsageLedger(NewUsageLedger(filepath.Join(t.TempDir(), "usage.json"), nil))
+
+			ctx := context.Background()
+			if _, err := service.AnalyzeSecurity(ctx, map[string]string{"Host": "web"}); err != nil {
+				t.Fatalf("First request failed: %v", err)
+			}
+			if service.BudgetStatus() == "" {
+				t.Error("Expected the budget to be reported as used up")
+			}
+
+			_, err := service.AnalyzeSecurity(ctx, map[string]string{"Host": "web"})
+			if action == aiDomain.BudgetRefuse {
+				if got := atomic.LoadInt32(&calls); !errors.Is(err, ErrTokenBudgetExceeded) || got != 1 {
+					t.Errorf("Expected the request to be refused, got %v after %d calls", err, got)
+				}
+				_, last := collect(t, service.AnalyzeSecurityStream(ctx, map[string]string{"Host": "db"}))
+				if !errors.Is(last.Error, ErrTokenBudgetExceeded) {
+					t.Errorf("Expected the stream to be refused, got %v", last.Error)
+				}
+			} else if got := atomic.LoadInt32(&calls); err != nil || got != 2 {
+				t.Errorf("Expected the request to go through with a warning, got %v after %d calls", err, got)
+			}
+		})
+	}
+}
+
+func TestAIService_Embed_UsageAndBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"embedding":[1,0]}`))
+	}))
+	defer server.Close()
+
+	config := aiDomain.DefaultAIConfig()
+	config.BaseURL = server.URL
+	config.Budgets = map[aiDomain.AIProvider]aiDomain.TokenBudget{
+		aiDomain.ProviderOllama: {Daily: 2, Action: aiDomain.BudgetRefuse},
+	}
+	service := NewAIService(config)
+	defer service.Stop()
+	service.SetUsageLedger(NewUsageLedger(filepath.Join(t.TempDir(), "usage.json"), nil))
+
+	if _, err := service.Embed(context.Background(), []string{"web-1 10.0.0.1", "db-prod 10.0.1.5"}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	usage := service.Usage().DailyUsage(aiDomain.ProviderOllama, time.Now())
+	if usage.PromptTokens == 0 || usage.Requests != 1 {
+		t.Errorf("Expected the embedding call to be recorded, got %+v", usage)
+	}
+
+	if _, err := service.Embed(context.Background(), []string{"web-1"}); !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Errorf("Expected the budget to refuse further embeddings, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected 2 provider calls for the first two texts only, got %d", got)
+	}
+}
+
+func TestResponseUsage_Estimates(t *testing.T) {
+	request := &aiDomain.AIRequest{Prompt: "12345678", Messages: []aiDomain.Message{{Content: "1234"}}}
+
+	usage := responseUsage(request, &aiDomain.AIResponse{Content: "1234"})
+	if usage.PromptTokens != 3 || usage.CompletionTokens != 1 || usage.Requests != 1 {
+		t.Errorf("Expected estimated usage, got %+v", usage)
+	}
+
+	usage = responseUsage(request, &aiDomain.AIResponse{Content: "1234", TokensUsed: 50})
+	if usage.PromptTokens != 49 || usage.CompletionTokens != 1 {
+		t.Errorf("Expected the reported total to be split, got %+v", usage)
+	}
+}
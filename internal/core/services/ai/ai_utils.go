@@ -57,6 +57,17 @@ func generateRecommendationID() string {
 	return fmt.Sprintf("rec_%d_%d", now, randNum.Int64())
 }
 
+// generateSessionID generates a unique conversation ID, safe as a file name
+func generateSessionID() string {
+	now := time.Now().UnixNano()
+	randNum, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		// Fallback to timestamp-based ID if crypto random fails
+		return fmt.Sprintf("session_%d_%d", now, now%10000)
+	}
+	return fmt.Sprintf("session_%d_%d", now, randNum.Int64())
+}
+
 // generateHash generates a hash for caching purposes
 func generateHash(data interface{}) string {
 	hash := sha256.Sum256([]byte(fmt.Sprintf("%v", data)))
@@ -88,7 +99,8 @@ func IsOllamaRunning(baseURL string) bool {
 	return resp.StatusCode == http.StatusOK
 }
 
-// GetAvailableModels returns a list of available AI models
+// GetAvailableModels returns well-known Ollama models, suggested when the
+// provider cannot be asked for its models (see AIService.ListModels).
 func GetAvailableModels() []string {
 	return []string{
 		"llama3.2:3b",
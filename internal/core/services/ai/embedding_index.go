@@ -0,0 +1,300 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"go.uber.org/zap"
+)
+
+const (
+	// embeddingIndexVersion is the schema version of the index file.
+	embeddingIndexVersion = 1
+	// DefaultIndexUpdateTimeout bounds embedding a server after it is saved.
+	DefaultIndexUpdateTimeout = 30 * time.Second
+	// embeddingChunkSize is how many servers are embedded per request; the
+	// index is saved after each, so a timeout keeps the finished ones.
+	embeddingChunkSize = 32
+)
+
+// embeddingFields are the server summary fields that are embedded, in order.
+var embeddingFields = []string{"Host", "HostName", "User", "Tags"}
+
+// Embedder turns texts into embedding vectors.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	EmbeddingModel() string
+}
+
+// embeddingEntry is the indexed embedding of one server. Hash identifies the
+// text that was embedded so unchanged servers are never embedded again.
+type embeddingEntry struct {
+	Hash   string    `json:"hash"`
+	Vector []float32 `json:"vector"`
+}
+
+// embeddingIndexFile is the on-disk form of the index.
+type embeddingIndexFile struct {
+	Version int                       `json:"version"`
+	Model   string                    `json:"model"`
+	Entries map[string]embeddingEntry `json:"entries"`
+}
+
+// EmbeddingIndex keeps an embedding per server, keyed by alias and persisted
+// to a JSON file, to preselect the servers sent with a natural language
+// search. It is updated incrementally: as a ports.ServerChangeListener when
+// servers are saved or deleted, and at query time for servers that changed
+// outside wooak. Switching embedding models discards the index.
+type EmbeddingIndex struct {
+	path     string
+	embedder Embedder
+	logger   *zap.SugaredLogger
+
+	mu      sync.Mutex
+	loaded  bool
+	model   string
+	entries map[string]embeddingEntry
+}
+
+// NewEmbeddingIndex creates an index stored at path. The file is read on
+// first use.
+func NewEmbeddingIndex(embedder Embedder, path string, logger *zap.SugaredLogger) *EmbeddingIndex {
+	return &EmbeddingIndex{
+		path:     path,
+		embedder: embedder,
+		logger:   logger,
+		entries:  make(map[string]embeddingEntry),
+	}
+}
+
+// TopK returns up to k of servers, most similar to query first, by cosine
+// similarity between their embeddings. Servers missing from the index or
+// changed since they were embedded are embedded first.
+func (x *EmbeddingIndex) TopK(ctx context.Context, query string, servers []map[string]string, k int) ([]map[string]string, error) {
+	if err := x.sync(ctx, servers); err != nil {
+		return nil, err
+	}
+	vectors, err := x.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed search query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed search query: got %d vectors", len(vectors))
+	}
+	queryVector := vectors[0]
+
+	type scored struct {
+		server map[string]string
+		score  float64
+	}
+	x.mu.Lock()
+	ranked := make([]scored, 0, len(servers))
+	for _, server := range servers {
+		if entry, ok := x.entries[server["Host"]]; ok {
+			ranked = append(ranked, scored{server: server, score: cosineSimilarity(queryVector, entry.Vector)})
+		}
+	}
+	x.mu.Unlock()
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+	top := make([]map[string]string, 0, len(ranked))
+	for _, r := range ranked {
+		top = append(top, r.server)
+	}
+	return top, nil
+}
+
+// ServerSaved embeds a new or changed server in the background and drops the
+// entry of its previous alias after a rename.
+func (x *EmbeddingIndex) ServerSaved(previousAlias string, server domain.Server) {
+	summary := ai.ServerSummary(server)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultIndexUpdateTimeout)
+		defer cancel()
+		if previousAlias != "" && previousAlias != server.Alias {
+			x.remove(previousAlias)
+		}
+		if err := x.sync(ctx, []map[string]string{summary}); err != nil {
+			x.logWarn("failed to update embedding index", "error", err, "alias", server.Alias)
+		}
+	}()
+}
+
+// ServerDeleted removes a deleted server from the index.
+func (x *EmbeddingIndex) ServerDeleted(alias string) {
+	x.remove(alias)
+}
+
+// sync embeds the servers whose text is not indexed yet, in chunks, and
+// saves the index after each one, so servers embedded before an error or
+// timeout need not be embedded again.
+func (x *EmbeddingIndex) sync(ctx context.Context, servers []map[string]string) error {
+	model := x.embedder.EmbeddingModel()
+
+	x.mu.Lock()
+	x.load(model)
+	var aliases, texts, hashes []string
+	for _, server := range servers {
+		alias := server["Host"]
+		if alias == "" {
+			continue
+		}
+		text := embeddingText(server)
+		hash := generateHash(text)
+		if entry, ok := x.entries[alias]; ok && entry.Hash == hash {
+			continue
+		}
+		aliases, texts, hashes = append(aliases, alias), append(texts, text), append(hashes, hash)
+	}
+	x.mu.Unlock()
+
+	for start := 0; start < len(texts); start += embeddingChunkSize {
+		end := min(start+embeddingChunkSize, len(texts))
+		if err := x.embedChunk(ctx, model, aliases[start:end], texts[start:end], hashes[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// embedChunk embeds texts and saves their vectors under aliases.
+func (x *EmbeddingIndex) embedChunk(ctx context.Context, model string, aliases, texts, hashes []string) error {
+	vectors, err := x.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embed %d server(s): %w", len(texts), err)
+	}
+	if len(vectors) != len(texts) {
+		return fmt.Errorf("embed %d server(s): got %d vectors", len(texts), len(vectors))
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.model != model {
+		return nil // the model changed while embedding; these vectors are stale
+	}
+	for i, alias := range aliases {
+		x.entries[alias] = embeddingEntry{Hash: hashes[i], Vector: vectors[i]}
+	}
+	return x.save()
+}
+
+func (x *EmbeddingIndex) remove(alias string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.load(x.embedder.EmbeddingModel())
+	if _, ok := x.entries[alias]; !ok {
+		return
+	}
+	delete(x.entries, alias)
+	if err := x.save(); err != nil {
+		x.logWarn("failed to save embedding index", "error", err, "alias", alias)
+	}
+}
+
+// load reads the index file once and discards entries made with a model
+// other than model (called with x.mu held).
+func (x *EmbeddingIndex) load(model string) {
+	if !x.loaded {
+		x.loaded = true
+		data, err := os.ReadFile(x.path)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			x.logWarn("failed to read embedding index", "error", err, "path", x.path)
+		default:
+			var file embeddingIndexFile
+			if err := json.Unmarshal(data, &file); err != nil || file.Version != embeddingIndexVersion {
+				x.logWarn("ignoring unreadable embedding index", "error", err, "path", x.path)
+			} else if file.Entries != nil {
+				x.model, x.entries = file.Model, file.Entries
+			}
+		}
+	}
+	if x.model != model {
+		x.model = model
+		x.entries = make(map[string]embeddingEntry)
+	}
+}
+
+// save writes the index with a write-temp-then-rename (called with x.mu
+// held).
+func (x *EmbeddingIndex) save() error {
+	if err := os.MkdirAll(filepath.Dir(x.path), 0o750); err != nil {
+		return fmt.Errorf("ensure embedding index directory for '%s': %w", x.path, err)
+	}
+	data, err := json.Marshal(embeddingIndexFile{Version: embeddingIndexVersion, Model: x.model, Entries: x.entries})
+	if err != nil {
+		return fmt.Errorf("marshal embedding index: %w", err)
+	}
+	tempFile := x.path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0o600); err != nil {
+		return fmt.Errorf("write temporary embedding index '%s': %w", tempFile, err)
+	}
+	if err := os.Rename(tempFile, x.path); err != nil {
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("rename temporary embedding index '%s' to '%s': %w", tempFile, x.path, err)
+	}
+	return nil
+}
+
+func (x *EmbeddingIndex) logWarn(msg string, keysAndValues ...interface{}) {
+	if x.logger != nil {
+		x.logger.Warnw(msg, keysAndValues...)
+	}
+}
+
+// embeddingText is the text embedded for a server summary.
+func embeddingText(server map[string]string) string {
+	var b strings.Builder
+	for _, key := range embeddingFields {
+		if value := server[key]; value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	}
+	return b.String()
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 for
+// vectors of different lengths or zero vectors.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
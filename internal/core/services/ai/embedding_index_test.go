@@ -0,0 +1,256 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// fakeEmbedder embeds a text as the number of times each of a few words
+// occurs in it, and records every text it was asked to embed.
+type fakeEmbedder struct {
+	mu       sync.Mutex
+	model    string
+	embedded []string
+	// failAfter makes Embed fail once this many texts were embedded; zero
+	// never fails.
+	failAfter int
+}
+
+var fakeEmbeddingWords = []string{"prod", "staging", "db", "web"}
+
+func (f *fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failAfter > 0 && len(f.embedded) >= f.failAfter {
+		return nil, context.DeadlineExceeded
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		f.embedded = append(f.embedded, text)
+		vector := make([]float32, len(fakeEmbeddingWords))
+		for j, word := range fakeEmbeddingWords {
+			vector[j] = float32(strings.Count(strings.ToLower(text), word))
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func (f *fakeEmbedder) EmbeddingModel() string { return f.model }
+
+func (f *fakeEmbedder) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.embedded)
+}
+
+func indexTestServers() []map[string]string {
+	return []map[string]string{
+		{"Host": "web-1", "HostName": "10.0.0.1", "Tags": "web, staging"},
+		{"Host": "db-prod", "HostName": "10.0.1.5", "Tags": "db, prod"},
+		{"Host": "web-prod", "HostName": "10.0.1.9", "Tags": "web, prod"},
+	}
+}
+
+func aliases(servers []map[string]string) []string {
+	out := make([]string, 0, len(servers))
+	for _, server := range servers {
+		out = append(out, server["Host"])
+	}
+	return out
+}
+
+func TestEmbeddingIndex_TopK(t *testing.T) {
+	embedder := &fakeEmbedder{model: "m1"}
+	index := NewEmbeddingIndex(embedder, filepath.Join(t.TempDir(), "embeddings.json"), nil)
+
+	top, err := index.TopK(context.Background(), "prod db", indexTestServers(), 2)
+	if err != nil {
+		t.Fatalf("TopK failed: %v", err)
+	}
+	if got := strings.Join(aliases(top), ","); got != "db-prod,web-prod" {
+		t.Errorf("Unexpected ranking %q", got)
+	}
+	// Three servers and the query.
+	if got := embedder.calls(); got != 4 {
+		t.Errorf("Expected 4 embedded texts, got %d", got)
+	}
+}
+
+func TestEmbeddingIndex_IncrementalSync(t *testing.T) {
+	embedder := &fakeEmbedder{model: "m1"}
+	path := filepath.Join(t.TempDir(), "embeddings.json")
+	index := NewEmbeddingIndex(embedder, path, nil)
+	servers := indexTestServers()
+
+	if err := index.sync(context.Background(), servers); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	servers[0]["Port"] = "2222"
+	servers[1]["Tags"] = "db, staging"
+	if err := index.sync(context.Background(), servers); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	// Port is not embedded, so only the retagged server is embedded again.
+	if got := embedder.calls(); got != 4 {
+		t.Errorf("Expected 4 embedded texts after the second sync, got %d", got)
+	}
+
+	// A new index over the same file starts from the saved entries.
+	reloaded := &fakeEmbedder{model: "m1"}
+	if err := NewEmbeddingIndex(reloaded, path, nil).sync(context.Background(), servers); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if got := reloaded.calls(); got != 0 {
+		t.Errorf("Expected the reloaded index to embed nothing, got %d texts", got)
+	}
+
+	// Switching models discards the saved entries.
+	switched := &fakeEmbedder{model: "m2"}
+	if err := NewEmbeddingIndex(switched, path, nil).sync(context.Background(), servers); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if got := switched.calls(); got != 3 {
+		t.Errorf("Expected every server to be embedded with the new model, got %d texts", got)
+	}
+}
+
+func TestEmbeddingIndex_SyncSavesChunks(t *testing.T) {
+	servers := make([]map[string]string, embeddingChunkSize+5)
+	for i := range servers {
+		servers[i] = map[string]string{"Host": fmt.Sprintf("web-%d", i), "Tags": "web"}
+	}
+	path := filepath.Join(t.TempDir(), "embeddings.json")
+
+	// The second chunk times out; the first is kept on disk.
+	embedder := &fakeEmbedder{model: "m1", failAfter: embeddingChunkSize}
+	if err := NewEmbeddingIndex(embedder, path, nil).sync(context.Background(), servers); err == nil {
+		t.Fatal("Expected the sync to fail")
+	}
+
+	retry := &fakeEmbedder{model: "m1"}
+	if err := NewEmbeddingIndex(retry, path, nil).sync(context.Background(), servers); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if got := retry.calls(); got != 5 {
+		t.Errorf("Expected only the 5 servers of the failed chunk to be embedded again, got %d", got)
+	}
+}
+
+func TestEmbeddingIndex_RemoveAndRename(t *testing.T) {
+	embedder := &fakeEmbedder{model: "m1"}
+	path := filepath.Join(t.TempDir(), "embeddings.json")
+	index := NewEmbeddingIndex(embedder, path, nil)
+	if err := index.sync(context.Background(), indexTestServers()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	index.ServerDeleted("web-1")
+
+	reloaded := NewEmbeddingIndex(&fakeEmbedder{model: "m1"}, path, nil)
+	reloaded.mu.Lock()
+	reloaded.load("m1")
+	_, found := reloaded.entries["web-1"]
+	count := len(reloaded.entries)
+	reloaded.mu.Unlock()
+	if found || count != 2 {
+		t.Errorf("Expected web-1 to be removed from the saved index, got %d entries", count)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"same direction", []float32{1, 2}, []float32{2, 4}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"different lengths", []float32{1}, []float32{1, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosineSimilarity(tt.a, tt.b); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("cosineSimilarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAIService_Embed_Ollama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var body struct {
+			Model  string `json:"model"`
+			Prompt string `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != aiDomain.DefaultOllamaEmbeddingModel {
+			t.Errorf("Unexpected model %q", body.Model)
+		}
+		_, _ = w.Write([]byte(`{"embedding":[` + map[string]string{"a": "1,0", "b": "0,1"}[body.Prompt] + `]}`))
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOllama, server.URL)
+	defer service.Stop()
+
+	vectors, err := service.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Unexpected vectors %v", vectors)
+	}
+}
+
+func TestAIService_Embed_OpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Unexpected Authorization header %q", got)
+		}
+		// Results may arrive out of order; index places them.
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOpenAI, server.URL)
+	defer service.Stop()
+
+	vectors, err := service.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Unexpected vectors %v", vectors)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadPromptTemplates reads the prompt templates in dir, one per *.yaml or
+// *.yml file, keyed by file name without its extension. A file named after a
+// built-in template, e.g. server_recommendation.yaml, replaces it. Each
+// template is validated; invalid files are left out and reported together in
+// the error, along with the valid templates. A missing dir is not an error.
+func LoadPromptTemplates(dir string) (map[string]*ai.PromptTemplate, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("list prompt templates in '%s': %w", dir, err)
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	templates := make(map[string]*ai.PromptTemplate, len(paths))
+	var errs []error
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if _, exists := templates[name]; exists {
+			errs = append(errs, fmt.Errorf("prompt template '%s': duplicate template %q", path, name))
+			continue
+		}
+		template, err := loadPromptTemplate(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("prompt template '%s': %w", path, err))
+			continue
+		}
+		templates[name] = template
+	}
+	return templates, errors.Join(errs...)
+}
+
+// loadPromptTemplate reads and validates the template in the file at path.
+// Its name defaults to the file name.
+func loadPromptTemplate(path string) (*ai.PromptTemplate, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is listed from the prompts directory
+	if err != nil {
+		return nil, err
+	}
+
+	var template ai.PromptTemplate
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&template); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("file is empty")
+		}
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	if template.Name == "" {
+		template.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	template.Source = path
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
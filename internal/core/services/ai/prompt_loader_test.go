@@ -0,0 +1,109 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+func writePromptFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPromptTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "terse_audit.yaml", `
+name: Terse Audit
+type: security
+variables: [config, server]
+format: json
+template: |
+  Audit {{.server.Host}}:
+  {{.config}}
+  {{answerFormat}}
+`)
+	writePromptFile(t, dir, "natural_language_search.yml", `
+type: search
+variables: [query, server_list]
+template: "{{.query}}: {{range .server_list}}{{.Host}} {{end}}"
+`)
+	writePromptFile(t, dir, "broken.yaml", `
+type: security
+variables: [config]
+template: "{{.config}} {{.query}}"
+`)
+	writePromptFile(t, dir, "typo.yaml", `
+type: security
+varaibles: [config]
+template: "{{.config}}"
+`)
+	writePromptFile(t, dir, "notes.txt", "not a template")
+
+	templates, err := LoadPromptTemplates(dir)
+	if err == nil {
+		t.Fatal("Expected the invalid templates to be reported")
+	}
+	for _, bad := range []string{"broken.yaml", "typo.yaml"} {
+		if !strings.Contains(err.Error(), bad) {
+			t.Errorf("Expected the error to name %s, got %v", bad, err)
+		}
+	}
+	if len(templates) != 2 {
+		t.Fatalf("Expected the two valid templates, got %d", len(templates))
+	}
+	if templates["terse_audit"].Name != "Terse Audit" || templates["terse_audit"].Source != filepath.Join(dir, "terse_audit.yaml") {
+		t.Errorf("Unexpected template %+v", templates["terse_audit"])
+	}
+	if templates["natural_language_search"].Name != "natural_language_search" {
+		t.Errorf("Expected the name to default to the file name, got %q", templates["natural_language_search"].Name)
+	}
+
+	if templates, err := LoadPromptTemplates(filepath.Join(dir, "missing")); err != nil || len(templates) != 0 {
+		t.Errorf("Expected no templates and no error for a missing directory, got %v, %v", templates, err)
+	}
+}
+
+func TestAIService_PromptTemplatePerType(t *testing.T) {
+	config := aiDomain.DefaultAIConfig()
+	config.Prompts = map[aiDomain.AIRequestType]string{aiDomain.RequestTypeSecurity: "terse_audit"}
+	service := NewAIService(config)
+	defer service.Stop()
+
+	service.SetPromptTemplates(map[string]*aiDomain.PromptTemplate{
+		"terse_audit": {
+			Name:      "Terse Audit",
+			Type:      aiDomain.RequestTypeSecurity,
+			Variables: []string{"server"},
+			Template:  "Audit {{.server.Host}}{{if .server.ForwardAgent}} (agent forwarding){{end}}",
+		},
+	})
+
+	request := service.securityRequest(config, map[string]string{"Host": "db1", "ForwardAgent": "yes"})
+	if request.Prompt != "Audit db1 (agent forwarding)" {
+		t.Errorf("Unexpected security prompt %q", request.Prompt)
+	}
+	request = service.recommendationRequest(config, map[string]string{"Host": "db1"}, "")
+	if !strings.HasPrefix(request.Prompt, "Based on the SSH server configuration below") {
+		t.Errorf("Expected the built-in recommendation template, got %q", request.Prompt)
+	}
+}
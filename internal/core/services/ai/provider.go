@@ -0,0 +1,244 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"go.uber.org/zap"
+)
+
+// ErrModelListUnsupported is returned by Provider.ListModels when the
+// provider cannot list its models.
+var ErrModelListUnsupported = errors.New("provider does not list its models")
+
+// Provider talks to one kind of AI backend. The AIService picks the provider
+// named by AIConfig.Provider for every request; redaction, retries, rate
+// limiting and metrics are handled by the service.
+type Provider interface {
+	// Complete sends request and waits for the whole answer.
+	Complete(ctx context.Context, client ProviderClient, config *ai.AIConfig, request *ai.AIRequest) (*ai.AIResponse, error)
+	// OpenStream starts a streamed completion and returns the response once
+	// the provider has accepted it. ReadStream reads its body.
+	OpenStream(ctx context.Context, client ProviderClient, config *ai.AIConfig, request *ai.AIRequest) (*http.Response, error)
+	// ReadStream reads a stream opened by OpenStream, calling emit with each
+	// piece of text, and returns the complete answer.
+	ReadStream(body io.Reader, request *ai.AIRequest, emit func(delta string) bool) (*ai.AIResponse, error)
+	// Embed returns an embedding vector for each of texts, in order.
+	Embed(ctx context.Context, client ProviderClient, config *ai.AIConfig, texts []string) ([][]float32, error)
+	// ListModels returns the models the provider offers, or
+	// ErrModelListUnsupported.
+	ListModels(ctx context.Context, client ProviderClient, config *ai.AIConfig) ([]string, error)
+}
+
+// ProviderClient holds what a Provider sends requests with.
+type ProviderClient struct {
+	// HTTP is pooled and bounded by AIConfig.Timeout.
+	HTTP *http.Client
+	// Stream has no overall timeout, for streamed completions.
+	Stream *http.Client
+	Logger *zap.SugaredLogger
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[ai.AIProvider]Provider{
+		ai.ProviderOllama: ollamaProvider{},
+		ai.ProviderOpenAI: openAIProvider{
+			name:           ai.ProviderOpenAI,
+			defaultBaseURL: OpenAIBaseURL,
+			defaultModel:   OpenAIDefaultModel,
+			requireAPIKey:  true,
+		},
+		ai.ProviderOpenAICompatible: openAIProvider{name: ai.ProviderOpenAICompatible},
+	}
+)
+
+// RegisterProvider makes provider available under info.Name, replacing any
+// provider registered under that name before.
+func RegisterProvider(info ai.ProviderInfo, provider Provider) {
+	ai.RegisterProviderInfo(info)
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[info.Name] = provider
+}
+
+// lookupProvider returns the provider registered under name.
+func lookupProvider(name ai.AIProvider) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	provider, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported AI provider: %s", name)
+	}
+	return provider, nil
+}
+
+// providerClient returns the clients providers send requests with.
+func (s *AIService) providerClient() ProviderClient {
+	return ProviderClient{HTTP: s.getPool().GetClient(), Stream: s.getStreamClient(), Logger: s.logger}
+}
+
+// ListModels asks the provider named by config which models it offers and
+// returns them sorted. config may be a configuration that is still being
+// edited rather than the service's own.
+func (s *AIService) ListModels(ctx context.Context, config *ai.AIConfig) ([]string, error) {
+	provider, err := lookupProvider(config.Provider)
+	if err != nil {
+		return nil, err
+	}
+	models, err := provider.ListModels(ctx, s.providerClient(), config)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// hasModel reports whether model is one of models. Ollama names a model
+// without a tag after its "latest" tag.
+func hasModel(models []string, model string) bool {
+	for _, m := range models {
+		if m == model || (!strings.Contains(model, ":") && m == model+":latest") {
+			return true
+		}
+	}
+	return false
+}
+
+// getJSON sends a GET request and decodes the answer into out. A 404 is
+// reported as ErrModelListUnsupported, as getJSON only lists models.
+func getJSON(ctx context.Context, client ProviderClient, url, apiKey string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request (url: %s): %w", url, err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", url, err)
+	}
+	defer closeResponseBody(client.Logger, resp, "models")
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrModelListUnsupported
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("request failed (status_code: %d, url: %s)%s", resp.StatusCode, url, providerErrorDetail(resp.Body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response (url: %s): %w", url, err)
+	}
+	return nil
+}
+
+// postJSON sends payload in a POST request and decodes the answer into out.
+// what names the request in errors.
+func postJSON(ctx context.Context, client ProviderClient, what, url, apiKey string, payload, out interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", what, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request (url: %s): %w", what, url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make %s request (url: %s): %w", what, url, err)
+	}
+	defer closeResponseBody(client.Logger, resp, what)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s request failed (status_code: %d, url: %s)%s", what, resp.StatusCode, url, providerErrorDetail(resp.Body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response (url: %s): %w", what, url, err)
+	}
+	return nil
+}
+
+// openStreamRequest posts payload to url with the stream client, adding
+// header, and returns the response once the provider has accepted the
+// request.
+func openStreamRequest(ctx context.Context, client ProviderClient, provider ai.AIProvider, url string, header map[string]string,
+	payload interface{}, request *ai.AIRequest,
+) (*http.Response, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s stream request (request_id: %s): %w", provider, request.ID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request (request_id: %s, url: %s): %w", request.ID, url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Stream.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make %s stream request (request_id: %s, url: %s): %w", provider, request.ID, url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer closeResponseBody(client.Logger, resp, request.ID)
+		return nil, fmt.Errorf("%s API request failed (request_id: %s, status_code: %d, url: %s)%s",
+			provider, request.ID, resp.StatusCode, url, providerErrorDetail(resp.Body))
+	}
+	return resp, nil
+}
+
+// closeResponseBody closes resp.Body, logging any error
+func closeResponseBody(logger *zap.SugaredLogger, resp *http.Response, requestID string) {
+	if err := resp.Body.Close(); err != nil && logger != nil {
+		logger.Warnw("Failed to close response body", "error", err, "request_id", requestID)
+	}
+}
+
+// chatMessages returns the conversation of request followed by its prompt as
+// the user's turn, in the role/content form of the chat APIs.
+func chatMessages(request *ai.AIRequest) []map[string]string {
+	messages := make([]map[string]string, 0, len(request.Messages)+1)
+	for _, message := range request.Messages {
+		messages = append(messages, map[string]string{
+			"role":    string(message.Role),
+			"content": message.Content,
+		})
+	}
+	return append(messages, map[string]string{
+		"role":    string(ai.RoleUser),
+		"content": request.Prompt,
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// ollamaProvider talks to Ollama's native API.
+type ollamaProvider struct{}
+
+// ollamaEndpoint returns /api/chat for requests carrying a conversation and
+// /api/generate for single prompts.
+func ollamaEndpoint(config *ai.AIConfig, request *ai.AIRequest) string {
+	if len(request.Messages) > 0 {
+		return config.BaseURL + "/api/chat"
+	}
+	return config.BaseURL + "/api/generate"
+}
+
+// Complete sends request to /api/generate, or /api/chat for a conversation.
+func (ollamaProvider) Complete(ctx context.Context, client ProviderClient, config *ai.AIConfig, request *ai.AIRequest) (*ai.AIResponse, error) {
+	url := ollamaEndpoint(config, request)
+
+	jsonData, err := json.Marshal(ollamaPayload(request, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Ollama request (request_id: %s, model: %s): %w", request.ID, request.Model, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request (request_id: %s, url: %s): %w", request.ID, url, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make Ollama HTTP request (request_id: %s, url: %s): %w", request.ID, url, err)
+	}
+	defer closeResponseBody(client.Logger, resp, request.ID)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama API request failed (request_id: %s, status_code: %d, url: %s)", request.ID, resp.StatusCode, url)
+	}
+
+	var ollamaResponse struct {
+		Response string `json:"response"`
+		Message  struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		Done            bool `json:"done"`
+		PromptEvalCount int  `json:"prompt_eval_count"`
+		EvalCount       int  `json:"eval_count"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&ollamaResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode Ollama response (request_id: %s, status_code: %d): %w", request.ID, resp.StatusCode, err)
+	}
+
+	return &ai.AIResponse{
+		ID:               generateResponseID(),
+		RequestID:        request.ID,
+		Content:          ollamaResponse.Response + ollamaResponse.Message.Content,
+		Type:             request.Type,
+		Model:            request.Model,
+		TokensUsed:       ollamaResponse.PromptEvalCount + ollamaResponse.EvalCount,
+		PromptTokens:     ollamaResponse.PromptEvalCount,
+		CompletionTokens: ollamaResponse.EvalCount,
+		Timestamp:        time.Now(),
+	}, nil
+}
+
+// OpenStream starts a streamed /api/generate or /api/chat request.
+func (ollamaProvider) OpenStream(ctx context.Context, client ProviderClient, config *ai.AIConfig, request *ai.AIRequest) (*http.Response, error) {
+	return openStreamRequest(ctx, client, config.Provider, ollamaEndpoint(config, request), nil, ollamaPayload(request, true), request)
+}
+
+// ollamaPayload builds the /api/generate or /api/chat request body.
+func ollamaPayload(request *ai.AIRequest, stream bool) map[string]interface{} {
+	payload := map[string]interface{}{
+		"model":  request.Model,
+		"stream": stream,
+		"options": map[string]interface{}{
+			"temperature": request.Temperature,
+			"num_predict": request.MaxTokens,
+		},
+	}
+	if len(request.Messages) > 0 {
+		payload["messages"] = chatMessages(request)
+	} else {
+		payload["prompt"] = request.Prompt
+	}
+	if request.Format == ai.FormatJSON {
+		payload["format"] = ai.FormatJSON
+	}
+	return payload
+}
+
+// ReadStream reads Ollama's newline-delimited JSON stream.
+func (ollamaProvider) ReadStream(body io.Reader, request *ai.AIRequest, emit func(delta string) bool) (*ai.AIResponse, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	var content strings.Builder
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk struct {
+			Model    string `json:"model"`
+			Response string `json:"response"`
+			Message  struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			Done            bool   `json:"done"`
+			Error           string `json:"error"`
+			PromptEvalCount int    `json:"prompt_eval_count"`
+			EvalCount       int    `json:"eval_count"`
+		}
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode Ollama stream chunk (request_id: %s): %w", request.ID, err)
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("Ollama stream failed (request_id: %s): %s", request.ID, chunk.Error)
+		}
+
+		// /api/generate streams the response, /api/chat the message.
+		if delta := chunk.Response + chunk.Message.Content; delta != "" {
+			content.WriteString(delta)
+			if !emit(delta) {
+				return nil, errStreamStopped
+			}
+		}
+		if chunk.Done {
+			model := chunk.Model
+			if model == "" {
+				model = request.Model
+			}
+			return &ai.AIResponse{
+				ID:               generateResponseID(),
+				RequestID:        request.ID,
+				Content:          content.String(),
+				Type:             request.Type,
+				Model:            model,
+				TokensUsed:       chunk.PromptEvalCount + chunk.EvalCount,
+				PromptTokens:     chunk.PromptEvalCount,
+				CompletionTokens: chunk.EvalCount,
+				Timestamp:        time.Now(),
+			}, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Ollama stream (request_id: %s): %w", request.ID, err)
+	}
+	return nil, fmt.Errorf("Ollama stream ended before completion (request_id: %s)", request.ID)
+}
+
+// Embed embeds one text per request; Ollama's /api/embeddings takes a single
+// prompt.
+func (ollamaProvider) Embed(ctx context.Context, client ProviderClient, config *ai.AIConfig, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		var response struct {
+			Embedding []float32 `json:"embedding"`
+		}
+		payload := map[string]interface{}{
+			"model":  config.EmbeddingModelName(),
+			"prompt": text,
+		}
+		if err := postJSON(ctx, client, "embeddings", config.BaseURL+"/api/embeddings", "", payload, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Embedding) == 0 {
+			return nil, fmt.Errorf("ollama returned an empty embedding (model: %s)", config.EmbeddingModelName())
+		}
+		vectors = append(vectors, response.Embedding)
+	}
+	return vectors, nil
+}
+
+// ListModels lists the locally pulled models from /api/tags.
+func (ollamaProvider) ListModels(ctx context.Context, client ProviderClient, config *ai.AIConfig) ([]string, error) {
+	var response struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := getJSON(ctx, client, config.BaseURL+"/api/tags", "", &response); err != nil {
+		return nil, fmt.Errorf("failed to list Ollama models: %w", err)
+	}
+	models := make([]string, 0, len(response.Models))
+	for _, model := range response.Models {
+		models = append(models, model.Name)
+	}
+	return models, nil
+}
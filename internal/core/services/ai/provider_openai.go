@@ -0,0 +1,328 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// OpenAIModelsEndpoint lists the models of OpenAI-compatible APIs, relative
+// to the base URL.
+const OpenAIModelsEndpoint = "/models"
+
+// openAIProvider talks to the OpenAI API and to servers implementing it, such
+// as llama.cpp, vLLM or LM Studio. Only OpenAI itself has a default base URL
+// and model and requires an API key.
+type openAIProvider struct {
+	name           ai.AIProvider
+	defaultBaseURL string
+	defaultModel   string
+	requireAPIKey  bool
+}
+
+// baseURL returns the configured base URL or the provider's default.
+func (p openAIProvider) baseURL(config *ai.AIConfig) string {
+	if config.BaseURL != "" {
+		return config.BaseURL
+	}
+	return p.defaultBaseURL
+}
+
+// checkAPIKey reports a missing API key when the provider requires one.
+func (p openAIProvider) checkAPIKey(config *ai.AIConfig, requestID string) error {
+	if p.requireAPIKey && config.APIKey == "" {
+		return fmt.Errorf("%s API key not configured (request_id: %s). Please set APIKey in AIConfig", p.displayName(), requestID)
+	}
+	return nil
+}
+
+func (p openAIProvider) displayName() string {
+	if p.name == ai.ProviderOpenAI {
+		return "OpenAI"
+	}
+	return string(p.name)
+}
+
+// model returns the model for request: the request's, the configured one or
+// the provider's default. It may be empty for servers that serve one model.
+func (p openAIProvider) model(config *ai.AIConfig, request *ai.AIRequest) string {
+	if request.Model != "" {
+		return request.Model
+	}
+	if config.Model != "" {
+		return config.Model
+	}
+	return p.defaultModel
+}
+
+// payload builds a Chat Completions request body.
+func (p openAIProvider) payload(config *ai.AIConfig, request *ai.AIRequest, stream bool) map[string]interface{} {
+	maxTokens := request.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = config.MaxTokens
+	}
+	payload := map[string]interface{}{
+		"model":       p.model(config, request),
+		"messages":    chatMessages(request),
+		"max_tokens":  maxTokens,
+		"temperature": request.Temperature,
+		"stream":      stream,
+	}
+	if stream {
+		payload["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+	return payload
+}
+
+// Complete sends request to the Chat Completions API.
+func (p openAIProvider) Complete(ctx context.Context, client ProviderClient, config *ai.AIConfig, request *ai.AIRequest) (*ai.AIResponse, error) {
+	if err := p.checkAPIKey(config, request.ID); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s%s", p.baseURL(config), OpenAIEndpoint)
+	name := p.displayName()
+
+	jsonData, err := json.Marshal(p.payload(config, request, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request (request_id: %s, model: %s): %w", name, request.ID, p.model(config, request), err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request (request_id: %s, url: %s): %w", request.ID, url, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if config.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", config.APIKey))
+	}
+
+	resp, err := client.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make %s HTTP request (request_id: %s, url: %s): %w", name, request.ID, url, err)
+	}
+	defer closeResponseBody(client.Logger, resp, request.ID)
+
+	// Handle non-200 status codes
+	if resp.StatusCode != http.StatusOK {
+		detail := providerErrorDetail(resp.Body)
+		if client.Logger != nil {
+			client.Logger.Errorw("OpenAI API request failed", "provider", p.name, "request_id", request.ID, "status_code", resp.StatusCode, "url", url, "response_body", detail)
+		}
+		return nil, fmt.Errorf("%s API request failed (request_id: %s, status_code: %d, url: %s)%s", name, request.ID, resp.StatusCode, url, detail)
+	}
+
+	// Parse OpenAI response
+	var openAIResponse struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		Created int64  `json:"created"`
+		Model   string `json:"model"`
+		Choices []struct {
+			Index   int `json:"index"`
+			Message struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&openAIResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response (request_id: %s, status_code: %d): %w", name, request.ID, resp.StatusCode, err)
+	}
+
+	// Extract content from first choice
+	if len(openAIResponse.Choices) == 0 {
+		return nil, fmt.Errorf("%s API returned no choices (request_id: %s)", name, request.ID)
+	}
+
+	model := openAIResponse.Model
+	if model == "" {
+		model = p.model(config, request)
+	}
+	return &ai.AIResponse{
+		ID:               generateResponseID(),
+		RequestID:        request.ID,
+		Content:          openAIResponse.Choices[0].Message.Content,
+		Type:             request.Type,
+		Model:            model,
+		TokensUsed:       openAIResponse.Usage.TotalTokens,
+		PromptTokens:     openAIResponse.Usage.PromptTokens,
+		CompletionTokens: openAIResponse.Usage.CompletionTokens,
+		Timestamp:        time.Now(),
+	}, nil
+}
+
+// OpenStream starts a streamed Chat Completions request.
+func (p openAIProvider) OpenStream(ctx context.Context, client ProviderClient, config *ai.AIConfig, request *ai.AIRequest) (*http.Response, error) {
+	if err := p.checkAPIKey(config, request.ID); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s%s", p.baseURL(config), OpenAIEndpoint)
+	header := map[string]string{"Accept": "text/event-stream"}
+	if config.APIKey != "" {
+		header["Authorization"] = fmt.Sprintf("Bearer %s", config.APIKey)
+	}
+	return openStreamRequest(ctx, client, config.Provider, url, header, p.payload(config, request, true), request)
+}
+
+// ReadStream reads a Chat Completions server-sent event stream.
+func (p openAIProvider) ReadStream(body io.Reader, request *ai.AIRequest, emit func(delta string) bool) (*ai.AIResponse, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	name := p.displayName()
+	var content strings.Builder
+	model := request.Model
+	var usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	}
+	for scanner.Scan() {
+		// Blank lines separate events; comments and event names carry no data.
+		data, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return &ai.AIResponse{
+				ID:               generateResponseID(),
+				RequestID:        request.ID,
+				Content:          content.String(),
+				Type:             request.Type,
+				Model:            model,
+				TokensUsed:       usage.TotalTokens,
+				PromptTokens:     usage.PromptTokens,
+				CompletionTokens: usage.CompletionTokens,
+				Timestamp:        time.Now(),
+			}, nil
+		}
+
+		var chunk struct {
+			Model   string `json:"model"`
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+				TotalTokens      int `json:"total_tokens"`
+			} `json:"usage"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode %s stream event (request_id: %s): %w", name, request.ID, err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("%s stream failed (request_id: %s): %s", name, request.ID, chunk.Error.Message)
+		}
+
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if !emit(choice.Delta.Content) {
+				return nil, errStreamStopped
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s stream (request_id: %s): %w", name, request.ID, err)
+	}
+	return nil, fmt.Errorf("%s stream ended before completion (request_id: %s)", name, request.ID)
+}
+
+// Embed sends every text in one /embeddings request.
+func (p openAIProvider) Embed(ctx context.Context, client ProviderClient, config *ai.AIConfig, texts []string) ([][]float32, error) {
+	if err := p.checkAPIKey(config, "embeddings"); err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	payload := map[string]interface{}{
+		"model": config.EmbeddingModelName(),
+		"input": texts,
+	}
+	if err := postJSON(ctx, client, "embeddings", p.baseURL(config)+OpenAIEmbeddingsEndpoint, config.APIKey, payload, &response); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range response.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = item.Embedding
+		}
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("embeddings response is missing input %d (model: %s)", i, config.EmbeddingModelName())
+		}
+	}
+	return vectors, nil
+}
+
+// ListModels lists the models from /models.
+func (p openAIProvider) ListModels(ctx context.Context, client ProviderClient, config *ai.AIConfig) ([]string, error) {
+	if err := p.checkAPIKey(config, "models"); err != nil {
+		return nil, err
+	}
+	var response struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, client, p.baseURL(config)+OpenAIModelsEndpoint, config.APIKey, &response); err != nil {
+		return nil, fmt.Errorf("failed to list %s models: %w", p.displayName(), err)
+	}
+	models := make([]string, 0, len(response.Data))
+	for _, model := range response.Data {
+		models = append(models, model.ID)
+	}
+	return models, nil
+}
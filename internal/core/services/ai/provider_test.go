@@ -0,0 +1,166 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+func TestAIService_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"mistral:7b"},{"name":"llama3.2:3b"}]}`))
+		case "/v1/models":
+			if got := r.Header.Get("Authorization"); got != "" {
+				t.Errorf("Expected no Authorization header without an API key, got %q", got)
+			}
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"qwen2.5-7b-instruct"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		provider aiDomain.AIProvider
+		baseURL  string
+		want     string
+	}{
+		{aiDomain.ProviderOllama, server.URL, "llama3.2:3b,mistral:7b"},
+		{aiDomain.ProviderOpenAICompatible, server.URL + "/v1", "qwen2.5-7b-instruct"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			service := newStreamTestService(tt.provider, tt.baseURL)
+			defer service.Stop()
+			config := service.GetConfig()
+			config.APIKey = ""
+
+			models, err := service.ListModels(context.Background(), config)
+			if err != nil {
+				t.Fatalf("ListModels failed: %v", err)
+			}
+			if got := strings.Join(models, ","); got != tt.want {
+				t.Errorf("ListModels() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAIService_TestConnection_Discovery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOllama, server.URL)
+	defer service.Stop()
+
+	config := service.GetConfig()
+	config.Model = "llama3"
+	if err := service.UpdateConfig(config); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	if err := service.TestConnection(context.Background()); err != nil {
+		t.Errorf("Expected llama3 to match llama3:latest, got %v", err)
+	}
+
+	config.Model = "mistral:7b"
+	if err := service.UpdateConfig(config); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	err := service.TestConnection(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "llama3:latest") {
+		t.Errorf("Expected a missing model error listing the available models, got %v", err)
+	}
+}
+
+func TestAIService_TestConnection_FallsBackToCompletion(t *testing.T) {
+	var completions int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		completions++
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"OK"}}]}`))
+	}))
+	defer server.Close()
+
+	service := newStreamTestService(aiDomain.ProviderOpenAICompatible, server.URL)
+	defer service.Stop()
+
+	if err := service.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection failed: %v", err)
+	}
+	if completions != 1 {
+		t.Errorf("Expected one test completion, got %d", completions)
+	}
+}
+
+// echoProvider answers every request with its prompt.
+type echoProvider struct{}
+
+func (echoProvider) Complete(_ context.Context, _ ProviderClient, _ *aiDomain.AIConfig, request *aiDomain.AIRequest) (*aiDomain.AIResponse, error) {
+	return &aiDomain.AIResponse{RequestID: request.ID, Content: request.Prompt}, nil
+}
+
+func (echoProvider) OpenStream(context.Context, ProviderClient, *aiDomain.AIConfig, *aiDomain.AIRequest) (*http.Response, error) {
+	return nil, ErrModelListUnsupported
+}
+
+func (echoProvider) ReadStream(io.Reader, *aiDomain.AIRequest, func(string) bool) (*aiDomain.AIResponse, error) {
+	return nil, ErrModelListUnsupported
+}
+
+func (echoProvider) Embed(context.Context, ProviderClient, *aiDomain.AIConfig, []string) ([][]float32, error) {
+	return nil, ErrModelListUnsupported
+}
+
+func (echoProvider) ListModels(context.Context, ProviderClient, *aiDomain.AIConfig) ([]string, error) {
+	return []string{"echo"}, nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider(aiDomain.ProviderInfo{Name: "echo", DisplayName: "Echo", Redaction: aiDomain.RedactionNone}, echoProvider{})
+
+	config := aiDomain.DefaultAIConfig()
+	config.Provider = "echo"
+	config.BaseURL = ""
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected a registered provider to be valid, got %v", err)
+	}
+	service := NewAIService(config)
+	defer service.Stop()
+
+	response, err := service.makeAIRequest(context.Background(), &aiDomain.AIRequest{ID: "req", Prompt: "HostName: db1.example.com"})
+	if err != nil {
+		t.Fatalf("makeAIRequest failed: %v", err)
+	}
+	if response.Content != "HostName: db1.example.com" {
+		t.Errorf("Unexpected content %q", response.Content)
+	}
+	if err := service.TestConnection(context.Background()); err == nil {
+		t.Error("Expected the configured model to be missing from the echo provider")
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"go.uber.org/zap"
+)
+
+// sessionFileExt is the extension of conversation files.
+const sessionFileExt = ".jsonl"
+
+// Kinds of session records.
+const (
+	sessionRecordMeta    = "meta"
+	sessionRecordMessage = "message"
+)
+
+// ErrSessionNotFound is returned for a conversation ID without a file.
+var ErrSessionNotFound = errors.New("conversation not found")
+
+// validSessionID keeps IDs usable as file names inside the store directory.
+var validSessionID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// sessionRecord is one line of a conversation file: its metadata or one
+// message. A later metadata record overrides the title of earlier ones.
+type sessionRecord struct {
+	Kind    string     `json:"kind"`
+	ID      string     `json:"id,omitempty"`
+	Title   *string    `json:"title,omitempty"`
+	Created *time.Time `json:"created,omitempty"`
+	*ai.Message
+}
+
+// SessionStore persists conversations as JSONL files, one per conversation,
+// in a directory. Records are only ever appended, so a crash loses at most
+// the line being written, and renaming appends a new metadata record.
+type SessionStore struct {
+	dir    string
+	logger *zap.SugaredLogger
+	mu     sync.Mutex
+}
+
+// NewSessionStore creates a store keeping conversations in dir, which is
+// created on the first write.
+func NewSessionStore(dir string, logger *zap.SugaredLogger) *SessionStore {
+	return &SessionStore{dir: dir, logger: logger}
+}
+
+// New returns an empty conversation. Nothing is written until its first
+// message is appended.
+func (s *SessionStore) New() *ai.Conversation {
+	now := time.Now()
+	return &ai.Conversation{ID: generateSessionID(), Created: now, Updated: now}
+}
+
+// Append adds messages to conversation and writes them to its file,
+// creating the file on the first message.
+func (s *SessionStore) Append(conversation *ai.Conversation, messages ...ai.Message) error {
+	path, err := s.path(conversation.ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("ensure session directory '%s': %w", s.dir, err)
+	}
+	var records []sessionRecord
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		created := conversation.Created
+		records = append(records, sessionRecord{Kind: sessionRecordMeta, ID: conversation.ID, Title: &conversation.Title, Created: &created})
+	}
+	for i := range messages {
+		if messages[i].Timestamp.IsZero() {
+			messages[i].Timestamp = time.Now()
+		}
+		records = append(records, sessionRecord{Kind: sessionRecordMessage, Message: &messages[i]})
+	}
+	if err := appendRecords(path, records); err != nil {
+		return err
+	}
+
+	conversation.Messages = append(conversation.Messages, messages...)
+	if n := len(messages); n > 0 {
+		conversation.Updated = messages[n-1].Timestamp
+	}
+	return nil
+}
+
+// Rename sets the title of the conversation with id. An empty title falls
+// back to the start of the first question.
+func (s *SessionStore) Rename(id, title string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("rename conversation %s: %w", id, ErrSessionNotFound)
+		}
+		return fmt.Errorf("stat conversation file '%s': %w", path, err)
+	}
+	title = strings.TrimSpace(title)
+	return appendRecords(path, []sessionRecord{{Kind: sessionRecordMeta, ID: id, Title: &title}})
+}
+
+// Delete removes the conversation with id.
+func (s *SessionStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("delete conversation %s: %w", id, ErrSessionNotFound)
+		}
+		return fmt.Errorf("delete conversation file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// Load reads the conversation with id.
+func (s *SessionStore) Load(id string) (*ai.Conversation, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, err := s.read(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("load conversation %s: %w", id, ErrSessionNotFound)
+	}
+	return conversation, err
+}
+
+// List returns every stored conversation, most recently updated first.
+// Unreadable files are skipped with a warning.
+func (s *SessionStore) List() ([]*ai.Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+sessionFileExt))
+	if err != nil {
+		return nil, fmt.Errorf("list conversations in '%s': %w", s.dir, err)
+	}
+	conversations := make([]*ai.Conversation, 0, len(paths))
+	for _, path := range paths {
+		conversation, err := s.read(path)
+		if err != nil {
+			s.logWarn("skipping unreadable conversation", "error", err, "path", path)
+			continue
+		}
+		conversations = append(conversations, conversation)
+	}
+	sort.SliceStable(conversations, func(i, j int) bool {
+		return conversations[i].Updated.After(conversations[j].Updated)
+	})
+	return conversations, nil
+}
+
+// read parses the conversation file at path (called with s.mu held). A
+// truncated or corrupt line, e.g. from a crash mid-write, is skipped.
+func (s *SessionStore) read(path string) (*ai.Conversation, error) {
+	file, err := os.Open(path) // #nosec G304 - path is built from a validated ID
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	conversation := &ai.Conversation{ID: strings.TrimSuffix(filepath.Base(path), sessionFileExt)}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		var record sessionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			s.logWarn("skipping corrupt conversation record", "error", err, "path", path, "line", line)
+			continue
+		}
+		switch record.Kind {
+		case sessionRecordMeta:
+			if record.Title != nil {
+				conversation.Title = *record.Title
+			}
+			if record.Created != nil {
+				conversation.Created = *record.Created
+			}
+		case sessionRecordMessage:
+			if record.Message != nil {
+				conversation.Messages = append(conversation.Messages, *record.Message)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read conversation file '%s': %w", path, err)
+	}
+
+	conversation.Updated = conversation.Created
+	if n := len(conversation.Messages); n > 0 {
+		conversation.Updated = conversation.Messages[n-1].Timestamp
+	}
+	return conversation, nil
+}
+
+// path returns the file of the conversation with id.
+func (s *SessionStore) path(id string) (string, error) {
+	if !validSessionID.MatchString(id) {
+		return "", fmt.Errorf("invalid conversation ID %q", id)
+	}
+	return filepath.Join(s.dir, id+sessionFileExt), nil
+}
+
+func (s *SessionStore) logWarn(msg string, keysAndValues ...interface{}) {
+	if s.logger != nil {
+		s.logger.Warnw(msg, keysAndValues...)
+	}
+}
+
+// appendRecords appends records to the file at path, one JSON object per
+// line. If the file ends in a partial line, e.g. after a crash mid-write, that
+// line is terminated first so the new records are not glued onto it.
+func appendRecords(path string, records []sessionRecord) error {
+	var data []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("marshal conversation record: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600) // #nosec G304 - path is built from a validated ID
+	if err != nil {
+		return fmt.Errorf("open conversation file '%s': %w", path, err)
+	}
+	partial, err := endsInPartialLine(file)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("read conversation file '%s': %w", path, err)
+	}
+	if partial {
+		data = append([]byte{'\n'}, data...)
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write conversation file '%s': %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close conversation file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// endsInPartialLine reports whether file is non-empty and does not end with a
+// newline.
+func endsInPartialLine(file *os.File) (bool, error) {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
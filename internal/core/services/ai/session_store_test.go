@@ -0,0 +1,182 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+func TestSessionStore_AppendAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sessions")
+	store := NewSessionStore(dir, nil)
+
+	conversation := store.New()
+	if err := store.Append(conversation,
+		aiDomain.Message{Role: aiDomain.RoleUser, Content: "harden db1", Type: aiDomain.RequestTypeSecurity},
+		aiDomain.Message{Role: aiDomain.RoleAssistant, Content: "1. [high] Disable password login"},
+	); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := store.Append(conversation, aiDomain.Message{Role: aiDomain.RoleUser, Content: "why?"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if len(conversation.Messages) != 3 {
+		t.Errorf("Expected Append to add to the conversation, got %d messages", len(conversation.Messages))
+	}
+
+	info, err := os.Stat(filepath.Join(dir, conversation.ID+".jsonl"))
+	if err != nil {
+		t.Fatalf("Expected a conversation file: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %o", info.Mode().Perm())
+	}
+
+	loaded, err := store.Load(conversation.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Messages) != 3 || loaded.Messages[0].Type != aiDomain.RequestTypeSecurity || loaded.Messages[2].Content != "why?" {
+		t.Errorf("Unexpected messages %+v", loaded.Messages)
+	}
+	if !loaded.Created.Equal(conversation.Created) {
+		t.Errorf("Created = %v, want %v", loaded.Created, conversation.Created)
+	}
+	if loaded.DisplayTitle() != "harden db1" {
+		t.Errorf("DisplayTitle() = %q", loaded.DisplayTitle())
+	}
+}
+
+func TestSessionStore_ListRenameDelete(t *testing.T) {
+	store := NewSessionStore(t.TempDir(), nil)
+
+	older := store.New()
+	newer := store.New()
+	if err := store.Append(older, aiDomain.Message{Role: aiDomain.RoleUser, Content: "first", Timestamp: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := store.Append(newer, aiDomain.Message{Role: aiDomain.RoleUser, Content: "second"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	// Conversations without messages are never written.
+	store.New()
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != newer.ID || list[1].ID != older.ID {
+		t.Fatalf("Expected the newer conversation first, got %+v", list)
+	}
+
+	if err := store.Rename(older.ID, "  DB hardening "); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	loaded, err := store.Load(older.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Title != "DB hardening" || len(loaded.Messages) != 1 {
+		t.Errorf("Unexpected conversation after rename: %+v", loaded)
+	}
+
+	if err := store.Delete(older.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load(older.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound after delete, got %v", err)
+	}
+	if err := store.Rename(older.ID, "gone"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound renaming a deleted conversation, got %v", err)
+	}
+}
+
+func TestSessionStore_SkipsCorruptLines(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSessionStore(dir, nil)
+	conversation := store.New()
+	if err := store.Append(conversation, aiDomain.Message{Role: aiDomain.RoleUser, Content: "kept"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	// Simulate a crash in the middle of writing a record.
+	path := filepath.Join(dir, conversation.ID+".jsonl")
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(`{"kind":"message","role":"assis`)
+	_ = file.Close()
+
+	loaded, err := store.Load(conversation.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Messages) != 1 || loaded.Messages[0].Content != "kept" {
+		t.Errorf("Unexpected messages %+v", loaded.Messages)
+	}
+}
+
+func TestSessionStore_AppendAfterTruncatedLine(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSessionStore(dir, nil)
+	conversation := store.New()
+	if err := store.Append(conversation,
+		aiDomain.Message{Role: aiDomain.RoleUser, Content: "kept"},
+		aiDomain.Message{Role: aiDomain.RoleAssistant, Content: "cut off by a crash"},
+	); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	// Simulate a crash in the middle of writing the last record.
+	path := filepath.Join(dir, conversation.ID+".jsonl")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-10); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Append(conversation, aiDomain.Message{Role: aiDomain.RoleUser, Content: "after the crash"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	loaded, err := store.Load(conversation.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Messages) != 2 || loaded.Messages[0].Content != "kept" || loaded.Messages[1].Content != "after the crash" {
+		t.Errorf("Expected only the truncated record to be lost, got %+v", loaded.Messages)
+	}
+}
+
+func TestSessionStore_RejectsInvalidIDs(t *testing.T) {
+	store := NewSessionStore(t.TempDir(), nil)
+	for _, id := range []string{"", "../config", "a/b", "x.jsonl"} {
+		if _, err := store.Load(id); err == nil {
+			t.Errorf("Expected an error loading %q", id)
+		}
+		if err := store.Delete(id); err == nil {
+			t.Errorf("Expected an error deleting %q", id)
+		}
+	}
+}
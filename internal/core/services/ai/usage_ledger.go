@@ -0,0 +1,241 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain/ai"
+	"go.uber.org/zap"
+)
+
+const (
+	// usageLedgerVersion is the schema version of the ledger file.
+	usageLedgerVersion = 1
+	// usageRetentionMonths is how many months before the current one the
+	// ledger keeps.
+	usageRetentionMonths = 12
+)
+
+// usageLedgerFile is the on-disk form of the ledger.
+type usageLedgerFile struct {
+	Version int              `json:"version"`
+	Records []ai.UsageRecord `json:"records"`
+}
+
+// usageKey identifies a ledger record.
+type usageKey struct {
+	day      string
+	provider ai.AIProvider
+	model    string
+	kind     ai.AIRequestType
+}
+
+// UsageLedger records the tokens used per provider, model, request type and
+// day, persisted to a JSON file so budgets hold across restarts. Records
+// older than a year are dropped. A ledger file that cannot be parsed is moved
+// aside to a ".corrupt" file rather than overwritten.
+type UsageLedger struct {
+	path   string
+	logger *zap.SugaredLogger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	loaded  bool
+	loadErr error
+	records map[usageKey]*ai.UsageRecord
+}
+
+// NewUsageLedger creates a ledger stored at path. The file is read on first
+// use.
+func NewUsageLedger(path string, logger *zap.SugaredLogger) *UsageLedger {
+	return &UsageLedger{
+		path:    path,
+		logger:  logger,
+		now:     time.Now,
+		records: make(map[usageKey]*ai.UsageRecord),
+	}
+}
+
+// Record adds usage by model of provider for a request of requestType to
+// today's record and saves the ledger.
+func (l *UsageLedger) Record(provider ai.AIProvider, model string, requestType ai.AIRequestType, usage ai.TokenUsage) error {
+	now := l.now()
+	key := usageKey{day: ai.UsageDay(now), provider: provider, model: model, kind: requestType}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.load()
+	if l.loadErr != nil {
+		// Saving now would overwrite the usage already on disk.
+		return l.loadErr
+	}
+	record, ok := l.records[key]
+	if !ok {
+		record = &ai.UsageRecord{Day: key.day, Provider: provider, Model: model, Type: requestType}
+		l.records[key] = record
+	}
+	record.Add(usage)
+	l.prune(now)
+	return l.save()
+}
+
+// DailyUsage returns provider's usage on the day t falls on; an empty
+// provider sums every provider.
+func (l *UsageLedger) DailyUsage(provider ai.AIProvider, t time.Time) ai.TokenUsage {
+	day := ai.UsageDay(t)
+	return l.sum(provider, func(recordDay string) bool { return recordDay == day })
+}
+
+// MonthlyUsage returns provider's usage in the calendar month t falls in; an
+// empty provider sums every provider.
+func (l *UsageLedger) MonthlyUsage(provider ai.AIProvider, t time.Time) ai.TokenUsage {
+	month := t.Format("2006-01-")
+	return l.sum(provider, func(recordDay string) bool { return strings.HasPrefix(recordDay, month) })
+}
+
+// Records returns the records since the day t falls on, newest day first,
+// then by provider, model and request type.
+func (l *UsageLedger) Records(since time.Time) []ai.UsageRecord {
+	day := ai.UsageDay(since)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.load()
+	var records []ai.UsageRecord
+	for _, record := range l.records {
+		if record.Day >= day {
+			records = append(records, *record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Day != b.Day {
+			return a.Day > b.Day
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.Type < b.Type
+	})
+	return records
+}
+
+// sum adds up provider's records on the days matching inPeriod.
+func (l *UsageLedger) sum(provider ai.AIProvider, inPeriod func(day string) bool) ai.TokenUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.load()
+	var total ai.TokenUsage
+	for key, record := range l.records {
+		if (provider == "" || key.provider == provider) && inPeriod(key.day) {
+			total.Add(record.TokenUsage)
+		}
+	}
+	return total
+}
+
+// prune drops records from before the retention period (called with l.mu
+// held).
+func (l *UsageLedger) prune(now time.Time) {
+	year, month, _ := now.Date()
+	oldest := ai.UsageDay(time.Date(year, month-usageRetentionMonths, 1, 0, 0, 0, 0, now.Location()))
+	for key := range l.records {
+		if key.day < oldest {
+			delete(l.records, key)
+		}
+	}
+}
+
+// load reads the ledger file once (called with l.mu held). If the file
+// cannot be read, or is unreadable and cannot be moved aside, loadErr is set
+// so that Record does not replace it.
+func (l *UsageLedger) load() {
+	if l.loaded {
+		return
+	}
+	l.loaded = true
+	data, err := os.ReadFile(l.path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		l.logWarn("failed to read token usage ledger", "error", err, "path", l.path)
+		l.loadErr = fmt.Errorf("read token usage ledger '%s': %w", l.path, err)
+	default:
+		var file usageLedgerFile
+		if err := json.Unmarshal(data, &file); err != nil || file.Version != usageLedgerVersion {
+			corruptPath := l.path + ".corrupt"
+			l.logWarn("moving aside unreadable token usage ledger", "error", err, "version", file.Version, "path", l.path, "moved_to", corruptPath)
+			if err := os.Rename(l.path, corruptPath); err != nil {
+				l.loadErr = fmt.Errorf("move unreadable token usage ledger '%s' to '%s': %w", l.path, corruptPath, err)
+			}
+			return
+		}
+		for i := range file.Records {
+			record := file.Records[i]
+			key := usageKey{day: record.Day, provider: record.Provider, model: record.Model, kind: record.Type}
+			if existing, ok := l.records[key]; ok {
+				existing.Add(record.TokenUsage)
+			} else {
+				l.records[key] = &record
+			}
+		}
+	}
+}
+
+// save writes the ledger with a write-temp-then-rename (called with l.mu
+// held).
+func (l *UsageLedger) save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+		return fmt.Errorf("ensure token usage ledger directory for '%s': %w", l.path, err)
+	}
+	file := usageLedgerFile{Version: usageLedgerVersion, Records: make([]ai.UsageRecord, 0, len(l.records))}
+	for _, record := range l.records {
+		file.Records = append(file.Records, *record)
+	}
+	sort.Slice(file.Records, func(i, j int) bool { return file.Records[i].Day < file.Records[j].Day })
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("marshal token usage ledger: %w", err)
+	}
+	tempFile := l.path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0o600); err != nil {
+		return fmt.Errorf("write temporary token usage ledger '%s': %w", tempFile, err)
+	}
+	if err := os.Rename(tempFile, l.path); err != nil {
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("rename temporary token usage ledger '%s' to '%s': %w", tempFile, l.path, err)
+	}
+	return nil
+}
+
+func (l *UsageLedger) logWarn(msg string, keysAndValues ...interface{}) {
+	if l.logger != nil {
+		l.logger.Warnw(msg, keysAndValues...)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	aiDomain "github.com/aryasoni98/wooak/internal/core/domain/ai"
+)
+
+// ledgerAt returns a ledger at path whose clock reads now.
+func ledgerAt(path string, now time.Time) *UsageLedger {
+	ledger := NewUsageLedger(path, nil)
+	ledger.now = func() time.Time { return now }
+	return ledger
+}
+
+func TestUsageLedger_RecordAndSum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	day := time.Date(2026, 3, 14, 12, 0, 0, 0, time.Local)
+	usage := aiDomain.TokenUsage{PromptTokens: 100, CompletionTokens: 20, Requests: 1}
+
+	earlier := ledgerAt(path, day.AddDate(0, 0, -1))
+	if err := earlier.Record(aiDomain.ProviderOpenAI, "gpt-4o", aiDomain.RequestTypeSearch, usage); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	lastMonth := ledgerAt(path, day.AddDate(0, -1, 0))
+	if err := lastMonth.Record(aiDomain.ProviderOpenAI, "gpt-4o", aiDomain.RequestTypeSearch, usage); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// A new instance reads what the others saved.
+	ledger := ledgerAt(path, day)
+	for _, provider := range []aiDomain.AIProvider{aiDomain.ProviderOpenAI, aiDomain.ProviderOllama, aiDomain.ProviderOpenAI} {
+		if err := ledger.Record(provider, "m", aiDomain.RequestTypeRecommendation, usage); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	if got := ledger.DailyUsage(aiDomain.ProviderOpenAI, day); got.Total() != 240 || got.Requests != 2 {
+		t.Errorf("Unexpected daily usage %+v", got)
+	}
+	if got := ledger.DailyUsage("", day); got.Total() != 360 {
+		t.Errorf("Expected every provider's usage to be summed, got %+v", got)
+	}
+	if got := ledger.MonthlyUsage(aiDomain.ProviderOpenAI, day); got.Total() != 360 || got.Requests != 3 {
+		t.Errorf("Unexpected monthly usage %+v", got)
+	}
+
+	records := ledger.Records(day.AddDate(0, 0, -1))
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records since yesterday, got %+v", records)
+	}
+	if records[0].Day != "2026-03-14" || records[0].Provider != aiDomain.ProviderOllama || records[2].Day != "2026-03-13" {
+		t.Errorf("Expected newest days first, then by provider, got %+v", records)
+	}
+}
+
+func TestUsageLedger_DropsOldRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.Local)
+	usage := aiDomain.TokenUsage{PromptTokens: 1, Requests: 1}
+
+	if err := ledgerAt(path, now.AddDate(-1, -1, 0)).Record(aiDomain.ProviderOllama, "m", aiDomain.RequestTypeSearch, usage); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := ledgerAt(path, now).Record(aiDomain.ProviderOllama, "m", aiDomain.RequestTypeSearch, usage); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	if records := ledgerAt(path, now).Records(time.Time{}); len(records) != 1 {
+		t.Errorf("Expected records older than a year to be dropped, got %+v", records)
+	}
+}
+
+func TestUsageLedger_KeepsUnreadableFile(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.Local)
+	usage := aiDomain.TokenUsage{PromptTokens: 1, Requests: 1}
+
+	for name, content := range map[string]string{
+		"bad json":        `{"version": 1, "records": [`,
+		"unknown version": `{"version": 99, "records": []}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "usage.json")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ledgerAt(path, now).Record(aiDomain.ProviderOllama, "m", aiDomain.RequestTypeSearch, usage); err != nil {
+				t.Fatalf("Record failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path + ".corrupt")
+			if err != nil || string(data) != content {
+				t.Errorf("Expected the original ledger to be kept aside, got %q (%v)", data, err)
+			}
+			if got := ledgerAt(path, now).DailyUsage("", now); got.Requests != 1 {
+				t.Errorf("Expected the new record to be saved, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestUsageLedger_UnreadableFileNotOverwritten(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "usage.json")
+	content := "not json"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in the way makes moving the file aside fail.
+	if err := os.Mkdir(path+".corrupt", 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path+".corrupt", "keep"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ledger := ledgerAt(path, time.Date(2026, 3, 14, 12, 0, 0, 0, time.Local))
+	if err := ledger.Record(aiDomain.ProviderOllama, "m", aiDomain.RequestTypeSearch, aiDomain.TokenUsage{Requests: 1}); err == nil {
+		t.Error("Expected Record to fail when the unreadable ledger cannot be moved aside")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != content {
+		t.Errorf("Expected the original ledger to be left in place, got %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
+)
+
+// Algorithms OpenSSH has deprecated or removed from its defaults.
+var (
+	weakCiphers = []string{
+		"3des-cbc", "aes128-cbc", "aes192-cbc", "aes256-cbc", "blowfish-cbc",
+		"cast128-cbc", "arcfour", "arcfour128", "arcfour256", "rijndael-cbc@lysator.liu.se",
+	}
+	weakMACs = []string{
+		"hmac-md5", "hmac-md5-96", "hmac-md5-etm@openssh.com", "hmac-md5-96-etm@openssh.com",
+		"hmac-sha1", "hmac-sha1-96", "hmac-sha1-etm@openssh.com", "hmac-sha1-96-etm@openssh.com",
+		"hmac-ripemd160", "hmac-ripemd160@openssh.com", "hmac-ripemd160-etm@openssh.com",
+		"umac-64@openssh.com", "umac-64-etm@openssh.com",
+	}
+	weakKexAlgorithms = []string{
+		"diffie-hellman-group1-sha1", "diffie-hellman-group14-sha1",
+		"diffie-hellman-group-exchange-sha1", "rsa1024-sha1",
+	}
+)
+
+// configCheck inspects one server for a rule and returns the finding without
+// its rule ID, severity and host, or nil if the server passes.
+type configCheck func(policy *security.SecurityPolicy, server domain.Server) *security.ConfigFinding
+
+// configChecks are the per-server rules in report order. The config file
+// permission rule is checked by ValidateSSHConfig.
+var configChecks = []struct {
+	id    string
+	check configCheck
+}{
+	{security.RuleStrictHostKeyChecking, checkStrictHostKeyChecking},
+	{security.RuleKnownHostsDiscarded, checkKnownHostsDiscarded},
+	{security.RuleForwardAgent, checkForwardAgent},
+	{security.RuleWeakCiphers, weakAlgorithmCheck("Ciphers", func(s domain.Server) string { return s.Ciphers }, weakCiphers)},
+	{security.RuleWeakMACs, weakAlgorithmCheck("MACs", func(s domain.Server) string { return s.MACs }, weakMACs)},
+	{security.RuleWeakKexAlgorithms, weakAlgorithmCheck("KexAlgorithms", func(s domain.Server) string { return s.KexAlgorithms }, weakKexAlgorithms)},
+	{security.RulePasswordAuthentication, checkPasswordAuthentication},
+	{security.RuleLocalCommand, checkLocalCommand},
+}
+
+// SetEffectiveConfigSource sets the function that returns the merged options
+// that apply to each of a list of aliases, e.g.
+// ServerService.GetEffectiveConfigs. With it, LintServer also checks what
+// servers inherit from Host * and other wildcard Host or Match blocks.
+func (s *SecurityService) SetEffectiveConfigSource(source func(aliases []string) (map[string]domain.EffectiveConfig, error)) {
+	s.effectiveConfigs = source
+}
+
+// LintServer checks the SSH options of server, including those it inherits
+// when an effective config source is set, against the config rules the
+// security policy enables.
+func (s *SecurityService) LintServer(server domain.Server) []security.ConfigFinding {
+	return s.LintEach([]domain.Server{server})[server.Alias]
+}
+
+// LintEach runs LintServer for each server and returns the findings by alias.
+// The effective configs of all servers are resolved in a single call.
+func (s *SecurityService) LintEach(servers []domain.Server) map[string][]security.ConfigFinding {
+	configs := s.resolveEffectiveConfigs(servers)
+	findings := make(map[string][]security.ConfigFinding, len(servers))
+	for _, server := range servers {
+		var effective *domain.EffectiveConfig
+		if config, ok := configs[server.Alias]; ok {
+			effective = &config
+		}
+		if serverFindings := s.lintServer(server, effective); len(serverFindings) > 0 {
+			findings[server.Alias] = serverFindings
+		}
+	}
+	return findings
+}
+
+// resolveEffectiveConfigs returns the effective configs of servers by alias,
+// or nil without an effective config source or if it fails.
+func (s *SecurityService) resolveEffectiveConfigs(servers []domain.Server) map[string]domain.EffectiveConfig {
+	if s.effectiveConfigs == nil {
+		return nil
+	}
+	aliases := make([]string, 0, len(servers))
+	for _, server := range servers {
+		if server.Alias != "" {
+			aliases = append(aliases, server.Alias)
+		}
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+	configs, err := s.effectiveConfigs(aliases)
+	if err != nil {
+		return nil
+	}
+	return configs
+}
+
+// lintServer lints server with the options effective, when non-nil, says it
+// inherits.
+func (s *SecurityService) lintServer(server domain.Server, effective *domain.EffectiveConfig) []security.ConfigFinding {
+	server, inherited := effectiveServer(server, effective)
+	var findings []security.ConfigFinding
+	for _, rule := range configChecks {
+		severity, enabled := s.policy.ConfigRuleSeverity(rule.id)
+		if !enabled {
+			continue
+		}
+		if finding := rule.check(s.policy, server); finding != nil {
+			finding.RuleID = rule.id
+			finding.Severity = severity
+			finding.Host = server.Alias
+			if block, ok := inherited[finding.Option]; ok {
+				finding.Message += fmt.Sprintf(" (set in %s)", block)
+			}
+			findings = append(findings, *finding)
+		}
+	}
+	return findings
+}
+
+// effectiveServer returns server with the options ssh uses for it according
+// to config, in first-match-wins order, and the blocks of those it inherits by
+// option. Without a config, server is returned as is.
+func effectiveServer(server domain.Server, config *domain.EffectiveConfig) (domain.Server, map[string]string) {
+	if config == nil {
+		return server, nil
+	}
+
+	inherited := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, option := range config.Options {
+		name, ok := domain.CanonicalServerOption(option.Key)
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if !option.Inherited {
+			continue
+		}
+		// Apply changes one at a time so an option ssh would reject does
+		// not hide the others.
+		patched, diffs, err := domain.ApplyServerChanges(server, []domain.ServerFieldChange{{Option: name, Value: option.Value}})
+		if err == nil && len(diffs) > 0 {
+			server = patched
+			inherited[name] = option.Block
+		}
+	}
+	return server, inherited
+}
+
+// LintServers runs LintServer for each server and returns all findings, in
+// the order of servers.
+func (s *SecurityService) LintServers(servers []domain.Server) []security.ConfigFinding {
+	byAlias := s.LintEach(servers)
+	var findings []security.ConfigFinding
+	for _, server := range servers {
+		findings = append(findings, byAlias[server.Alias]...)
+		// A repeated alias is reported once.
+		delete(byAlias, server.Alias)
+	}
+	return findings
+}
+
+func checkStrictHostKeyChecking(_ *security.SecurityPolicy, server domain.Server) *security.ConfigFinding {
+	value := strings.ToLower(server.StrictHostKeyChecking)
+	if value != "no" && value != "off" {
+		return nil
+	}
+	return &security.ConfigFinding{
+		Option:         "StrictHostKeyChecking",
+		Value:          server.StrictHostKeyChecking,
+		Message:        fmt.Sprintf("StrictHostKeyChecking %s accepts changed host keys, so a man-in-the-middle goes unnoticed", server.StrictHostKeyChecking),
+		Recommendation: "Use StrictHostKeyChecking yes, or accept-new to trust only first-time hosts",
+	}
+}
+
+func checkKnownHostsDiscarded(_ *security.SecurityPolicy, server domain.Server) *security.ConfigFinding {
+	for _, file := range strings.Fields(server.UserKnownHostsFile) {
+		if file == "/dev/null" || strings.EqualFold(file, "none") {
+			return &security.ConfigFinding{
+				Option:         "UserKnownHostsFile",
+				Value:          server.UserKnownHostsFile,
+				Message:        fmt.Sprintf("UserKnownHostsFile %s never remembers host keys, so every connection trusts whatever key it is offered", file),
+				Recommendation: "Remove UserKnownHostsFile or point it at a real file",
+			}
+		}
+	}
+	return nil
+}
+
+func checkForwardAgent(policy *security.SecurityPolicy, server domain.Server) *security.ConfigFinding {
+	value := strings.TrimSpace(server.ForwardAgent)
+	if value == "" || strings.EqualFold(value, "no") || hostListed(policy.AllowedHosts, server) {
+		return nil
+	}
+	return &security.ConfigFinding{
+		Option:         "ForwardAgent",
+		Value:          value,
+		Message:        "ForwardAgent lets anyone with root on the remote host use your keys while you are connected",
+		Recommendation: "Disable ForwardAgent, use ProxyJump instead, or add the host to the allowed hosts",
+	}
+}
+
+// weakAlgorithmCheck returns a check that flags the weak algorithms an
+// algorithm list option enables.
+func weakAlgorithmCheck(option string, value func(domain.Server) string, weak []string) configCheck {
+	return func(_ *security.SecurityPolicy, server domain.Server) *security.ConfigFinding {
+		list := strings.TrimSpace(value(server))
+		found := enabledAlgorithms(list, weak)
+		if len(found) == 0 {
+			return nil
+		}
+		return &security.ConfigFinding{
+			Option:         option,
+			Value:          list,
+			Message:        fmt.Sprintf("%s enables weak algorithms: %s", option, strings.Join(found, ", ")),
+			Recommendation: fmt.Sprintf("Remove %s from %s", strings.Join(found, ", "), option),
+		}
+	}
+}
+
+// enabledAlgorithms returns the entries of weak that an algorithm list
+// enables. Lists starting with "-" only remove algorithms; "+" and "^" add
+// to the defaults. Entries may use * and ? wildcards.
+func enabledAlgorithms(list string, weak []string) []string {
+	if list == "" || strings.HasPrefix(list, "-") {
+		return nil
+	}
+	list = strings.TrimLeft(list, "+^")
+
+	var found []string
+	for _, weakName := range weak {
+		for _, entry := range strings.Split(list, ",") {
+			entry = strings.ToLower(strings.TrimSpace(entry))
+			if matched, err := path.Match(entry, weakName); err == nil && matched {
+				found = append(found, weakName)
+				break
+			}
+		}
+	}
+	return found
+}
+
+func checkPasswordAuthentication(_ *security.SecurityPolicy, server domain.Server) *security.ConfigFinding {
+	if !strings.EqualFold(server.PasswordAuthentication, "yes") {
+		return nil
+	}
+	return &security.ConfigFinding{
+		Option:         "PasswordAuthentication",
+		Value:          server.PasswordAuthentication,
+		Message:        "PasswordAuthentication yes allows passwords, which can be guessed or phished",
+		Recommendation: "Use key authentication and set PasswordAuthentication no",
+	}
+}
+
+func checkLocalCommand(_ *security.SecurityPolicy, server domain.Server) *security.ConfigFinding {
+	if !strings.EqualFold(server.PermitLocalCommand, "yes") || strings.TrimSpace(server.LocalCommand) == "" {
+		return nil
+	}
+	return &security.ConfigFinding{
+		Option:         "LocalCommand",
+		Value:          server.LocalCommand,
+		Message:        fmt.Sprintf("PermitLocalCommand runs %q on this machine at every connection", server.LocalCommand),
+		Recommendation: "Remove LocalCommand or set PermitLocalCommand no unless the command is needed",
+	}
+}
+
+// hostListed reports whether the server's host name or alias is in hosts.
+// Entries that do not parse are skipped, as CheckHostSecurity does. Names
+// are not resolved, so CIDR entries only match IP addresses.
+func hostListed(hosts []string, server domain.Server) bool {
+	list := parseHostList(hosts, "", nil)
+	if len(list) == 0 {
+		return false
+	}
+	for _, name := range []string{server.Host, server.Alias} {
+		if name == "" {
+			continue
+		}
+		if _, matched := list.Match(name, nil); matched {
+			return true
+		}
+	}
+	return false
+}
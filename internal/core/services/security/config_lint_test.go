@@ -0,0 +1,186 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	securityDomain "github.com/aryasoni98/wooak/internal/core/domain/security"
+)
+
+func findingIDs(findings []securityDomain.ConfigFinding) string {
+	ids := make([]string, 0, len(findings))
+	for _, f := range findings {
+		ids = append(ids, f.RuleID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSecurityService_LintServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		server domain.Server
+		want   string
+	}{
+		{name: "clean", server: domain.Server{StrictHostKeyChecking: "accept-new", ForwardAgent: "no", Ciphers: "chacha20-poly1305@openssh.com"}},
+		{name: "host key checking off", server: domain.Server{StrictHostKeyChecking: "no"}, want: securityDomain.RuleStrictHostKeyChecking},
+		{name: "known hosts discarded", server: domain.Server{UserKnownHostsFile: "~/.ssh/known_hosts /dev/null"}, want: securityDomain.RuleKnownHostsDiscarded},
+		{name: "forward agent", server: domain.Server{ForwardAgent: "yes"}, want: securityDomain.RuleForwardAgent},
+		{name: "forward agent socket", server: domain.Server{ForwardAgent: "$SSH_AUTH_SOCK"}, want: securityDomain.RuleForwardAgent},
+		{name: "weak cipher appended", server: domain.Server{Ciphers: "+aes128-cbc"}, want: securityDomain.RuleWeakCiphers},
+		{name: "removing weak cipher", server: domain.Server{Ciphers: "-aes128-cbc,3des-cbc"}},
+		{name: "weak mac wildcard", server: domain.Server{MACs: "hmac-sha2-256,hmac-md5*"}, want: securityDomain.RuleWeakMACs},
+		{name: "weak kex", server: domain.Server{KexAlgorithms: "^diffie-hellman-group1-sha1"}, want: securityDomain.RuleWeakKexAlgorithms},
+		{name: "password authentication", server: domain.Server{PasswordAuthentication: "yes"}, want: securityDomain.RulePasswordAuthentication},
+		{name: "local command without permit", server: domain.Server{LocalCommand: "notify-send hi"}},
+		{name: "local command", server: domain.Server{PermitLocalCommand: "yes", LocalCommand: "notify-send hi"}, want: securityDomain.RuleLocalCommand},
+		{
+			name:   "rule order",
+			server: domain.Server{PasswordAuthentication: "yes", StrictHostKeyChecking: "off", ForwardAgent: "yes"},
+			want:   "strict-host-key-checking,forward-agent,password-authentication",
+		},
+	}
+
+	service := NewSecurityService(securityDomain.DefaultSecurityPolicy())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.Alias = "web"
+			findings := service.LintServer(tt.server)
+			if got := findingIDs(findings); got != tt.want {
+				t.Fatalf("LintServer() rules = %q, want %q", got, tt.want)
+			}
+			for _, f := range findings {
+				if f.Host != "web" || f.Severity == "" || f.Message == "" {
+					t.Errorf("incomplete finding: %+v", f)
+				}
+			}
+		})
+	}
+}
+
+func TestSecurityService_LintServer_Policy(t *testing.T) {
+	server := domain.Server{Alias: "bastion", Host: "bastion.example.com", ForwardAgent: "yes", PasswordAuthentication: "yes", MACs: "hmac-sha1"}
+
+	policy := securityDomain.DefaultSecurityPolicy()
+	// A broken entry does not stop the others from matching.
+	policy.AllowedHosts = []string{"/[unclosed/", "*.example.com"}
+	policy.ConfigRules = map[string]securityDomain.ConfigRuleSetting{
+		securityDomain.RulePasswordAuthentication: {Disabled: true},
+		securityDomain.RuleWeakMACs:               {Severity: securityDomain.SeverityCritical},
+	}
+
+	findings := NewSecurityService(policy).LintServer(server)
+	if got := findingIDs(findings); got != securityDomain.RuleWeakMACs {
+		t.Fatalf("rules = %q, want only %s (agent forwarding to an allowed host and a disabled rule)", got, securityDomain.RuleWeakMACs)
+	}
+	if findings[0].Severity != securityDomain.SeverityCritical {
+		t.Errorf("severity = %s, want the policy override", findings[0].Severity)
+	}
+}
+
+func TestSecurityService_LintServer_Inherited(t *testing.T) {
+	// Host web
+	//     ForwardAgent no
+	// Host *
+	//     ForwardAgent yes
+	//     StrictHostKeyChecking no
+	server := domain.Server{Alias: "web", Host: "web.example.com", ForwardAgent: "no"}
+	service := NewSecurityService(securityDomain.DefaultSecurityPolicy())
+	calls := 0
+	service.SetEffectiveConfigSource(func(aliases []string) (map[string]domain.EffectiveConfig, error) {
+		calls++
+		configs := make(map[string]domain.EffectiveConfig, len(aliases))
+		for _, alias := range aliases {
+			configs[alias] = domain.EffectiveConfig{Alias: alias, Options: []domain.EffectiveOption{
+				{Key: "HostName", Value: alias + ".example.com", Block: "Host " + alias, Kind: domain.BlockHost},
+				{Key: "ForwardAgent", Value: "no", Block: "Host " + alias, Kind: domain.BlockHost},
+				{Key: "ForwardAgent", Value: "yes", Block: "Host *", Kind: domain.BlockHost, Inherited: true},
+				{Key: "StrictHostKeyChecking", Value: "no", Block: "Host *", Kind: domain.BlockHost, Inherited: true},
+			}}
+		}
+		return configs, nil
+	})
+
+	findings := service.LintServer(server)
+	if got := findingIDs(findings); got != securityDomain.RuleStrictHostKeyChecking {
+		t.Fatalf("rules = %q, want only %s (the server's own ForwardAgent wins)", got, securityDomain.RuleStrictHostKeyChecking)
+	}
+	if findings[0].Host != "web" || !strings.HasSuffix(findings[0].Message, "(set in Host *)") {
+		t.Errorf("finding = %+v, want it attributed to web and the Host * block", findings[0])
+	}
+
+	// Linting a list resolves the effective configs once for all servers.
+	calls = 0
+	db := domain.Server{Alias: "db", Host: "db.example.com", ForwardAgent: "no"}
+	byAlias := service.LintEach([]domain.Server{server, db})
+	if calls != 1 {
+		t.Errorf("effective config source called %d times, want once", calls)
+	}
+	if len(byAlias["web"]) != 1 || len(byAlias["db"]) != 1 || byAlias["db"][0].Host != "db" {
+		t.Errorf("LintEach() = %+v, want one inherited finding per server", byAlias)
+	}
+
+	service.SetEffectiveConfigSource(func([]string) (map[string]domain.EffectiveConfig, error) {
+		return nil, errors.New("config unreadable")
+	})
+	if findings := service.LintServer(server); len(findings) != 0 {
+		t.Errorf("findings = %+v, want the server's own options to be linted when the effective config fails", findings)
+	}
+}
+
+func TestSecurityService_ValidateSSHConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte("Host web\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(configPath, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	servers := []domain.Server{
+		{Alias: "web", StrictHostKeyChecking: "no"},
+		{Alias: "db", ForwardAgent: "yes", PasswordAuthentication: "yes"},
+	}
+	service := NewSecurityService(securityDomain.DefaultSecurityPolicy())
+	result := service.ValidateSSHConfig(configPath, servers)
+
+	want := "config-permissions,strict-host-key-checking,forward-agent,password-authentication"
+	if got := findingIDs(result.Findings); got != want {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+	if result.IsValid {
+		t.Error("error findings should make the config invalid")
+	}
+	if len(result.Issues) != 2 || !strings.HasPrefix(result.Issues[1], "[strict-host-key-checking] web: ") {
+		t.Errorf("issues = %q", result.Issues)
+	}
+	if len(result.Warnings) != 2 || len(result.Recommendations) != 4 {
+		t.Errorf("warnings = %q, recommendations = %q", result.Warnings, result.Recommendations)
+	}
+
+	// Warnings alone keep the config valid.
+	if err := os.Chmod(configPath, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result = service.ValidateSSHConfig(configPath, servers[1:])
+	if !result.IsValid || len(result.Findings) != 2 {
+		t.Errorf("result = %+v, want valid with 2 findings", result)
+	}
+}
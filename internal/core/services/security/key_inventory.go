@@ -0,0 +1,269 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
+)
+
+// maxKeyFileSize bounds how much of a key file is read; real keys are a few
+// kilobytes.
+const maxKeyFileSize = 64 << 10
+
+// commonKeyFiles are the default identity file names ssh tries, listed first
+// by DiscoverSSHKeys.
+var commonKeyFiles = []string{
+	"id_ed25519",
+	"id_rsa",
+	"id_ecdsa",
+	"id_dsa",
+	"id_ed25519_sk",
+	"id_ecdsa_sk",
+}
+
+// DiscoverSSHKeys returns the private key files in homeDir's .ssh directory,
+// in tilde notation (e.g. ~/.ssh/id_ed25519): the default identity files
+// first, then any other readable file that looks like a private key.
+func DiscoverSSHKeys(homeDir string) []string {
+	sshDir := filepath.Join(homeDir, ".ssh")
+	keys := []string{}
+
+	// First, add common key files if they exist and are readable
+	for _, keyName := range commonKeyFiles {
+		keyPath := filepath.Join(sshDir, keyName)
+		if info, err := os.Stat(keyPath); err == nil && !info.IsDir() {
+			// #nosec G304 - keyPath is constructed from known safe values
+			if file, err := os.Open(keyPath); err == nil {
+				_ = file.Close()
+				keys = append(keys, "~/.ssh/"+keyName)
+			}
+		}
+	}
+
+	// Additionally, scan for any other files that look like private keys
+	entries, err := os.ReadDir(sshDir)
+	if err != nil {
+		return keys // Return what we have so far
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		// Skip public keys, known_hosts, config, and authorized_keys
+		if strings.HasSuffix(name, ".pub") ||
+			name == "known_hosts" ||
+			name == "config" ||
+			name == "authorized_keys" ||
+			strings.HasPrefix(name, ".") ||
+			containsString(commonKeyFiles, name) {
+			continue
+		}
+
+		// Check if file is readable and looks like a private key
+		keyPath := filepath.Join(sshDir, name)
+		// #nosec G304 - keyPath is constructed from safe directory enumeration
+		if file, err := os.Open(keyPath); err == nil {
+			buffer := make([]byte, 100)
+			n, readErr := file.Read(buffer)
+			_ = file.Close()
+			if readErr == nil && n > 0 && strings.Contains(string(buffer[:n]), "PRIVATE KEY") {
+				keys = append(keys, "~/.ssh/"+name)
+			}
+		}
+	}
+
+	return keys
+}
+
+// AuditKeys checks the private keys in ~/.ssh and the IdentityFiles of
+// servers against the security policy. Each key is listed once, with the
+// servers using it and its file permissions; validation results are cached
+// until the file changes.
+func (s *SecurityService) AuditKeys(servers []domain.Server) []security.KeyAuditEntry {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = ""
+	}
+
+	users := make(map[string][]string) // absolute path -> aliases
+	var paths []string
+	addPath := func(path string) {
+		if _, ok := users[path]; !ok {
+			users[path] = nil
+			paths = append(paths, path)
+		}
+	}
+	if homeDir != "" {
+		for _, key := range DiscoverSSHKeys(homeDir) {
+			addPath(expandIdentityFile(key, homeDir, domain.Server{}))
+		}
+	}
+	for _, server := range servers {
+		for _, identityFile := range server.IdentityFiles {
+			path := expandIdentityFile(identityFile, homeDir, server)
+			addPath(path)
+			if !containsString(users[path], server.Alias) {
+				users[path] = append(users[path], server.Alias)
+			}
+		}
+	}
+
+	entries := make([]security.KeyAuditEntry, 0, len(paths))
+	issues := 0
+	for _, path := range paths {
+		entry := s.auditKeyFile(path)
+		entry.Path = tildePath(path, homeDir)
+		entry.Servers = users[path]
+		sort.Strings(entry.Servers)
+		if entry.HasIssues() {
+			issues++
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	severity := security.SeverityInfo
+	if issues > 0 {
+		severity = security.SeverityWarning
+	}
+	s.auditLog.LogEvent(security.NewSecurityEvent(
+		security.EventTypeKeyValidation,
+		severity,
+		fmt.Sprintf("Key inventory scan found %d key(s), %d with issues", len(entries), issues),
+	).WithSource("security_service").WithDetails("keys", len(entries)).WithDetails("issues", issues))
+
+	return entries
+}
+
+// auditKeyFile checks the key file at path. The validation result is cached
+// by path, modification time and size, and the file permissions are checked
+// on every scan.
+func (s *SecurityService) auditKeyFile(path string) security.KeyAuditEntry {
+	var entry security.KeyAuditEntry
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		entry.Error = "file does not exist"
+		return entry
+	case err != nil:
+		entry.Error = err.Error()
+		return entry
+	case info.IsDir():
+		entry.Error = "path is a directory"
+		return entry
+	case info.Size() > maxKeyFileSize:
+		entry.Error = fmt.Sprintf("file is larger than %d bytes", maxKeyFileSize)
+		return entry
+	}
+	entry.Mode = fmt.Sprintf("%04o", info.Mode().Perm())
+	entry.Modified = info.ModTime()
+
+	cacheKey := fmt.Sprintf("file:%s:%d:%d", path, info.ModTime().UnixNano(), info.Size())
+	result, cached := s.keyCache.Get(cacheKey)
+	if !cached {
+		data, err := os.ReadFile(path) // #nosec G304 - path is a key file the user's SSH config or ~/.ssh refers to
+		if err != nil {
+			entry.Error = err.Error()
+			return entry
+		}
+		result = s.validator.ValidateKey(string(data))
+		if result.KeyInfo != nil && result.KeyInfo.Comment == "" {
+			// Encrypted keys hide their comment; the .pub file has it.
+			result.KeyInfo.Comment = publicKeyComment(path + ".pub")
+		}
+		s.keyCache.Set(cacheKey, result)
+	}
+
+	// Copy the cached result before adding the permission check.
+	checked := *result
+	checked.Issues = append([]string(nil), result.Issues...)
+	if result.KeyInfo != nil && result.KeyInfo.Private && info.Mode().Perm()&0o077 != 0 {
+		checked.IsValid = false
+		checked.Issues = append(checked.Issues, fmt.Sprintf("Private key permissions %s are too open; ssh ignores the key unless only you can read it (chmod 600)", entry.Mode))
+	}
+	entry.Validation = &checked
+	return entry
+}
+
+// publicKeyComment returns the comment of the public key file at path, or ""
+// if there is none.
+func publicKeyComment(path string) string {
+	data, err := os.ReadFile(path) // #nosec G304 - path is the .pub file next to a key file
+	if err != nil || len(data) > maxKeyFileSize {
+		return ""
+	}
+	fields := strings.Fields(strings.SplitN(string(data), "\n", 2)[0])
+	if len(fields) < 3 {
+		return ""
+	}
+	return strings.Join(fields[2:], " ")
+}
+
+// expandIdentityFile resolves an IdentityFile value the way ssh does: ~ and
+// %d are the home directory, %u the local user, %h, %r and %p the server's
+// host, user and port, and %% a literal percent sign.
+func expandIdentityFile(path, homeDir string, server domain.Server) string {
+	if path == "~" {
+		path = homeDir
+	} else if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		path = filepath.Join(homeDir, rest)
+	}
+	if strings.Contains(path, "%") {
+		localUser := ""
+		if u, err := user.Current(); err == nil {
+			localUser = u.Username
+		}
+		host := server.Host
+		if host == "" {
+			host = server.Alias
+		}
+		port := ""
+		if server.Port > 0 {
+			port = fmt.Sprintf("%d", server.Port)
+		}
+		path = strings.NewReplacer("%%", "%", "%d", homeDir, "%u", localUser, "%h", host, "%r", server.User, "%p", port).Replace(path)
+	}
+	return filepath.Clean(path)
+}
+
+// tildePath shortens paths in homeDir to tilde notation.
+func tildePath(path, homeDir string) string {
+	if homeDir != "" {
+		if rest, ok := strings.CutPrefix(path, homeDir+string(filepath.Separator)); ok {
+			return "~/" + filepath.ToSlash(rest)
+		}
+	}
+	return path
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -15,9 +15,14 @@
 package security
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/aryasoni98/wooak/internal/core/domain"
 	"github.com/aryasoni98/wooak/internal/core/domain/security"
 	"go.uber.org/zap"
 )
@@ -28,6 +33,10 @@ type SecurityService struct {
 	validator *security.KeyValidator
 	auditLog  *AuditLogger
 	keyCache  *KeyValidationCache
+	lookupIP  func(ctx context.Context, host string) ([]net.IPAddr, error)
+	// effectiveConfigs returns the merged options that apply to each alias;
+	// nil lints servers by their own Host block only.
+	effectiveConfigs func(aliases []string) (map[string]domain.EffectiveConfig, error)
 }
 
 // NewSecurityService creates a new security service
@@ -46,6 +55,7 @@ func NewSecurityServiceWithLogger(policy *security.SecurityPolicy, logger *zap.S
 		validator: validator,
 		auditLog:  auditLog,
 		keyCache:  keyCache,
+		lookupIP:  net.DefaultResolver.LookupIPAddr,
 	}
 }
 
@@ -71,34 +81,40 @@ func (s *SecurityService) ValidateSSHKey(keyData string) *security.KeyValidation
 	return result
 }
 
-// ValidateSSHConfig validates the SSH configuration for security issues
-func (s *SecurityService) ValidateSSHConfig(configPath string) *SecurityConfigValidationResult {
+// ValidateSSHConfig validates the SSH configuration for security issues: the
+// permissions of the config file and the options of servers, checked against
+// the config rules the security policy enables.
+func (s *SecurityService) ValidateSSHConfig(configPath string, servers []domain.Server) *SecurityConfigValidationResult {
 	result := &SecurityConfigValidationResult{
 		IsValid:         true,
 		Issues:          []string{},
 		Warnings:        []string{},
 		Recommendations: []string{},
+		Findings:        []security.ConfigFinding{},
 	}
 
-	// Check if config file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		result.Warnings = append(result.Warnings, "SSH config file does not exist")
-		return result
-	}
-
-	// Check file permissions
+	// Check the config file and its permissions
 	info, err := os.Stat(configPath)
-	if err != nil {
+	switch {
+	case os.IsNotExist(err):
+		result.Warnings = append(result.Warnings, "SSH config file does not exist")
+	case err != nil:
 		result.Issues = append(result.Issues, fmt.Sprintf("Cannot read SSH config file: %v", err))
 		result.IsValid = false
-		return result
+	case info.Mode()&0o077 != 0:
+		if severity, enabled := s.policy.ConfigRuleSeverity(security.RuleConfigPermissions); enabled {
+			result.addFinding(security.ConfigFinding{
+				RuleID:         security.RuleConfigPermissions,
+				Severity:       severity,
+				Value:          fmt.Sprintf("%04o", info.Mode().Perm()),
+				Message:        "SSH config file has overly permissive permissions",
+				Recommendation: fmt.Sprintf("chmod 600 %s", configPath),
+			})
+		}
 	}
 
-	// Check if file permissions are too permissive
-	mode := info.Mode()
-	if mode&0o077 != 0 {
-		result.Issues = append(result.Issues, "SSH config file has overly permissive permissions")
-		result.IsValid = false
+	for _, finding := range s.LintServers(servers) {
+		result.addFinding(finding)
 	}
 
 	// Log the validation
@@ -106,12 +122,16 @@ func (s *SecurityService) ValidateSSHConfig(configPath string) *SecurityConfigVa
 		security.EventTypeConfigChange,
 		security.SeverityInfo,
 		"SSH config validation completed",
-	).WithSource("security_service").WithDetails("config_path", configPath))
+	).WithSource("security_service").WithDetails("config_path", configPath).WithDetails("findings", len(result.Findings)))
 
 	return result
 }
 
-// CheckHostSecurity checks if a host meets security requirements
+// CheckHostSecurity checks if a host meets security requirements. Allowed
+// and blocked host entries may be names, globs, /regex/ patterns, CIDRs or IP
+// addresses, and "!" negates an entry; the result names the entry that
+// decided. CIDR and IP entries match host names once they are resolved,
+// within the policy's DNS timeout.
 func (s *SecurityService) CheckHostSecurity(host string) *HostSecurityResult {
 	result := &HostSecurityResult{
 		Host:            host,
@@ -121,27 +141,36 @@ func (s *SecurityService) CheckHostSecurity(host string) *HostSecurityResult {
 		Recommendations: []string{},
 	}
 
+	allowed := parseHostList(s.policy.AllowedHosts, "allowed", result)
+	blocked := parseHostList(s.policy.BlockedHosts, "blocked", result)
+	var addrs []net.IP
+	if allowed.NeedsAddresses() || blocked.NeedsAddresses() {
+		addrs = s.resolveHost(host, result)
+	}
+
 	// Check if host is in blocked list
-	for _, blockedHost := range s.policy.BlockedHosts {
-		if host == blockedHost {
-			result.IsSecure = false
-			result.Issues = append(result.Issues, "Host is in blocked hosts list")
-			break
+	if rule, matched := blocked.Match(host, addrs); matched {
+		result.IsSecure = false
+		result.BlockedBy = rule
+		if rule == "" {
+			result.Issues = append(result.Issues, "Host is in blocked hosts list (not excluded by any rule)")
+		} else {
+			result.Issues = append(result.Issues, fmt.Sprintf("Host is in blocked hosts list (rule %q)", rule))
 		}
 	}
 
 	// Check if host is in allowed list (if allowed list is not empty)
 	if len(s.policy.AllowedHosts) > 0 {
-		allowed := false
-		for _, allowedHost := range s.policy.AllowedHosts {
-			if host == allowedHost {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
+		rule, matched := allowed.Match(host, addrs)
+		switch {
+		case matched:
+			result.AllowedBy = rule
+		case rule != "":
 			result.IsSecure = false
-			result.Issues = append(result.Issues, "Host is not in allowed hosts list")
+			result.Issues = append(result.Issues, fmt.Sprintf("Host is not in allowed hosts list (excluded by rule %q)", rule))
+		default:
+			result.IsSecure = false
+			result.Issues = append(result.Issues, "Host is not in allowed hosts list (no rule matches)")
 		}
 	}
 
@@ -155,11 +184,118 @@ func (s *SecurityService) CheckHostSecurity(host string) *HostSecurityResult {
 		security.EventTypeConnection,
 		severity,
 		fmt.Sprintf("Host security check completed for %s", host),
-	).WithSource("security_service").WithHost(host).WithDetails("is_secure", result.IsSecure))
+	).WithSource("security_service").WithHost(host).WithDetails("is_secure", result.IsSecure).
+		WithDetails("allowed_by", result.AllowedBy).WithDetails("blocked_by", result.BlockedBy))
 
 	return result
 }
 
+// parseHostList parses a host list of the policy, skipping entries that do
+// not parse. Each skipped entry adds a warning to result when it is non-nil;
+// list names the list in the warning.
+func parseHostList(entries []string, list string, result *HostSecurityResult) security.HostList {
+	hosts := make(security.HostList, 0, len(entries))
+	for _, entry := range entries {
+		pattern, err := security.ParseHostPattern(entry)
+		if err != nil {
+			if result != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("Ignoring %s hosts entry: %v", list, err))
+			}
+			continue
+		}
+		hosts = append(hosts, pattern)
+	}
+	return hosts
+}
+
+// resolveHost returns the addresses of host for CIDR and IP entries. IP
+// literals are matched directly, and nothing is resolved when the policy's
+// DNS timeout is zero.
+func (s *SecurityService) resolveHost(host string, result *HostSecurityResult) []net.IP {
+	if net.ParseIP(host) != nil || s.policy.DNSTimeout <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.policy.DNSTimeout)
+	defer cancel()
+	addrs, err := s.lookupIP(ctx, host)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Could not resolve %s within %s, so CIDR rules only match IP addresses: %v", host, s.policy.DNSTimeout, err))
+		return nil
+	}
+
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+		result.Addresses = append(result.Addresses, addr.IP.String())
+	}
+	return ips
+}
+
+// CheckConnection checks a connection to alias against the security policy
+// before ssh starts. resolved is the configuration `ssh -G` computes for
+// alias; its hostname is checked against the allowed and blocked hosts, and
+// its host key options against RequireHostKeyCheck. Violations are recorded
+// as a policy_violation event; they block the connection only when the
+// policy's enforcement is "block".
+func (s *SecurityService) CheckConnection(alias string, resolved domain.ResolvedConfig) security.ConnectionDecision {
+	host, _ := resolved.Get("hostname")
+	if host == "" {
+		host = alias
+	}
+	decision := security.ConnectionDecision{Alias: alias, Host: host, Allowed: true}
+	if s.policy.MaxConnectionTime > 0 {
+		decision.MaxDuration = time.Duration(s.policy.MaxConnectionTime) * time.Minute
+	}
+
+	for _, issue := range s.CheckHostSecurity(host).Issues {
+		decision.Violations = append(decision.Violations, fmt.Sprintf("%s: %s", host, issue))
+	}
+	if s.policy.RequireHostKeyCheck {
+		if value, _ := resolved.Get("stricthostkeychecking"); value == "false" || value == "no" {
+			decision.Violations = append(decision.Violations, "StrictHostKeyChecking is off, but the policy requires host key verification")
+		}
+		if value, _ := resolved.Get("userknownhostsfile"); containsString(strings.Fields(value), "/dev/null") {
+			decision.Violations = append(decision.Violations, "UserKnownHostsFile is /dev/null, but the policy requires host key verification")
+		}
+	}
+	if len(decision.Violations) == 0 {
+		return decision
+	}
+
+	severity, result := security.SeverityWarning, "warned"
+	if s.policy.Enforcement == security.EnforcementBlock {
+		severity, result = security.SeverityError, "blocked"
+		decision.Allowed = false
+	}
+	s.auditLog.LogEvent(security.NewSecurityEvent(
+		security.EventTypePolicyViolation,
+		severity,
+		fmt.Sprintf("Connection to %s %s by security policy: %s", alias, result, decision.Reason()),
+	).WithSource("security_service").WithHost(host).WithAction("connect").WithResult(result).
+		WithDetails("alias", alias).WithDetails("violations", decision.Violations))
+
+	return decision
+}
+
+// RecordConfigChange writes a config_change event for edits applied to the
+// server alias, listing each changed option with its old and new value.
+// source names what proposed the edits, e.g. "ai_assistant".
+func (s *SecurityService) RecordConfigChange(alias string, diffs []domain.ServerFieldDiff, source string) {
+	changes := make([]map[string]string, 0, len(diffs))
+	options := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		changes = append(changes, map[string]string{"option": diff.Option, "old": diff.Old, "new": diff.New})
+		options = append(options, diff.Option)
+	}
+
+	s.auditLog.LogEvent(security.NewSecurityEvent(
+		security.EventTypeConfigChange,
+		security.SeverityInfo,
+		fmt.Sprintf("Applied %d SSH config change(s) to %s: %s", len(diffs), alias, strings.Join(options, ", ")),
+	).WithSource(source).WithHost(alias).WithAction("update_server").WithResult("applied").WithDetails("changes", changes))
+}
+
 // GetSecurityPolicy returns the current security policy
 func (s *SecurityService) GetSecurityPolicy() *security.SecurityPolicy {
 	return s.policy
@@ -220,15 +356,41 @@ func (s *SecurityService) GetAuditLogStats() map[string]interface{} {
 
 // SecurityConfigValidationResult represents the result of SSH config validation
 type SecurityConfigValidationResult struct {
-	IsValid         bool     `json:"is_valid"`
-	Issues          []string `json:"issues"`
-	Warnings        []string `json:"warnings"`
-	Recommendations []string `json:"recommendations"`
+	IsValid         bool                     `json:"is_valid"`
+	Issues          []string                 `json:"issues"`
+	Warnings        []string                 `json:"warnings"`
+	Recommendations []string                 `json:"recommendations"`
+	Findings        []security.ConfigFinding `json:"findings"`
+}
+
+// addFinding records finding and lists its message as an issue (error and
+// critical findings, which make the config invalid) or as a warning.
+func (r *SecurityConfigValidationResult) addFinding(finding security.ConfigFinding) {
+	r.Findings = append(r.Findings, finding)
+
+	message := finding.Message
+	if finding.Host != "" {
+		message = fmt.Sprintf("%s: %s", finding.Host, message)
+	}
+	message = fmt.Sprintf("[%s] %s", finding.RuleID, message)
+	if security.SeverityRank(finding.Severity) >= security.SeverityRank(security.SeverityError) {
+		r.Issues = append(r.Issues, message)
+		r.IsValid = false
+	} else {
+		r.Warnings = append(r.Warnings, message)
+	}
+
+	if finding.Recommendation != "" && !containsString(r.Recommendations, finding.Recommendation) {
+		r.Recommendations = append(r.Recommendations, finding.Recommendation)
+	}
 }
 
 // HostSecurityResult represents the result of host security check
 type HostSecurityResult struct {
 	Host            string   `json:"host"`
+	Addresses       []string `json:"addresses,omitempty"`  // Resolved for CIDR and IP entries
+	AllowedBy       string   `json:"allowed_by,omitempty"` // Allowed hosts entry that matched
+	BlockedBy       string   `json:"blocked_by,omitempty"` // Blocked hosts entry that matched
 	IsSecure        bool     `json:"is_secure"`
 	Issues          []string `json:"issues"`
 	Warnings        []string `json:"warnings"`
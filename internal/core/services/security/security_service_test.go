@@ -15,8 +15,17 @@
 package security
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aryasoni98/wooak/internal/core/domain"
 	securityDomain "github.com/aryasoni98/wooak/internal/core/domain/security"
 )
 
@@ -134,3 +143,228 @@ func TestSecurityService_ValidateSSHKey_InvalidKey(t *testing.T) {
 		t.Error("Expected issues to be reported for invalid key")
 	}
 }
+
+func TestSecurityService_RecordConfigChange(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	service := NewSecurityService(securityDomain.DefaultSecurityPolicy())
+	service.RecordConfigChange("web", []domain.ServerFieldDiff{
+		{Option: "ForwardAgent", Old: "yes", New: "no"},
+		{Option: "ServerAliveInterval", Old: "", New: "60"},
+	}, "ai_assistant")
+
+	data, err := os.ReadFile(filepath.Join(home, ".wooak", "logs", "security-audit.log"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Expected one JSON entry, got %q: %v", data, err)
+	}
+	if entry["type"] != string(securityDomain.EventTypeConfigChange) || entry["host"] != "web" || entry["source"] != "ai_assistant" {
+		t.Errorf("Unexpected audit entry: %v", entry)
+	}
+	details, _ := entry["details"].(map[string]interface{})
+	changes, _ := details["changes"].([]interface{})
+	if len(changes) != 2 {
+		t.Fatalf("Expected both changes in the details, got %v", details)
+	}
+	if first, _ := changes[0].(map[string]interface{}); first["option"] != "ForwardAgent" || first["old"] != "yes" || first["new"] != "no" {
+		t.Errorf("Unexpected change detail: %v", changes[0])
+	}
+}
+
+func TestSecurityService_CheckConnection(t *testing.T) {
+	resolved := func(options ...string) domain.ResolvedConfig {
+		config := domain.ResolvedConfig{Alias: "web"}
+		for i := 0; i+1 < len(options); i += 2 {
+			config.Options = append(config.Options, domain.ResolvedOption{Key: options[i], Value: options[i+1]})
+		}
+		return config
+	}
+
+	tests := []struct {
+		name       string
+		mutate     func(p *securityDomain.SecurityPolicy)
+		resolved   domain.ResolvedConfig
+		allowed    bool
+		violations int
+	}{
+		{name: "allowed", resolved: resolved("hostname", "web.example.com", "stricthostkeychecking", "true"), allowed: true},
+		{
+			name:       "blocked host",
+			mutate:     func(p *securityDomain.SecurityPolicy) { p.BlockedHosts = []string{"web.example.com"} },
+			resolved:   resolved("hostname", "web.example.com"),
+			violations: 1,
+		},
+		{
+			name:       "not in allowed hosts",
+			mutate:     func(p *securityDomain.SecurityPolicy) { p.AllowedHosts = []string{"db.example.com"} },
+			resolved:   resolved("hostname", "web.example.com"),
+			violations: 1,
+		},
+		{
+			name:       "alias checked when unresolved",
+			mutate:     func(p *securityDomain.SecurityPolicy) { p.BlockedHosts = []string{"web"} },
+			resolved:   resolved(),
+			violations: 1,
+		},
+		{
+			name:       "host key checking off",
+			resolved:   resolved("hostname", "web.example.com", "stricthostkeychecking", "false", "userknownhostsfile", "/dev/null"),
+			violations: 2,
+		},
+		{
+			name:     "host key checking not required",
+			mutate:   func(p *securityDomain.SecurityPolicy) { p.RequireHostKeyCheck = false },
+			resolved: resolved("hostname", "web.example.com", "stricthostkeychecking", "false"),
+			allowed:  true,
+		},
+		{
+			name: "warn enforcement",
+			mutate: func(p *securityDomain.SecurityPolicy) {
+				p.BlockedHosts = []string{"web.example.com"}
+				p.Enforcement = securityDomain.EnforcementWarn
+			},
+			resolved:   resolved("hostname", "web.example.com"),
+			allowed:    true,
+			violations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			policy := securityDomain.DefaultSecurityPolicy()
+			policy.Enforcement = securityDomain.EnforcementBlock
+			if tt.mutate != nil {
+				tt.mutate(policy)
+			}
+			decision := NewSecurityService(policy).CheckConnection("web", tt.resolved)
+			if decision.Allowed != tt.allowed || len(decision.Violations) != tt.violations {
+				t.Errorf("CheckConnection() = %+v, want allowed=%v with %d violation(s)", decision, tt.allowed, tt.violations)
+			}
+		})
+	}
+}
+
+func TestSecurityService_CheckConnection_Defaults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// The default policy reports violations but neither blocks nor limits
+	// connections until the user opts in.
+	policy := securityDomain.DefaultSecurityPolicy()
+	policy.BlockedHosts = []string{"web.example.com"}
+	service := NewSecurityService(policy)
+	decision := service.CheckConnection("web", domain.ResolvedConfig{Alias: "web", Options: []domain.ResolvedOption{{Key: "hostname", Value: "web.example.com"}}})
+	if !decision.Allowed || len(decision.Violations) != 1 || decision.MaxDuration != 0 {
+		t.Errorf("CheckConnection() = %+v, want allowed with 1 violation and no time limit", decision)
+	}
+
+	policy.MaxConnectionTime = 30
+	if decision := service.CheckConnection("web", domain.ResolvedConfig{Alias: "web"}); decision.MaxDuration != 30*time.Minute {
+		t.Errorf("MaxDuration = %s, want 30m", decision.MaxDuration)
+	}
+}
+
+func TestSecurityService_CheckConnection_RecordsViolation(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	policy := securityDomain.DefaultSecurityPolicy()
+	policy.BlockedHosts = []string{"10.0.0.5"}
+	policy.Enforcement = securityDomain.EnforcementBlock
+	service := NewSecurityService(policy)
+	service.CheckConnection("db", domain.ResolvedConfig{Alias: "db", Options: []domain.ResolvedOption{{Key: "hostname", Value: "10.0.0.5"}}})
+
+	data, err := os.ReadFile(filepath.Join(home, ".wooak", "logs", "security-audit.log"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	var violation map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid audit entry %q: %v", line, err)
+		}
+		if entry["type"] == string(securityDomain.EventTypePolicyViolation) {
+			violation = entry
+		}
+	}
+	if violation == nil {
+		t.Fatalf("Expected a policy_violation event, got %s", data)
+	}
+	if violation["host"] != "10.0.0.5" || violation["result"] != "blocked" || violation["severity"] != string(securityDomain.SeverityError) {
+		t.Errorf("Unexpected violation entry: %v", violation)
+	}
+}
+
+func TestSecurityService_CheckHostSecurity_Patterns(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	policy := securityDomain.DefaultSecurityPolicy()
+	policy.AllowedHosts = []string{"*.corp.example.com", "/^lab-[0-9]+$/", "10.0.0.0/8"}
+	policy.BlockedHosts = []string{"*.corp.example.com", "!build.corp.example.com"}
+	service := NewSecurityService(policy)
+	service.lookupIP = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		if host == "db.internal" {
+			return []net.IPAddr{{IP: net.ParseIP("10.1.2.3")}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	tests := []struct {
+		host      string
+		secure    bool
+		allowedBy string
+		blockedBy string
+		issue     string
+	}{
+		{host: "build.corp.example.com", secure: true, allowedBy: "*.corp.example.com"},
+		{host: "web.corp.example.com", allowedBy: "*.corp.example.com", blockedBy: "*.corp.example.com", issue: `blocked hosts list (rule "*.corp.example.com")`},
+		{host: "LAB-7", secure: true, allowedBy: "/^lab-[0-9]+$/"},
+		{host: "db.internal", secure: true, allowedBy: "10.0.0.0/8"},
+		{host: "10.200.0.1", secure: true, allowedBy: "10.0.0.0/8"},
+		{host: "example.org", issue: "not in allowed hosts list (no rule matches)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			result := service.CheckHostSecurity(tt.host)
+			if result.IsSecure != tt.secure || result.AllowedBy != tt.allowedBy || result.BlockedBy != tt.blockedBy {
+				t.Errorf("CheckHostSecurity(%q) = %+v", tt.host, result)
+			}
+			if tt.issue != "" && (len(result.Issues) == 0 || !strings.Contains(result.Issues[0], tt.issue)) {
+				t.Errorf("issues = %q, want %q", result.Issues, tt.issue)
+			}
+		})
+	}
+
+	if result := service.CheckHostSecurity("db.internal"); len(result.Addresses) != 1 || result.Addresses[0] != "10.1.2.3" {
+		t.Errorf("addresses = %v, want the resolved address", result.Addresses)
+	}
+	if result := service.CheckHostSecurity("unknown.host"); len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "Could not resolve unknown.host") {
+		t.Errorf("warnings = %q, want the resolution failure", result.Warnings)
+	}
+}
+
+func TestSecurityService_CheckHostSecurity_Negation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	policy := securityDomain.DefaultSecurityPolicy()
+	policy.AllowedHosts = []string{"!legacy.example.com"}
+	policy.DNSTimeout = 0
+	service := NewSecurityService(policy)
+	service.lookupIP = func(context.Context, string) ([]net.IPAddr, error) {
+		t.Error("nothing should be resolved without CIDR entries")
+		return nil, nil
+	}
+
+	if result := service.CheckHostSecurity("web.example.com"); !result.IsSecure {
+		t.Errorf("web.example.com should be allowed by a list of negations: %+v", result)
+	}
+	result := service.CheckHostSecurity("legacy.example.com")
+	if result.IsSecure || len(result.Issues) != 1 || !strings.Contains(result.Issues[0], `excluded by rule "!legacy.example.com"`) {
+		t.Errorf("legacy.example.com result = %+v", result)
+	}
+}
@@ -16,6 +16,7 @@ package services
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"net"
@@ -36,18 +37,32 @@ import (
 const (
 	// DefaultPingTimeout is the default timeout for ping operations
 	DefaultPingTimeout = 3 * time.Second
+	// DefaultSSHResolveTimeout bounds a single `ssh -G` invocation
+	DefaultSSHResolveTimeout = 5 * time.Second
 )
 
 type serverService struct {
 	serverRepository ports.ServerRepository
 	logger           *zap.SugaredLogger
+	listeners        []ports.ServerChangeListener
+	policy           ports.ConnectionPolicy
 }
 
-// NewServerService creates a new instance of serverService.
-func NewServerService(logger *zap.SugaredLogger, sr ports.ServerRepository) ports.ServerService {
+// NewServerService creates a new instance of serverService. listeners are
+// notified of every server it adds, updates or deletes.
+func NewServerService(logger *zap.SugaredLogger, sr ports.ServerRepository, listeners ...ports.ServerChangeListener) ports.ServerService {
+	return NewServerServiceWithPolicy(logger, sr, nil, listeners...)
+}
+
+// NewServerServiceWithPolicy creates a serverService that checks every SSH
+// connection against policy before starting ssh and ends sessions that
+// exceed the policy's time limit. A nil policy allows every connection.
+func NewServerServiceWithPolicy(logger *zap.SugaredLogger, sr ports.ServerRepository, policy ports.ConnectionPolicy, listeners ...ports.ServerChangeListener) ports.ServerService {
 	return &serverService{
 		logger:           logger,
 		serverRepository: sr,
+		listeners:        listeners,
+		policy:           policy,
 	}
 }
 
@@ -164,6 +179,9 @@ func (s *serverService) UpdateServer(server domain.Server, newServer domain.Serv
 		s.logger.Errorw("failed to update server", "error", err, "trace_id", traceID, "old_alias", server.Alias, "new_alias", newServer.Alias)
 		return WrapError(err, errorCtx)
 	}
+	for _, l := range s.listeners {
+		l.ServerSaved(server.Alias, newServer)
+	}
 	return nil
 }
 
@@ -187,6 +205,9 @@ func (s *serverService) AddServer(server domain.Server) error {
 		s.logger.Errorw("failed to add server", "error", err, "trace_id", traceID, "alias", server.Alias, "host", server.Host)
 		return WrapError(err, errorCtx)
 	}
+	for _, l := range s.listeners {
+		l.ServerSaved("", server)
+	}
 	return nil
 }
 
@@ -203,6 +224,9 @@ func (s *serverService) DeleteServer(server domain.Server) error {
 		s.logger.Errorw("failed to delete server", "error", err, "trace_id", traceID, "alias", server.Alias)
 		return WrapError(err, errorCtx)
 	}
+	for _, l := range s.listeners {
+		l.ServerDeleted(server.Alias)
+	}
 	return nil
 }
 
@@ -217,7 +241,8 @@ func (s *serverService) SetPinned(alias string, pinned bool) error {
 }
 
 // SSH starts an interactive SSH session to the given alias using the system's ssh client.
-func (s *serverService) SSH(alias string) error {
+// Security policy warnings are logged and passed to notify when it is non-nil.
+func (s *serverService) SSH(alias string, notify ports.SSHNotifier) error {
 	ctx := context.Background()
 	traceID := tracing.GetTraceIDOrNew(ctx)
 
@@ -237,12 +262,46 @@ func (s *serverService) SSH(alias string) error {
 		return WrapSecurityError(err, errorCtx, "SSH access validation failed")
 	}
 
+	// Security policy checks against the destination ssh resolves
+	decision := s.checkConnectionPolicy(alias)
+	if !decision.Allowed {
+		s.logger.Warnw("ssh blocked by security policy", "trace_id", traceID, "alias", alias, "host", decision.Host, "violations", decision.Violations)
+		errorCtx := NewErrorContext("SSH connection").
+			WithTraceID(string(traceID)).
+			WithField("alias", alias).
+			WithField("host", decision.Host)
+		return NewSecurityError(errorCtx, "connection blocked by security policy: "+decision.Reason())
+	}
+	if len(decision.Violations) > 0 {
+		s.logger.Warnw("ssh allowed despite security policy violations", "trace_id", traceID, "alias", alias, "host", decision.Host, "violations", decision.Violations)
+		if notify != nil {
+			for _, violation := range decision.Violations {
+				notify("security policy warning: " + violation)
+			}
+		}
+	}
+
 	s.logger.Infow("ssh start", "trace_id", traceID, "alias", alias)
 	cmd := exec.Command("ssh", alias)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+	err := cmd.Start()
+	if err == nil {
+		stop := limitSession(cmd.Process, decision.MaxDuration, notify)
+		err = cmd.Wait()
+		if stop() {
+			s.logger.Warnw("ssh session reached the connection time limit", "trace_id", traceID, "alias", alias, "limit", decision.MaxDuration)
+			if recordErr := s.serverRepository.RecordSSH(alias); recordErr != nil {
+				s.logger.Errorw("failed to record ssh metadata", "trace_id", traceID, "alias", alias, "error", recordErr)
+			}
+			errorCtx := NewErrorContext("SSH connection").
+				WithTraceID(string(traceID)).
+				WithField("alias", alias)
+			return NewSecurityError(errorCtx, fmt.Sprintf("session closed after reaching the connection time limit of %s", decision.MaxDuration))
+		}
+	}
+	if err != nil {
 		s.logger.Errorw("ssh command failed", "trace_id", traceID, "alias", alias, "error", err)
 		errorCtx := NewErrorContext("SSH connection").
 			WithTraceID(string(traceID)).
@@ -311,29 +370,17 @@ func resolveSSHDestination(alias string) (string, int, bool) {
 	if alias == "" {
 		return "", 0, false
 	}
-	cmd := exec.Command("ssh", "-G", alias)
-	out, err := cmd.Output()
+	options, err := runSSHConfigDump(alias)
 	if err != nil {
 		return "", 0, false
 	}
-	host := ""
+	resolved := domain.ResolvedConfig{Alias: alias, Options: options}
+
+	host, _ := resolved.Get("hostname")
 	port := 0
-	scanner := bufio.NewScanner(strings.NewReader(string(out)))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "hostname ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				host = parts[1]
-			}
-		}
-		if strings.HasPrefix(line, "port ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				if p, err := strconv.Atoi(parts[1]); err == nil {
-					port = p
-				}
-			}
+	if value, ok := resolved.Get("port"); ok {
+		if p, err := strconv.Atoi(value); err == nil {
+			port = p
 		}
 	}
 	if host == "" {
@@ -345,6 +392,84 @@ func resolveSSHDestination(alias string) (string, int, bool) {
 	return host, port, true
 }
 
+// ResolveConfig returns the full configuration ssh computes for alias, as
+// printed by `ssh -G`. Each option is compared with `ssh -F none -G <alias>`,
+// i.e. what ssh would use without any config file, and marked as Default when
+// the value is the same.
+func (s *serverService) ResolveConfig(alias string) (domain.ResolvedConfig, error) {
+	ctx := context.Background()
+	traceID := tracing.GetTraceIDOrNew(ctx)
+	errorCtx := NewErrorContext("resolve config").
+		WithTraceID(string(traceID)).
+		WithField("alias", alias)
+
+	if !isValidAlias(alias) || strings.HasPrefix(alias, "-") {
+		return domain.ResolvedConfig{}, NewSecurityError(errorCtx, "invalid alias format: alias must contain only alphanumeric characters, dots, dashes, and underscores")
+	}
+
+	options, err := runSSHConfigDump(alias)
+	if err != nil {
+		s.logger.Errorw("ssh -G failed", "error", err, "trace_id", traceID, "alias", alias)
+		return domain.ResolvedConfig{}, WrapError(err, errorCtx)
+	}
+
+	defaults, err := runSSHConfigDump(alias, "-F", "none")
+	if err != nil {
+		// Still useful without the comparison; every option is shown as set.
+		s.logger.Warnw("ssh -F none -G failed", "error", err, "trace_id", traceID, "alias", alias)
+	}
+	markDefaults(options, defaults)
+
+	return domain.ResolvedConfig{Alias: alias, Options: options}, nil
+}
+
+// runSSHConfigDump runs `ssh [args] -G <alias>` and parses its output.
+func runSSHConfigDump(alias string, args ...string) ([]domain.ResolvedOption, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultSSHResolveTimeout)
+	defer cancel()
+
+	cmdArgs := append(append([]string{}, args...), "-G", alias)
+	out, err := exec.CommandContext(ctx, "ssh", cmdArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", strings.Join(cmdArgs, " "), err)
+	}
+	return parseSSHConfigDump(out), nil
+}
+
+// parseSSHConfigDump parses `ssh -G` output: one "key value" pair per line,
+// with multi-valued keys such as identityfile repeated.
+func parseSSHConfigDump(out []byte) []domain.ResolvedOption {
+	var options []domain.ResolvedOption
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		options = append(options, domain.ResolvedOption{
+			Key:   strings.ToLower(key),
+			Value: strings.TrimSpace(value),
+		})
+	}
+	return options
+}
+
+// markDefaults flags options whose key/value pair also appears in defaults.
+func markDefaults(options, defaults []domain.ResolvedOption) {
+	remaining := make(map[domain.ResolvedOption]int, len(defaults))
+	for _, d := range defaults {
+		remaining[d]++
+	}
+	for i := range options {
+		key := domain.ResolvedOption{Key: options[i].Key, Value: options[i].Value}
+		if remaining[key] > 0 {
+			remaining[key]--
+			options[i].Default = true
+		}
+	}
+}
+
 // isValidAlias validates that an alias is safe for SSH command execution.
 //
 // This function implements a defense-in-depth security strategy to prevent
@@ -52,6 +52,34 @@ func (m *mockRepository) RecordSSH(alias string) error {
 	return nil
 }
 
+func (m *mockRepository) GetEffectiveConfig(alias string) (domain.EffectiveConfig, error) {
+	return domain.EffectiveConfig{Alias: alias}, nil
+}
+
+func (m *mockRepository) GetEffectiveConfigs(aliases []string) (map[string]domain.EffectiveConfig, error) {
+	configs := make(map[string]domain.EffectiveConfig, len(aliases))
+	for _, alias := range aliases {
+		configs[alias] = domain.EffectiveConfig{Alias: alias}
+	}
+	return configs, nil
+}
+
+func (m *mockRepository) ListProfiles() ([]domain.Profile, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) AddProfile(profile domain.Profile) error {
+	return nil
+}
+
+func (m *mockRepository) UpdateProfile(profile domain.Profile, newProfile domain.Profile) error {
+	return nil
+}
+
+func (m *mockRepository) DeleteProfile(profile domain.Profile) error {
+	return nil
+}
+
 // BenchmarkListServers benchmarks server listing
 func BenchmarkListServers(b *testing.B) {
 	// Create mock repository with sample servers
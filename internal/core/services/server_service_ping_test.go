@@ -15,6 +15,7 @@
 package services
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -138,3 +139,59 @@ func TestResolveSSHDestination(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSSHConfigDump(t *testing.T) {
+	out := []byte("user deploy\nHostName web.example.com\n\nidentityfile ~/.ssh/a\nidentityfile ~/.ssh/b\nsendenv LANG LC_*\n")
+	want := []domain.ResolvedOption{
+		{Key: "user", Value: "deploy"},
+		{Key: "hostname", Value: "web.example.com"},
+		{Key: "identityfile", Value: "~/.ssh/a"},
+		{Key: "identityfile", Value: "~/.ssh/b"},
+		{Key: "sendenv", Value: "LANG LC_*"},
+	}
+	if got := parseSSHConfigDump(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSSHConfigDump() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMarkDefaults(t *testing.T) {
+	options := []domain.ResolvedOption{
+		{Key: "port", Value: "22"},
+		{Key: "user", Value: "deploy"},
+		{Key: "identityfile", Value: "~/.ssh/id_rsa"},
+		{Key: "identityfile", Value: "~/.ssh/id_rsa"},
+	}
+	defaults := []domain.ResolvedOption{
+		{Key: "port", Value: "22"},
+		{Key: "user", Value: "alice"},
+		{Key: "identityfile", Value: "~/.ssh/id_rsa"},
+	}
+	markDefaults(options, defaults)
+
+	got := make([]bool, 0, len(options))
+	for _, opt := range options {
+		got = append(got, opt.Default)
+	}
+	// A repeated value is only a default as many times as ssh itself repeats it.
+	if want := []bool{true, false, true, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Default flags = %v, want %v", got, want)
+	}
+
+	resolved := domain.ResolvedConfig{Options: options}
+	if len(resolved.NonDefault()) != 2 {
+		t.Errorf("Expected 2 non-default options, got %+v", resolved.NonDefault())
+	}
+}
+
+func TestServerService_ResolveConfig_InvalidAlias(t *testing.T) {
+	service := &serverService{
+		logger:           zap.NewNop().Sugar(),
+		serverRepository: &mockServerRepository{},
+	}
+
+	for _, alias := range []string{"", "-oProxyCommand", "a;b", "../x"} {
+		if _, err := service.ResolveConfig(alias); err == nil {
+			t.Errorf("Expected error for alias %q", alias)
+		}
+	}
+}
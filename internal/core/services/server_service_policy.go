@@ -0,0 +1,93 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
+	"github.com/aryasoni98/wooak/internal/core/ports"
+)
+
+// sessionWarningLead is how long before the connection time limit the user
+// is warned that the session will be closed.
+const sessionWarningLead = time.Minute
+
+// sshConfigDump resolves the configuration ssh uses for an alias; tests
+// replace it.
+var sshConfigDump = runSSHConfigDump
+
+// checkConnectionPolicy resolves alias with `ssh -G` and asks the connection
+// policy whether ssh may connect. Without a policy every connection is
+// allowed.
+func (s *serverService) checkConnectionPolicy(alias string) security.ConnectionDecision {
+	if s.policy == nil {
+		return security.ConnectionDecision{Alias: alias, Host: alias, Allowed: true}
+	}
+
+	resolved := domain.ResolvedConfig{Alias: alias}
+	options, err := sshConfigDump(alias)
+	if err != nil {
+		// ssh will most likely fail too; check the alias itself.
+		s.logger.Warnw("ssh -G failed, checking policy against the alias", "error", err, "alias", alias)
+	}
+	resolved.Options = options
+
+	return s.policy.CheckConnection(alias, resolved)
+}
+
+// limitSession terminates process once limit has passed, passing a warning
+// to notify shortly before. The returned stop function cancels both timers and
+// reports whether the limit was reached. A limit of zero does nothing.
+func limitSession(process *os.Process, limit time.Duration, notify ports.SSHNotifier) (stop func() bool) {
+	if limit <= 0 {
+		return func() bool { return false }
+	}
+
+	lead := sessionWarningLead
+	if lead >= limit {
+		lead = limit / 2
+	}
+	warn := time.AfterFunc(limit-lead, func() {
+		if notify != nil {
+			notify(fmt.Sprintf("the security policy limits connections to %s; this session will be closed in %s", limit, lead))
+		}
+	})
+	var expired atomic.Bool
+	kill := time.AfterFunc(limit, func() {
+		expired.Store(true)
+		_ = terminateProcess(process)
+	})
+
+	return func() bool {
+		warn.Stop()
+		kill.Stop()
+		return expired.Load()
+	}
+}
+
+// terminateProcess asks process to exit so ssh can restore the terminal,
+// and kills it where signals other than kill are not supported.
+func terminateProcess(process *os.Process) error {
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/domain/security"
+	"go.uber.org/zap"
+)
+
+// fakeConnectionPolicy records the config it is asked about and returns a
+// fixed decision.
+type fakeConnectionPolicy struct {
+	decision security.ConnectionDecision
+	resolved domain.ResolvedConfig
+	calls    int
+}
+
+func (p *fakeConnectionPolicy) CheckConnection(alias string, resolved domain.ResolvedConfig) security.ConnectionDecision {
+	p.calls++
+	p.resolved = resolved
+	d := p.decision
+	d.Alias = alias
+	return d
+}
+
+// stubSSHConfigDump replaces `ssh -G` for the duration of the test.
+func stubSSHConfigDump(t *testing.T, options []domain.ResolvedOption, err error) {
+	t.Helper()
+	original := sshConfigDump
+	sshConfigDump = func(string, ...string) ([]domain.ResolvedOption, error) { return options, err }
+	t.Cleanup(func() { sshConfigDump = original })
+}
+
+func TestServerService_CheckConnectionPolicy(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	stubSSHConfigDump(t, []domain.ResolvedOption{{Key: "hostname", Value: "10.0.0.5"}}, nil)
+
+	policy := &fakeConnectionPolicy{decision: security.ConnectionDecision{Allowed: true}}
+	service := NewServerServiceWithPolicy(logger.Sugar(), &mockServerRepository{}, policy).(*serverService)
+
+	if decision := service.checkConnectionPolicy("web"); !decision.Allowed || decision.Alias != "web" {
+		t.Errorf("decision = %+v", decision)
+	}
+	if host, _ := policy.resolved.Get("hostname"); host != "10.0.0.5" || policy.resolved.Alias != "web" {
+		t.Errorf("policy got resolved config %+v, want the ssh -G result", policy.resolved)
+	}
+
+	// Without a policy every connection is allowed.
+	unchecked := NewServerService(logger.Sugar(), &mockServerRepository{}).(*serverService)
+	if decision := unchecked.checkConnectionPolicy("web"); !decision.Allowed {
+		t.Errorf("decision without policy = %+v, want allowed", decision)
+	}
+}
+
+func TestServerService_SSH_BlockedByPolicy(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	stubSSHConfigDump(t, nil, errors.New("ssh -G failed"))
+
+	policy := &fakeConnectionPolicy{decision: security.ConnectionDecision{
+		Host:       "web",
+		Allowed:    false,
+		Violations: []string{"web: Host is in blocked hosts list"},
+	}}
+	repo := &mockServerRepository{servers: []domain.Server{{Alias: "web", Host: "web.example.com"}}}
+	service := NewServerServiceWithPolicy(logger.Sugar(), repo, policy)
+
+	err := service.SSH("web", nil)
+	if err == nil || !strings.Contains(err.Error(), "connection blocked by security policy: web: Host is in blocked hosts list") {
+		t.Fatalf("SSH() error = %v, want the policy reason", err)
+	}
+	if policy.calls != 1 || len(policy.resolved.Options) != 0 {
+		t.Errorf("policy calls = %d, resolved = %+v; want one check of the bare alias", policy.calls, policy.resolved)
+	}
+}
+
+// messages collects notifications sent from timer goroutines.
+type messages struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (m *messages) add(message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.list = append(m.list, message)
+}
+
+func (m *messages) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return strings.Join(m.list, "\n")
+}
+
+func TestLimitSession(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	var out messages
+	start := time.Now()
+	stop := limitSession(cmd.Process, 200*time.Millisecond, out.add)
+	_ = cmd.Wait()
+
+	if !stop() {
+		t.Error("stop() = false, want the limit to be reported as reached")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("process ran for %s, want it terminated at the limit", elapsed)
+	}
+	if !strings.Contains(out.String(), "this session will be closed in 100ms") {
+		t.Errorf("warning = %q", out.String())
+	}
+
+	// Sessions that end first are left alone.
+	quick := exec.Command("sleep", "0")
+	if err := quick.Start(); err != nil {
+		t.Fatal(err)
+	}
+	stop = limitSession(quick.Process, time.Hour, out.add)
+	if err := quick.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if stop() {
+		t.Error("stop() = true for a session that ended before the limit")
+	}
+}
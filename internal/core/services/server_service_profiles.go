@@ -0,0 +1,161 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aryasoni98/wooak/internal/core/domain"
+	"github.com/aryasoni98/wooak/internal/core/services/tracing"
+)
+
+// GetEffectiveConfig returns the merged options that apply to alias, including
+// values inherited from wildcard Host and Match blocks.
+func (s *serverService) GetEffectiveConfig(alias string) (domain.EffectiveConfig, error) {
+	ctx := context.Background()
+	traceID := tracing.GetTraceIDOrNew(ctx)
+	errorCtx := NewErrorContext("get effective config").
+		WithTraceID(string(traceID)).
+		WithField("alias", alias)
+
+	cfg, err := s.serverRepository.GetEffectiveConfig(alias)
+	if err != nil {
+		s.logger.Errorw("failed to resolve effective config", "error", err, "trace_id", traceID, "alias", alias)
+		return domain.EffectiveConfig{}, WrapError(err, errorCtx)
+	}
+	return cfg, nil
+}
+
+// GetEffectiveConfigs returns the effective config of each alias, keyed by
+// alias, reading the config files once for all of them.
+func (s *serverService) GetEffectiveConfigs(aliases []string) (map[string]domain.EffectiveConfig, error) {
+	ctx := context.Background()
+	traceID := tracing.GetTraceIDOrNew(ctx)
+	errorCtx := NewErrorContext("get effective configs").
+		WithTraceID(string(traceID)).
+		WithField("aliases", len(aliases))
+
+	configs, err := s.serverRepository.GetEffectiveConfigs(aliases)
+	if err != nil {
+		s.logger.Errorw("failed to resolve effective configs", "error", err, "trace_id", traceID, "aliases", len(aliases))
+		return nil, WrapError(err, errorCtx)
+	}
+	return configs, nil
+}
+
+// ListProfiles returns all wildcard Host blocks.
+func (s *serverService) ListProfiles() ([]domain.Profile, error) {
+	ctx := context.Background()
+	traceID := tracing.GetTraceIDOrNew(ctx)
+	errorCtx := NewErrorContext("list profiles").
+		WithTraceID(string(traceID))
+
+	profiles, err := s.serverRepository.ListProfiles()
+	if err != nil {
+		s.logger.Errorw("failed to list profiles", "error", err, "trace_id", traceID)
+		return nil, WrapError(err, errorCtx)
+	}
+	return profiles, nil
+}
+
+// validateProfile checks that a profile can be written as a Host block.
+func validateProfile(profile domain.Profile) error {
+	if len(profile.Patterns) == 0 {
+		return fmt.Errorf("at least one host pattern is required")
+	}
+	wildcard := false
+	for _, p := range profile.Patterns {
+		if p == "" || p == "!" || strings.ContainsAny(p, " \t\"#") {
+			return fmt.Errorf("invalid host pattern %q", p)
+		}
+		// Same wildcard characters as the repository uses to tell profiles
+		// from servers.
+		if strings.ContainsAny(p, "!*?[]") {
+			wildcard = true
+		}
+	}
+	if !wildcard {
+		return fmt.Errorf("profile patterns must contain a wildcard or negation; add a server instead")
+	}
+	for _, opt := range profile.Options {
+		key := strings.TrimSpace(opt.Key)
+		if key == "" || strings.ContainsAny(key, " \t=") {
+			return fmt.Errorf("invalid option name %q", opt.Key)
+		}
+		switch strings.ToLower(key) {
+		case "host", "match", "include":
+			return fmt.Errorf("%s cannot be used as a profile option", key)
+		}
+	}
+	return nil
+}
+
+// AddProfile adds a new wildcard Host block.
+func (s *serverService) AddProfile(profile domain.Profile) error {
+	ctx := context.Background()
+	traceID := tracing.GetTraceIDOrNew(ctx)
+	errorCtx := NewErrorContext("add profile").
+		WithTraceID(string(traceID)).
+		WithField("patterns", profile.Name())
+
+	if err := validateProfile(profile); err != nil {
+		s.logger.Warnw("validation failed on profile add", "error", err, "trace_id", traceID, "patterns", profile.Name())
+		return WrapErrorf(err, errorCtx, "validation failed for profile")
+	}
+	if err := s.serverRepository.AddProfile(profile); err != nil {
+		s.logger.Errorw("failed to add profile", "error", err, "trace_id", traceID, "patterns", profile.Name())
+		return WrapError(err, errorCtx)
+	}
+	return nil
+}
+
+// UpdateProfile replaces the patterns and options of an existing profile.
+func (s *serverService) UpdateProfile(profile domain.Profile, newProfile domain.Profile) error {
+	ctx := context.Background()
+	traceID := tracing.GetTraceIDOrNew(ctx)
+	errorCtx := NewErrorContext("update profile").
+		WithTraceID(string(traceID)).
+		WithFields(map[string]interface{}{
+			"old_patterns": profile.Name(),
+			"new_patterns": newProfile.Name(),
+		})
+
+	if err := validateProfile(newProfile); err != nil {
+		s.logger.Warnw("validation failed on profile update", "error", err, "trace_id", traceID, "patterns", profile.Name())
+		return WrapErrorf(err, errorCtx, "validation failed for profile update")
+	}
+	if err := s.serverRepository.UpdateProfile(profile, newProfile); err != nil {
+		s.logger.Errorw("failed to update profile", "error", err, "trace_id", traceID, "patterns", profile.Name())
+		return WrapError(err, errorCtx)
+	}
+	return nil
+}
+
+// DeleteProfile removes a wildcard Host block.
+func (s *serverService) DeleteProfile(profile domain.Profile) error {
+	ctx := context.Background()
+	traceID := tracing.GetTraceIDOrNew(ctx)
+	errorCtx := NewErrorContext("delete profile").
+		WithTraceID(string(traceID)).
+		WithField("patterns", profile.Name())
+
+	if err := s.serverRepository.DeleteProfile(profile); err != nil {
+		s.logger.Errorw("failed to delete profile", "error", err, "trace_id", traceID, "patterns", profile.Name())
+		return WrapError(err, errorCtx)
+	}
+	return nil
+}
@@ -15,6 +15,7 @@
 package services
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/aryasoni98/wooak/internal/core/domain"
@@ -51,6 +52,34 @@ func (m *mockServerRepository) RecordSSH(alias string) error {
 	return m.err
 }
 
+func (m *mockServerRepository) GetEffectiveConfig(alias string) (domain.EffectiveConfig, error) {
+	return domain.EffectiveConfig{Alias: alias}, m.err
+}
+
+func (m *mockServerRepository) GetEffectiveConfigs(aliases []string) (map[string]domain.EffectiveConfig, error) {
+	configs := make(map[string]domain.EffectiveConfig, len(aliases))
+	for _, alias := range aliases {
+		configs[alias] = domain.EffectiveConfig{Alias: alias}
+	}
+	return configs, m.err
+}
+
+func (m *mockServerRepository) ListProfiles() ([]domain.Profile, error) {
+	return nil, m.err
+}
+
+func (m *mockServerRepository) AddProfile(profile domain.Profile) error {
+	return m.err
+}
+
+func (m *mockServerRepository) UpdateProfile(profile domain.Profile, newProfile domain.Profile) error {
+	return m.err
+}
+
+func (m *mockServerRepository) DeleteProfile(profile domain.Profile) error {
+	return m.err
+}
+
 func TestIsValidAlias(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -315,7 +344,7 @@ func TestServerService_SSH_Validation(t *testing.T) {
 				serverRepository: mockRepo,
 			}
 
-			err := service.SSH(tt.alias)
+			err := service.SSH(tt.alias, nil)
 
 			if tt.expectError {
 				if err == nil {
@@ -666,3 +695,115 @@ func TestServerService_SetPinned(t *testing.T) {
 		})
 	}
 }
+
+// TestServerService_AddProfile tests profile validation before it reaches the repository
+func TestServerService_AddProfile(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	tests := []struct {
+		name        string
+		profile     domain.Profile
+		repoErr     error
+		expectError bool
+	}{
+		{
+			name: "valid profile",
+			profile: domain.Profile{
+				Patterns: []string{"*.prod", "!bastion.prod"},
+				Options:  []domain.ConfigOption{{Key: "User", Value: "deploy"}},
+			},
+		},
+		{
+			name:        "no patterns",
+			profile:     domain.Profile{},
+			expectError: true,
+		},
+		{
+			name:        "concrete alias",
+			profile:     domain.Profile{Patterns: []string{"web"}},
+			expectError: true,
+		},
+		{
+			name:    "character class",
+			profile: domain.Profile{Patterns: []string{"web[0-9]"}},
+		},
+		{
+			name:        "pattern with space",
+			profile:     domain.Profile{Patterns: []string{"* x"}},
+			expectError: true,
+		},
+		{
+			name: "nested block keyword",
+			profile: domain.Profile{
+				Patterns: []string{"*"},
+				Options:  []domain.ConfigOption{{Key: "Match", Value: "all"}},
+			},
+			expectError: true,
+		},
+		{
+			name:        "repository error",
+			profile:     domain.Profile{Patterns: []string{"*"}},
+			repoErr:     &MockError{message: "repository error"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &serverService{
+				logger:           logger.Sugar(),
+				serverRepository: &mockServerRepository{err: tt.repoErr},
+			}
+
+			err := service.AddProfile(tt.profile)
+			if tt.expectError && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// recordingListener records the changes a ServerChangeListener is told about.
+type recordingListener struct {
+	events []string
+}
+
+func (l *recordingListener) ServerSaved(previousAlias string, server domain.Server) {
+	l.events = append(l.events, "saved "+previousAlias+"->"+server.Alias)
+}
+
+func (l *recordingListener) ServerDeleted(alias string) {
+	l.events = append(l.events, "deleted "+alias)
+}
+
+// TestServerService_ChangeListeners tests that listeners hear about successful
+// changes only
+func TestServerService_ChangeListeners(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	listener := &recordingListener{}
+	repo := &mockServerRepository{}
+	service := NewServerService(logger.Sugar(), repo, listener)
+
+	server := domain.Server{Alias: "web", Host: "10.0.0.1"}
+	renamed := domain.Server{Alias: "web-1", Host: "10.0.0.1"}
+	if err := service.AddServer(server); err != nil {
+		t.Fatalf("AddServer failed: %v", err)
+	}
+	if err := service.UpdateServer(server, renamed); err != nil {
+		t.Fatalf("UpdateServer failed: %v", err)
+	}
+	if err := service.DeleteServer(renamed); err != nil {
+		t.Fatalf("DeleteServer failed: %v", err)
+	}
+
+	repo.err = &MockError{message: "repository error"}
+	_ = service.DeleteServer(renamed)
+
+	want := []string{"saved ->web", "saved web->web-1", "deleted web-1"}
+	if strings.Join(listener.events, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected events %q, want %q", listener.events, want)
+	}
+}